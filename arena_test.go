@@ -0,0 +1,108 @@
+package yjson
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestArena(t *testing.T) {
+	tests := []parseTest{
+		{`1`, `1`},
+		{`{"a": [1, "x", null], "b": {"c": true}}`, `{"a":[1,"x",null],"b":{"c":true}}`},
+		{"[" + strings.Repeat("[0],", ARENA_SLAB_SIZE) + "1]", "[" + strings.Repeat("[0],", ARENA_SLAB_SIZE) + "1]"},
+		{`[1`, "error: EOF"},
+	}
+	a := NewArena()
+	var values []*Value
+	for _, tt := range tests {
+		v, err := a.Parse([]byte(tt.input))
+		if msg, ok := strings.CutPrefix(tt.want, "error: "); ok {
+			if err == nil || err.Error() != msg {
+				t.Errorf("%.20q: got error %v, want %s", tt.input, err, msg)
+			}
+			values = append(values, nil)
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%.20q: %v", tt.input, err)
+		}
+		values = append(values, v)
+	}
+	// 同一个 Arena 中之后的解析不会覆盖之前的值
+	for i, v := range values {
+		if v != nil && mustEncode(t, v) != tests[i].want {
+			t.Errorf("%.20q: got %.40s", tests[i].input, mustEncode(t, v))
+		}
+	}
+	if len(a.slabs) < 2 {
+		t.Errorf("got %d slabs, want at least 2", len(a.slabs))
+	}
+
+	// Release 后 slab 被清零并复用, 不再分配新的 slab
+	slabs := len(a.slabs)
+	a.Release()
+	for i := range a.slabs {
+		for j := range a.slabs[i] {
+			if !reflect.ValueOf(a.slabs[i][j]).IsZero() {
+				t.Fatalf("slab %d not cleared", i)
+			}
+		}
+	}
+	for i := 0; i < 3; i++ {
+		v, err := a.Parse([]byte(tests[2].input))
+		if err != nil || mustEncode(t, v) != tests[2].want {
+			t.Fatalf("after release: %v", err)
+		}
+		a.Release()
+	}
+	if len(a.slabs) != slabs {
+		t.Errorf("got %d slabs after release, want %d", len(a.slabs), slabs)
+	}
+}
+
+func TestArenaOptions(t *testing.T) {
+	a := NewArena()
+	v, err := a.ParseWithOptions([]byte(`{a: [1,], /* c */ b: 'x'}`), ParseOptions{JSON5: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mustEncode(t, v); got != `{"a":[1],"b":"x"}` {
+		t.Errorf("got %s", got)
+	}
+
+	// 修改得到的值时新节点在堆上分配, 不占用 Arena
+	n := a.n
+	if err := v.Set("c", NewInt(2)); err != nil {
+		t.Fatal(err)
+	}
+	if a.n != n || mustEncode(t, v) != `{"a":[1],"b":"x","c":2}` {
+		t.Errorf("after Set: %d nodes, got %s", a.n, mustEncode(t, v))
+	}
+
+	// 为 nil 的 Arena 使用堆分配
+	var nilArena *Arena
+	if nilArena.alloc() == nil {
+		t.Errorf("nil arena returned nil")
+	}
+}
+
+// 复用 Arena 时解析几乎不再为节点分配内存
+func TestArenaAllocs(t *testing.T) {
+	data := []byte(`[` + strings.Repeat(`[1, true, null],`, 100) + `0]`)
+	a := NewArena()
+	arena := testing.AllocsPerRun(20, func() {
+		if _, err := a.Parse(data); err != nil {
+			t.Fatal(err)
+		}
+		a.Release()
+	})
+	heap := testing.AllocsPerRun(20, func() {
+		if _, err := Parse(data); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if arena >= heap/2 {
+		t.Errorf("arena: %v allocs per parse, heap: %v", arena, heap)
+	}
+}
@@ -0,0 +1,68 @@
+package yjson
+
+import "fmt"
+
+// 数组的元素个数或对象的成员个数, 其他类型返回 0
+func (j *Value) Len() int {
+	if j.load() != nil {
+		return 0
+	}
+	switch j.Type() {
+	case JSON_ARRAY:
+		return len(j.arr)
+	case JSON_OBJECT:
+		return len(j.obj)
+	}
+	return 0
+}
+
+func (j *Value) Append(items ...*Value) error {
+	if err := j.expectKind(JSON_ARRAY); err != nil {
+		return err
+	}
+	if err := j.load(); err != nil {
+		return err
+	}
+	j.arr = append(j.arr, items...)
+	return nil
+}
+
+// 在下标 i 处插入 v, i 等于长度时追加
+func (j *Value) InsertAt(i int, v *Value) error {
+	if err := j.expectKind(JSON_ARRAY); err != nil {
+		return err
+	}
+	if err := j.load(); err != nil {
+		return err
+	}
+
+	arr := j.arr
+	if i < 0 || i > len(arr) {
+		return fmt.Errorf("index %d out of range, array length is %d", i, len(arr))
+	}
+	arr = append(arr, nil)
+	copy(arr[i+1:], arr[i:])
+	arr[i] = v
+	j.arr = arr
+	return nil
+}
+
+// 删除并返回下标 i 处的元素
+func (j *Value) RemoveAt(i int) (*Value, error) {
+	if err := j.expectKind(JSON_ARRAY); err != nil {
+		return nil, err
+	}
+	if err := j.load(); err != nil {
+		return nil, err
+	}
+
+	arr := j.arr
+	if i < 0 || i >= len(arr) {
+		return nil, fmt.Errorf("index %d out of range, array length is %d", i, len(arr))
+	}
+	v := arr[i]
+	copy(arr[i:], arr[i+1:])
+	arr[len(arr)-1] = nil
+	j.arr = arr[:len(arr)-1]
+	return v, nil
+}
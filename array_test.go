@@ -0,0 +1,84 @@
+package yjson
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestArrayHelpers(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		op   func(v *Value) error
+		want string
+	}{
+		{"append", `[1]`, func(v *Value) error { return v.Append(NewInt(2), NewString("x")) }, `[1,2,"x"]`},
+		{"append none", `[]`, func(v *Value) error { return v.Append() }, `[]`},
+		{"insert front", `[2,3]`, func(v *Value) error { return v.InsertAt(0, NewInt(1)) }, `[1,2,3]`},
+		{"insert middle", `[1,3]`, func(v *Value) error { return v.InsertAt(1, NewInt(2)) }, `[1,2,3]`},
+		{"insert end", `[1]`, func(v *Value) error { return v.InsertAt(1, NewInt(2)) }, `[1,2]`},
+		{"insert out of range", `[1]`, func(v *Value) error { return v.InsertAt(2, NewInt(2)) }, `error: index 2 out of range, array length is 1`},
+		{"insert negative", `[]`, func(v *Value) error { return v.InsertAt(-1, NewInt(2)) }, `error: index -1 out of range, array length is 0`},
+		{"remove", `[1,2,3]`, func(v *Value) error { _, err := v.RemoveAt(1); return err }, `[1,3]`},
+		{"remove last", `[1]`, func(v *Value) error { _, err := v.RemoveAt(0); return err }, `[]`},
+		{"remove out of range", `[1]`, func(v *Value) error { _, err := v.RemoveAt(1); return err }, `error: index 1 out of range, array length is 1`},
+		{"append to object", `{}`, func(v *Value) error { return v.Append(NewInt(1)) }, `error: expect array, but get object`},
+		{"insert into string", `"s"`, func(v *Value) error { return v.InsertAt(0, NewInt(1)) }, `error: expect array, but get string`},
+		{"remove from null", `null`, func(v *Value) error { _, err := v.RemoveAt(0); return err }, `error: expect array, but get null`},
+	}
+	for _, tt := range tests {
+		v := mustParse(t, tt.doc)
+		err := tt.op(v)
+		if msg, ok := strings.CutPrefix(tt.want, "error: "); ok {
+			if err == nil || err.Error() != msg {
+				t.Errorf("%s: got error %v, want %s", tt.name, err, msg)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := mustEncode(t, v); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	v := mustParse(t, `["a","b"]`)
+	removed, err := v.RemoveAt(0)
+	if err != nil || removed.MustString() != "a" {
+		t.Errorf("RemoveAt: got %v, %v", removed, err)
+	}
+
+	// Lazy 模式下没有展开的数组先展开
+	lazy, err := ParseWithOptions([]byte(`{"a":[1,2]}`), ParseOptions{Lazy: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lazy.Get("a").Append(NewInt(3)); err != nil || mustEncode(t, lazy) != `{"a":[1,2,3]}` {
+		t.Errorf("lazy: got %s, %v", mustEncode(t, lazy), err)
+	}
+}
+
+func TestLen(t *testing.T) {
+	tests := []struct {
+		doc  string
+		want int
+	}{
+		{`[]`, 0},
+		{`[1,[2,3],{}]`, 3},
+		{`{"a":1,"b":2}`, 2},
+		{`"abc"`, 0},
+		{`7`, 0},
+		{`null`, 0},
+	}
+	for _, tt := range tests {
+		if got := mustParse(t, tt.doc).Len(); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.doc, got, tt.want)
+		}
+	}
+	var missing *Value
+	if missing.Len() != 0 {
+		t.Errorf("nil: got %d", missing.Len())
+	}
+}
@@ -0,0 +1,77 @@
+package yjson
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+)
+
+// encoding 标签的取值, 默认 base64, 与 encoding/json 相同
+var byteEncodings = map[string]*base64.Encoding{
+	"":             base64.StdEncoding,
+	"base64":       base64.StdEncoding,
+	"base64url":    base64.URLEncoding,
+	"base64raw":    base64.RawStdEncoding,
+	"base64rawurl": base64.RawURLEncoding,
+}
+
+// []byte 按字符串编码, 元素类型自己实现了序列化接口时除外
+func isByteSlice(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uint8 {
+		return false
+	}
+	return !implementsMarshaler(t.Elem()) && !implementsMarshaler(reflect.PointerTo(t.Elem()))
+}
+
+func encodeBytes(b []byte, encoding string) (string, error) {
+	if encoding == "hex" {
+		return hex.EncodeToString(b), nil
+	}
+	enc, ok := byteEncodings[encoding]
+	if !ok {
+		return "", fmt.Errorf("unknown bytes encoding %q", encoding)
+	}
+	return enc.EncodeToString(b), nil
+}
+
+func decodeBytes(s, encoding string) ([]byte, error) {
+	if encoding == "hex" {
+		return hex.DecodeString(s)
+	}
+	enc, ok := byteEncodings[encoding]
+	if !ok {
+		return nil, fmt.Errorf("unknown bytes encoding %q", encoding)
+	}
+	return enc.DecodeString(s)
+}
+
+func marshalBytes(path string, rv reflect.Value, encoding string) (*Value, error) {
+	if rv.IsNil() {
+		return NewNull(), nil
+	}
+	s, err := encodeBytes(rv.Bytes(), encoding)
+	if err != nil {
+		if path == "" {
+			return nil, fmt.Errorf("marshal: %v", err)
+		}
+		return nil, fmt.Errorf("marshal: %v at %s", err, path)
+	}
+	return NewString(s), nil
+}
+
+// 字符串按 encoding 解码. 数组仍按逐个元素处理
+func (d *decodeState) decodeBytes(path string, j *Value, rv reflect.Value, encoding string) error {
+	if j.Type() != JSON_STRING {
+		return d.decodeValue(path, j, rv)
+	}
+	b, err := decodeBytes(j.str, encoding)
+	if err != nil {
+		if path == "" {
+			return fmt.Errorf("unmarshal: %v", err)
+		}
+		return fmt.Errorf("unmarshal: %v at %s", err, path)
+	}
+	rv.SetBytes(b)
+	return nil
+}
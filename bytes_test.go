@@ -0,0 +1,104 @@
+package yjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+type byteFields struct {
+	Std    []byte `json:"std"`
+	URL    []byte `json:"url" encoding:"base64url"`
+	Raw    []byte `json:"raw" encoding:"base64raw"`
+	RawURL []byte `json:"raw_url" encoding:"base64rawurl"`
+	Hex    []byte `json:"hex" encoding:"hex"`
+}
+
+func TestBytes(t *testing.T) {
+	data := []byte{0xfb, 0xff, 0x01}
+	v := byteFields{Std: data, URL: data, Raw: data, RawURL: data, Hex: data}
+	got, err := Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"std":"+/8B","url":"-_8B","raw":"+/8B","raw_url":"-_8B","hex":"fbff01"}`
+	if string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	var back byteFields
+	if err := Unmarshal(got, &back); err != nil {
+		t.Fatal(err)
+	}
+	for name, b := range map[string][]byte{"std": back.Std, "url": back.URL, "raw": back.Raw, "raw_url": back.RawURL, "hex": back.Hex} {
+		if !bytes.Equal(b, data) {
+			t.Errorf("%s: got %x", name, b)
+		}
+	}
+
+	// 填充的差别
+	got, _ = Marshal(byteFields{Std: []byte("a"), Raw: []byte("a")})
+	if want := `{"std":"YQ==","url":null,"raw":"YQ","raw_url":null,"hex":null}`; string(got) != want {
+		t.Errorf("padding: got %s, want %s", got, want)
+	}
+}
+
+// 没有标签时与 encoding/json 相同
+func TestBytesMatchesEncodingJSON(t *testing.T) {
+	type named []byte
+	tests := []interface{}{
+		[]byte(nil),
+		[]byte{},
+		[]byte("hello, world"),
+		named("x"),
+		[]named{named("a"), nil},
+		map[string][]byte{"k": {0}},
+		[2]byte{1, 2},
+	}
+	for _, v := range tests {
+		got, err := Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if std, _ := json.Marshal(v); string(got) != string(std) {
+			t.Errorf("%T: got %s, encoding/json %s", v, got, std)
+		}
+	}
+
+	// 数组形式的输入仍按元素解码
+	var b []byte
+	if err := Unmarshal([]byte(`[1,2,255]`), &b); err != nil || !bytes.Equal(b, []byte{1, 2, 255}) {
+		t.Errorf("array input: got %v, %v", b, err)
+	}
+	if err := Unmarshal([]byte(`null`), &b); err != nil || b != nil {
+		t.Errorf("null: got %v, %v", b, err)
+	}
+}
+
+func TestBytesErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"std":"***"}`, `unmarshal: illegal base64 data at input byte 0 at std`},
+		{`{"raw":"YQ=="}`, `unmarshal: illegal base64 data at input byte 2 at raw`},
+		{`{"hex":"zz"}`, `unmarshal: encoding/hex: invalid byte: U+007A 'z' at hex`},
+		{`{"std":[256]}`, `unmarshal: number 256 overflows uint8 at std[0]`},
+	}
+	for _, tt := range tests {
+		var v byteFields
+		err := Unmarshal([]byte(tt.input), &v)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: got %v, want %s", tt.input, err, tt.want)
+		}
+	}
+
+	bad := struct {
+		B []byte `json:"b" encoding:"base32"`
+	}{[]byte("x")}
+	if _, err := Marshal(bad); err == nil || err.Error() != `marshal: unknown bytes encoding "base32" at b` {
+		t.Errorf("marshal: got %v", err)
+	}
+	if err := Unmarshal([]byte(`{"b":"x"}`), &bad); err == nil || err.Error() != `unmarshal: unknown bytes encoding "base32" at b` {
+		t.Errorf("unmarshal: got %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package yjson
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+)
+
+// RFC 8785 (JCS) 规范化输出: 键按 UTF-16 码元排序, 数字按 ES6 规则格式化,
+// 字符串最少转义, 没有空白
+func (j *Value) Canonical() ([]byte, error) {
+	return j.EncodeWithOptions(EncodeOptions{Canonical: true})
+}
+
+// 按 UTF-16 码元比较, JCS 的键排序规则
+func lessUTF16(a, b string) bool {
+	ua := utf16.Encode([]rune(a))
+	ub := utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
+
+func sortKeysUTF16(keys []string) {
+	sort.Slice(keys, func(i, k int) bool {
+		return lessUTF16(keys[i], keys[k])
+	})
+}
+
+// 所有数字都按 IEEE 754 双精度解释
+func canonicalFloat(n interface{}) (float64, error) {
+	switch n := n.(type) {
+	case int64:
+		return float64(n), nil
+	case uint64:
+		return float64(n), nil
+	case float64:
+		return n, nil
+	case Number:
+		return n.Float64()
+	case *big.Int:
+		f, _ := new(big.Float).SetInt(n).Float64()
+		return f, nil
+	case *big.Float:
+		f, _ := n.Float64()
+		return f, nil
+	case fmt.Stringer:
+		return Number(n.String()).Float64()
+	}
+	return 0, fmt.Errorf("cannot encode number of type %T", n)
+}
+
+// ECMAScript Number.prototype.toString
+func appendES6Number(dst []byte, f float64) ([]byte, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return dst, fmt.Errorf("cannot canonicalize %v", f)
+	}
+	if f == 0 {
+		return append(dst, '0'), nil
+	}
+	if f < 0 {
+		dst = append(dst, MINUS)
+		f = -f
+	}
+
+	// d.ddddde±XX => 有效数字 digits, 小数点位置 n
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	e := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == 'e' {
+			e, _ = strconv.Atoi(s[i+1:])
+			s = s[:i]
+			break
+		}
+	}
+	digits := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != DECIMAL_POINT {
+			digits = append(digits, s[i])
+		}
+	}
+	k := len(digits)
+	n := e + 1
+
+	switch {
+	case k <= n && n <= 21:
+		dst = append(dst, digits...)
+		for i := 0; i < n-k; i++ {
+			dst = append(dst, '0')
+		}
+	case 0 < n && n <= 21:
+		dst = append(dst, digits[:n]...)
+		dst = append(dst, DECIMAL_POINT)
+		dst = append(dst, digits[n:]...)
+	case -6 < n && n <= 0:
+		dst = append(dst, '0', DECIMAL_POINT)
+		for i := 0; i < -n; i++ {
+			dst = append(dst, '0')
+		}
+		dst = append(dst, digits...)
+	default:
+		dst = append(dst, digits[0])
+		if k > 1 {
+			dst = append(dst, DECIMAL_POINT)
+			dst = append(dst, digits[1:]...)
+		}
+		dst = append(dst, 'e')
+		if n-1 >= 0 {
+			dst = append(dst, PLUS)
+		}
+		dst = strconv.AppendInt(dst, int64(n-1), 10)
+	}
+	return dst, nil
+}
@@ -0,0 +1,96 @@
+package yjson
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// RFC 8785 附录 B 的数字示例
+func TestCanonicalNumbers(t *testing.T) {
+	tests := []struct {
+		bits uint64
+		want string
+	}{
+		{0x0000000000000000, "0"},
+		{0x8000000000000000, "0"},
+		{0x0000000000000001, "5e-324"},
+		{0x8000000000000001, "-5e-324"},
+		{0x7fefffffffffffff, "1.7976931348623157e+308"},
+		{0xffefffffffffffff, "-1.7976931348623157e+308"},
+		{0x4340000000000000, "9007199254740992"},
+		{0xc340000000000000, "-9007199254740992"},
+		{0x4430000000000000, "295147905179352830000"},
+		{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+		{0x44b52d02c7e14af6, "1e+23"},
+		{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+		{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+		{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+		{0x444b1ae4d6e2ef50, "1e+21"},
+		{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+		{0x3eb0c6f7a0b5ed8d, "0.000001"},
+		{0x41b3de4355555553, "333333333.3333332"},
+		{0x41b3de4355555554, "333333333.33333325"},
+		{0x41b3de4355555555, "333333333.3333333"},
+		{0x41b3de4355555556, "333333333.3333334"},
+		{0x41b3de4355555557, "333333333.33333343"},
+		{0xbecbf647612f3696, "-0.0000033333333333333333"},
+		{0x43143ff3c1cb0959, "1424953923781206.2"},
+	}
+	for _, tt := range tests {
+		got, err := NewFloat(math.Float64frombits(tt.bits)).Canonical()
+		if err != nil || string(got) != tt.want {
+			t.Errorf("%#016x: got %s, %v, want %s", tt.bits, got, err, tt.want)
+		}
+	}
+
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, err := NewFloat(f).Canonical(); err == nil {
+			t.Errorf("%v: expected an error", f)
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		input string
+		opts  ParseOptions
+		want  string
+	}{
+		{` { "b" : [ 1.50 , 1E2, -0 ] , "a" : "x" } `, ParseOptions{}, `{"a":"x","b":[1.5,100,0]}`},
+		{`"\u20ac\u0001\n\/<>"`, ParseOptions{}, `"€\u0001\n/<>"`},
+		{`123456789012345678901234567890`, ParseOptions{UseBigNumbers: true}, `1.2345678901234568e+29`},
+		{`[1.0, 0x10]`, ParseOptions{UseRawNumbers: true, AllowHexNumbers: true}, `[1,16]`},
+		{`{"a":1}`, ParseOptions{Lazy: true}, `{"a":1}`},
+	}
+	for _, tt := range tests {
+		v, err := ParseWithOptions([]byte(tt.input), tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := v.Canonical()
+		if err != nil || string(got) != tt.want {
+			t.Errorf("%s: got %s, %v, want %s", tt.input, got, err, tt.want)
+		}
+	}
+
+	// 开启 Canonical 时忽略其他选项
+	v := mustParse(t, `{"b":"<","a":[1]}`)
+	got, _ := v.EncodeWithOptions(EncodeOptions{Canonical: true, Indent: "  ", EscapeHTML: true, EscapeNonASCII: true, Colors: &DefaultColors})
+	if string(got) != `{"a":[1],"b":"<"}` {
+		t.Errorf("options not ignored: got %s", got)
+	}
+}
+
+// RFC 8785 3.2.3 的键排序示例, 按 UTF-16 码元而不是码点排序
+func TestCanonicalKeyOrder(t *testing.T) {
+	input := `{"\u20ac":1,"\r":2,"\ufb33":3,"1":4,"\ud83d\ude00":5,"\u0080":6,"\u00f6":7}`
+	got, err := mustParse(t, input).Canonical()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"\r", "1", "\u0080", "\u00f6", "\u20ac", "\U0001f600", "\ufb33"}
+	if keys := mustParse(t, string(got)).Keys(); !reflect.DeepEqual(keys, want) {
+		t.Errorf("got %q, want %q", keys, want)
+	}
+}
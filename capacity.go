@@ -0,0 +1,30 @@
+package yjson
+
+// AdaptiveCapacity 记住的容量上限, 避免一个特别大的容器让之后的同层容器都预分配过多
+const MAX_CAPACITY_HINT = 1 << 16
+
+// 当前深度新建对象或数组时的初始容量. AdaptiveCapacity 开启且这一层已经解析过
+// 同类容器时使用上一个的大小, 否则使用选项中的固定值
+func (p *Parser) capacityHint(hint int, learned []int) int {
+	if p.opts.AdaptiveCapacity && p.depth < len(learned) && learned[p.depth] > 0 {
+		return learned[p.depth]
+	}
+	if hint < 0 {
+		return 0
+	}
+	return hint
+}
+
+// 记录当前深度刚解析完的容器大小
+func (p *Parser) learnCapacity(learned *[]int, n int) {
+	if !p.opts.AdaptiveCapacity {
+		return
+	}
+	if n > MAX_CAPACITY_HINT {
+		n = MAX_CAPACITY_HINT
+	}
+	for len(*learned) <= p.depth {
+		*learned = append(*learned, 0)
+	}
+	(*learned)[p.depth] = n
+}
@@ -0,0 +1,77 @@
+package yjson
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCapacityHints(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		opts   ParseOptions
+		path   string
+		arrCap int
+		keyCap int
+	}{
+		{"default", `[1, 2]`, ParseOptions{}, "", 2, 0},
+		{"array capacity", `[1, 2]`, ParseOptions{ArrayCapacity: 16}, "", 16, 0},
+		{"object capacity", `{"a": 1}`, ParseOptions{ObjectCapacity: 8}, "", 0, 8},
+		{"negative", `[1]`, ParseOptions{ArrayCapacity: -1}, "", 1, 0},
+		{"nested uses fixed hint", `[[1], [2]]`, ParseOptions{ArrayCapacity: 4}, "[1]", 4, 0},
+		// 同一深度上一个容器的大小, 优先于固定值
+		{"adaptive arrays", `[[1, 2, 3, 4, 5], [1, 2, 3, 4, 5], [1]]`, ParseOptions{AdaptiveCapacity: true, ArrayCapacity: 2}, "[2]", 5, 0},
+		{"adaptive objects", `[{"a": 1, "b": 2, "c": 3}, {"a": 1}]`, ParseOptions{AdaptiveCapacity: true}, "[1]", 0, 3},
+		{"empty does not reset", `[[1, 2, 3], [], [1]]`, ParseOptions{AdaptiveCapacity: true}, "[2]", 3, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := ParseWithOptions([]byte(tt.input), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.path != "" {
+				v = v.Get(tt.path)
+			}
+			if cap(v.arr) != tt.arrCap || cap(v.keys) != tt.keyCap {
+				t.Errorf("got cap %d/%d, want %d/%d", cap(v.arr), cap(v.keys), tt.arrCap, tt.keyCap)
+			}
+		})
+	}
+}
+
+// 复用 Parser 时学到的大小保留到下一次解析, 但不超过 MAX_CAPACITY_HINT
+func TestAdaptiveCapacityAcrossParses(t *testing.T) {
+	p := NewParser([]byte(`{"items": [1, 2, 3, 4, 5, 6]}`))
+	p.SetOptions(ParseOptions{AdaptiveCapacity: true})
+	if _, err := p.Parse(); err != nil {
+		t.Fatal(err)
+	}
+	p.Reset([]byte(`{"items": [1]}`))
+	v, err := p.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cap(v.Get("items").arr); got != 6 {
+		t.Errorf("got cap %d, want 6", got)
+	}
+
+	big := "[" + strings.TrimSuffix(strings.Repeat("0,", MAX_CAPACITY_HINT+10), ",") + "]"
+	p.Reset([]byte(fmt.Sprintf(`{"items": %s}`, big)))
+	if _, err := p.Parse(); err != nil {
+		t.Fatal(err)
+	}
+	p.Reset([]byte(`{"items": []}`))
+	v, _ = p.Parse()
+	if got := cap(v.Get("items").arr); got != MAX_CAPACITY_HINT {
+		t.Errorf("got cap %d, want %d", got, MAX_CAPACITY_HINT)
+	}
+
+	// 结果与不使用提示时相同
+	input := `[{"a": [1, 2], "b": {}}, {"a": [], "b": {"c": 1}}, {"a": [3]}]`
+	v, _ = ParseWithOptions([]byte(input), ParseOptions{AdaptiveCapacity: true})
+	if got, want := mustEncode(t, v), mustEncode(t, mustParse(t, input)); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Yohox/yjson"
+)
+
+// 用法: yjson gen [-package name] [-type name] [-tag key] [-lines] [file ...].
+// 文件中每个文档是一个示例, 多个文档首尾相连或以空白分隔, -lines 时每行一个;
+// 所有示例合并后生成 Go 类型定义, 输出到标准输出
+func gen(args []string) int {
+	fs := flag.NewFlagSet("gen", flag.ExitOnError)
+	pkg := fs.String("package", "main", "package name of the generated code")
+	name := fs.String("type", "Root", "name of the top-level type")
+	tag := fs.String("tag", "json", "struct tag key")
+	lines := fs.Bool("lines", false, "read each line as a separate sample")
+	fs.Parse(args)
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	var samples []*yjson.Value
+	for _, file := range files {
+		vs, err := readSamples(file, *lines)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			return 1
+		}
+		samples = append(samples, vs...)
+	}
+
+	out, err := yjson.GenerateGoWithOptions(samples, yjson.GenOptions{Package: *pkg, Name: *name, Tag: *tag})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if _, err := os.Stdout.Write(out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func readSamples(name string, lines bool) ([]*yjson.Value, error) {
+	r := io.Reader(os.Stdin)
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var samples []*yjson.Value
+	if !lines {
+		err := yjson.NewDecoder(r).Each(func(v *yjson.Value) error {
+			samples = append(samples, v)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return samples, nil
+	}
+	dec := yjson.NewLineDecoder(r)
+	for {
+		v := new(yjson.Value)
+		if err := dec.Decode(v); err != nil {
+			if err == io.EOF {
+				return samples, nil
+			}
+			return nil, err
+		}
+		samples = append(samples, v)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadSamples(t *testing.T) {
+	tests := []struct {
+		content string
+		lines   bool
+		want    int // 样本个数, -1 表示出错
+	}{
+		{`{"a":1}`, false, 1},
+		{"{\"a\":1}\n\n{\"a\":2}\n", true, 2},
+		{"", true, 0},
+		{"{\"a\":1}\n{\"a\":2}{\"a\":3}", false, 3},
+		{`{"a":1} garbage`, false, -1},
+		{`{"a":`, false, -1},
+		{"{\"a\":1}\n{\"a\":\n", true, -1},
+	}
+	for _, tt := range tests {
+		samples, err := readSamples(writeFile(t, "in.json", tt.content), tt.lines)
+		if tt.want < 0 {
+			if err == nil {
+				t.Errorf("%q: expected an error", tt.content)
+			}
+			continue
+		}
+		if err != nil || len(samples) != tt.want {
+			t.Errorf("%q: got %d samples, %v, want %d", tt.content, len(samples), err, tt.want)
+		}
+	}
+
+	if _, err := readSamples(filepath.Join(t.TempDir(), "missing.json"), false); err == nil {
+		t.Errorf("missing file: expected an error")
+	}
+}
+
+// 运行 gen 并返回标准输出和退出码
+func runGen(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	out, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = out, out
+	code := gen(args)
+	os.Stdout, os.Stderr = stdout, stderr
+
+	data, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data), code
+}
+
+func TestGen(t *testing.T) {
+	a := writeFile(t, "a.json", `{"id":1,"name":"x"}`)
+	b := writeFile(t, "b.json", `{"id":2}`)
+	lines := writeFile(t, "c.ndjson", "{\"id\":1}\n{\"id\":2,\"ok\":true}\n")
+
+	tests := []struct {
+		args []string
+		want string // 输出中应包含的内容
+		code int
+	}{
+		{[]string{a, b}, "package main\n\ntype Root struct {\n\tID   int64   `json:\"id\"`\n\tName *string `json:\"name,omitempty\"`\n}\n", 0},
+		{[]string{"-package", "models", "-type", "Event", "-tag", "yjson", a}, "package models\n\ntype Event struct {\n\tID   int64  `yjson:\"id\"`\n\tName string `yjson:\"name\"`\n}\n", 0},
+		{[]string{"-lines", lines}, "\tOk *bool `json:\"ok,omitempty\"`\n", 0},
+		{[]string{"-type", "lower", a}, `generate: invalid type name "lower"`, 1},
+		{[]string{filepath.Join(t.TempDir(), "missing.json")}, "missing.json: ", 1},
+	}
+	for _, tt := range tests {
+		got, code := runGen(t, tt.args...)
+		if code != tt.code || !strings.Contains(got, tt.want) {
+			t.Errorf("gen %q: got exit %d\n%s\nwant exit %d with\n%s", tt.args, code, got, tt.code, tt.want)
+		}
+	}
+}
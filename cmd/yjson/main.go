@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Yohox/yjson"
+)
+
+// 用法: yjson [-q expr] [-lines] [-c] [file ...], 不带文件时读取标准输入.
+// 每个文件中可以有多个首尾相连或以空白分隔的文档. 不带 -q 时检查每个文档能否解析;
+// 带 -q 时对每个文档执行 jq 程序并输出结果.
+// -lines 表示输入为 NDJSON, 每行一个文档; -c 输出紧凑的单行 JSON.
+// yjson gen 由示例 JSON 生成 Go 结构体定义, 见 gen
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "gen" {
+		os.Exit(gen(os.Args[2:]))
+	}
+
+	query := flag.String("q", "", "jq program to run on each document")
+	lines := flag.Bool("lines", false, "read input as newline-delimited JSON")
+	compact := flag.Bool("c", false, "compact output")
+	flag.Parse()
+
+	var prog *yjson.JQ
+	if *query != "" {
+		var err error
+		prog, err = yjson.CompileJQ(*query)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+
+	files := flag.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	enc := yjson.NewEncoder(w)
+	if !*compact {
+		enc.SetIndent("", "  ")
+	}
+	emit := func(v *yjson.Value) error {
+		if prog == nil {
+			return nil
+		}
+		return enc.Encode(v)
+	}
+
+	failed := false
+	for _, name := range files {
+		err := run(name, prog, *lines, emit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+			failed = true
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		failed = true
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func run(name string, prog *yjson.JQ, lines bool, emit func(v *yjson.Value) error) error {
+	r := io.Reader(os.Stdin)
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	if lines && prog != nil {
+		return prog.RunLines(r, emit)
+	}
+	if lines {
+		dec := yjson.NewLineDecoder(r)
+		for {
+			var v yjson.Value
+			if err := dec.Decode(&v); err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return err
+			}
+		}
+	}
+
+	// 文件中可以有多个首尾相连或以空白分隔的文档, 末尾多余的内容报告为错误
+	return yjson.NewDecoder(r).Each(func(v *yjson.Value) error {
+		if prog == nil {
+			return nil
+		}
+		out, err := prog.Run(v)
+		if err != nil {
+			return err
+		}
+		for _, o := range out {
+			if err := emit(o); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
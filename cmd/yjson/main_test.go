@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Yohox/yjson"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		content string
+		query   string
+		lines   bool
+		want    string // 输出编码后以空格连接, 以 "error: " 开头时为错误信息中应包含的内容
+	}{
+		{`{"a":[1,2]}`, ``, false, ``},
+		{`{"a":[1,2]}`, `.a[]`, false, `1 2`},
+		{"{\"n\":1}\n{\"n\":2}\n", `.n`, true, `1 2`},
+		{"{\"n\":1}\n{\"n\":2}\n", ``, true, ``},
+		// 多个文档依次处理, 末尾多余的内容是错误
+		{`{"a":1}{"a":2}`, `.a`, false, `1 2`},
+		{"{\"a\":1}\n [2] 3", `.`, false, `{"a":1} [2] 3`},
+		{`{"a":1} garbage`, `.a`, false, `error: `},
+		{`{"a":1} garbage`, ``, false, `error: `},
+		{`{"a":1}{"a":`, ``, false, `error: EOF`},
+		{`{"a":`, ``, false, `error: EOF`},
+		{"{\"n\":1}\n{\"n\":\n", ``, true, `error: line 2`},
+		{"{\"n\":1}\n{\"n\":\"x\"}\n", `.n - 1`, true, `error: line 2`},
+		{`{"a":"x"}`, `.a - 1`, false, `error: jq ".a - 1": `},
+	}
+	for _, tt := range tests {
+		var prog *yjson.JQ
+		if tt.query != "" {
+			var err error
+			if prog, err = yjson.CompileJQ(tt.query); err != nil {
+				t.Fatal(err)
+			}
+		}
+		var got []string
+		err := run(writeFile(t, "in.json", tt.content), prog, tt.lines, func(v *yjson.Value) error {
+			b, err := v.Encode()
+			got = append(got, string(b))
+			return err
+		})
+		if msg, ok := strings.CutPrefix(tt.want, "error: "); ok {
+			if err == nil || !strings.Contains(err.Error(), msg) {
+				t.Errorf("%q %s: got %v, want error %q", tt.content, tt.query, err, msg)
+			}
+			continue
+		}
+		if err != nil || strings.Join(got, " ") != tt.want {
+			t.Errorf("%q %s: got %v, %v, want %s", tt.content, tt.query, got, err, tt.want)
+		}
+	}
+
+	if err := run("/nonexistent/in.json", nil, false, nil); err == nil {
+		t.Errorf("missing file: expected an error")
+	}
+}
@@ -0,0 +1,51 @@
+package yjson
+
+// 终端着色输出使用的 ANSI 转义序列, 空字符串表示该类值不着色
+type ColorScheme struct {
+	Key     string
+	String  string
+	Number  string
+	Boolean string
+	Null    string
+}
+
+const ANSI_RESET = "\x1b[0m"
+
+var DefaultColors = ColorScheme{
+	Key:     "\x1b[34;1m", // 亮蓝
+	String:  "\x1b[32m",   // 绿
+	Number:  "\x1b[36m",   // 青
+	Boolean: "\x1b[33m",   // 黄
+	Null:    "\x1b[90m",   // 灰
+}
+
+// 用 DefaultColors 和两个空格缩进输出, 用于命令行和调试日志
+func (j *Value) Colorize() ([]byte, error) {
+	return j.EncodeWithOptions(EncodeOptions{Indent: "  ", Colors: &DefaultColors})
+}
+
+func (e *encoder) colorFor(k Kind) string {
+	if e.opts.Colors == nil {
+		return ""
+	}
+	switch k {
+	case JSON_STRING:
+		return e.opts.Colors.String
+	case JSON_NUMBER:
+		return e.opts.Colors.Number
+	case JSON_BOOLEAN:
+		return e.opts.Colors.Boolean
+	case JSON_NULL:
+		return e.opts.Colors.Null
+	}
+	return ""
+}
+
+func (e *encoder) appendColoredKey(dst []byte, key string) []byte {
+	if e.opts.Colors == nil || e.opts.Colors.Key == "" {
+		return e.appendString(dst, key)
+	}
+	dst = append(dst, e.opts.Colors.Key...)
+	dst = e.appendString(dst, key)
+	return append(dst, ANSI_RESET...)
+}
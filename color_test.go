@@ -0,0 +1,53 @@
+package yjson
+
+import (
+	"regexp"
+	"testing"
+)
+
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func TestColorize(t *testing.T) {
+	v := mustParse(t, `{"s":"x","n":1.5,"b":true,"z":null,"a":[{}]}`)
+	got, err := v.Colorize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	indented, _ := v.EncodeIndent("", "  ")
+	if plain := ansiEscape.ReplaceAllString(string(got), ""); plain != string(indented) {
+		t.Errorf("without colors: got\n%s\nwant\n%s", plain, indented)
+	}
+
+	c := DefaultColors
+	want := "{\n" +
+		"  " + c.Key + `"s"` + ANSI_RESET + ": " + c.String + `"x"` + ANSI_RESET + ",\n" +
+		"  " + c.Key + `"n"` + ANSI_RESET + ": " + c.Number + `1.5` + ANSI_RESET + ",\n" +
+		"  " + c.Key + `"b"` + ANSI_RESET + ": " + c.Boolean + `true` + ANSI_RESET + ",\n" +
+		"  " + c.Key + `"z"` + ANSI_RESET + ": " + c.Null + `null` + ANSI_RESET + ",\n" +
+		"  " + c.Key + `"a"` + ANSI_RESET + ": [\n" +
+		"    {}\n" +
+		"  ]\n" +
+		"}"
+	if string(got) != want {
+		t.Errorf("got %q\nwant %q", got, want)
+	}
+}
+
+func TestColorScheme(t *testing.T) {
+	tests := []struct {
+		name   string
+		colors ColorScheme
+		want   string
+	}{
+		{"empty scheme", ColorScheme{}, `{"k":["s",1]}`},
+		{"keys only", ColorScheme{Key: "<K>"}, `{<K>"k"` + ANSI_RESET + `:["s",1]}`},
+		{"numbers only", ColorScheme{Number: "<N>"}, `{"k":["s",<N>1` + ANSI_RESET + `]}`},
+	}
+	for _, tt := range tests {
+		colors := tt.colors
+		got, err := mustParse(t, `{"k":["s",1]}`).EncodeWithOptions(EncodeOptions{Colors: &colors})
+		if err != nil || string(got) != tt.want {
+			t.Errorf("%s: got %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
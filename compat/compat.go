@@ -0,0 +1,105 @@
+// Package compat 提供与 encoding/json 同名, 同签名的函数和类型, 大部分代码替换
+// import 后即可切换到 yjson:
+//
+//	import json "github.com/Yohox/yjson/compat"
+//
+// 解析按严格的 RFC 8259 进行, 数字解码到 interface{} 时为 float64 (UseNumber 后为
+// json.Number), 输出默认转义 HTML 字符, map 的键按字典序输出.
+// 与 encoding/json 的区别:
+//   - 字符串中的非法 UTF-8 和孤立的代理对是错误, 而不是替换为 U+FFFD
+//   - 值为整数的小数和指数形式 (如 1.0 和 1e3) 可以解码到整数类型, -0 可以解码到
+//     无符号整数类型; encoding/json 拒绝这些数字
+//   - ,string 选项的字符串内容首尾可以有空白, 如 " 12"
+//   - 对象中重复的键以最后一个值整体替换之前的值, 不会把两个对象合并到同一个
+//     结构体或 map 中
+//   - 遇到第一个类型不符的值就停止并返回错误, 不会继续填充其余字段
+//   - 错误是普通的 error, 不是 *json.SyntaxError / *json.UnmarshalTypeError 等类型.
+//     SyntaxError, UnmarshalTypeError, InvalidUnmarshalError 和 MarshalerError 只是
+//     encoding/json 类型的别名, 让引用它们的代码能够编译
+//   - json.Unmarshaler 和 RawMessage 得到的是重新编码的紧凑文本, 不保留输入中的空白
+package compat
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"github.com/Yohox/yjson"
+)
+
+type (
+	RawMessage  = json.RawMessage
+	Number      = json.Number
+	Marshaler   = json.Marshaler
+	Unmarshaler = json.Unmarshaler
+	Delim       = json.Delim
+	Token       = json.Token
+
+	SyntaxError           = json.SyntaxError
+	UnmarshalTypeError    = json.UnmarshalTypeError
+	InvalidUnmarshalError = json.InvalidUnmarshalError
+	MarshalerError        = json.MarshalerError
+)
+
+func Marshal(v interface{}) ([]byte, error) {
+	return yjson.MarshalWithOptions(v, marshalOptions("", "", true))
+}
+
+func MarshalIndent(v interface{}, prefix, indent string) ([]byte, error) {
+	return yjson.MarshalWithOptions(v, marshalOptions(prefix, indent, true))
+}
+
+func Unmarshal(data []byte, v interface{}) error {
+	return yjson.UnmarshalWithOptions(data, v, unmarshalOptions(false, false))
+}
+
+func Valid(data []byte) bool {
+	return yjson.Valid(data)
+}
+
+// 去掉 src 中无意义的空白后追加到 dst, src 不是合法 JSON 时 dst 不变
+func Compact(dst *bytes.Buffer, src []byte) error {
+	if _, err := yjson.ParseWithOptions(src, yjson.ParseOptions{Strict: true}); err != nil {
+		return err
+	}
+	b, err := yjson.Minify(src)
+	if err != nil {
+		return err
+	}
+	dst.Write(b)
+	return nil
+}
+
+// 与 json.Indent 相同
+func Indent(dst *bytes.Buffer, src []byte, prefix, indent string) error {
+	return json.Indent(dst, src, prefix, indent)
+}
+
+// 与 json.HTMLEscape 相同, 把字符串中的 <, >, & 和 U+2028, U+2029 写作 \u 转义
+func HTMLEscape(dst *bytes.Buffer, src []byte) {
+	json.HTMLEscape(dst, src)
+}
+
+func marshalOptions(prefix, indent string, escapeHTML bool) yjson.MarshalOptions {
+	return yjson.MarshalOptions{Encode: yjson.EncodeOptions{Prefix: prefix, Indent: indent, EscapeHTML: escapeHTML}}
+}
+
+func unmarshalOptions(useNumber, disallowUnknown bool) yjson.UnmarshalOptions {
+	opts := yjson.UnmarshalOptions{
+		Parse:                 yjson.ParseOptions{Strict: true},
+		DisallowUnknownFields: disallowUnknown,
+		InterfaceNumber:       float64Number,
+	}
+	if useNumber {
+		opts.InterfaceNumber = jsonNumber
+	}
+	return opts
+}
+
+func float64Number(n yjson.Number) (interface{}, error) {
+	return strconv.ParseFloat(string(n), 64)
+}
+
+func jsonNumber(n yjson.Number) (interface{}, error) {
+	return json.Number(n), nil
+}
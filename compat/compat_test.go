@@ -0,0 +1,181 @@
+package compat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type quotedFields struct {
+	E  float64     `json:"e,string"`
+	I  int         `json:"i,string"`
+	U  uint8       `json:"u,string"`
+	B  bool        `json:"b,string"`
+	S  string      `json:"s,string"`
+	P  *int        `json:"p,string"`
+	N  json.Number `json:"n,string"`
+	O  int         `json:"o,string,omitempty"`
+	X  []int       `json:"x,string"` // 对切片无效
+	NP *float64    `json:"np,string"`
+}
+
+type basicFields struct {
+	Name  string            `json:"name"`
+	Count int               `json:"count,omitempty"`
+	Tags  []string          `json:"tags"`
+	Attrs map[string]string `json:"attrs"`
+	Inner *basicFields      `json:"inner,omitempty"`
+	Skip  string            `json:"-"`
+	Raw   RawMessage        `json:"raw"`
+	HTML  string            `json:"html"`
+}
+
+func TestMarshalMatchesEncodingJSON(t *testing.T) {
+	p := 7
+	tests := []interface{}{
+		quotedFields{E: 1.5, I: -3, U: 200, B: true, S: `a"<b>`, P: &p, N: "12.5", X: []int{1}},
+		quotedFields{},
+		basicFields{Name: "x", Tags: []string{"a"}, Attrs: map[string]string{"b": "2", "a": "1"}, Raw: RawMessage(`{"k":[1]}`), HTML: "<&>"},
+		basicFields{Inner: &basicFields{Name: "in"}, Raw: RawMessage(`null`)},
+		map[string]interface{}{"z": 1.0, "a": []interface{}{"s", nil, true}},
+		[]float64{0, 1e21, 1e-7, 123456789},
+	}
+	for _, v := range tests {
+		want, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := Marshal(v)
+		if err != nil {
+			t.Fatalf("%T: %v", v, err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("%T:\ngot  %s\nwant %s", v, got, want)
+		}
+	}
+}
+
+func TestUnmarshalMatchesEncodingJSON(t *testing.T) {
+	tests := []struct {
+		input string
+		new   func() interface{}
+	}{
+		{`{"e":"2.5","i":"-4","u":"9","b":"false","s":"\"x\"","p":"8","n":"3e2","x":[1,2],"np":null}`, func() interface{} { return &quotedFields{} }},
+		{`{"e":null,"s":"null","p":"null"}`, func() interface{} { return &quotedFields{} }},
+		{`{"name":"a","count":2,"tags":["t"],"attrs":{"k":"v"},"inner":{"name":"b"},"Skip":"no","raw":[1,2]}`, func() interface{} { return &basicFields{} }},
+		{`{"NAME":"fold"}`, func() interface{} { return &basicFields{} }},
+		{`[1, "a", {"b": null}]`, func() interface{} { var v interface{}; return &v }},
+		{`[3.4028235e+38, -3.4028235e+38, 1e-45]`, func() interface{} { return new([]float32) }},
+	}
+	for _, tt := range tests {
+		want, got := tt.new(), tt.new()
+		if err := json.Unmarshal([]byte(tt.input), want); err != nil {
+			t.Fatal(err)
+		}
+		if err := Unmarshal([]byte(tt.input), got); err != nil {
+			t.Fatalf("%s: %v", tt.input, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s:\ngot  %+v\nwant %+v", tt.input, got, want)
+		}
+	}
+}
+
+func TestUnmarshalQuotedErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"e":2.5}`, `invalid use of ,string struct tag, trying to unmarshal unquoted number into float64 at e`},
+		{`{"i":"abc"}`, `invalid use of ,string struct tag, trying to unmarshal "abc" into int at i`},
+		{`{"b":"1"}`, `invalid use of ,string struct tag, trying to unmarshal "1" into bool at b`},
+		{`{"s":"x"}`, `invalid use of ,string struct tag, trying to unmarshal "x" into string at s`},
+		{`{"i":"1.5"}`, `at i`},
+	}
+	for _, tt := range tests {
+		var v quotedFields
+		err := Unmarshal([]byte(tt.input), &v)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want %q", tt.input, err, tt.want)
+		}
+		if json.Unmarshal([]byte(tt.input), &v) == nil {
+			t.Errorf("%s: encoding/json accepts the input", tt.input)
+		}
+	}
+}
+
+// 包文档中列出的与 encoding/json 不同的行为
+func TestDifferencesFromEncodingJSON(t *testing.T) {
+	type target struct {
+		A int            `json:"a"`
+		B string         `json:"b"`
+		Q int            `json:"q,string"`
+		O map[string]int `json:"o"`
+	}
+	tests := []struct {
+		input string
+		want  target
+		err   string // yjson 的错误, 空表示成功
+	}{
+		{`{"a":1e3}`, target{A: 1000}, ""},
+		{`{"a":1.0}`, target{A: 1}, ""},
+		{`{"q":" 12"}`, target{Q: 12}, ""},
+		{`{"o":{"x":1},"o":{"y":2}}`, target{O: map[string]int{"y": 2}}, ""},
+		{`{"a":"x","b":"y"}`, target{}, "cannot unmarshal string into int at a"},
+	}
+	for _, tt := range tests {
+		var got, std target
+		err := Unmarshal([]byte(tt.input), &got)
+		stdErr := json.Unmarshal([]byte(tt.input), &std)
+		if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%s: got error %v, want %q", tt.input, err, tt.err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.input, got, tt.want)
+		}
+		if stdErr == nil && reflect.DeepEqual(std, got) {
+			t.Errorf("%s: encoding/json behaves the same, remove it from the package doc", tt.input)
+		}
+	}
+
+	var u uint
+	if err := Unmarshal([]byte(`-0`), &u); err != nil || json.Unmarshal([]byte(`-0`), &u) == nil {
+		t.Errorf("-0 into uint: got %v", err)
+	}
+	var syntax *SyntaxError
+	if err := Unmarshal([]byte(`{`), new(interface{})); err == nil || errors.As(err, &syntax) {
+		t.Errorf("syntax error: got %T %v, want a plain error", err, err)
+	}
+}
+
+func TestIndentAndHTMLEscape(t *testing.T) {
+	src := []byte(`{"a":[1,"<&>"]}`)
+	var got, want bytes.Buffer
+	if err := Indent(&got, src, ">", "  "); err != nil {
+		t.Fatal(err)
+	}
+	json.Indent(&want, src, ">", "  ")
+	if got.String() != want.String() {
+		t.Errorf("Indent: got %s, want %s", got.String(), want.String())
+	}
+	if err := Indent(&got, []byte(`{`), "", " "); err == nil {
+		t.Errorf("Indent: expected an error")
+	}
+
+	got.Reset()
+	want.Reset()
+	HTMLEscape(&got, src)
+	json.HTMLEscape(&want, src)
+	if got.String() != want.String() {
+		t.Errorf("HTMLEscape: got %s, want %s", got.String(), want.String())
+	}
+
+	// 解码器返回的 Token 与 encoding/json 的类型相同
+	tok, err := NewDecoder(strings.NewReader(`[1]`)).Token()
+	if delim, ok := tok.(Delim); err != nil || !ok || delim != '[' {
+		t.Errorf("Token: got %v %T, %v", tok, tok, err)
+	}
+}
@@ -0,0 +1,101 @@
+package compat
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/Yohox/yjson"
+)
+
+// 从 io.Reader 依次读取 JSON 值, 与 json.Decoder 相同, 只缓冲到当前值结束
+type Decoder struct {
+	dec *yjson.Decoder
+
+	useNumber       bool
+	disallowUnknown bool
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	dec := yjson.NewDecoder(r)
+	dec.SetOptions(yjson.ParseOptions{Strict: true})
+	return &Decoder{dec: dec}
+}
+
+// 数字解码到 interface{} 时使用 json.Number 而不是 float64
+func (dec *Decoder) UseNumber() {
+	dec.useNumber = true
+}
+
+func (dec *Decoder) DisallowUnknownFields() {
+	dec.disallowUnknown = true
+}
+
+func (dec *Decoder) Decode(v interface{}) error {
+	data, err := dec.dec.ReadValue()
+	if err != nil {
+		return err
+	}
+	return yjson.UnmarshalWithOptions(data, v, unmarshalOptions(dec.useNumber, dec.disallowUnknown))
+}
+
+// 与 json.Decoder.Token 相同, 数字为 float64, UseNumber 后为 json.Number
+func (dec *Decoder) Token() (Token, error) {
+	tok, err := dec.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case yjson.Delim:
+		return Delim(t), nil
+	case yjson.Number:
+		if dec.useNumber {
+			return json.Number(t), nil
+		}
+		return strconv.ParseFloat(string(t), 64)
+	}
+	return tok, nil
+}
+
+// 当前数组或对象中是否还有元素
+func (dec *Decoder) More() bool {
+	return dec.dec.More()
+}
+
+// 已经读入但还没有解码的数据
+func (dec *Decoder) Buffered() io.Reader {
+	return dec.dec.Buffered()
+}
+
+// 当前在输入中的字节偏移
+func (dec *Decoder) InputOffset() int64 {
+	return dec.dec.InputOffset()
+}
+
+// 把值依次写入 io.Writer, 每个值之后写一个换行
+type Encoder struct {
+	enc *yjson.Encoder
+}
+
+// 与 encoding/json 相同, 默认转义 HTML 字符
+func NewEncoder(w io.Writer) *Encoder {
+	enc := &Encoder{enc: yjson.NewEncoder(w)}
+	enc.enc.SetEscapeHTML(true)
+	return enc
+}
+
+func (enc *Encoder) Encode(v interface{}) error {
+	j, err := yjson.ValueOf(v)
+	if err != nil {
+		return err
+	}
+	return enc.enc.Encode(j)
+}
+
+func (enc *Encoder) SetIndent(prefix, indent string) {
+	enc.enc.SetIndent(prefix, indent)
+}
+
+func (enc *Encoder) SetEscapeHTML(on bool) {
+	enc.enc.SetEscapeHTML(on)
+}
@@ -0,0 +1,39 @@
+package compat
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecoderTokenMatchesEncodingJSON(t *testing.T) {
+	tests := []string{
+		`{"a": [1, 2.5, "x", true, null], "b": {}}`,
+		`[] 3 "s"`,
+		`[-0, 1e3, 12345678901234567890]`,
+	}
+	for _, input := range tests {
+		for _, useNumber := range []bool{false, true} {
+			got, want := NewDecoder(strings.NewReader(input)), json.NewDecoder(strings.NewReader(input))
+			if useNumber {
+				got.UseNumber()
+				want.UseNumber()
+			}
+			for {
+				gt, gerr := got.Token()
+				wt, werr := want.Token()
+				if (gerr == nil) != (werr == nil) {
+					t.Fatalf("%s: got error %v, encoding/json %v", input, gerr, werr)
+				}
+				if werr == io.EOF || gerr != nil {
+					break
+				}
+				if !reflect.DeepEqual(gt, wt) {
+					t.Errorf("%s (UseNumber %v): got %#v, want %#v", input, useNumber, gt, wt)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package yjson
+
+import "fmt"
+
+// 不可变的文档. Set 和 Delete 不修改原文档, 而是返回新的 Snapshot, 只复制从根节点
+// 到修改位置的路径上的容器, 其余子树与原文档共享. 每个 Snapshot 都可以在多个
+// goroutine 中同时读取, 更新时也不影响正在读取旧版本的 goroutine.
+// Root, Get 和 GetAll 返回的值与其他版本共享, 不要修改
+type Snapshot struct {
+	root *Value
+}
+
+// 深拷贝 v 并展开所有延迟解析的子树, 之后修改 v 不影响 Snapshot
+func NewSnapshot(v *Value) (*Snapshot, error) {
+	root, err := freezeValue(v)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{root: root}, nil
+}
+
+// 读取时不会再触发 load, 多个 goroutine 同时读取是安全的
+func freezeValue(v *Value) (*Value, error) {
+	if v == nil {
+		return NewNull(), nil
+	}
+	c := copyValue(v)
+	if err := c.Expand(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *Snapshot) Root() *Value {
+	return s.root
+}
+
+func (s *Snapshot) Get(path string) *Value {
+	return s.root.Get(path)
+}
+
+func (s *Snapshot) GetAll(path string) []*Value {
+	return s.root.GetAll(path)
+}
+
+func (s *Snapshot) Encode() ([]byte, error) {
+	return s.root.Encode()
+}
+
+// 只复制容器本身, 成员和元素仍然共享
+func shallowCopy(j *Value) *Value {
+	c := *j
+	switch c.valueType {
+	case JSON_ARRAY:
+		c.arr = append(make([]*Value, 0, len(j.arr)+1), j.arr...)
+	case JSON_OBJECT:
+		c.obj = make(map[string]*Value, len(j.obj)+1)
+		for k, v := range j.obj {
+			c.obj[k] = v
+		}
+		c.keys = append(make([]string, 0, len(j.keys)+1), j.keys...)
+	}
+	return &c
+}
+
+// 与 Value.Set 的规则相同, 返回写入 v 的拷贝后的新版本
+func (s *Snapshot) Set(path string, v *Value) (*Snapshot, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if multiMatch(segments) {
+		return nil, fmt.Errorf("set %q: cannot use * or .. in path", path)
+	}
+	if v, err = freezeValue(v); err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 {
+		return &Snapshot{root: v}, nil
+	}
+
+	root := shallowCopy(s.root)
+	cur := root
+	for i, seg := range segments[:len(segments)-1] {
+		next := seg.lookup(cur)
+		switch {
+		case next != nil:
+			next = shallowCopy(next)
+		case segments[i+1].isIndex:
+			next = NewArray()
+		default:
+			next = NewObject()
+		}
+		if err := seg.store(cur, next); err != nil {
+			return nil, fmt.Errorf("set %q: %v", path, err)
+		}
+		cur = next
+	}
+
+	if err := segments[len(segments)-1].store(cur, v); err != nil {
+		return nil, fmt.Errorf("set %q: %v", path, err)
+	}
+	return &Snapshot{root: root}, nil
+}
+
+// 与 Value.Delete 的规则相同, 返回删除后的新版本
+func (s *Snapshot) Delete(path string) (*Snapshot, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("delete: empty path")
+	}
+	if multiMatch(segments) {
+		return nil, fmt.Errorf("delete %q: cannot use * or .. in path", path)
+	}
+
+	root := shallowCopy(s.root)
+	cur := root
+	for _, seg := range segments[:len(segments)-1] {
+		next := seg.lookup(cur)
+		if next == nil {
+			return nil, fmt.Errorf("delete %q: %s not found", path, seg)
+		}
+		next = shallowCopy(next)
+		if err := seg.store(cur, next); err != nil {
+			return nil, fmt.Errorf("delete %q: %v", path, err)
+		}
+		cur = next
+	}
+
+	if err := segments[len(segments)-1].remove(cur); err != nil {
+		return nil, fmt.Errorf("delete %q: %v", path, err)
+	}
+	return &Snapshot{root: root}, nil
+}
@@ -0,0 +1,189 @@
+package yjson
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+const cowDoc = `{"a":{"b":[1,2],"c":"x"},"d":{"e":true}}`
+
+func mustSnapshot(t *testing.T, s string) *Snapshot {
+	t.Helper()
+	snap, err := NewSnapshot(mustParse(t, s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return snap
+}
+
+func TestSnapshotSet(t *testing.T) {
+	tests := []struct {
+		path  string
+		value string
+		want  string // 以 "error: " 开头时为错误信息
+	}{
+		{"a.c", `"y"`, `{"a":{"b":[1,2],"c":"y"},"d":{"e":true}}`},
+		{"a.b[0]", `0`, `{"a":{"b":[0,2],"c":"x"},"d":{"e":true}}`},
+		{"a.b[2]", `3`, `{"a":{"b":[1,2,3],"c":"x"},"d":{"e":true}}`},
+		{"n.m[0]", `1`, `{"a":{"b":[1,2],"c":"x"},"d":{"e":true},"n":{"m":[1]}}`},
+		{"", `[1]`, `[1]`},
+		{"a.b[5]", `1`, `error: set "a.b[5]": index [5] out of range, array length is 2`},
+		{"a.c.x", `1`, `error: set "a.c.x": `},
+		{"a.*", `1`, `error: set "a.*": cannot use * or .. in path`},
+		{"a[", `1`, `error: invalid path "a["`},
+	}
+	for _, tt := range tests {
+		snap := mustSnapshot(t, cowDoc)
+		next, err := snap.Set(tt.path, mustParse(t, tt.value))
+		if got := mustEncode(t, snap.Root()); got != cowDoc {
+			t.Errorf("%s: original changed to %s", tt.path, got)
+		}
+		if msg, ok := strings.CutPrefix(tt.want, "error: "); ok {
+			if err == nil || !strings.Contains(err.Error(), msg) {
+				t.Errorf("%s: got %v, want error %q", tt.path, err, msg)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.path, err)
+			continue
+		}
+		if got, err := next.Encode(); err != nil || string(got) != tt.want {
+			t.Errorf("%s:\ngot  %s %v\nwant %s", tt.path, got, err, tt.want)
+		}
+	}
+}
+
+func TestSnapshotDelete(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"a.c", `{"a":{"b":[1,2]},"d":{"e":true}}`},
+		{"a.b[0]", `{"a":{"b":[2],"c":"x"},"d":{"e":true}}`},
+		{"d", `{"a":{"b":[1,2],"c":"x"}}`},
+		{"", `error: delete: empty path`},
+		{"x.y", `error: delete "x.y": "x" not found`},
+		{"a.b[9]", `error: delete "a.b[9]": `},
+		{"..c", `error: delete "..c": cannot use * or .. in path`},
+	}
+	for _, tt := range tests {
+		snap := mustSnapshot(t, cowDoc)
+		next, err := snap.Delete(tt.path)
+		if got := mustEncode(t, snap.Root()); got != cowDoc {
+			t.Errorf("%s: original changed to %s", tt.path, got)
+		}
+		if msg, ok := strings.CutPrefix(tt.want, "error: "); ok {
+			if err == nil || !strings.Contains(err.Error(), msg) {
+				t.Errorf("%s: got %v, want error %q", tt.path, err, msg)
+			}
+			continue
+		}
+		if err != nil || mustEncode(t, next.Root()) != tt.want {
+			t.Errorf("%s: got %v, want %s", tt.path, err, tt.want)
+		}
+	}
+}
+
+// 只复制修改路径上的容器, 其余子树共享
+func TestSnapshotSharing(t *testing.T) {
+	snap := mustSnapshot(t, cowDoc)
+	next, err := snap.Set("a.c", NewString("y"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Get("d") != snap.Get("d") || next.Get("a.b") != snap.Get("a.b") {
+		t.Errorf("unchanged subtrees should be shared")
+	}
+	if next.Root() == snap.Root() || next.Get("a") == snap.Get("a") {
+		t.Errorf("containers on the path should be copied")
+	}
+	if all := next.GetAll("..e"); len(all) != 1 || all[0] != snap.Get("d.e") {
+		t.Errorf("GetAll: got %v", all)
+	}
+
+	// 写入的值和 NewSnapshot 的输入都被复制
+	v := mustParse(t, `{"k":1}`)
+	next, err = snap.Set("v", v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Set("k", NewInt(2)); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := next.Get("v.k").Int64(); n != 1 {
+		t.Errorf("Set value is not copied")
+	}
+	src := mustParse(t, cowDoc)
+	frozen, err := NewSnapshot(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Set("a.c", NewString("changed")); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := frozen.Get("a.c").String(); s != "x" {
+		t.Errorf("NewSnapshot input is not copied")
+	}
+	if s, _ := mustSnapshot(t, `null`).Root().Encode(); string(s) != "null" {
+		t.Errorf("null root: got %s", s)
+	}
+}
+
+func TestSnapshotLazy(t *testing.T) {
+	lazy, err := ParseWithOptions([]byte(cowDoc), ParseOptions{Lazy: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := NewSnapshot(lazy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mustEncode(t, snap.Root()); got != cowDoc {
+		t.Errorf("got %s", got)
+	}
+	// 所有子树已经展开, 读取时不会再修改节点
+	snap.Root().Walk(func(path string, v *Value) bool {
+		if v.lazy != nil {
+			t.Errorf("%s is not expanded", path)
+		}
+		return true
+	})
+}
+
+// 更新时读取旧版本是安全的, 用 -race 检查
+func TestSnapshotConcurrent(t *testing.T) {
+	snap := mustSnapshot(t, `{"n":0,"list":[0]}`)
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				if n, _ := snap.Get("n").Int64(); n != 0 {
+					t.Errorf("old version changed: n = %d", n)
+					return
+				}
+				snap.Encode()
+			}
+		}()
+	}
+	cur := snap
+	for i := 1; i <= 200; i++ {
+		var err error
+		if cur, err = cur.Set("n", NewInt(int64(i))); err != nil {
+			t.Fatal(err)
+		}
+		if cur, err = cur.Set("list[1]", NewInt(int64(i))); err != nil {
+			t.Fatal(err)
+		}
+		if cur, err = cur.Delete("list[1]"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+	if got := mustEncode(t, cur.Root()); got != `{"n":200,"list":[0]}` {
+		t.Errorf("got %s", got)
+	}
+}
@@ -0,0 +1,265 @@
+package yjson
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 数组元素个数的乘积超过这个值时不再计算最长公共子序列, 按下标逐个比较
+const PATCH_LCS_LIMIT = 1 << 20
+
+// 计算把 a 变成 b 的 RFC 6902 JSON Patch, ApplyPatch(a, patch) 的结果与 b 相等.
+// 对象按成员比较, 只为增删改过的成员生成操作; 数组按元素的最长公共子序列比较,
+// 插入和删除只涉及改动过的元素, 同一位置上的修改在元素内部继续比较.
+// 数字和对象的比较规则与 test 操作相同. 结果中的值是 b 的拷贝
+func CreatePatch(a, b *Value) (*Value, error) {
+	d := &patchDiff{ops: NewArray()}
+	if err := d.diff("", a, b); err != nil {
+		return nil, err
+	}
+	return d.ops, nil
+}
+
+type patchDiff struct {
+	ops *Value
+}
+
+func (d *patchDiff) emit(op, path string, v *Value) {
+	item := NewObject()
+	item.setMember("op", NewString(op))
+	item.setMember("path", NewString(path))
+	if v != nil {
+		item.setMember("value", copyValue(v))
+	}
+	d.ops.arr = append(d.ops.arr, item)
+}
+
+func (d *patchDiff) diff(path string, a, b *Value) error {
+	if err := a.load(); err != nil {
+		return err
+	}
+	if err := b.load(); err != nil {
+		return err
+	}
+	switch {
+	case a.Type() == JSON_OBJECT && b.Type() == JSON_OBJECT:
+		return d.diffObject(path, a, b)
+	case a.Type() == JSON_ARRAY && b.Type() == JSON_ARRAY:
+		return d.diffArray(path, a, b)
+	}
+	if !equalValues(a, b) {
+		d.emit("replace", path, b)
+	}
+	return nil
+}
+
+func (d *patchDiff) diffObject(path string, a, b *Value) error {
+	for _, key := range a.keys {
+		child := path + "/" + escapePointer(key)
+		if bv, ok := b.obj[key]; ok {
+			if err := d.diff(child, a.obj[key], bv); err != nil {
+				return err
+			}
+		} else {
+			d.emit("remove", child, nil)
+		}
+	}
+	for _, key := range b.keys {
+		if _, ok := a.obj[key]; !ok {
+			d.emit("add", path+"/"+escapePointer(key), b.obj[key])
+		}
+	}
+	return nil
+}
+
+// 按对齐结果生成插入, 删除和对元素的修改
+func (d *patchDiff) diffArray(path string, a, b *Value) error {
+	edits, err := alignArrays(a.arr, b.arr)
+	if err != nil {
+		return err
+	}
+	// index 为当前元素在已执行部分操作的数组中的下标
+	index := 0
+	for _, e := range edits {
+		elem := path + "/" + strconv.Itoa(index)
+		switch e.op {
+		case editKeep:
+		case editChange:
+			if err := d.diff(elem, a.arr[e.i], b.arr[e.j]); err != nil {
+				return err
+			}
+		case editRemove:
+			d.emit("remove", elem, nil)
+			continue
+		case editAdd:
+			d.emit("add", elem, b.arr[e.j])
+		}
+		index++
+	}
+	return nil
+}
+
+// 两个数组对齐后的一步: 保留 a[i] (等于 b[j]), 把 a[i] 改为 b[j], 删除 a[i] 或插入 b[j]
+type arrayEdit struct {
+	op   int
+	i, j int
+}
+
+const (
+	editKeep = iota
+	editChange
+	editRemove
+	editAdd
+)
+
+// 先去掉相同的前缀和后缀, 中间部分按最长公共子序列对齐, 同一位置上一删一增时
+// 合并为修改. 元素个数的乘积超过 PATCH_LCS_LIMIT 时按下标逐个对齐
+func alignArrays(xs, ys []*Value) ([]arrayEdit, error) {
+	ka, err := canonicalKeys(xs)
+	if err != nil {
+		return nil, err
+	}
+	kb, err := canonicalKeys(ys)
+	if err != nil {
+		return nil, err
+	}
+
+	edits := make([]arrayEdit, 0, len(ka))
+	start := 0
+	for start < len(ka) && start < len(kb) && ka[start] == kb[start] {
+		edits = append(edits, arrayEdit{editKeep, start, start})
+		start++
+	}
+	ea, eb := len(ka), len(kb)
+	for ea > start && eb > start && ka[ea-1] == kb[eb-1] {
+		ea, eb = ea-1, eb-1
+	}
+
+	// lcs[i][j] 为 ka[start+i:ea] 和 kb[start+j:eb] 的最长公共子序列长度
+	n, m := ea-start, eb-start
+	var lcs [][]int
+	if n*m <= PATCH_LCS_LIMIT {
+		lcs = make([][]int, n+1)
+		for i := range lcs {
+			lcs[i] = make([]int, m+1)
+		}
+		for i := n - 1; i >= 0; i-- {
+			for j := m - 1; j >= 0; j-- {
+				if ka[start+i] == kb[start+j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else if lcs[i+1][j] >= lcs[i][j+1] {
+					lcs[i][j] = lcs[i+1][j]
+				} else {
+					lcs[i][j] = lcs[i][j+1]
+				}
+			}
+		}
+	}
+	at := func(i, j int) int {
+		if lcs == nil {
+			return 0
+		}
+		return lcs[i][j]
+	}
+
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case ka[start+i] == kb[start+j]:
+			edits = append(edits, arrayEdit{editKeep, start + i, start + j})
+			i, j = i+1, j+1
+		case at(i, j) == at(i+1, j+1):
+			edits = append(edits, arrayEdit{editChange, start + i, start + j})
+			i, j = i+1, j+1
+		case at(i+1, j) >= at(i, j+1):
+			edits = append(edits, arrayEdit{editRemove, start + i, start + j})
+			i++
+		default:
+			edits = append(edits, arrayEdit{editAdd, start + i, start + j})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		edits = append(edits, arrayEdit{editRemove, start + i, start + j})
+	}
+	for ; j < m; j++ {
+		edits = append(edits, arrayEdit{editAdd, start + i, start + j})
+	}
+	for k := 0; ea+k < len(ka); k++ {
+		edits = append(edits, arrayEdit{editKeep, ea + k, eb + k})
+	}
+	return edits, nil
+}
+
+// 元素的比较键, 按 equalValues 相等的元素得到相同的字符串. 不使用 Canonical,
+// 它按 float64 格式化数字, 会把不同的大整数变成同一个键
+func canonicalKeys(items []*Value) ([]string, error) {
+	keys := make([]string, len(items))
+	for i, v := range items {
+		b, err := appendExactKey(nil, v)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = string(b)
+	}
+	return keys, nil
+}
+
+// 数字写作精确值的最简分数, 对象的键排序, 字符串加引号
+func appendExactKey(dst []byte, v *Value) ([]byte, error) {
+	if err := v.load(); err != nil {
+		return nil, err
+	}
+	switch v.Type() {
+	case JSON_NUMBER:
+		if r, ok := v.exactNumber(); ok {
+			return append(dst, r.RatString()...), nil
+		}
+		f, _ := v.Float64()
+		return strconv.AppendFloat(dst, f, 'g', -1, 64), nil
+	case JSON_STRING:
+		return strconv.AppendQuote(dst, v.str), nil
+	case JSON_ARRAY:
+		dst = append(dst, LB)
+		for i, item := range v.arr {
+			if i > 0 {
+				dst = append(dst, DOT)
+			}
+			var err error
+			if dst, err = appendExactKey(dst, item); err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, RB), nil
+	case JSON_OBJECT:
+		keys := make([]string, 0, len(v.obj))
+		for key := range v.obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		dst = append(dst, OB)
+		for i, key := range keys {
+			if i > 0 {
+				dst = append(dst, DOT)
+			}
+			dst = append(strconv.AppendQuote(dst, key), VALUE_SEPARATOR)
+			var err error
+			if dst, err = appendExactKey(dst, v.obj[key]); err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, CB), nil
+	case JSON_BOOLEAN:
+		return strconv.AppendBool(dst, v.b), nil
+	}
+	return append(dst, "null"...), nil
+}
+
+// JSON Pointer 中键的 ~ 和 / 写作 ~0 和 ~1
+func escapePointer(key string) string {
+	if !strings.ContainsAny(key, "~/") {
+		return key
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,115 @@
+package yjson
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestCreatePatch(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		patch string
+	}{
+		{`{"a":1}`, `{"a":1}`, `[]`},
+		{`{"a":1,"b":2}`, `{"a":1,"c":3}`, `[{"op":"remove","path":"/b"},{"op":"add","path":"/c","value":3}]`},
+		{`{"a":{"b":1}}`, `{"a":{"b":2}}`, `[{"op":"replace","path":"/a/b","value":2}]`},
+		{`{"a/b":1,"c~d":1}`, `{"a/b":2,"c~d":2}`, `[{"op":"replace","path":"/a~1b","value":2},{"op":"replace","path":"/c~0d","value":2}]`},
+		{`[1,2,3]`, `[1,3]`, `[{"op":"remove","path":"/1"}]`},
+		{`[1,3]`, `[1,2,3]`, `[{"op":"add","path":"/1","value":2}]`},
+		{`[{"id":1,"v":1}]`, `[{"id":1,"v":2}]`, `[{"op":"replace","path":"/0/v","value":2}]`},
+		{`1`, `"x"`, `[{"op":"replace","path":"","value":"x"}]`},
+		{`{"id":9007199254740993}`, `{"id":9007199254740992}`, `[{"op":"replace","path":"/id","value":9007199254740992}]`},
+		{`[9007199254740993,1]`, `[9007199254740992,1]`, `[{"op":"replace","path":"/0","value":9007199254740992}]`},
+		{`[1.0,2]`, `[1,2]`, `[]`},
+	}
+	for _, tt := range tests {
+		a, b := mustParse(t, tt.a), mustParse(t, tt.b)
+		patch, err := CreatePatch(a, b)
+		if err != nil {
+			t.Fatalf("CreatePatch(%s, %s): %v", tt.a, tt.b, err)
+		}
+		if got := mustEncode(t, patch); got != tt.patch {
+			t.Errorf("CreatePatch(%s, %s) = %s, want %s", tt.a, tt.b, got, tt.patch)
+		}
+		out, err := ApplyPatch(a, patch)
+		if err != nil {
+			t.Fatalf("ApplyPatch(%s, %s): %v", tt.a, mustEncode(t, patch), err)
+		}
+		if !Equal(out, b) {
+			t.Errorf("ApplyPatch(%s, CreatePatch) = %s, want %s", tt.a, mustEncode(t, out), tt.b)
+		}
+	}
+}
+
+// 随机生成的文档对, ApplyPatch(a, CreatePatch(a, b)) 必须等于 b
+func TestCreatePatchRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	numbers := []string{"0", "1", "-1", "1.5", "9007199254740992", "9007199254740993", "18446744073709551615", "1e300"}
+	var gen func(depth int) string
+	gen = func(depth int) string {
+		switch k := rng.Intn(6); {
+		case depth > 3 || k == 0:
+			return numbers[rng.Intn(len(numbers))]
+		case k == 1:
+			return fmt.Sprintf("%q", string(rune('a'+rng.Intn(3))))
+		case k == 2:
+			return []string{"null", "true", "false"}[rng.Intn(3)]
+		case k == 3 || k == 4:
+			s := "["
+			for i, n := 0, rng.Intn(5); i < n; i++ {
+				if i > 0 {
+					s += ","
+				}
+				s += gen(depth + 1)
+			}
+			return s + "]"
+		default:
+			s := "{"
+			for i, n := 0, rng.Intn(4); i < n; i++ {
+				if i > 0 {
+					s += ","
+				}
+				s += fmt.Sprintf("%q:%s", string(rune('a'+rng.Intn(4))), gen(depth+1))
+			}
+			return s + "}"
+		}
+	}
+	for i := 0; i < 5000; i++ {
+		as, bs := gen(0), gen(0)
+		a, b := mustParse(t, as), mustParse(t, bs)
+		patch, err := CreatePatch(a, b)
+		if err != nil {
+			t.Fatalf("CreatePatch(%s, %s): %v", as, bs, err)
+		}
+		out, err := ApplyPatch(a, patch)
+		if err != nil {
+			t.Fatalf("ApplyPatch(%s, %s): %v", as, mustEncode(t, patch), err)
+		}
+		if !Equal(out, b) {
+			t.Fatalf("ApplyPatch(%s, %s) = %s, want %s", as, mustEncode(t, patch), mustEncode(t, out), bs)
+		}
+	}
+}
+
+// 超过 PATCH_LCS_LIMIT 时按下标对齐, 结果仍然正确
+func TestCreatePatchLargeArrays(t *testing.T) {
+	n := 1200
+	xs, ys := make([]*Value, n), make([]*Value, n)
+	for i := range xs {
+		xs[i] = NewInt(int64(i))
+		ys[i] = NewInt(int64((i * 7) % n))
+	}
+	a, b := NewArray(xs...), NewArray(ys...)
+	patch, err := CreatePatch(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := ApplyPatch(a, patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(out, b) {
+		t.Error("large array round trip failed")
+	}
+}
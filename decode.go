@@ -0,0 +1,570 @@
+package yjson
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// 绑定到 Go 值时的选项
+type UnmarshalOptions struct {
+	Parse ParseOptions
+
+	// 对象中出现目标结构体没有的字段时返回错误, 错误中包含键名和路径
+	DisallowUnknownFields bool
+
+	// 没有标签名的字段按该策略生成键名, 如 SnakeCase 时 UserID 匹配 user_id
+	Naming NamingStrategy
+
+	// 数字解码到 interface{} 时的转换, 参数为数字的源文本.
+	// 为 nil 时保持解析得到的类型 (int64, float64, Number ...)
+	InterfaceNumber func(Number) (interface{}, error)
+
+	// 解码 time.Time 时依次尝试的布局, 为空时使用 time.RFC3339Nano.
+	// 字段的 layout 标签优先, 如 `layout:"2006-01-02"`
+	TimeLayouts []string
+
+	// 接口类型字段按判别字段选择具体类型, 为 nil 时使用 DefaultTypes
+	Types *TypeRegistry
+
+	// 在内置规则之前依次调用, 用于自定义转换, 见 DecodeHook
+	Hooks []DecodeHook
+
+	// null 的处理方式, 默认与 encoding/json 相同. 需要区分缺失和 null 时用 Nullable
+	Nulls NullPolicy
+}
+
+// 解析 data 并填充 v 指向的结构体, map, 切片, 指针或基本类型,
+// 结构体字段名匹配时忽略大小写, 与 encoding/json 相同
+func Unmarshal(data []byte, v interface{}) error {
+	return UnmarshalWithOptions(data, v, UnmarshalOptions{})
+}
+
+func UnmarshalWithOptions(data []byte, v interface{}, opts UnmarshalOptions) error {
+	j, err := ParseWithOptions(data, opts.Parse)
+	if err != nil {
+		return err
+	}
+	return j.UnmarshalWithOptions(v, opts)
+}
+
+// 解析 data 并返回 T 类型的值, 等价于声明变量后调用 Unmarshal
+//
+//	cfg, err := yjson.Decode[Config](data)
+func Decode[T any](data []byte) (T, error) {
+	return DecodeWithOptions[T](data, UnmarshalOptions{})
+}
+
+func DecodeWithOptions[T any](data []byte, opts UnmarshalOptions) (T, error) {
+	var v T
+	err := UnmarshalWithOptions(data, &v, opts)
+	return v, err
+}
+
+// 读取 r 的全部内容后按 Decode 处理
+func DecodeReader[T any](r io.Reader) (T, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return Decode[T](data)
+}
+
+// 把已经解析好的值绑定到 v, v 必须是非 nil 指针
+func (j *Value) Unmarshal(v interface{}) error {
+	return j.UnmarshalWithOptions(v, UnmarshalOptions{})
+}
+
+func (j *Value) UnmarshalWithOptions(v interface{}, opts UnmarshalOptions) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("unmarshal: non-nil pointer required, but get %T", v)
+	}
+	d := &decodeState{opts: opts}
+	return d.decode("", j, rv.Elem())
+}
+
+type decodeState struct {
+	opts UnmarshalOptions
+}
+
+var valueReflectType = reflect.TypeOf(Value{})
+
+func (d *decodeState) typeError(path string, j *Value, t reflect.Type) error {
+	if path == "" {
+		return fmt.Errorf("unmarshal: cannot unmarshal %s into %s", j.Type(), t)
+	}
+	return fmt.Errorf("unmarshal: cannot unmarshal %s into %s at %s", j.Type(), t, path)
+}
+
+// Lazy 模式下展开时的语法错误
+func (d *decodeState) loadError(path string, err error) error {
+	if path == "" {
+		return fmt.Errorf("unmarshal: %v", err)
+	}
+	return fmt.Errorf("unmarshal: %v at %s", err, path)
+}
+
+func (d *decodeState) decode(path string, j *Value, rv reflect.Value) error {
+	j, done, err := d.runHooks(path, j, rv)
+	if done || err != nil {
+		return err
+	}
+	return d.decodeValue(path, j, rv)
+}
+
+func (d *decodeState) decodeValue(path string, j *Value, rv reflect.Value) error {
+	if rv.Type() == valueReflectType {
+		rv.Set(reflect.ValueOf(*j))
+		return nil
+	}
+	if err := j.load(); err != nil {
+		return d.loadError(path, err)
+	}
+	if rv.CanAddr() {
+		if n, ok := rv.Addr().Interface().(nullableDecoder); ok {
+			target := n.decodeTarget(j.IsNull())
+			if j.IsNull() {
+				return nil
+			}
+			return d.decode(path, j, target)
+		}
+	}
+	if j.IsNull() && d.decodeNull(rv) {
+		return nil
+	}
+	switch rv.Type() {
+	case timeReflectType:
+		return d.decodeTime(path, j, rv, "")
+	case durationReflectType:
+		return d.decodeDuration(path, j, rv, "")
+	}
+	if ok, err := d.decodeCustom(path, j, rv); ok {
+		return err
+	}
+
+	if j.Type() == JSON_NULL {
+		switch rv.Kind() {
+		case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+			rv.Set(reflect.Zero(rv.Type()))
+		}
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return d.decode(path, j, rv.Elem())
+	case reflect.Interface:
+		if u := d.types().lookup(rv.Type()); u != nil {
+			return d.decodeUnion(path, j, rv, u)
+		}
+		if rv.NumMethod() != 0 {
+			return d.typeError(path, j, rv.Type())
+		}
+		iv, err := d.interfaceValue(path, j)
+		if err != nil {
+			return err
+		}
+		if iv == nil {
+			rv.Set(reflect.Zero(rv.Type()))
+		} else {
+			rv.Set(reflect.ValueOf(iv))
+		}
+		return nil
+	}
+
+	switch j.Type() {
+	case JSON_BOOLEAN:
+		if rv.Kind() != reflect.Bool {
+			return d.typeError(path, j, rv.Type())
+		}
+		rv.SetBool(j.b)
+	case JSON_STRING:
+		if isByteSlice(rv.Type()) {
+			return d.decodeBytes(path, j, rv, "")
+		}
+		if rv.Kind() != reflect.String {
+			return d.typeError(path, j, rv.Type())
+		}
+		rv.SetString(j.str)
+	case JSON_NUMBER:
+		return d.decodeNumber(path, j, rv)
+	case JSON_ARRAY:
+		return d.decodeArray(path, j, rv)
+	case JSON_OBJECT:
+		return d.decodeObject(path, j, rv)
+	default:
+		return d.typeError(path, j, rv.Type())
+	}
+	return nil
+}
+
+func (d *decodeState) decodeNumber(path string, j *Value, rv reflect.Value) error {
+	numberError := func(err error) error {
+		if err == nil {
+			err = fmt.Errorf("number %v overflows %s", j.number(), rv.Type())
+		}
+		if path == "" {
+			return fmt.Errorf("unmarshal: %v", err)
+		}
+		return fmt.Errorf("unmarshal: %v at %s", err, path)
+	}
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := j.Int64()
+		if _, uerr := j.Uint64(); err != nil && uerr == nil {
+			// 超出 int64 的整数与负数解码到无符号类型相同, 按目标类型报告溢出
+			return numberError(nil)
+		}
+		if err != nil || rv.OverflowInt(i) {
+			return numberError(err)
+		}
+		rv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := j.Uint64()
+		if _, ierr := j.Int64(); err != nil && ierr == nil {
+			return numberError(nil)
+		}
+		if err != nil || rv.OverflowUint(u) {
+			return numberError(err)
+		}
+		rv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := j.Float64()
+		if err != nil {
+			return numberError(err)
+		}
+		if rv.Kind() == reflect.Float32 && !math.IsInf(f, 0) && !math.IsNaN(f) {
+			// 按 float32 舍入源文本, 3.4028235e+38 这样舍入后等于 MaxFloat32 的数字不算溢出.
+			// 十六进制等 ParseFloat 不支持的形式由 float64 转换
+			f32, perr := strconv.ParseFloat(d.numberText(j), 32)
+			if ne, ok := perr.(*strconv.NumError); ok && ne.Err == strconv.ErrRange {
+				return numberError(nil)
+			}
+			if perr != nil {
+				f32 = float64(float32(f))
+				if math.IsInf(f32, 0) {
+					return numberError(nil)
+				}
+			}
+			f = f32
+		}
+		rv.SetFloat(f)
+	case reflect.String:
+		// yjson.Number 或 json.Number, 保存源文本
+		if rv.Type() != numberReflectType && rv.Type() != jsonNumberReflectType {
+			return d.typeError(path, j, rv.Type())
+		}
+		rv.SetString(d.numberText(j))
+	default:
+		if rv.Type() == bigIntReflectType || rv.Type() == bigFloatReflectType {
+			return d.decodeBigNumber(path, j, rv)
+		}
+		return d.typeError(path, j, rv.Type())
+	}
+	return nil
+}
+
+func (d *decodeState) numberText(j *Value) string {
+	if len(j.raw) > 0 {
+		return string(j.raw)
+	}
+	e := encoder{}
+	b, err := e.appendNumber(nil, j)
+	if err != nil {
+		return fmt.Sprint(j.number())
+	}
+	return string(b)
+}
+
+func (d *decodeState) decodeBigNumber(path string, j *Value, rv reflect.Value) error {
+	text := d.numberText(j)
+	switch rv.Addr().Interface().(type) {
+	case *big.Int:
+		n, ok := new(big.Int).SetString(text, 10)
+		if !ok {
+			return fmt.Errorf("unmarshal: number %s is not an integer at %s", text, path)
+		}
+		rv.Set(reflect.ValueOf(*n))
+	case *big.Float:
+		n, _, err := big.ParseFloat(text, 10, BIG_FLOAT_PREC, big.ToNearestEven)
+		if err != nil {
+			return fmt.Errorf("unmarshal: invalid number %s at %s", text, path)
+		}
+		rv.Set(reflect.ValueOf(*n))
+	}
+	return nil
+}
+
+func (d *decodeState) decodeArray(path string, j *Value, rv reflect.Value) error {
+	arr := j.arr
+	switch rv.Kind() {
+	case reflect.Slice:
+		s := reflect.MakeSlice(rv.Type(), len(arr), len(arr))
+		for i, v := range arr {
+			if err := d.decode(joinIndexPath(path, i), v, s.Index(i)); err != nil {
+				return err
+			}
+		}
+		rv.Set(s)
+	case reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if i >= len(arr) {
+				rv.Index(i).Set(reflect.Zero(rv.Type().Elem()))
+				continue
+			}
+			if err := d.decode(joinIndexPath(path, i), arr[i], rv.Index(i)); err != nil {
+				return err
+			}
+		}
+	default:
+		return d.typeError(path, j, rv.Type())
+	}
+	return nil
+}
+
+func (d *decodeState) decodeObject(path string, j *Value, rv reflect.Value) error {
+	m := j.obj
+	switch rv.Kind() {
+	case reflect.Map:
+		keyType := rv.Type().Key()
+		if !isUnmarshalKeyType(keyType) {
+			return d.typeError(path, j, rv.Type())
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMapWithSize(rv.Type(), len(m)))
+		}
+		elemType := rv.Type().Elem()
+		for _, k := range j.keys {
+			key, err := parseMapKey(k, keyType)
+			if err != nil {
+				return fmt.Errorf("unmarshal: %v at %s", err, joinKeyPath(path, k))
+			}
+			elem := reflect.New(elemType).Elem()
+			if err := d.decode(joinKeyPath(path, k), m[k], elem); err != nil {
+				return err
+			}
+			rv.SetMapIndex(key, elem)
+		}
+	case reflect.Struct:
+		fields := cachedFields(rv.Type(), d.opts.Naming)
+		seen := make(map[string]bool, len(j.keys))
+		present := make(map[string]bool, len(j.keys)) // 出现且不为 null
+		for _, k := range j.keys {
+			f, ok := fields.lookup(k)
+			if !ok {
+				if d.opts.DisallowUnknownFields {
+					return fmt.Errorf("unmarshal: unknown field %q at %s", k, joinKeyPath(path, k))
+				}
+				continue
+			}
+			seen[f.name] = true
+			if m[k].IsNull() && f.hasDefault {
+				continue
+			}
+			present[f.name] = true
+			fv, err := fieldByIndexAlloc(rv, f.index)
+			if err != nil {
+				return fmt.Errorf("unmarshal: %v at %s", err, joinKeyPath(path, k))
+			}
+			if err := d.decodeField(joinKeyPath(path, k), m[k], fv, f); err != nil {
+				return err
+			}
+		}
+		if err := d.checkRequired(path, fields, seen); err != nil {
+			return err
+		}
+		return d.applyDefaults(path, fields, present, rv)
+	default:
+		return d.typeError(path, j, rv.Type())
+	}
+	return nil
+}
+
+// 字段标签中的格式选项只作用于该字段 (及其指针), 其余情况按 decode 处理
+func (d *decodeState) decodeField(path string, j *Value, rv reflect.Value, f *field) error {
+	if !f.hasFormat() {
+		return d.decode(path, j, rv)
+	}
+	j, done, err := d.runHooks(path, j, rv)
+	if done || err != nil {
+		return err
+	}
+	if f.quoted {
+		return d.decodeQuoted(path, j, rv)
+	}
+	if isByteSlice(rv.Type()) {
+		return d.decodeBytes(path, j, rv, f.encoding)
+	}
+
+	t := rv.Type()
+	if t.Kind() == reflect.Pointer && (t.Elem() == timeReflectType || t.Elem() == durationReflectType) {
+		if j.IsNull() {
+			if !d.decodeNull(rv) {
+				rv.Set(reflect.Zero(t))
+			}
+			return nil
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.New(t.Elem()))
+		}
+		rv, t = rv.Elem(), t.Elem()
+	}
+	switch t {
+	case timeReflectType:
+		return d.decodeTime(path, j, rv, f.layout)
+	case durationReflectType:
+		return d.decodeDuration(path, j, rv, f.unit)
+	}
+	return d.decodeValue(path, j, rv)
+}
+
+// string 选项: 字符串的内容按 JSON 解析后再解码, 类型必须与字段一致, 如 "1.5"
+// 可以解码到 float64, "\"a\"" 解码到 string. null 和内容为 null 的字符串按 null 处理
+func (d *decodeState) decodeQuoted(path string, j *Value, rv reflect.Value) error {
+	if err := j.load(); err != nil {
+		return d.loadError(path, err)
+	}
+	if j.IsNull() {
+		return d.decodeValue(path, j, rv)
+	}
+	if j.Type() != JSON_STRING {
+		return fmt.Errorf("unmarshal: invalid use of ,string struct tag, trying to unmarshal unquoted %s into %s at %s", j.Type(), rv.Type(), path)
+	}
+	inner, err := ParseWithOptions([]byte(j.str), ParseOptions{Strict: true})
+	if err == nil && !inner.IsNull() {
+		t := rv.Type()
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		want := JSON_NUMBER
+		switch {
+		case t == numberReflectType || t == jsonNumberReflectType:
+		case t.Kind() == reflect.Bool:
+			want = JSON_BOOLEAN
+		case t.Kind() == reflect.String:
+			want = JSON_STRING
+		}
+		if inner.Type() != want {
+			err = fmt.Errorf("expect %s", want)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("unmarshal: invalid use of ,string struct tag, trying to unmarshal %q into %s at %s", j.str, rv.Type(), path)
+	}
+	return d.decodeValue(path, inner, rv)
+}
+
+// interface{} 目标: 对象为 map[string]interface{}, 数组为 []interface{},
+// 数字保持解析时的类型 (int64, float64, Number ...) 或交给 InterfaceNumber 转换
+func (d *decodeState) interfaceValue(path string, j *Value) (interface{}, error) {
+	if err := j.load(); err != nil {
+		return nil, d.loadError(path, err)
+	}
+	switch j.Type() {
+	case JSON_OBJECT:
+		m := make(map[string]interface{}, len(j.keys))
+		for _, k := range j.keys {
+			v, err := d.interfaceValue(joinKeyPath(path, k), j.obj[k])
+			if err != nil {
+				return nil, err
+			}
+			m[k] = v
+		}
+		return m, nil
+	case JSON_ARRAY:
+		arr := j.arr
+		s := make([]interface{}, len(arr))
+		for i, v := range arr {
+			iv, err := d.interfaceValue(joinIndexPath(path, i), v)
+			if err != nil {
+				return nil, err
+			}
+			s[i] = iv
+		}
+		return s, nil
+	case JSON_NUMBER:
+		if d.opts.InterfaceNumber != nil {
+			v, err := d.opts.InterfaceNumber(Number(d.numberText(j)))
+			if err != nil {
+				if path == "" {
+					return nil, fmt.Errorf("unmarshal: %v", err)
+				}
+				return nil, fmt.Errorf("unmarshal: %v at %s", err, path)
+			}
+			return v, nil
+		}
+	}
+	return j.Interface(), nil
+}
+
+// 列出所有带 required 选项但输入中没有出现的字段
+func (d *decodeState) checkRequired(path string, fields *structFields, seen map[string]bool) error {
+	missing := make([]string, 0)
+	for _, f := range fields.list {
+		if f.required && !seen[f.name] {
+			missing = append(missing, strconv.Quote(f.name))
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	msg := fmt.Sprintf("unmarshal: missing required field %s", strings.Join(missing, ", "))
+	if len(missing) > 1 {
+		msg = fmt.Sprintf("unmarshal: missing required fields %s", strings.Join(missing, ", "))
+	}
+	if path != "" {
+		msg += " at " + path
+	}
+	return errors.New(msg)
+}
+
+// 输入中缺失或为 null 的字段使用 default 标签的值. 字符串字段直接使用标签原文,
+// 其他字段把标签当作 JSON 解析, 如 default:"8080", default:"[1,2]"
+func (d *decodeState) applyDefaults(path string, fields *structFields, present map[string]bool, rv reflect.Value) error {
+	for _, f := range fields.list {
+		if !f.hasDefault || present[f.name] {
+			continue
+		}
+
+		fv, err := fieldByIndexAlloc(rv, f.index)
+		if err != nil {
+			return fmt.Errorf("unmarshal: %v at %s", err, joinKeyPath(path, f.name))
+		}
+		def, err := d.defaultFor(f, fv.Type())
+		if err != nil {
+			return fmt.Errorf("unmarshal: bad default %q for %s: %v", f.defaultValue, joinKeyPath(path, f.name), err)
+		}
+		if err := d.decodeField(joinKeyPath(path, f.name), def, fv, &f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *decodeState) defaultFor(f field, t reflect.Type) (*Value, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.String && t != numberReflectType {
+		return NewString(f.defaultValue), nil
+	}
+
+	j, err := ParseWithOptions([]byte(f.defaultValue), ParseOptions{Strict: true})
+	if err != nil && (t == timeReflectType || t == durationReflectType) {
+		// 时间和时长可以直接写 default:"2020-01-02", default:"5s"
+		return NewString(f.defaultValue), nil
+	}
+	return j, err
+}
@@ -0,0 +1,402 @@
+package yjson
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+type decodeAddress struct {
+	City string `json:"city"`
+	Zip  *int   `json:"zip"`
+}
+
+type decodeUser struct {
+	Name    string                 `json:"name"`
+	Age     int                    `json:"age"`
+	Score   float64                `json:"score"`
+	Admin   bool                   `json:"admin"`
+	Tags    []string               `json:"tags"`
+	Pair    [2]int                 `json:"pair"`
+	Address *decodeAddress         `json:"address"`
+	Extra   map[string]interface{} `json:"extra"`
+	Any     interface{}            `json:"any"`
+	Plain   string
+}
+
+func TestUnmarshal(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		new   func() interface{}
+	}{
+		{"struct", `{"name":"a","age":30,"score":1.5,"admin":true,"tags":["x","y"],"pair":[1,2],
+			"address":{"city":"c","zip":123},"extra":{"k":[1,"v",null]},"any":{"n":2},"plain":"p"}`,
+			func() interface{} { return new(decodeUser) }},
+		{"case insensitive", `{"NAME":"a","Age":1,"PLAIN":"x"}`, func() interface{} { return new(decodeUser) }},
+		{"unknown fields ignored", `{"name":"a","nope":[1,2]}`, func() interface{} { return new(decodeUser) }},
+		{"null fields", `{"name":null,"tags":null,"address":null,"any":null}`, func() interface{} { return new(decodeUser) }},
+		{"short array", `{"pair":[7]}`, func() interface{} { return new(decodeUser) }},
+		{"long array", `{"pair":[1,2,3]}`, func() interface{} { return new(decodeUser) }},
+		{"slice of structs", `[{"city":"a"},{"city":"b","zip":1}]`, func() interface{} { return new([]decodeAddress) }},
+		{"map of slices", `{"a":[1,2],"b":[]}`, func() interface{} { return new(map[string][]int) }},
+		{"interface", `{"a":[1,2.5,"s",true,null,{}]}`, func() interface{} { return new(interface{}) }},
+		{"pointer to pointer", `5`, func() interface{} { return new(**int) }},
+		{"int8", `-128`, func() interface{} { return new(int8) }},
+		{"uint16", `65535`, func() interface{} { return new(uint16) }},
+		{"float32", `3.25`, func() interface{} { return new(float32) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, want := tt.new(), tt.new()
+			if err := Unmarshal([]byte(tt.input), got); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.input), want); err != nil {
+				t.Fatal(err)
+			}
+			// interface{} 中的数字为 int64 或 float64, encoding/json 统一为 float64
+			if !reflect.DeepEqual(normalizeNumbers(got), normalizeNumbers(want)) {
+				t.Errorf("got %#v, want %#v", reflect.ValueOf(got).Elem(), reflect.ValueOf(want).Elem())
+			}
+		})
+	}
+}
+
+// 把 interface{} 中的整数转换为 float64, 便于与 encoding/json 的结果比较
+func normalizeNumbers(v interface{}) interface{} {
+	b, _ := json.Marshal(v)
+	var out interface{}
+	json.Unmarshal(b, &out)
+	return out
+}
+
+func TestUnmarshalInterfaceNumbers(t *testing.T) {
+	var v interface{}
+	if err := Unmarshal([]byte(`[1, -2, 18446744073709551615, 1.5]`), &v); err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{int64(1), int64(-2), uint64(18446744073709551615), 1.5}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("got %#v, want %#v", v, want)
+	}
+}
+
+// 与 encoding/json 不同, 整数值的浮点数可以解码到整数类型
+func TestUnmarshalIntegralFloat(t *testing.T) {
+	var v struct {
+		N int8   `json:"n"`
+		U uint64 `json:"u"`
+	}
+	if err := Unmarshal([]byte(`{"n":-1e2,"u":5.0}`), &v); err != nil || v.N != -100 || v.U != 5 {
+		t.Errorf("got %+v, %v", v, err)
+	}
+}
+
+func TestUnmarshalExisting(t *testing.T) {
+	// 与 encoding/json 相同: 结构体中没有出现的字段保持原值, map 合并
+	u := decodeUser{Name: "keep", Age: 1, Extra: map[string]interface{}{"old": true}}
+	if err := Unmarshal([]byte(`{"age":2,"extra":{"new":1}}`), &u); err != nil {
+		t.Fatal(err)
+	}
+	if u.Name != "keep" || u.Age != 2 || len(u.Extra) != 2 {
+		t.Errorf("got %+v", u)
+	}
+
+	// 已有的指针复用
+	zip := 1
+	a := decodeAddress{Zip: &zip}
+	if err := Unmarshal([]byte(`{"zip":2}`), &a); err != nil {
+		t.Fatal(err)
+	}
+	if a.Zip != &zip || zip != 2 {
+		t.Errorf("pointer not reused")
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		v     interface{}
+		want  string
+	}{
+		{`"x"`, new(int), `unmarshal: cannot unmarshal string into int`},
+		{`{"age":"x"}`, new(decodeUser), `unmarshal: cannot unmarshal string into int at age`},
+		{`{"tags":[1]}`, new(decodeUser), `unmarshal: cannot unmarshal number into string at tags[0]`},
+		{`{"address":{"city":[]}}`, new(decodeUser), `unmarshal: cannot unmarshal array into string at address.city`},
+		{`{"a.b":{"c":true}}`, new(map[string]map[string]int), `unmarshal: cannot unmarshal boolean into int at ["a.b"].c`},
+		{`[1]`, new(map[string]int), `unmarshal: cannot unmarshal array into map[string]int`},
+		{`{}`, new([]int), `unmarshal: cannot unmarshal object into []int`},
+		{`300`, new(int8), `unmarshal: number 300 overflows int8`},
+		{`-1`, new(uint), `unmarshal: number -1 overflows uint`},
+		{`18446744073709551615`, new(int64), `unmarshal: number 18446744073709551615 overflows int64`},
+		{`1.5`, new(int), `unmarshal: number 1.5 is not an integer`},
+		{`{"age":1e40}`, new(decodeUser), `unmarshal: number 1e+40 overflows int64 at age`},
+		{`1e39`, new(float32), `unmarshal: number 1e+39 overflows float32`},
+		{`3.4028236e38`, new(float32), `unmarshal: number 3.4028236e+38 overflows float32`},
+		{`{"a":1}`, new(json.Marshaler), `unmarshal: cannot unmarshal object into json.Marshaler`},
+		{`{"a":`, new(interface{}), `EOF`},
+	}
+	for _, tt := range tests {
+		err := Unmarshal([]byte(tt.input), tt.v)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s into %T: got %v, want %s", tt.input, tt.v, err, tt.want)
+		}
+	}
+
+	for _, v := range []interface{}{nil, decodeUser{}, (*decodeUser)(nil)} {
+		if err := Unmarshal([]byte(`{}`), v); err == nil {
+			t.Errorf("%T: expected an error", v)
+		}
+	}
+}
+
+// Marshal 写出的 float32 总能解码回相同的值, 包括 MaxFloat32
+func TestFloat32RoundTrip(t *testing.T) {
+	for _, f := range []float32{math.MaxFloat32, -math.MaxFloat32, math.SmallestNonzeroFloat32, 0.1, 16777217} {
+		b, err := Marshal(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got float32
+		if err := Unmarshal(b, &got); err != nil || got != f {
+			t.Errorf("%s: got %v, %v, want %v", b, got, err, f)
+		}
+		var std float32
+		if err := json.Unmarshal(b, &std); err != nil || std != got {
+			t.Errorf("%s: encoding/json gives %v, %v", b, std, err)
+		}
+	}
+}
+
+func TestValueUnmarshal(t *testing.T) {
+	v := mustParse(t, `{"users":[{"name":"a"},{"name":"b","age":3}]}`)
+	var u decodeUser
+	if err := v.Get("users[1]").Unmarshal(&u); err != nil {
+		t.Fatal(err)
+	}
+	if u.Name != "b" || u.Age != 3 {
+		t.Errorf("got %+v", u)
+	}
+
+	// *Value 和 Value 类型的字段保存原值
+	var holder struct {
+		Users []*Value `json:"users"`
+		Raw   Value    `json:"raw"`
+	}
+	if err := Unmarshal([]byte(`{"users":[{"name":"a"}],"raw":[1]}`), &holder); err != nil {
+		t.Fatal(err)
+	}
+	if holder.Users[0].Get("name").MustString() != "a" || holder.Raw.Len() != 1 {
+		t.Errorf("got %+v", holder)
+	}
+}
+
+func TestDisallowUnknownFields(t *testing.T) {
+	opts := UnmarshalOptions{DisallowUnknownFields: true}
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"name":"a","NAME":"b","Plain":"p"}`, ""},
+		{`{"name":"a","nope":1}`, `unmarshal: unknown field "nope" at nope`},
+		{`{"address":{"city":"c","street":"s"}}`, `unmarshal: unknown field "street" at address.street`},
+		{`{"tags":["x"],"address":{"zip":1},"a.b":null}`, `unmarshal: unknown field "a.b" at ["a.b"]`},
+		// map, interface{} 和 *Value 字段中的键不受限制
+		{`{"extra":{"anything":1},"any":{"x":{"y":2}}}`, ""},
+	}
+	for _, tt := range tests {
+		var u decodeUser
+		err := UnmarshalWithOptions([]byte(tt.input), &u, opts)
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tt.input, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: got %v, want %s", tt.input, err, tt.want)
+		}
+	}
+
+	// 嵌套在切片中的结构体
+	var list []decodeAddress
+	err := UnmarshalWithOptions([]byte(`[{"city":"a"},{"city":"b","x":1}]`), &list, opts)
+	if err == nil || err.Error() != `unmarshal: unknown field "x" at [1].x` {
+		t.Errorf("slice: got %v", err)
+	}
+
+	// 与 encoding/json 的 DisallowUnknownFields 对同样的输入报错
+	for _, tt := range tests {
+		dec := json.NewDecoder(strings.NewReader(tt.input))
+		dec.DisallowUnknownFields()
+		var u decodeUser
+		if stdErr := dec.Decode(&u); (stdErr == nil) != (tt.want == "") {
+			t.Errorf("%s: encoding/json gives %v", tt.input, stdErr)
+		}
+	}
+}
+
+type requiredConfig struct {
+	Host  string         `json:"host,required"`
+	Port  int            `json:"port,required"`
+	Debug bool           `json:"debug"`
+	Inner *requiredInner `json:"inner"`
+}
+
+type requiredInner struct {
+	ID string `json:"id,required"`
+}
+
+func TestRequiredFields(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"host":"h","port":1}`, ""},
+		{`{"host":"","port":0}`, ""},
+		{`{"host":null,"port":1}`, ""}, // 出现即可, null 也算
+		{`{"HOST":"h","Port":1}`, ""},
+		{`{"host":"h"}`, `unmarshal: missing required field "port"`},
+		{`{"debug":true}`, `unmarshal: missing required fields "host", "port"`},
+		{`{"host":"h","port":1,"inner":{}}`, `unmarshal: missing required field "id" at inner`},
+		{`{"host":"h","port":1,"inner":null}`, ""},
+	}
+	for _, tt := range tests {
+		var c requiredConfig
+		err := Unmarshal([]byte(tt.input), &c)
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tt.input, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: got %v, want %s", tt.input, err, tt.want)
+		}
+	}
+
+	var list []requiredInner
+	err := Unmarshal([]byte(`[{"id":"a"},{}]`), &list)
+	if err == nil || err.Error() != `unmarshal: missing required field "id" at [1]` {
+		t.Errorf("slice: got %v", err)
+	}
+
+	// required 不影响编码
+	if b, err := Marshal(requiredConfig{}); err != nil || string(b) != `{"host":"","port":0,"debug":false,"inner":null}` {
+		t.Errorf("marshal: got %s, %v", b, err)
+	}
+}
+
+type defaultConfig struct {
+	Host    string            `yjson:"host" default:"localhost"`
+	Port    int               `yjson:"port" default:"8080"`
+	Ratio   *float64          `yjson:"ratio" default:"0.5"`
+	Tags    []string          `yjson:"tags" default:"[\"a\",\"b\"]"`
+	Labels  map[string]int    `yjson:"labels" default:"{\"x\":1}"`
+	Enabled bool              `yjson:"enabled" default:"true"`
+	Timeout time.Duration     `yjson:"timeout" default:"5s"`
+	Since   time.Time         `yjson:"since" layout:"2006-01-02" default:"2020-01-02"`
+	Quoted  string            `yjson:"quoted" default:"\"kept\""`
+	Nested  defaultNested     `yjson:"nested"`
+	Raw     map[string]string `yjson:"raw"`
+}
+
+type defaultNested struct {
+	Level int `yjson:"level" default:"3"`
+}
+
+func TestDefaults(t *testing.T) {
+	var c defaultConfig
+	if err := Unmarshal([]byte(`{"nested":{}}`), &c); err != nil {
+		t.Fatal(err)
+	}
+	want := defaultConfig{
+		Host:    "localhost",
+		Port:    8080,
+		Ratio:   func() *float64 { f := 0.5; return &f }(),
+		Tags:    []string{"a", "b"},
+		Labels:  map[string]int{"x": 1},
+		Enabled: true,
+		Timeout: 5 * time.Second,
+		Since:   time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		Quoted:  `"kept"`,
+		Nested:  defaultNested{Level: 3},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("got %+v\nwant %+v", c, want)
+	}
+
+	// 出现的值优先, null 按缺失处理, 零值不会被替换
+	var d defaultConfig
+	if err := Unmarshal([]byte(`{"host":null,"port":0,"enabled":false,"tags":[]}`), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Host != "localhost" || d.Port != 0 || d.Enabled || len(d.Tags) != 0 || d.Tags == nil {
+		t.Errorf("got %+v", d)
+	}
+	// 没有出现的嵌套结构体不会被创建, 其中的默认值也不会应用
+	if d.Nested.Level != 0 {
+		t.Errorf("nested: got %d", d.Nested.Level)
+	}
+}
+
+func TestDefaultErrors(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{&struct {
+			N int `json:"n" default:"x"`
+		}{}, `unmarshal: bad default "x" for n: not match`},
+		{&struct {
+			N int8 `json:"n" default:"300"`
+		}{}, `unmarshal: number 300 overflows int8 at n`},
+		{&struct {
+			N []int `json:"n" default:"{}"`
+		}{}, `unmarshal: cannot unmarshal object into []int at n`},
+	}
+	for _, tt := range tests {
+		err := Unmarshal([]byte(`{}`), tt.v)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%T: got %v, want %s", tt.v, err, tt.want)
+		}
+	}
+}
+
+func TestDecodeGeneric(t *testing.T) {
+	u, err := Decode[decodeUser]([]byte(`{"name":"a","tags":["x"]}`))
+	if err != nil || u.Name != "a" || len(u.Tags) != 1 {
+		t.Errorf("struct: got %+v, %v", u, err)
+	}
+	p, err := Decode[*decodeAddress]([]byte(`{"city":"c"}`))
+	if err != nil || p == nil || p.City != "c" {
+		t.Errorf("pointer: got %+v, %v", p, err)
+	}
+	m, err := Decode[map[string][]int]([]byte(`{"a":[1,2]}`))
+	if err != nil || len(m["a"]) != 2 {
+		t.Errorf("map: got %v, %v", m, err)
+	}
+	n, err := Decode[int]([]byte(`"x"`))
+	if err == nil || err.Error() != "unmarshal: cannot unmarshal string into int" || n != 0 {
+		t.Errorf("error: got %v, %v", n, err)
+	}
+
+	c, err := DecodeWithOptions[requiredConfig]([]byte(`{"host":"h","port":1,"x":1}`), UnmarshalOptions{DisallowUnknownFields: true})
+	if err == nil || err.Error() != `unmarshal: unknown field "x" at x` {
+		t.Errorf("options: got %+v, %v", c, err)
+	}
+
+	r, err := DecodeReader[[]string](strings.NewReader(`["a","b"]`))
+	if err != nil || len(r) != 2 {
+		t.Errorf("reader: got %v, %v", r, err)
+	}
+	if _, err := DecodeReader[int](iotest.ErrReader(errors.New("read failed"))); err == nil || err.Error() != "read failed" {
+		t.Errorf("reader error: got %v", err)
+	}
+}
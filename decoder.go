@@ -0,0 +1,395 @@
+package yjson
+
+import (
+	"bytes"
+	"context"
+	"io"
+)
+
+const readSize = 4096
+
+// 从 io.Reader 依次读取 JSON 文档, 内部缓冲只保留到当前文档结束, 不需要先把
+// 整个输入读入内存
+type Decoder struct {
+	r       io.Reader
+	opts    ParseOptions
+	buf     []byte
+	scanp   int   // buf 中尚未消费的位置
+	scanned int64 // 已经从 buf 中丢弃的字节数
+	err     error // 读取 r 时遇到的错误, 包括 io.EOF
+
+	// 为 true 时 err 只是暂停读取 (ctx 取消, PushParser 等待数据), 不表示输入结束,
+	// 下一次调用时清除
+	interrupted bool
+	ctx         context.Context
+
+	// Token 的状态, 见 token.go
+	tokenState int
+	tokenStack []int
+
+	// readValue 未完成时的扫描状态
+	scan valueScan
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r, opts: ParseOptions{}.normalize()}
+}
+
+// 解析选项, 同时决定如何识别注释和单引号字符串
+func (dec *Decoder) SetOptions(opts ParseOptions) {
+	dec.opts = opts.normalize()
+}
+
+// 读取并解析下一个文档. 输入结束时返回 io.EOF, 文档不完整时返回 io.ErrUnexpectedEOF
+func (dec *Decoder) Decode(v *Value) error {
+	return dec.DecodeContext(nil, v)
+}
+
+// 同 Decode, 在读取之间和解析过程中检查 ctx, 被取消时返回 ctx.Err().
+// 阻塞中的 Read 无法被打断. 取消后未读完的文档保留在缓冲区中, 可以再次调用继续读取
+func (dec *Decoder) DecodeContext(ctx context.Context, v *Value) error {
+	dec.ctx = ctx
+	defer func() { dec.ctx = nil }()
+
+	data, err := dec.ReadValue()
+	if err != nil {
+		return err
+	}
+	// 数字的 raw 会引用输入, 缓冲区之后会被复用, 需要拷贝
+	j, err := parseDocument(ctx, append([]byte(nil), data...), dec.opts)
+	if err != nil {
+		return err
+	}
+	*v = *j
+	return nil
+}
+
+// 依次读取首尾相连或以空白分隔的多个文档, 如 {"a":1}{"b":2}, 输入结束时返回 nil.
+// fn 返回错误时停止并返回该错误
+func (dec *Decoder) Each(fn func(v *Value) error) error {
+	for {
+		v := &Value{}
+		if err := dec.Decode(v); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+}
+
+// 解析 data 中的所有文档, 见 Decoder.Each
+func ParseAll(data []byte) ([]*Value, error) {
+	return ParseAllWithOptions(data, ParseOptions{})
+}
+
+func ParseAllWithOptions(data []byte, opts ParseOptions) ([]*Value, error) {
+	dec := NewDecoder(bytes.NewReader(data))
+	dec.SetOptions(opts)
+	values := make([]*Value, 0)
+	err := dec.Each(func(v *Value) error {
+		values = append(values, v)
+		return nil
+	})
+	return values, err
+}
+
+// 返回下一个文档的原始字节, 不做语法检查. 结果引用内部缓冲区, 只在下一次读取前有效.
+// 可以和 Token 混用, 用 Token 读到数组或对象内部后逐个读取元素
+func (dec *Decoder) ReadValue() ([]byte, error) {
+	dec.resume()
+	if err := dec.tokenPrepareForDecode(); err != nil {
+		return nil, err
+	}
+	if !dec.tokenValueAllowed() {
+		return nil, dec.tokenError()
+	}
+	data, err := dec.readValue()
+	if err == nil {
+		dec.tokenValueEnd()
+	}
+	return data, err
+}
+
+func (dec *Decoder) readValue() ([]byte, error) {
+	for {
+		if err := dec.skipSpace(); err != nil {
+			return nil, dec.unexpectedEOF()
+		}
+		if n, ok := dec.valueEnd(dec.buf[dec.scanp:], dec.atEOF()); ok {
+			data := dec.buf[dec.scanp : dec.scanp+n]
+			dec.scanp += n
+			return data, nil
+		}
+		if dec.err != nil {
+			return nil, dec.unexpectedEOF()
+		}
+		dec.refill()
+	}
+}
+
+// 当前层级是否还有元素, 即下一个非空白字符不是 ] 或 } 且输入没有结束
+func (dec *Decoder) More() bool {
+	dec.resume()
+	if dec.skipSpace() != nil {
+		return false
+	}
+	c := dec.buf[dec.scanp]
+	if c == DOT && dec.opts.AllowTrailingCommas && (dec.tokenState == tokenArrayComma || dec.tokenState == tokenObjectComma) {
+		// 允许结尾的逗号时要看逗号之后是否是 ] }, 逗号在这里消费掉
+		if dec.tokenState == tokenArrayComma {
+			dec.tokenState = tokenArrayValue
+		} else {
+			dec.tokenState = tokenObjectKey
+		}
+		dec.scanp++
+		if dec.skipSpace() != nil {
+			return false
+		}
+		c = dec.buf[dec.scanp]
+	}
+	return c != RB && c != CB
+}
+
+// 已经读入但还没有解析的数据
+func (dec *Decoder) Buffered() io.Reader {
+	return bytes.NewReader(dec.buf[dec.scanp:])
+}
+
+// 当前在输入中的字节偏移
+func (dec *Decoder) InputOffset() int64 {
+	return dec.scanned + int64(dec.scanp)
+}
+
+// 输入不会再增加
+func (dec *Decoder) atEOF() bool {
+	return dec.err != nil && !dec.interrupted
+}
+
+func (dec *Decoder) resume() {
+	if dec.interrupted {
+		dec.err, dec.interrupted = nil, false
+	}
+}
+
+func (dec *Decoder) unexpectedEOF() error {
+	if dec.err == io.EOF && (dec.scanp < len(dec.buf) || len(dec.tokenStack) > 0) {
+		return io.ErrUnexpectedEOF
+	}
+	return dec.err
+}
+
+// 跳过空白和注释, 直到缓冲区中有下一个有效字符. 输入结束时返回 dec.err
+func (dec *Decoder) skipSpace() error {
+	for {
+		for dec.scanp < len(dec.buf) {
+			c := dec.buf[dec.scanp]
+			if c == BLANK_SPACE || c == HORIZONTAL_TAB || c == LINE_BREAK || c == CARRIAGE_RETURN ||
+				((c == '\v' || c == '\f') && dec.opts.JSON5) {
+				dec.scanp++
+				continue
+			}
+			if c != SLASH || !dec.opts.AllowComments {
+				return nil
+			}
+			n, ok := commentEnd(dec.buf[dec.scanp:], dec.atEOF())
+			if !ok {
+				break
+			}
+			if n == 0 {
+				return nil // 不是注释, 交给解析器报错
+			}
+			dec.scanp += n
+		}
+		if dec.err != nil {
+			// 暂停读取时注释可能还没有结束, 等待更多数据
+			if dec.scanp == len(dec.buf) || dec.interrupted {
+				return dec.err
+			}
+			return nil
+		}
+		dec.refill()
+	}
+}
+
+func (dec *Decoder) refill() {
+	if dec.ctx != nil {
+		if err := dec.ctx.Err(); err != nil {
+			dec.err, dec.interrupted = err, true
+			return
+		}
+	}
+
+	// 丢弃已消费的数据, 空间不够时扩容
+	if dec.scanp > 0 {
+		dec.scanned += int64(dec.scanp)
+		n := copy(dec.buf, dec.buf[dec.scanp:])
+		dec.buf = dec.buf[:n]
+		dec.scanp = 0
+	}
+	if cap(dec.buf)-len(dec.buf) < readSize/2 {
+		buf := make([]byte, len(dec.buf), 2*cap(dec.buf)+readSize)
+		copy(buf, dec.buf)
+		dec.buf = buf
+	}
+
+	n, err := dec.r.Read(dec.buf[len(dec.buf):cap(dec.buf)])
+	dec.buf = dec.buf[:len(dec.buf)+n]
+	if err != nil {
+		dec.err, dec.interrupted = err, err == errNeedMore
+	}
+}
+
+// buf 以 / 开头, 返回注释的长度. 0 表示不是注释, false 表示需要更多数据
+func commentEnd(buf []byte, atEOF bool) (int, bool) {
+	if len(buf) < 2 {
+		return 0, atEOF
+	}
+	switch buf[1] {
+	case SLASH:
+		if i := bytes.IndexByte(buf[2:], LINE_BREAK); i >= 0 {
+			return i + 3, true
+		}
+		if atEOF {
+			return len(buf), true
+		}
+		return 0, false
+	case '*':
+		if i := bytes.Index(buf[2:], []byte("*/")); i >= 0 {
+			return i + 4, true
+		}
+		// 未闭合的块注释交给解析器报错
+		return 0, atEOF
+	}
+	return 0, true
+}
+
+// 找到 buf 开头的一个完整文档的长度. 对象和数组按括号配对, 字符串到闭合的引号,
+// 其他值到第一个不属于数字或字面量的字符为止. 内容是否合法交给解析器检查.
+// atEOF 表示数据不会再增加. 需要更多数据时扫描的位置和状态保存在 dec.scan 中,
+// refill 之后从上次停下的地方继续, 不会重新扫描已经读入的部分
+func (dec *Decoder) valueEnd(buf []byte, atEOF bool) (int, bool) {
+	if len(buf) == 0 {
+		return 0, false
+	}
+	s := &dec.scan
+	if !s.active || s.start != dec.InputOffset() {
+		*s = valueScan{active: true, start: dec.InputOffset()}
+	}
+	if c := buf[0]; c != OB && c != LB && c != DQ && !(c == SQ && dec.opts.JSON5) {
+		return s.scalarEnd(buf, atEOF)
+	}
+
+	i := s.i
+	for ; i < len(buf); i++ {
+		c := buf[i]
+		switch {
+		case s.quote != 0:
+			if s.escaped {
+				s.escaped = false
+			} else if c == BACKSLASH {
+				s.escaped = true
+			} else if c == s.quote {
+				s.quote = 0
+				if s.depth == 0 {
+					return s.done(i + 1)
+				}
+			}
+			continue
+		case s.comment == lineComment:
+			if c == LINE_BREAK {
+				s.comment = noComment
+			}
+			continue
+		case s.comment == blockComment:
+			if s.star && c == SLASH {
+				s.comment = noComment
+			}
+			s.star = c == '*'
+			continue
+		}
+
+		switch c {
+		case DQ:
+			s.quote = c
+		case SQ:
+			if dec.opts.JSON5 {
+				s.quote = c
+			}
+		case OB, LB:
+			s.depth++
+		case CB, RB:
+			s.depth--
+			if s.depth <= 0 {
+				return s.done(i + 1)
+			}
+		case SLASH:
+			if !dec.opts.AllowComments {
+				continue
+			}
+			if i+1 == len(buf) {
+				if atEOF {
+					continue
+				}
+				// 还不知道是不是注释, 下次从 / 开始
+				s.i = i
+				return 0, false
+			}
+			switch buf[i+1] {
+			case SLASH:
+				s.comment = lineComment
+				i++
+			case '*':
+				s.comment, s.star = blockComment, false
+				i++
+			}
+		}
+	}
+	s.i = i
+	return 0, false // 未闭合, 需要更多数据
+}
+
+const (
+	noComment = iota
+	lineComment
+	blockComment
+)
+
+// Decoder.valueEnd 在多次 refill 之间保留的扫描状态. i 是相对于值开头的偏移,
+// refill 丢弃已消费的数据时值的开头和 i 一起移动, 不需要调整
+type valueScan struct {
+	active  bool
+	start   int64 // 值在输入中的偏移, 与当前位置不同时状态已经失效
+	i       int   // 已经扫描过的字节数
+	depth   int
+	quote   byte
+	escaped bool
+	comment int
+	star    bool // 块注释中上一个字符是 *
+}
+
+func (s *valueScan) done(n int) (int, bool) {
+	*s = valueScan{}
+	return n, true
+}
+
+// 数字, true, false, null, NaN 之类的值只含字母数字和 +-. , 其他字符单独作为
+// 一个文档, 让解析器报告错误
+func (s *valueScan) scalarEnd(buf []byte, atEOF bool) (int, bool) {
+	for i := s.i; i < len(buf); i++ {
+		if !isScalarByte(buf[i]) {
+			if i == 0 {
+				return s.done(1)
+			}
+			return s.done(i)
+		}
+	}
+	if atEOF {
+		return s.done(len(buf))
+	}
+	s.i = len(buf)
+	return 0, false
+}
@@ -0,0 +1,239 @@
+package yjson
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func decodeAll(t *testing.T, r io.Reader, opts ParseOptions) []string {
+	t.Helper()
+	dec := NewDecoder(r)
+	dec.SetOptions(opts)
+	var out []string
+	err := dec.Each(func(v *Value) error {
+		out = append(out, mustEncode(t, v))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return out
+}
+
+func TestDecoder(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  ParseOptions
+		want  []string
+	}{
+		{"single", `{"a":1}`, ParseOptions{}, []string{`{"a":1}`}},
+		{"concatenated", `{"a":1}{"b":2}[3]`, ParseOptions{}, []string{`{"a":1}`, `{"b":2}`, `[3]`}},
+		{"whitespace", " 1\n\t\"x\" true null ", ParseOptions{}, []string{`1`, `"x"`, `true`, `null`}},
+		{"brackets in strings", `["]}", "\"[{"] {"k}":"{"}`, ParseOptions{}, []string{`["]}","\"[{"]`, `{"k}":"{"}`}},
+		{"escaped backslash", `"a\\" "b"`, ParseOptions{}, []string{`"a\\"`, `"b"`}},
+		{"nested", `{"a":[{"b":[]}]} 2`, ParseOptions{}, []string{`{"a":[{"b":[]}]}`, `2`}},
+		{"number at end", `[1] 12.5e3`, ParseOptions{}, []string{`[1]`, `12500`}},
+		{
+			"comments",
+			"// lead\n{\"a\": /* } */ 1, // ]\n\"b\": 2} /**/ [3]",
+			ParseOptions{AllowComments: true},
+			[]string{`{"a":1,"b":2}`, `[3]`},
+		},
+		{"slash in string", `["//", "/*"]`, ParseOptions{AllowComments: true}, []string{`["//","/*"]`}},
+		{"single quotes", `{'a': ']'} ['}']`, ParseOptions{JSON5: true}, []string{`{"a":"]"}`, `["}"]`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readers := map[string]io.Reader{
+				"whole":    strings.NewReader(tt.input),
+				"one byte": iotest.OneByteReader(strings.NewReader(tt.input)),
+			}
+			for rn, r := range readers {
+				got := decodeAll(t, r, tt.opts)
+				if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+					t.Errorf("%s: got %q, want %q", rn, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestDecoderErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  error
+	}{
+		{`{"a":1`, io.ErrUnexpectedEOF},
+		{`["abc`, io.ErrUnexpectedEOF},
+		{`{} [`, io.ErrUnexpectedEOF},
+	}
+	for _, tt := range tests {
+		dec := NewDecoder(iotest.OneByteReader(strings.NewReader(tt.input)))
+		err := dec.Each(func(*Value) error { return nil })
+		if err != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.input, err, tt.want)
+		}
+	}
+}
+
+// 每次只读入一个字节时, 扫描从上次停下的地方继续, 总时间与输入长度成正比.
+// 每次 refill 后从头重新扫描需要约 n²/2 次比较, 这里会超时
+func TestDecoderSlowReader(t *testing.T) {
+	var b bytes.Buffer
+	b.WriteString(`{"items":[`)
+	for i := 0; b.Len() < 256<<10; i++ {
+		if i > 0 {
+			b.WriteByte(DOT)
+		}
+		b.WriteString(`{"s":"a\"]}","n":[1,2,3]}`)
+	}
+	b.WriteString(`]} "tail"`)
+
+	got := decodeAll(t, iotest.OneByteReader(bytes.NewReader(b.Bytes())), ParseOptions{})
+	if len(got) != 2 || got[1] != `"tail"` {
+		t.Fatalf("got %d documents", len(got))
+	}
+	if !strings.HasPrefix(got[0], `{"items":[{"s":"a\"]}"`) {
+		t.Errorf("got %.40s", got[0])
+	}
+}
+
+// PushParser 在数据不足时中断 Decode, 之后追加的数据接着上次的状态扫描
+func TestDecoderPushResume(t *testing.T) {
+	input := `{"a":"}{"} [1,` + "\n" + `2] "x\"y" 42`
+	var got []string
+	p := NewPushParser(func(v *Value) error {
+		got = append(got, mustEncode(t, v))
+		return nil
+	})
+	for i := 0; i < len(input); i++ {
+		if _, err := p.Write([]byte{input[i]}); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	want := []string{`{"a":"}{"}`, `[1,2]`, `"x\"y"`, `42`}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// Token 读到数组内部后, ReadValue 逐个读取元素, 每个元素都重新开始扫描
+func TestDecoderTokenThenReadValue(t *testing.T) {
+	dec := NewDecoder(iotest.OneByteReader(strings.NewReader(`[{"a":[1]}, "b", 3]`)))
+	if _, err := dec.Token(); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for dec.More() {
+		data, err := dec.ReadValue()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(data))
+	}
+	want := []string{`{"a":[1]}`, `"b"`, `3`}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	tests := []struct {
+		input string
+		opts  ParseOptions
+		want  string
+	}{
+		{``, ParseOptions{}, ``},
+		{" \n\t", ParseOptions{}, ``},
+		{`{"a":1}{"b":2}`, ParseOptions{}, `{"a":1} {"b":2}`},
+		{`[1][2] [3]`, ParseOptions{}, `[1] [2] [3]`},
+		{`1 2 "a""b"`, ParseOptions{}, `1 2 "a" "b"`},
+		{`true null{}`, ParseOptions{}, `true null {}`},
+		{"{} /* c */ []", ParseOptions{AllowComments: true}, `{} []`},
+		{`{"a":1`, ParseOptions{}, "error: unexpected EOF"},
+		{`{} {"a" 1}`, ParseOptions{}, "error: "},
+	}
+	for _, tt := range tests {
+		values, err := ParseAllWithOptions([]byte(tt.input), tt.opts)
+		if want, ok := strings.CutPrefix(tt.want, "error: "); ok {
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("%q: got error %v, want %q", tt.input, err, want)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%q: %v", tt.input, err)
+		}
+		got := make([]string, len(values))
+		for i, v := range values {
+			got[i] = mustEncode(t, v)
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("%q: got %q, want %s", tt.input, got, tt.want)
+		}
+	}
+
+	if values, err := ParseAll(nil); err != nil || values == nil || len(values) != 0 {
+		t.Errorf("empty input: got %v, %v", values, err)
+	}
+}
+
+// fn 返回的错误原样返回, 之后的文档不再读取
+func TestDecoderEachStops(t *testing.T) {
+	stop := errors.New("stop")
+	dec := NewDecoder(strings.NewReader(`1 2 3`))
+	n := 0
+	err := dec.Each(func(v *Value) error {
+		n++
+		if n == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || n != 2 {
+		t.Errorf("got %v after %d documents", err, n)
+	}
+	var v Value
+	if err := dec.Decode(&v); err != nil || mustEncode(t, &v) != `3` {
+		t.Errorf("remaining: got %v", err)
+	}
+}
+
+// ctx 在读取之间被检查, 取消后未读完的文档留在缓冲区中, 之后可以继续读取
+func TestDecodeContext(t *testing.T) {
+	input := `{"a": [1, 2, 3], "b": "x"} [4]`
+	dec := NewDecoder(iotest.OneByteReader(strings.NewReader(input)))
+	var v Value
+	if err := dec.DecodeContext(newCancelAfter(5), &v); err != context.Canceled {
+		t.Fatalf("got %v", err)
+	}
+	if dec.InputOffset() != 0 {
+		t.Errorf("offset after cancel: %d", dec.InputOffset())
+	}
+	if err := dec.DecodeContext(context.Background(), &v); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustEncode(t, &v); got != `{"a":[1,2,3],"b":"x"}` {
+		t.Errorf("got %s", got)
+	}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := dec.DecodeContext(canceled, &v); err != context.Canceled {
+		t.Errorf("canceled: got %v", err)
+	}
+	if err := dec.Decode(&v); err != nil || mustEncode(t, &v) != `[4]` {
+		t.Errorf("after cancel: got %v", err)
+	}
+	if err := dec.Decode(&v); err != io.EOF {
+		t.Errorf("end of input: got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package yjson
+
+import "strings"
+
+// Diff 找到的差异的种类
+type ChangeKind int
+
+const (
+	ChangeAdded    ChangeKind = iota // 只在 b 中存在
+	ChangeRemoved                    // 只在 a 中存在
+	ChangeModified                   // 两边都存在但不相等
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "modified"
+	}
+	return "unknown"
+}
+
+// Diff 找到的一处差异. Before 和 After 引用 a 和 b 中的值, 新增时 Before 为 nil,
+// 删除时 After 为 nil
+type Change struct {
+	Kind   ChangeKind
+	Path   string // Get 的路径语法, 根节点为空字符串
+	Before *Value
+	After  *Value
+}
+
+type DiffReport struct {
+	Changes []Change
+}
+
+// 比较 a 和 b, 按文档顺序返回新增, 删除和修改的路径. 对象按成员比较;
+// 数组的对齐方式与 CreatePatch 相同, 删除的元素使用在 a 中的下标, 其他元素使用
+// 在 b 中的下标. 数字和对象的比较规则与 JSON Patch 的 test 操作相同
+func Diff(a, b *Value) (*DiffReport, error) {
+	r := &DiffReport{Changes: make([]Change, 0)}
+	if err := r.diff("", a, b); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *DiffReport) add(kind ChangeKind, path string, before, after *Value) {
+	r.Changes = append(r.Changes, Change{Kind: kind, Path: path, Before: before, After: after})
+}
+
+func (r *DiffReport) diff(path string, a, b *Value) error {
+	if err := a.load(); err != nil {
+		return err
+	}
+	if err := b.load(); err != nil {
+		return err
+	}
+	switch {
+	case a.Type() == JSON_OBJECT && b.Type() == JSON_OBJECT:
+		for _, key := range a.keys {
+			child := joinKeyPath(path, key)
+			if bv, ok := b.obj[key]; ok {
+				if err := r.diff(child, a.obj[key], bv); err != nil {
+					return err
+				}
+			} else {
+				r.add(ChangeRemoved, child, a.obj[key], nil)
+			}
+		}
+		for _, key := range b.keys {
+			if _, ok := a.obj[key]; !ok {
+				r.add(ChangeAdded, joinKeyPath(path, key), nil, b.obj[key])
+			}
+		}
+		return nil
+	case a.Type() == JSON_ARRAY && b.Type() == JSON_ARRAY:
+		edits, err := alignArrays(a.arr, b.arr)
+		if err != nil {
+			return err
+		}
+		for _, e := range edits {
+			switch e.op {
+			case editChange:
+				if err := r.diff(joinIndexPath(path, e.j), a.arr[e.i], b.arr[e.j]); err != nil {
+					return err
+				}
+			case editRemove:
+				r.add(ChangeRemoved, joinIndexPath(path, e.i), a.arr[e.i], nil)
+			case editAdd:
+				r.add(ChangeAdded, joinIndexPath(path, e.j), nil, b.arr[e.j])
+			}
+		}
+		return nil
+	}
+	if !equalValues(a, b) {
+		r.add(ChangeModified, path, a, b)
+	}
+	return nil
+}
+
+// 两个值是否没有差异
+func (r *DiffReport) Equal() bool {
+	return len(r.Changes) == 0
+}
+
+// 每处差异一行, 值为紧凑的 JSON, 根节点的路径显示为 $. 新增, 删除和修改分别以
+// + - ~ 开头, 如 ~ user.name: "alice" -> "bob"
+func (r *DiffReport) String() string {
+	var sb strings.Builder
+	for _, c := range r.Changes {
+		path := c.Path
+		if path == "" {
+			path = "$"
+		}
+		switch c.Kind {
+		case ChangeAdded:
+			sb.WriteString("+ " + path + ": " + diffText(c.After))
+		case ChangeRemoved:
+			sb.WriteString("- " + path + ": " + diffText(c.Before))
+		default:
+			sb.WriteString("~ " + path + ": " + diffText(c.Before) + " -> " + diffText(c.After))
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+func diffText(v *Value) string {
+	b, err := v.Encode()
+	if err != nil {
+		return "<" + err.Error() + ">"
+	}
+	return string(b)
+}
@@ -0,0 +1,59 @@
+package yjson
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{`{"a":1}`, `{"a":1.0}`, ``},
+		{`{"a":1,"b":2}`, `{"a":1,"c":3}`, "- b: 2\n+ c: 3\n"},
+		{`{"user":{"name":"alice"}}`, `{"user":{"name":"bob"}}`, "~ user.name: \"alice\" -> \"bob\"\n"},
+		{`{"a.b":1}`, `{"a.b":2}`, "~ [\"a.b\"]: 1 -> 2\n"},
+		{`[1,2,3]`, `[1,3,4]`, "- [1]: 2\n+ [2]: 4\n"},
+		{`[{"v":1}]`, `[{"v":2}]`, "~ [0].v: 1 -> 2\n"},
+		{`1`, `"x"`, "~ $: 1 -> \"x\"\n"},
+		{`{"id":9007199254740993}`, `{"id":9007199254740992}`, "~ id: 9007199254740993 -> 9007199254740992\n"},
+		{`[9007199254740993]`, `[9007199254740992]`, "~ [0]: 9007199254740993 -> 9007199254740992\n"},
+	}
+	for _, tt := range tests {
+		r, err := Diff(mustParse(t, tt.a), mustParse(t, tt.b))
+		if err != nil {
+			t.Fatalf("Diff(%s, %s): %v", tt.a, tt.b, err)
+		}
+		if got := r.String(); got != tt.want {
+			t.Errorf("Diff(%s, %s) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+		if r.Equal() != (tt.want == "") {
+			t.Errorf("Diff(%s, %s).Equal() = %v", tt.a, tt.b, r.Equal())
+		}
+	}
+}
+
+func TestDiffChanges(t *testing.T) {
+	a, b := mustParse(t, `{"x":1,"y":[1]}`), mustParse(t, `{"y":[1,2],"z":null}`)
+	r, err := Diff(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		kind ChangeKind
+		path string
+	}{
+		{ChangeRemoved, "x"},
+		{ChangeAdded, "y[1]"},
+		{ChangeAdded, "z"},
+	}
+	if len(r.Changes) != len(want) {
+		t.Fatalf("got %d changes, want %d: %s", len(r.Changes), len(want), r)
+	}
+	for i, c := range r.Changes {
+		if c.Kind != want[i].kind || c.Path != want[i].path {
+			t.Errorf("change %d: got %s %s, want %s %s", i, c.Kind, c.Path, want[i].kind, want[i].path)
+		}
+	}
+	if r.Changes[0].Before != a.Get("x") || r.Changes[0].After != nil {
+		t.Error("removed change should reference the value in a")
+	}
+}
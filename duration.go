@@ -0,0 +1,96 @@
+package yjson
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+var durationReflectType = reflect.TypeOf(time.Duration(0))
+
+// unit 标签的取值. "string" 表示输出为 "1m30s" 形式的字符串
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+}
+
+func durationUnit(unit string) (time.Duration, error) {
+	if unit == "" || unit == "string" {
+		return time.Nanosecond, nil
+	}
+	if u, ok := durationUnits[unit]; ok {
+		return u, nil
+	}
+	return 0, fmt.Errorf("unknown duration unit %q", unit)
+}
+
+// 字符串按 time.ParseDuration 解析, 如 "5s", "1h30m". 数字的单位由 unit 标签决定,
+// 默认为纳秒, 与 encoding/json 相同. null 保持原值
+func (d *decodeState) decodeDuration(path string, j *Value, rv reflect.Value, unit string) error {
+	var (
+		v   time.Duration
+		err error
+	)
+	switch j.Type() {
+	case JSON_NULL:
+		return nil
+	case JSON_STRING:
+		v, err = time.ParseDuration(j.str)
+	case JSON_NUMBER:
+		v, err = numberDuration(j, unit)
+	default:
+		return d.typeError(path, j, rv.Type())
+	}
+	if err != nil {
+		if path == "" {
+			return fmt.Errorf("unmarshal: %v", err)
+		}
+		return fmt.Errorf("unmarshal: %v at %s", err, path)
+	}
+	rv.SetInt(int64(v))
+	return nil
+}
+
+func numberDuration(j *Value, unit string) (time.Duration, error) {
+	u, err := durationUnit(unit)
+	if err != nil {
+		return 0, err
+	}
+	if i, err := j.Int64(); err == nil {
+		if i > math.MaxInt64/int64(u) || i < math.MinInt64/int64(u) {
+			return 0, fmt.Errorf("duration %d%s overflows time.Duration", i, unit)
+		}
+		return time.Duration(i) * u, nil
+	}
+
+	f, err := j.Float64()
+	if err != nil {
+		return 0, err
+	}
+	ns := math.Round(f * float64(u))
+	if math.IsNaN(ns) || ns >= math.MaxInt64 || ns < math.MinInt64 {
+		return 0, fmt.Errorf("duration %v%s overflows time.Duration", j.number(), unit)
+	}
+	return time.Duration(ns), nil
+}
+
+// 没有 unit 标签时输出纳秒整数. 有单位时整除输出整数, 否则输出小数
+func marshalDuration(rv reflect.Value, unit string) (*Value, error) {
+	v := time.Duration(rv.Int())
+	if unit == "string" {
+		return NewString(v.String()), nil
+	}
+	u, err := durationUnit(unit)
+	if err != nil {
+		return nil, err
+	}
+	if v%u == 0 {
+		return NewInt(int64(v / u)), nil
+	}
+	return NewFloat(float64(v) / float64(u)), nil
+}
@@ -0,0 +1,107 @@
+package yjson
+
+import (
+	"testing"
+	"time"
+)
+
+type durationFields struct {
+	Default time.Duration  `json:"default"`
+	Millis  time.Duration  `json:"millis" unit:"ms"`
+	Seconds *time.Duration `json:"seconds" unit:"s"`
+	Text    time.Duration  `json:"text" unit:"string"`
+	Bad     time.Duration  `json:"bad" unit:"fortnight"`
+}
+
+func TestDecodeDuration(t *testing.T) {
+	tests := []struct {
+		input string
+		get   func(v *durationFields) time.Duration
+		want  time.Duration
+	}{
+		{`{"default":"1h30m"}`, func(v *durationFields) time.Duration { return v.Default }, 90 * time.Minute},
+		{`{"default":"-1.5s"}`, func(v *durationFields) time.Duration { return v.Default }, -1500 * time.Millisecond},
+		{`{"default":1500}`, func(v *durationFields) time.Duration { return v.Default }, 1500},
+		{`{"millis":250}`, func(v *durationFields) time.Duration { return v.Millis }, 250 * time.Millisecond},
+		{`{"millis":0.5}`, func(v *durationFields) time.Duration { return v.Millis }, 500 * time.Microsecond},
+		{`{"millis":"2s"}`, func(v *durationFields) time.Duration { return v.Millis }, 2 * time.Second},
+		{`{"seconds":30}`, func(v *durationFields) time.Duration { return *v.Seconds }, 30 * time.Second},
+		{`{"text":"5s"}`, func(v *durationFields) time.Duration { return v.Text }, 5 * time.Second},
+		{`{"text":5}`, func(v *durationFields) time.Duration { return v.Text }, 5},
+	}
+	for _, tt := range tests {
+		var v durationFields
+		if err := Unmarshal([]byte(tt.input), &v); err != nil {
+			t.Errorf("%s: %v", tt.input, err)
+			continue
+		}
+		if got := tt.get(&v); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.input, got, tt.want)
+		}
+	}
+
+	// 顶层和切片中的 time.Duration 没有标签, 数字为纳秒
+	d, err := Decode[[]time.Duration]([]byte(`["1m", 1000]`))
+	if err != nil || len(d) != 2 || d[0] != time.Minute || d[1] != time.Microsecond {
+		t.Errorf("slice: got %v, %v", d, err)
+	}
+
+	v := durationFields{Default: time.Second}
+	if err := Unmarshal([]byte(`{"default":null,"seconds":null}`), &v); err != nil || v.Default != time.Second || v.Seconds != nil {
+		t.Errorf("null: got %+v, %v", v, err)
+	}
+}
+
+func TestDecodeDurationErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"default":"soon"}`, `unmarshal: time: invalid duration "soon" at default`},
+		{`{"default":true}`, `unmarshal: cannot unmarshal boolean into time.Duration at default`},
+		{`{"seconds":9223372037}`, `unmarshal: duration 9223372037s overflows time.Duration at seconds`},
+		{`{"seconds":1e300}`, `unmarshal: duration 1e+300s overflows time.Duration at seconds`},
+		{`{"bad":1}`, `unmarshal: unknown duration unit "fortnight" at bad`},
+	}
+	for _, tt := range tests {
+		var v durationFields
+		err := Unmarshal([]byte(tt.input), &v)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: got %v, want %s", tt.input, err, tt.want)
+		}
+	}
+}
+
+type durationOut struct {
+	Default time.Duration  `json:"default"`
+	Millis  time.Duration  `json:"millis" unit:"ms"`
+	Seconds *time.Duration `json:"seconds" unit:"s"`
+	Text    time.Duration  `json:"text" unit:"string"`
+}
+
+func TestMarshalDuration(t *testing.T) {
+	s := 90 * time.Second
+	tests := []struct {
+		v    durationOut
+		want string
+	}{
+		{durationOut{}, `{"default":0,"millis":0,"seconds":null,"text":"0s"}`},
+		{durationOut{Default: 1500, Millis: 1500 * time.Microsecond, Seconds: &s, Text: s},
+			`{"default":1500,"millis":1.5,"seconds":90,"text":"1m30s"}`},
+	}
+	for _, tt := range tests {
+		got, err := Marshal(tt.v)
+		if err != nil || string(got) != tt.want {
+			t.Errorf("got %s, %v, want %s", got, err, tt.want)
+			continue
+		}
+		var back durationOut
+		if err := Unmarshal(got, &back); err != nil || back.Default != tt.v.Default || back.Millis != tt.v.Millis || back.Text != tt.v.Text {
+			t.Errorf("round trip: got %+v, %v", back, err)
+		}
+	}
+
+	if _, err := Marshal(durationFields{}); err == nil || err.Error() != `marshal: unknown duration unit "fortnight" at bad` {
+		t.Errorf("bad unit: got %v", err)
+	}
+}
@@ -0,0 +1,425 @@
+package yjson
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// 序列化选项, 零值输出紧凑的标准 JSON
+type EncodeOptions struct {
+	// 输出 NaN, Infinity, -Infinity, 否则遇到这些值时返回错误
+	AllowNaN bool
+
+	// 非空时换行缩进输出, 与 json.MarshalIndent 相同: 每个新行以 Prefix 开头,
+	// 之后按嵌套层数重复 Indent
+	Prefix string
+	Indent string
+
+	// 按键的字典序 (字节序) 输出对象成员, 而不是按原始顺序
+	SortKeys bool
+
+	// 把 <, >, & 和 U+2028, U+2029 转义为 \uXXXX, 使输出可以直接嵌入 <script>
+	EscapeHTML bool
+
+	// 所有非 ASCII 字符转义为 \uXXXX (BMP 之外使用代理对), 输出只包含 ASCII
+	EscapeNonASCII bool
+
+	// 非 nil 时用 ANSI 转义序列为不同类型的值着色, 见 DefaultColors
+	Colors *ColorScheme
+
+	// RFC 8785 规范化输出, 开启后忽略其他所有选项
+	Canonical bool
+}
+
+type encoder struct {
+	opts  EncodeOptions
+	depth int
+	w     io.Writer // 非 nil 时缓冲区超过 flushSize 就写出
+}
+
+const flushSize = 4096
+
+// 流式输出时把已经生成的部分写出, 返回清空后的缓冲区
+func (e *encoder) flush(dst []byte) ([]byte, error) {
+	if e.w == nil || len(dst) < flushSize {
+		return dst, nil
+	}
+	_, err := e.w.Write(dst)
+	return dst[:0], err
+}
+
+func (j *Value) Encode() ([]byte, error) {
+	return j.EncodeWithOptions(EncodeOptions{})
+}
+
+func (j *Value) EncodeWithOptions(opts EncodeOptions) ([]byte, error) {
+	return AppendJSONWithOptions(make([]byte, 0, 64), j, opts)
+}
+
+// 把 v 序列化后追加到 dst, 风格与 strconv.Append* 相同, 便于复用缓冲区
+func AppendJSON(dst []byte, v *Value) ([]byte, error) {
+	return AppendJSONWithOptions(dst, v, EncodeOptions{})
+}
+
+func AppendJSONWithOptions(dst []byte, v *Value, opts EncodeOptions) ([]byte, error) {
+	if opts.Canonical {
+		opts = EncodeOptions{Canonical: true}
+	}
+	e := encoder{opts: opts}
+	return e.appendValue(dst, v)
+}
+
+// 追加带引号并转义的 JSON 字符串
+func AppendString(dst []byte, s string) []byte {
+	e := encoder{}
+	return e.appendString(dst, s)
+}
+
+func AppendInt(dst []byte, i int64) []byte {
+	return strconv.AppendInt(dst, i, 10)
+}
+
+func AppendUint(dst []byte, u uint64) []byte {
+	return strconv.AppendUint(dst, u, 10)
+}
+
+// NaN 和 Inf 不是合法的 JSON 数字, 返回错误
+func AppendFloat(dst []byte, f float64) ([]byte, error) {
+	e := encoder{}
+	return e.appendFloat(dst, f)
+}
+
+func AppendBool(dst []byte, b bool) []byte {
+	if b {
+		return append(dst, TRUE...)
+	}
+	return append(dst, FALSE...)
+}
+
+func AppendNull(dst []byte) []byte {
+	return append(dst, NULL...)
+}
+
+// 缩进输出, 如 EncodeIndent("", "  ") 或 EncodeIndent("", "\t")
+func (j *Value) EncodeIndent(prefix, indent string) ([]byte, error) {
+	return j.EncodeWithOptions(EncodeOptions{Prefix: prefix, Indent: indent})
+}
+
+// 实现 io.WriterTo
+func (j *Value) WriteTo(w io.Writer) (int64, error) {
+	buf, err := j.Encode()
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+func (e *encoder) appendValue(dst []byte, j *Value) ([]byte, error) {
+	if color := e.colorFor(j.Type()); color != "" {
+		dst = append(dst, color...)
+		dst, err := e.appendScalar(dst, j)
+		return append(dst, ANSI_RESET...), err
+	}
+	return e.appendScalar(dst, j)
+}
+
+func (e *encoder) appendScalar(dst []byte, j *Value) ([]byte, error) {
+	switch j.Type() {
+	case JSON_NULL:
+		return append(dst, NULL...), nil
+	case JSON_BOOLEAN:
+		return AppendBool(dst, j.b), nil
+	case JSON_STRING:
+		return e.appendString(dst, j.str), nil
+	case JSON_NUMBER:
+		return e.appendNumber(dst, j)
+	case JSON_ARRAY:
+		return e.appendArray(dst, j)
+	case JSON_OBJECT:
+		return e.appendObject(dst, j)
+	}
+	return dst, fmt.Errorf("cannot encode %s value", j.Type())
+}
+
+func (e *encoder) indented() bool {
+	return e.opts.Prefix != "" || e.opts.Indent != ""
+}
+
+func (e *encoder) appendNewline(dst []byte) []byte {
+	if !e.indented() {
+		return dst
+	}
+	dst = append(dst, LINE_BREAK)
+	dst = append(dst, e.opts.Prefix...)
+	for i := 0; i < e.depth; i++ {
+		dst = append(dst, e.opts.Indent...)
+	}
+	return dst
+}
+
+func (e *encoder) appendArray(dst []byte, j *Value) ([]byte, error) {
+	if err := j.load(); err != nil {
+		return dst, err
+	}
+	var err error
+	arr := j.arr
+	if len(arr) == 0 {
+		return append(dst, LB, RB), nil
+	}
+
+	dst = append(dst, LB)
+	e.depth++
+	for i, v := range arr {
+		if i > 0 {
+			dst = append(dst, DOT)
+		}
+		dst = e.appendNewline(dst)
+		dst, err = e.appendValue(dst, v)
+		if err != nil {
+			return dst, err
+		}
+		dst, err = e.flush(dst)
+		if err != nil {
+			return dst, err
+		}
+	}
+	e.depth--
+	dst = e.appendNewline(dst)
+	return append(dst, RB), nil
+}
+
+func (e *encoder) appendObject(dst []byte, j *Value) ([]byte, error) {
+	if err := j.load(); err != nil {
+		return dst, err
+	}
+	var err error
+	m := j.obj
+	if len(j.keys) == 0 {
+		return append(dst, OB, CB), nil
+	}
+
+	keys := j.keys
+	if e.opts.SortKeys || e.opts.Canonical {
+		keys = make([]string, len(j.keys))
+		copy(keys, j.keys)
+		if e.opts.Canonical {
+			sortKeysUTF16(keys)
+		} else {
+			sort.Strings(keys)
+		}
+	}
+
+	dst = append(dst, OB)
+	e.depth++
+	for i, k := range keys {
+		if i > 0 {
+			dst = append(dst, DOT)
+		}
+		dst = e.appendNewline(dst)
+		dst = e.appendColoredKey(dst, k)
+		dst = append(dst, VALUE_SEPARATOR)
+		if e.indented() {
+			dst = append(dst, BLANK_SPACE)
+		}
+		dst, err = e.appendValue(dst, m[k])
+		if err != nil {
+			return dst, err
+		}
+		dst, err = e.flush(dst)
+		if err != nil {
+			return dst, err
+		}
+	}
+	e.depth--
+	dst = e.appendNewline(dst)
+	return append(dst, CB), nil
+}
+
+const hexDigits = "0123456789abcdef"
+
+func (e *encoder) appendString(dst []byte, s string) []byte {
+	dst = append(dst, DQ)
+	start := 0
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			if b >= BLANK_SPACE && b != DQ && b != BACKSLASH && !(e.opts.EscapeHTML && isHTMLSpecial(b)) {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch b {
+			case DQ, BACKSLASH:
+				dst = append(dst, BACKSLASH, b)
+			case '\b':
+				dst = append(dst, BACKSLASH, 'b')
+			case '\f':
+				dst = append(dst, BACKSLASH, 'f')
+			case '\n':
+				dst = append(dst, BACKSLASH, 'n')
+			case '\r':
+				dst = append(dst, BACKSLASH, 'r')
+			case '\t':
+				dst = append(dst, BACKSLASH, 't')
+			default:
+				dst = appendUnicodeEscape(dst, rune(b))
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 && !e.opts.EscapeNonASCII {
+			// 非法的 UTF-8 替换为 U+FFFD
+			dst = append(dst, s[start:i]...)
+			dst = utf8.AppendRune(dst, utf8.RuneError)
+			i += size
+			start = i
+			continue
+		}
+		if e.opts.EscapeNonASCII {
+			dst = append(dst, s[start:i]...)
+			if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+				dst = appendUnicodeEscape(dst, r1)
+				dst = appendUnicodeEscape(dst, r2)
+			} else {
+				dst = appendUnicodeEscape(dst, r)
+			}
+			i += size
+			start = i
+			continue
+		}
+		if e.opts.EscapeHTML && (r == 0x2028 || r == 0x2029) {
+			dst = append(dst, s[start:i]...)
+			dst = appendUnicodeEscape(dst, r)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, DQ)
+}
+
+func isHTMLSpecial(b byte) bool {
+	return b == '<' || b == '>' || b == '&'
+}
+
+// \uXXXX, 只用于 BMP 内的码点
+func appendUnicodeEscape(dst []byte, r rune) []byte {
+	return append(dst, BACKSLASH, 'u', hexDigits[r>>12&0xF], hexDigits[r>>8&0xF], hexDigits[r>>4&0xF], hexDigits[r&0xF])
+}
+
+func (e *encoder) appendNumber(dst []byte, j *Value) ([]byte, error) {
+	if e.opts.Canonical {
+		f, err := canonicalFloat(j.number())
+		if err != nil && isJSONNumber(string(j.raw)) {
+			f, err = strconv.ParseFloat(string(j.raw), 64)
+		}
+		if err != nil {
+			return dst, err
+		}
+		return appendES6Number(dst, f)
+	}
+
+	switch j.repr {
+	case REPR_INT:
+		return strconv.AppendInt(dst, j.i, 10), nil
+	case REPR_UINT:
+		return strconv.AppendUint(dst, uint64(j.i), 10), nil
+	case REPR_FLOAT:
+		return e.appendFloat(dst, j.f)
+	}
+
+	switch n := j.num.(type) {
+	case Number:
+		return e.appendRawNumber(dst, n)
+	case *big.Int:
+		return n.Append(dst, 10), nil
+	case *big.Float:
+		if n.IsInf() {
+			f, _ := n.Float64()
+			return e.appendFloat(dst, f)
+		}
+		return n.Append(dst, 'g', -1), nil
+	}
+	// NumberHook 构造的值: 解析得到的值输出源文本, 否则要求 String() 是合法的 JSON 数字.
+	// *big.Rat 之类的 String() 不是 JSON 数字, 只能靠源文本
+	if isJSONNumber(string(j.raw)) {
+		return append(dst, j.raw...), nil
+	}
+	if n, ok := j.num.(fmt.Stringer); ok {
+		return e.appendRawNumber(dst, Number(n.String()))
+	}
+	return dst, fmt.Errorf("cannot encode number of type %T", j.num)
+}
+
+func (e *encoder) appendFloat(dst []byte, f float64) ([]byte, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		if !e.opts.AllowNaN {
+			return dst, fmt.Errorf("cannot encode %v without AllowNaN", f)
+		}
+		switch {
+		case math.IsNaN(f):
+			return append(dst, NAN...), nil
+		case f > 0:
+			return append(dst, INFINITY...), nil
+		default:
+			return append(dst, "-"+INFINITY...), nil
+		}
+	}
+
+	// 与 encoding/json 相同: 指数过大或过小时使用科学计数法
+	abs := math.Abs(f)
+	format := byte('f')
+	if abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	dst = strconv.AppendFloat(dst, f, format, -1, 64)
+	if format == 'e' {
+		// 1e-07 => 1e-7
+		n := len(dst)
+		if n >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+			dst[n-2] = dst[n-1]
+			dst = dst[:n-1]
+		}
+	}
+	return dst, nil
+}
+
+// 源文本若是合法的 RFC 8259 数字则原样输出, 否则 (十六进制, 前导 + 等宽松写法)
+// 先转换再输出
+func (e *encoder) appendRawNumber(dst []byte, n Number) ([]byte, error) {
+	if isJSONNumber(string(n)) {
+		return append(dst, n...), nil
+	}
+	if i, err := n.Int64(); err == nil {
+		return strconv.AppendInt(dst, i, 10), nil
+	}
+	if u, err := n.Uint64(); err == nil {
+		return strconv.AppendUint(dst, u, 10), nil
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return dst, fmt.Errorf("cannot encode number %q", string(n))
+	}
+	return e.appendFloat(dst, f)
+}
+
+func isJSONNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	p := &Parser{buf: []byte(s), len: len(s), opts: ParseOptions{Strict: true}.normalize()}
+	v := &Value{}
+	return s[0] != PLUS && p.parseNumber(v) == nil && p.i == p.len
+}
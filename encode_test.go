@@ -0,0 +1,302 @@
+package yjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`null`, `null`},
+		{`true`, `true`},
+		{` [ 1 , -2 , 3.5 ] `, `[1,-2,3.5]`},
+		{`{ "a" : { } , "b" : [ ] }`, `{"a":{},"b":[]}`},
+		{`1E2`, `100`},
+		{`1e-7`, `1e-7`},
+		{`1e21`, `1e+21`},
+		{`123456789e12`, `123456789000000000000`},
+		{`-0.0`, `-0`},
+		{`"a\"b\\c"`, `"a\"b\\c"`},
+		{`"\n\r\t\b\f"`, `"\n\r\t\b\f"`},
+		{`"\u0001\u001f"`, `"\u0001\u001f"`},
+		{`"\u00e9\u2028<&>"`, `"é <&>"`},
+		{`"\ud83d\ude00"`, `"😀"`},
+		{`"\/"`, `"/"`},
+	}
+	for _, tt := range tests {
+		if got := mustEncode(t, mustParse(t, tt.input)); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeBuiltValues(t *testing.T) {
+	obj := NewObject()
+	obj.Set("s", NewString("x"))
+	obj.Set("arr", NewArray(NewInt(-1), NewUint(math.MaxUint64), NewFloat(0.1), NewBool(false), NewNull()))
+	want := `{"s":"x","arr":[-1,18446744073709551615,0.1,false,null]}`
+	if got := mustEncode(t, obj); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	// 非法的 UTF-8 替换为 U+FFFD, 与 encoding/json 相同
+	got := mustEncode(t, NewString("a\xffb"))
+	if std, _ := json.Marshal("a\xffb"); got != string(std) {
+		t.Errorf("invalid UTF-8: got %s, encoding/json %s", got, std)
+	}
+
+	var missing *Value
+	if _, err := missing.Encode(); err == nil || err.Error() != "cannot encode missing value" {
+		t.Errorf("missing: got %v", err)
+	}
+}
+
+// 浮点数的格式与 encoding/json 相同
+func TestEncodeFloats(t *testing.T) {
+	floats := []float64{0, 1, -1.5, 0.1, 1e20, 1e21, 1e-6, 1e-7, 123456.789, math.MaxFloat64, math.SmallestNonzeroFloat64, 5e-324, 1.7976931348623157e308, 100e-9}
+	for _, f := range floats {
+		got := mustEncode(t, NewFloat(f))
+		std, _ := json.Marshal(f)
+		if got != string(std) {
+			t.Errorf("%v: got %s, encoding/json %s", f, got, std)
+		}
+	}
+}
+
+func TestEncodeNaN(t *testing.T) {
+	tests := []struct {
+		f    float64
+		want string
+	}{
+		{math.NaN(), "NaN"},
+		{math.Inf(1), "Infinity"},
+		{math.Inf(-1), "-Infinity"},
+	}
+	for _, tt := range tests {
+		v := NewArray(NewFloat(tt.f))
+		if _, err := v.Encode(); err == nil || !strings.HasSuffix(err.Error(), "without AllowNaN") {
+			t.Errorf("%v: got %v", tt.f, err)
+		}
+		got, err := v.EncodeWithOptions(EncodeOptions{AllowNaN: true})
+		if err != nil || string(got) != "["+tt.want+"]" {
+			t.Errorf("%v: got %s, %v", tt.f, got, err)
+		}
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := mustParse(t, `{"a": [1, 2]}`).WriteTo(&buf)
+	if err != nil || n != int64(buf.Len()) || buf.String() != `{"a":[1,2]}` {
+		t.Errorf("got %q, %d, %v", buf.String(), n, err)
+	}
+	if _, err := NewFloat(math.NaN()).WriteTo(&buf); err == nil {
+		t.Errorf("NaN: expected an error")
+	}
+}
+
+func TestEncodeIndent(t *testing.T) {
+	docs := []string{
+		`{}`,
+		`[]`,
+		`1`,
+		`{"a":1}`,
+		`{"a":[1,{"b":[]},{}],"c":{"d":null,"e":"s"}}`,
+		`[[[]],[{}],[1,[2]]]`,
+	}
+	indents := []struct{ prefix, indent string }{
+		{"", "  "},
+		{"", "\t"},
+		{"> ", "    "},
+		{"//", ""},
+	}
+	for _, doc := range docs {
+		for _, in := range indents {
+			got, err := mustParse(t, doc).EncodeIndent(in.prefix, in.indent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var std bytes.Buffer
+			if err := json.Indent(&std, []byte(doc), in.prefix, in.indent); err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != std.String() {
+				t.Errorf("%s %q %q: got\n%s\nwant\n%s", doc, in.prefix, in.indent, got, std.String())
+			}
+		}
+	}
+
+	want := "{\n  \"a\": [\n    1,\n    2\n  ],\n  \"b\": {}\n}"
+	if got, _ := mustParse(t, `{"a":[1,2],"b":{}}`).EncodeIndent("", "  "); string(got) != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	v := map[string]interface{}{"list": []int{1, 2}, "name": "x"}
+	got, err := MarshalIndent(v, "", "\t")
+	std, _ := json.MarshalIndent(v, "", "\t")
+	if err != nil || string(got) != string(std) {
+		t.Errorf("MarshalIndent: got\n%s\nwant\n%s", got, std)
+	}
+}
+
+func TestEncodeSortKeys(t *testing.T) {
+	input := `{"b":1,"a":{"z":[{"y":1,"x":2}],"B":0,"é":1,"e":2},"":3,"aa":4}`
+	want := `{"":3,"a":{"B":0,"e":2,"z":[{"x":2,"y":1}],"é":1},"aa":4,"b":1}`
+	v := mustParse(t, input)
+	got, err := v.EncodeWithOptions(EncodeOptions{SortKeys: true})
+	if err != nil || string(got) != want {
+		t.Errorf("got %s, %v, want %s", got, err, want)
+	}
+
+	// 与 encoding/json 对 map 的排序相同
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(input), &m); err != nil {
+		t.Fatal(err)
+	}
+	if std, _ := json.Marshal(m); string(std) != want {
+		t.Errorf("encoding/json gives %s", std)
+	}
+
+	// 排序不改变原始顺序
+	if got := mustEncode(t, v); got != input {
+		t.Errorf("original order changed: %s", got)
+	}
+
+	indented, _ := mustParse(t, `{"b":1,"a":2}`).EncodeWithOptions(EncodeOptions{SortKeys: true, Indent: " "})
+	if string(indented) != "{\n \"a\": 2,\n \"b\": 1\n}" {
+		t.Errorf("indented: got %q", indented)
+	}
+}
+
+// EscapeHTML 的结果与 encoding/json 默认的输出相同
+func TestEncodeEscapeHTML(t *testing.T) {
+	strs := []string{
+		"<script>alert('x')</script>",
+		"a && b > c",
+		"line\u2028sep\u2029para",
+		"plain",
+		"é and 😀",
+		"bad\xffutf8",
+		"ctrl\x01\x1f\x7f",
+	}
+	for _, s := range strs {
+		got, err := NewString(s).EncodeWithOptions(EncodeOptions{EscapeHTML: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		std, _ := json.Marshal(s)
+		if string(got) != string(std) {
+			t.Errorf("%q: got %s, encoding/json %s", s, got, std)
+		}
+	}
+
+	v := mustParse(t, `{"<k>":"&"}`)
+	if got := mustEncode(t, v); got != `{"<k>":"&"}` {
+		t.Errorf("default: got %s", got)
+	}
+	got, _ := v.EncodeWithOptions(EncodeOptions{EscapeHTML: true})
+	if string(got) != `{"\u003ck\u003e":"\u0026"}` {
+		t.Errorf("keys: got %s", got)
+	}
+	if b, err := MarshalWithOptions("<", MarshalOptions{Encode: EncodeOptions{EscapeHTML: true}}); err != nil || string(b) != `"\u003c"` {
+		t.Errorf("Marshal: got %s, %v", b, err)
+	}
+}
+
+func TestEncodeEscapeNonASCII(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"plain ascii", `"plain ascii"`},
+		{"é", `"\u00e9"`},
+		{"中文", `"\u4e2d\u6587"`},
+		{"😀", `"\ud83d\ude00"`},
+		{"\u2028", `"\u2028"`},
+		{"bad\xff", `"bad\ufffd"`},
+		{"<&>", `"<&>"`},
+		{"tab\t", `"tab\t"`},
+	}
+	for _, tt := range tests {
+		got, err := NewString(tt.s).EncodeWithOptions(EncodeOptions{EscapeNonASCII: true})
+		if err != nil || string(got) != tt.want {
+			t.Errorf("%q: got %s, %v, want %s", tt.s, got, err, tt.want)
+			continue
+		}
+		for _, b := range got {
+			if b >= 0x80 {
+				t.Errorf("%q: output contains non-ASCII byte %#x", tt.s, b)
+				break
+			}
+		}
+		if back := mustParse(t, string(got)).MustString(); back != strings.ToValidUTF8(tt.s, "\ufffd") {
+			t.Errorf("%q: round trip gives %q", tt.s, back)
+		}
+	}
+
+	v := mustParse(t, `{"ключ":"<значение>"}`)
+	got, _ := v.EncodeWithOptions(EncodeOptions{EscapeNonASCII: true, EscapeHTML: true})
+	want := `{"\u043a\u043b\u044e\u0447":"\u003c\u0437\u043d\u0430\u0447\u0435\u043d\u0438\u0435\u003e"}`
+	if string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestAppendJSON(t *testing.T) {
+	dst := []byte("prefix:")
+	dst, err := AppendJSON(dst, mustParse(t, `{"a": [1, true]}`))
+	if err != nil || string(dst) != `prefix:{"a":[1,true]}` {
+		t.Errorf("AppendJSON: got %s, %v", dst, err)
+	}
+	dst, err = AppendJSONWithOptions(dst[:0], mustParse(t, `{"b":1,"a":2}`), EncodeOptions{SortKeys: true})
+	if err != nil || string(dst) != `{"a":2,"b":1}` {
+		t.Errorf("AppendJSONWithOptions: got %s, %v", dst, err)
+	}
+
+	// 出错时仍返回 dst, 已追加的部分保留
+	dst, err = AppendJSON([]byte("x"), NewFloat(math.NaN()))
+	if err == nil || string(dst) != "x" {
+		t.Errorf("NaN: got %s, %v", dst, err)
+	}
+
+	// 缓冲区足够时不重新分配
+	buf := make([]byte, 0, 64)
+	out, _ := AppendJSON(buf, mustParse(t, `[1,2,3]`))
+	if &out[0] != &buf[:1][0] {
+		t.Errorf("AppendJSON reallocated a large enough buffer")
+	}
+}
+
+func TestAppendScalars(t *testing.T) {
+	var dst []byte
+	dst = AppendString(dst, "a\"<")
+	dst = append(dst, ',')
+	dst = AppendInt(dst, math.MinInt64)
+	dst = append(dst, ',')
+	dst = AppendUint(dst, math.MaxUint64)
+	dst = append(dst, ',')
+	dst, err := AppendFloat(dst, 1e-7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst = append(dst, ',')
+	dst = AppendBool(dst, true)
+	dst = append(dst, ',')
+	dst = AppendBool(dst, false)
+	dst = append(dst, ',')
+	dst = AppendNull(dst)
+	want := `"a\"<",-9223372036854775808,18446744073709551615,1e-7,true,false,null`
+	if string(dst) != want {
+		t.Errorf("got %s, want %s", dst, want)
+	}
+
+	if _, err := AppendFloat(nil, math.Inf(1)); err == nil {
+		t.Errorf("AppendFloat(+Inf): expected an error")
+	}
+}
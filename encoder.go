@@ -0,0 +1,58 @@
+package yjson
+
+import "io"
+
+// 把文档依次写入 io.Writer, 大文档边生成边写出, 不在内存中保留完整输出
+type Encoder struct {
+	w       io.Writer
+	opts    EncodeOptions
+	newline bool
+	buf     []byte
+}
+
+// 默认每个文档之后写一个换行, 与 encoding/json 相同
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w, newline: true}
+}
+
+func (enc *Encoder) SetOptions(opts EncodeOptions) {
+	enc.opts = opts
+}
+
+func (enc *Encoder) SetIndent(prefix, indent string) {
+	enc.opts.Prefix = prefix
+	enc.opts.Indent = indent
+}
+
+func (enc *Encoder) SetEscapeHTML(on bool) {
+	enc.opts.EscapeHTML = on
+}
+
+// 是否在每个文档之后写换行, 关闭后文档首尾相连
+func (enc *Encoder) SetNewline(on bool) {
+	enc.newline = on
+}
+
+// 写出一个文档. 大文档是分段写出的, 出错时 w 中可能已经有部分输出
+func (enc *Encoder) Encode(v *Value) error {
+	opts := enc.opts
+	if opts.Canonical {
+		opts = EncodeOptions{Canonical: true}
+	}
+	e := encoder{opts: opts, w: enc.w}
+
+	dst, err := e.appendValue(enc.buf[:0], v)
+	if err != nil {
+		return err
+	}
+	if enc.newline {
+		dst = append(dst, LINE_BREAK)
+	}
+	_, err = enc.w.Write(dst)
+
+	// 复用缓冲区, 但不保留过大的
+	if cap(dst) <= 4*flushSize {
+		enc.buf = dst[:0]
+	}
+	return err
+}
@@ -0,0 +1,110 @@
+package yjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestEncoder(t *testing.T) {
+	docs := []string{`{"a":[1,2],"b":"<x>"}`, `null`, `[{}]`}
+	tests := []struct {
+		name  string
+		setup func(enc *Encoder, std *json.Encoder)
+	}{
+		{"default", func(enc *Encoder, std *json.Encoder) { std.SetEscapeHTML(false) }},
+		{"indent", func(enc *Encoder, std *json.Encoder) {
+			enc.SetIndent("", "  ")
+			std.SetIndent("", "  ")
+			std.SetEscapeHTML(false)
+		}},
+		{"escape HTML", func(enc *Encoder, std *json.Encoder) { enc.SetEscapeHTML(true) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got, want bytes.Buffer
+			enc, std := NewEncoder(&got), json.NewEncoder(&want)
+			tt.setup(enc, std)
+			for _, doc := range docs {
+				if err := enc.Encode(mustParse(t, doc)); err != nil {
+					t.Fatal(err)
+				}
+				// 用 RawMessage 保持键的顺序
+				if err := std.Encode(json.RawMessage(doc)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got.String() != want.String() {
+				t.Errorf("got\n%s\nwant\n%s", got.String(), want.String())
+			}
+		})
+	}
+}
+
+func TestEncoderOptions(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.SetNewline(false)
+	enc.SetOptions(EncodeOptions{SortKeys: true})
+	enc.Encode(mustParse(t, `{"b":1,"a":2}`))
+	enc.Encode(mustParse(t, `[3]`))
+	if buf.String() != `{"a":2,"b":1}[3]` {
+		t.Errorf("got %s", buf.String())
+	}
+
+	buf.Reset()
+	enc = NewEncoder(&buf)
+	enc.SetOptions(EncodeOptions{Canonical: true, Indent: "  "})
+	enc.Encode(mustParse(t, `{"b":1.0,"a":[]}`))
+	if buf.String() != "{\"a\":[],\"b\":1}\n" {
+		t.Errorf("canonical: got %q", buf.String())
+	}
+}
+
+// 大文档分段写出, 结果与一次生成的相同
+func TestEncoderLargeDocument(t *testing.T) {
+	arr := NewArray()
+	for i := 0; i < 5000; i++ {
+		arr.Append(NewString(strings.Repeat("x", i%50)))
+	}
+	w := &countingWriter{}
+	if err := NewEncoder(w).Encode(arr); err != nil {
+		t.Fatal(err)
+	}
+	if w.writes < 2 {
+		t.Errorf("expected the document to be written in several parts, got %d writes", w.writes)
+	}
+	if want := mustEncode(t, arr) + "\n"; w.String() != want {
+		t.Errorf("output differs from Encode")
+	}
+}
+
+func TestEncoderErrors(t *testing.T) {
+	if err := NewEncoder(failingWriter{}).Encode(NewInt(1)); err == nil || err.Error() != "disk full" {
+		t.Errorf("write error: got %v", err)
+	}
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(NewFloat(math.NaN())); err == nil {
+		t.Errorf("NaN: expected an error")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("NaN: wrote %q", buf.String())
+	}
+}
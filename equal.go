@@ -0,0 +1,160 @@
+package yjson
+
+import "math"
+
+// Equal 的比较选项
+type EqualOption func(*equalConfig)
+
+type equalConfig struct {
+	tolerance float64
+	keyOrder  bool
+	unordered [][]pathSegment
+	ignored   [][]pathSegment
+}
+
+// 两个数字的差的绝对值不超过 eps 时视为相等
+func NumericTolerance(eps float64) EqualOption {
+	return func(c *equalConfig) {
+		c.tolerance = eps
+	}
+}
+
+// 对象的键的顺序也必须相同, 默认忽略键的顺序
+func StrictKeyOrder() EqualOption {
+	return func(c *equalConfig) {
+		c.keyOrder = true
+	}
+}
+
+// 按 Get 的路径语法指定忽略元素顺序的数组, 可以使用 * 和 .., 如 "tags", "items.*.roles".
+// 非法的路径被忽略
+func IgnoreArrayOrder(paths ...string) EqualOption {
+	return func(c *equalConfig) {
+		c.unordered = appendPatterns(c.unordered, paths)
+	}
+}
+
+// 按 Get 的路径语法指定不参与比较的值, 可以使用 * 和 .., 如 "..updated_at".
+// 被忽略的成员在一边存在而另一边不存在也视为相等. 非法的路径被忽略
+func IgnorePaths(paths ...string) EqualOption {
+	return func(c *equalConfig) {
+		c.ignored = appendPatterns(c.ignored, paths)
+	}
+}
+
+func appendPatterns(patterns [][]pathSegment, paths []string) [][]pathSegment {
+	for _, path := range paths {
+		if segments, err := parsePath(path); err == nil {
+			patterns = append(patterns, segments)
+		}
+	}
+	return patterns
+}
+
+// 深度比较 a 和 b. 默认规则与 JSON Patch 的 test 操作相同: 数字按数值比较,
+// 对象忽略键的顺序, 数组按顺序逐个比较; opts 可以放宽或收紧这些规则
+func Equal(a, b *Value, opts ...EqualOption) bool {
+	var c equalConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c.equal(a, b, make([]pathSegment, 0))
+}
+
+func matchAny(patterns [][]pathSegment, path []pathSegment) bool {
+	for _, pattern := range patterns {
+		if matchSegments(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *equalConfig) equal(a, b *Value, path []pathSegment) bool {
+	if matchAny(c.ignored, path) {
+		return true
+	}
+	if a.load() != nil || b.load() != nil || a.Type() != b.Type() {
+		return false
+	}
+
+	switch a.Type() {
+	case JSON_NUMBER:
+		if c.tolerance > 0 {
+			x, err1 := a.Float64()
+			y, err2 := b.Float64()
+			return err1 == nil && err2 == nil && math.Abs(x-y) <= c.tolerance
+		}
+		return equalValues(a, b)
+	case JSON_OBJECT:
+		return c.equalObject(a, b, path)
+	case JSON_ARRAY:
+		if len(a.arr) != len(b.arr) {
+			return false
+		}
+		if matchAny(c.unordered, path) {
+			return c.equalUnordered(a, b, path)
+		}
+		for i := range a.arr {
+			if !c.equal(a.arr[i], b.arr[i], append(path, pathSegment{index: i, isIndex: true})) {
+				return false
+			}
+		}
+		return true
+	}
+	return equalValues(a, b)
+}
+
+func (c *equalConfig) equalObject(a, b *Value, path []pathSegment) bool {
+	// 两边没有被忽略的键, 用于检查顺序
+	var ka, kb []string
+	for _, key := range a.keys {
+		child := append(path, pathSegment{key: key})
+		if matchAny(c.ignored, child) {
+			continue
+		}
+		bv, ok := b.obj[key]
+		if !ok || !c.equal(a.obj[key], bv, child) {
+			return false
+		}
+		ka = append(ka, key)
+	}
+	for _, key := range b.keys {
+		if matchAny(c.ignored, append(path, pathSegment{key: key})) {
+			continue
+		}
+		if _, ok := a.obj[key]; !ok {
+			return false
+		}
+		kb = append(kb, key)
+	}
+	if c.keyOrder {
+		for i := range ka {
+			if ka[i] != kb[i] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// 为 a 的每个元素找一个还没有用过的相等的 b 元素. 元素的路径使用各自的下标
+func (c *equalConfig) equalUnordered(a, b *Value, path []pathSegment) bool {
+	used := make([]bool, len(b.arr))
+	for i, x := range a.arr {
+		found := false
+		for k, y := range b.arr {
+			if used[k] {
+				continue
+			}
+			if c.equal(x, y, append(path, pathSegment{index: i, isIndex: true})) {
+				used[k], found = true, true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
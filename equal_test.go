@@ -0,0 +1,82 @@
+package yjson
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		a, b string
+		opts []EqualOption
+		want bool
+	}{
+		{`1`, `1.0`, nil, true},
+		{`1e2`, `100`, nil, true},
+		{`0.1`, `0.10`, nil, true},
+		{`9007199254740993`, `9007199254740992`, nil, false},
+		{`{"id":9007199254740993}`, `{"id":9007199254740992}`, nil, false},
+		{`[18446744073709551615]`, `[18446744073709551614]`, nil, false},
+		{`-1`, `18446744073709551615`, nil, false},
+		{`9007199254740993`, `9007199254740993.0`, nil, true},
+		{`9007199254740993.5`, `9007199254740993.25`, nil, false},
+		{`"a"`, `"a"`, nil, true},
+		{`"1"`, `1`, nil, false},
+		{`null`, `null`, nil, true},
+		{`{"a":1,"b":2}`, `{"b":2,"a":1}`, nil, true},
+		{`{"a":1,"b":2}`, `{"b":2,"a":1}`, []EqualOption{StrictKeyOrder()}, false},
+		{`{"a":1}`, `{"a":1,"b":2}`, nil, false},
+		{`[1,2]`, `[2,1]`, nil, false},
+		{`{"tags":[1,2]}`, `{"tags":[2,1]}`, []EqualOption{IgnoreArrayOrder("tags")}, true},
+		{`{"tags":[1,2,2]}`, `{"tags":[2,1,1]}`, []EqualOption{IgnoreArrayOrder("tags")}, false},
+		{`{"a":{"when":1,"x":1}}`, `{"a":{"when":2,"x":1}}`, []EqualOption{IgnorePaths("..when")}, true},
+		{`{"a":{"x":1}}`, `{"a":{"when":2,"x":1}}`, []EqualOption{IgnorePaths("a.when")}, true},
+		{`1.0001`, `1`, []EqualOption{NumericTolerance(0.001)}, true},
+		{`1.01`, `1`, []EqualOption{NumericTolerance(0.001)}, false},
+	}
+	for _, tt := range tests {
+		if got := Equal(mustParse(t, tt.a), mustParse(t, tt.b), tt.opts...); got != tt.want {
+			t.Errorf("Equal(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// 不同的存放方式表示同一个数值时相等
+func TestEqualNumberRepresentations(t *testing.T) {
+	big1 := new(big.Int).Lsh(big.NewInt(1), 70)
+	tests := []struct {
+		name string
+		a, b *Value
+		want bool
+	}{
+		{"int uint", NewInt(5), NewUint(5), true},
+		{"int float", NewInt(1 << 53), NewFloat(1 << 53), true},
+		{"int float near 2^53", NewInt(1<<53 + 1), NewFloat(1 << 53), false},
+		{"uint max", NewUint(1<<64 - 1), NewFloat(1 << 64), false},
+		{"big int", &Value{valueType: JSON_NUMBER, repr: REPR_OTHER, num: big1}, NewFloat(1 << 70), true},
+		{"raw number", &Value{valueType: JSON_NUMBER, repr: REPR_OTHER, num: Number("12345678901234567891")}, NewUint(12345678901234567891), true},
+	}
+	for _, tt := range tests {
+		if got := Equal(tt.a, tt.b); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	opts := []ParseOptions{{UseRawNumbers: true}, {UseBigNumbers: true}, {}}
+	for _, o := range opts {
+		a, err := ParseWithOptions([]byte(`[9007199254740993,1.5]`), o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ParseWithOptions([]byte(`[9007199254740992,1.50]`), o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if Equal(a, b) {
+			t.Errorf("%+v: large integers compared as float64", o)
+		}
+		if !Equal(a.Get("[1]"), b.Get("[1]")) {
+			t.Errorf("%+v: 1.5 != 1.50", o)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package yjson
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 结构体中参与编解码的字段
+type field struct {
+	name      string
+	tagged    bool  // 名字来自标签
+	index     []int // 嵌入字段时为逐层的下标
+	typ       reflect.Type
+	omitEmpty bool
+	omitZero  bool
+	required  bool
+	quoted    bool // string 选项, 值编码为 JSON 文本后再作为字符串
+
+	hasDefault   bool
+	defaultValue string // default 标签的原文
+
+	layout string // time.Time 字段的 layout 标签
+	unit   string // time.Duration 字段的 unit 标签
+
+	encoding string // []byte 字段的 encoding 标签
+}
+
+// 字段带有只作用于特定类型的格式标签
+func (f *field) hasFormat() bool {
+	return f.layout != "" || f.unit != "" || f.encoding != "" || f.quoted
+}
+
+// 与 encoding/json 相同, string 选项只作用于布尔, 数字和字符串 (及其指针).
+// 自己实现编解码的类型和 time.Duration 不受影响
+func quotableType(t reflect.Type) bool {
+	if t.Name() == "" && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+	default:
+		return false
+	}
+	if t == durationReflectType {
+		return false // 由 unit 标签决定格式
+	}
+	p := reflect.PointerTo(t)
+	return !implementsMarshaler(t) && !implementsMarshaler(p) && !p.Implements(unmarshalerType) &&
+		!p.Implements(jsonUnmarshalerType) && !p.Implements(textUnmarshalerType)
+}
+
+type structFields struct {
+	list   []field
+	byName map[string]int // 精确匹配
+	byFold map[string]int // 忽略大小写匹配
+}
+
+type fieldCacheKey struct {
+	typ    reflect.Type
+	naming NamingStrategy
+}
+
+var fieldCache sync.Map // map[fieldCacheKey]*structFields
+
+func cachedFields(t reflect.Type, naming NamingStrategy) *structFields {
+	key := fieldCacheKey{t, naming}
+	if f, ok := fieldCache.Load(key); ok {
+		return f.(*structFields)
+	}
+	f, _ := fieldCache.LoadOrStore(key, typeFields(t, naming))
+	return f.(*structFields)
+}
+
+// 字段收集规则与 encoding/json 相同: 没有标签名的匿名结构体字段 (包括指针) 被展开,
+// 其字段提升到外层. 同名时层级浅的优先, 同一层级有且只有一个带标签名的字段优先,
+// 否则这些同名字段都被忽略
+func typeFields(t reflect.Type, naming NamingStrategy) *structFields {
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+
+	var all []field
+	visited := make(map[reflect.Type]bool)
+	// 同一层级中每个嵌入类型出现的次数, 出现多次的类型的字段互相冲突
+	count, nextCount := map[reflect.Type]int{}, map[reflect.Type]int{}
+	next := []embedded{{typ: t}}
+	for len(next) > 0 {
+		current := next
+		next = nil
+		count, nextCount = nextCount, map[reflect.Type]int{}
+		for _, e := range current {
+			if visited[e.typ] {
+				continue
+			}
+			visited[e.typ] = true
+
+			for i := 0; i < e.typ.NumField(); i++ {
+				sf := e.typ.Field(i)
+				ft := sf.Type
+				if ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				if sf.Anonymous {
+					// 未导出的匿名结构体仍然展开其导出字段
+					if !sf.IsExported() && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !sf.IsExported() {
+					continue
+				}
+
+				tag, ok := sf.Tag.Lookup("yjson")
+				if !ok {
+					tag = sf.Tag.Get("json")
+				}
+				if tag == "-" {
+					continue
+				}
+				name, opts := parseTag(tag)
+				index := append(append(make([]int, 0, len(e.index)+1), e.index...), i)
+				if name == "" && sf.Anonymous && ft.Kind() == reflect.Struct {
+					if nextCount[ft]++; nextCount[ft] == 1 {
+						next = append(next, embedded{typ: ft, index: index})
+					}
+					continue
+				}
+
+				tagged := name != ""
+				if !tagged {
+					name = naming.apply(sf.Name)
+				}
+				def, hasDefault := sf.Tag.Lookup("default")
+				all = append(all, field{
+					name:         name,
+					tagged:       tagged,
+					index:        index,
+					typ:          sf.Type,
+					omitEmpty:    opts.contains("omitempty"),
+					omitZero:     opts.contains("omitzero"),
+					required:     opts.contains("required"),
+					quoted:       opts.contains("string") && quotableType(sf.Type),
+					hasDefault:   hasDefault,
+					defaultValue: def,
+					layout:       sf.Tag.Get("layout"),
+					unit:         sf.Tag.Get("unit"),
+					encoding:     sf.Tag.Get("encoding"),
+				})
+				if count[e.typ] > 1 {
+					// 多次嵌入的同一类型只展开一次, 再加一个同名字段让 dominantField 忽略它
+					all = append(all, all[len(all)-1])
+				}
+			}
+		}
+	}
+
+	// 按名字分组选出胜出的字段, 再按字段在结构体中的顺序排列
+	byName := make(map[string][]int)
+	for i, f := range all {
+		byName[f.name] = append(byName[f.name], i)
+	}
+	fields := &structFields{byName: make(map[string]int), byFold: make(map[string]int)}
+	for i, f := range all {
+		if winner, ok := dominantField(all, byName[f.name]); ok && winner == i {
+			fields.list = append(fields.list, f)
+		}
+	}
+	sort.Slice(fields.list, func(i, j int) bool {
+		return lessIndex(fields.list[i].index, fields.list[j].index)
+	})
+
+	for i, f := range fields.list {
+		fields.byName[f.name] = i
+		fold := strings.ToLower(f.name)
+		if _, ok := fields.byFold[fold]; !ok {
+			fields.byFold[fold] = i
+		}
+	}
+	return fields
+}
+
+// 同名字段中选出层级最浅的一个, 层级相同时只有唯一带标签名的字段能胜出
+func dominantField(all []field, candidates []int) (int, bool) {
+	depth := len(all[candidates[0]].index)
+	for _, i := range candidates[1:] {
+		if d := len(all[i].index); d < depth {
+			depth = d
+		}
+	}
+
+	winner, n, tagged := -1, 0, 0
+	for _, i := range candidates {
+		if len(all[i].index) != depth {
+			continue
+		}
+		n++
+		if all[i].tagged {
+			tagged++
+			winner = i
+		} else if tagged == 0 {
+			winner = i
+		}
+	}
+	if n == 1 || tagged == 1 {
+		return winner, true
+	}
+	return -1, false
+}
+
+func lessIndex(a, b []int) bool {
+	for k := 0; k < len(a) && k < len(b); k++ {
+		if a[k] != b[k] {
+			return a[k] < b[k]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// 先精确匹配, 再忽略大小写, 与 encoding/json 相同
+func (fs *structFields) lookup(key string) (*field, bool) {
+	if i, ok := fs.byName[key]; ok {
+		return &fs.list[i], true
+	}
+	if i, ok := fs.byFold[strings.ToLower(key)]; ok {
+		return &fs.list[i], true
+	}
+	return nil, false
+}
+
+// 按 index 取字段, 途经的嵌入指针为 nil 时返回 false
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for k, i := range index {
+		if k > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v, true
+}
+
+// 按 index 取字段用于写入, 途经的 nil 嵌入指针会被分配.
+// 未导出的嵌入结构体指针无法分配, 返回错误
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, error) {
+	for k, i := range index {
+		if k > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("cannot set embedded pointer to unexported struct %v", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v, nil
+}
+
+type tagOptions string
+
+// `yjson:"name,omitempty"` 拆分为名字和选项, 没有 yjson 标签时使用 json 标签
+func parseTag(tag string) (string, tagOptions) {
+	name, opts, _ := strings.Cut(tag, ",")
+	return name, tagOptions(opts)
+}
+
+func (o tagOptions) contains(name string) bool {
+	s := string(o)
+	for s != "" {
+		var opt string
+		opt, s, _ = strings.Cut(s, ",")
+		if opt == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,347 @@
+package yjson
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type tagged struct {
+	Renamed    string            `json:"renamed"`
+	Ignored    string            `json:"-"`
+	Dash       string            `json:"-,"`
+	Omit       string            `json:"omit,omitempty"`
+	OmitInt    int               `json:",omitempty"`
+	OmitPtr    *int              `json:"omit_ptr,omitempty"`
+	OmitMap    map[string]int    `json:"omit_map,omitempty"`
+	OmitSlice  []int             `json:"omit_slice,omitempty"`
+	OmitBool   bool              `json:"omit_bool,omitempty"`
+	Struct     struct{ A int }   `json:"struct,omitempty"`
+	Kept       map[string]string `json:"kept"`
+	Untagged   float64
+	unexported int
+}
+
+type yjsonTagged struct {
+	A string `json:"json_a" yjson:"yjson_a"`
+	B string `json:"json_b" yjson:"-"`
+	C string `json:"json_c"`
+	D string `yjson:",omitempty" json:"json_d"`
+}
+
+func TestStructTags(t *testing.T) {
+	tests := []struct {
+		name string
+		v    tagged
+	}{
+		{"zero", tagged{}},
+		{"filled", tagged{Renamed: "r", Ignored: "i", Dash: "d", Omit: "o", OmitInt: 1, OmitPtr: new(int),
+			OmitMap: map[string]int{"a": 1}, OmitSlice: []int{0}, OmitBool: true, Kept: map[string]string{}, Untagged: 1.5}},
+		{"empty but not nil", tagged{OmitMap: map[string]int{}, OmitSlice: []int{}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Marshal(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			std, _ := json.Marshal(tt.v)
+			if string(got) != string(std) {
+				t.Errorf("got %s, encoding/json %s", got, std)
+			}
+
+			var back, stdBack tagged
+			if err := Unmarshal(got, &back); err != nil {
+				t.Fatal(err)
+			}
+			json.Unmarshal(got, &stdBack)
+			if !reflect.DeepEqual(back, stdBack) {
+				t.Errorf("unmarshal: got %+v, encoding/json %+v", back, stdBack)
+			}
+		})
+	}
+
+	// 忽略的字段不参与解码
+	var v tagged
+	if err := Unmarshal([]byte(`{"Ignored":"x","-":"y","unexported":1}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Ignored != "" || v.Dash != "y" || v.unexported != 0 {
+		t.Errorf("got %+v", v)
+	}
+}
+
+// yjson 标签优先于 json 标签
+func TestYjsonTag(t *testing.T) {
+	v := yjsonTagged{A: "a", B: "b", C: "c"}
+	got, err := Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != `{"yjson_a":"a","json_c":"c"}` {
+		t.Errorf("got %s", got)
+	}
+
+	var back yjsonTagged
+	if err := Unmarshal([]byte(`{"yjson_a":"1","json_a":"2","json_b":"3","json_c":"4","D":"5"}`), &back); err != nil {
+		t.Fatal(err)
+	}
+	if want := (yjsonTagged{A: "1", C: "4", D: "5"}); back != want {
+		t.Errorf("got %+v, want %+v", back, want)
+	}
+}
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		name string
+		opts []string
+		not  []string
+	}{
+		{"", "", nil, []string{"omitempty"}},
+		{"name", "name", nil, []string{"name"}},
+		{"name,omitempty", "name", []string{"omitempty"}, []string{"omitzero"}},
+		{",omitempty,string", "", []string{"omitempty", "string"}, []string{""}},
+		{"a,omitemptyx", "a", nil, []string{"omitempty"}},
+	}
+	for _, tt := range tests {
+		name, opts := parseTag(tt.tag)
+		if name != tt.name {
+			t.Errorf("%q: got name %q, want %q", tt.tag, name, tt.name)
+		}
+		for _, o := range tt.opts {
+			if !opts.contains(o) {
+				t.Errorf("%q: missing option %q", tt.tag, o)
+			}
+		}
+		for _, o := range tt.not {
+			if opts.contains(o) {
+				t.Errorf("%q: unexpected option %q", tt.tag, o)
+			}
+		}
+	}
+}
+
+type zeroer struct{ n int }
+
+func (z zeroer) IsZero() bool { return z.n < 0 }
+
+type ptrZeroer struct{ n int }
+
+func (z *ptrZeroer) IsZero() bool { return z.n == 7 }
+
+type omitZero struct {
+	Int    int             `json:"int,omitzero"`
+	Bool   bool            `json:"bool,omitzero"`
+	Struct struct{ A int } `json:"struct,omitzero"`
+	Array  [2]int          `json:"array,omitzero"`
+	Slice  []int           `json:"slice,omitzero"`
+	Map    map[string]int  `json:"map,omitzero"`
+	Ptr    *int            `json:"ptr,omitzero"`
+	Time   time.Time       `json:"time,omitzero"`
+	Custom zeroer          `json:"custom,omitzero"`
+	PtrZ   ptrZeroer       `json:"ptr_z,omitzero"`
+	Both   []int           `json:"both,omitempty,omitzero"`
+}
+
+func TestOmitZero(t *testing.T) {
+	tests := []struct {
+		name string
+		v    omitZero
+		want string
+	}{
+		{"zero", omitZero{Custom: zeroer{-1}}, `{"ptr_z":{}}`},
+		{"empty but not zero", omitZero{Slice: []int{}, Map: map[string]int{}, Ptr: new(int), Custom: zeroer{-1}, PtrZ: ptrZeroer{7}},
+			`{"slice":[],"map":{},"ptr":0}`},
+		{"custom zero", omitZero{Custom: zeroer{0}, PtrZ: ptrZeroer{7}}, `{"custom":{}}`},
+		{"non-zero", omitZero{Int: 1, Array: [2]int{0, 1}, Time: time.Unix(0, 0).UTC(), Custom: zeroer{-1}, PtrZ: ptrZeroer{7}, Both: []int{}},
+			`{"int":1,"array":[0,1],"time":"1970-01-01T00:00:00Z"}`},
+	}
+	for _, tt := range tests {
+		got, err := Marshal(&tt.v)
+		if err != nil || string(got) != tt.want {
+			t.Errorf("%s: got %s, %v, want %s", tt.name, got, err, tt.want)
+			continue
+		}
+		if std, _ := json.Marshal(&tt.v); string(std) != tt.want {
+			t.Errorf("%s: encoding/json gives %s", tt.name, std)
+		}
+	}
+}
+
+type EmbedBase struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type EmbedAudit struct {
+	Name    string `json:"name"`
+	Created string `json:"created"`
+}
+
+type EmbedTagged struct {
+	Name string `json:"name"`
+}
+
+type embedInner struct {
+	Secret string `json:"secret"`
+}
+
+type EmbedOuter struct {
+	EmbedBase
+	*EmbedAudit
+	embedInner
+	Extra string `json:"extra"`
+}
+
+type EmbedShadow struct {
+	EmbedBase
+	Name string `json:"name"` // 层级浅的字段胜出
+}
+
+type EmbedUntaggedOther struct {
+	Name string
+	Kind int
+}
+
+type EmbedConflict struct {
+	EmbedUntaggedName
+	EmbedUntaggedOther // 与 EmbedUntaggedName.Name 同层同名, 都没有标签, 都被忽略
+}
+
+type EmbedNamed struct {
+	EmbedBase `json:"base"`
+	Other     int `json:"other"`
+}
+
+type EmbedUntaggedName struct {
+	Name string
+}
+
+type EmbedTagWins struct {
+	EmbedUntaggedName
+	EmbedTagged // 同层中只有它带标签名, 胜出
+}
+
+type EmbedDiamondLeaf struct{ X int }
+
+type EmbedDiamondLeft struct{ EmbedDiamondLeaf }
+
+type EmbedDiamondRight struct{ EmbedDiamondLeaf }
+
+// 同一类型在同一层级嵌入两次, 其字段互相冲突, 都被忽略
+type EmbedDiamond struct {
+	EmbedDiamondLeft
+	EmbedDiamondRight
+}
+
+// 较浅层级的同名字段不受深层冲突影响
+type EmbedDiamondShadow struct {
+	EmbedDiamond
+	X int
+}
+
+func TestEmbeddedStructs(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       interface{}
+		input   string
+		newFunc func() interface{}
+	}{
+		{"promoted", EmbedOuter{EmbedBase: EmbedBase{1, "base"}, EmbedAudit: &EmbedAudit{"audit", "now"}, embedInner: embedInner{"s"}, Extra: "e"},
+			`{"id":2,"name":"n","created":"c","secret":"x","extra":"y"}`, func() interface{} { return new(EmbedOuter) }},
+		{"nil embedded pointer", EmbedOuter{EmbedBase: EmbedBase{ID: 1}},
+			`{"id":3}`, func() interface{} { return new(EmbedOuter) }},
+		{"shadow", EmbedShadow{EmbedBase: EmbedBase{1, "inner"}, Name: "outer"},
+			`{"id":1,"name":"n"}`, func() interface{} { return new(EmbedShadow) }},
+		{"conflict", EmbedConflict{EmbedUntaggedName{"a"}, EmbedUntaggedOther{"b", 1}},
+			`{"Name":"n","Kind":2}`, func() interface{} { return new(EmbedConflict) }},
+		{"named embedded", EmbedNamed{EmbedBase{1, "a"}, 2},
+			`{"base":{"id":5},"id":6,"other":7}`, func() interface{} { return new(EmbedNamed) }},
+		{"tag wins", EmbedTagWins{EmbedUntaggedName{"u"}, EmbedTagged{"t"}},
+			`{"name":"n","Name":"N"}`, func() interface{} { return new(EmbedTagWins) }},
+		{"diamond", EmbedDiamond{},
+			`{"X":5}`, func() interface{} { return new(EmbedDiamond) }},
+		{"diamond shadowed", EmbedDiamondShadow{X: 1},
+			`{"X":5}`, func() interface{} { return new(EmbedDiamondShadow) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Marshal(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if std, _ := json.Marshal(tt.v); string(got) != string(std) {
+				t.Errorf("marshal: got %s, encoding/json %s", got, std)
+			}
+
+			v, stdV := tt.newFunc(), tt.newFunc()
+			if err := Unmarshal([]byte(tt.input), v); err != nil {
+				t.Fatal(err)
+			}
+			json.Unmarshal([]byte(tt.input), stdV)
+			if !reflect.DeepEqual(v, stdV) {
+				t.Errorf("unmarshal: got %+v, encoding/json %+v", v, stdV)
+			}
+		})
+	}
+}
+
+type embedCycleA struct {
+	*embedCycleB
+	A int `json:"a"`
+}
+
+type embedCycleB struct {
+	*embedCycleA
+	B int `json:"b"`
+}
+
+func TestEmbeddedErrors(t *testing.T) {
+	// 未导出的嵌入结构体指针无法分配
+	type hidden struct {
+		*embedInner
+	}
+	var h hidden
+	err := Unmarshal([]byte(`{"secret":"x"}`), &h)
+	if err == nil || err.Error() != "unmarshal: cannot set embedded pointer to unexported struct yjson.embedInner at secret" {
+		t.Errorf("got %v", err)
+	}
+
+	// 互相嵌入的类型不会无限展开
+	fields := cachedFields(reflect.TypeOf(embedCycleA{}), NamingDefault)
+	if len(fields.list) != 2 {
+		t.Errorf("got %d fields", len(fields.list))
+	}
+	b, err := Marshal(embedCycleA{A: 1})
+	if err != nil || string(b) != `{"a":1}` {
+		t.Errorf("marshal: got %s, %v", b, err)
+	}
+}
+
+func TestDominantField(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []field
+		winner int
+		ok     bool
+	}{
+		{"single", []field{{index: []int{0, 1}}}, 0, true},
+		{"shallower wins", []field{{index: []int{0, 1}, tagged: true}, {index: []int{2}}}, 1, true},
+		{"only tagged wins", []field{{index: []int{0, 1}}, {index: []int{1, 0}, tagged: true}}, 1, true},
+		{"two tagged", []field{{index: []int{0, 1}, tagged: true}, {index: []int{1, 0}, tagged: true}}, -1, false},
+		{"two untagged", []field{{index: []int{0, 1}}, {index: []int{1, 0}}}, -1, false},
+		{"deeper ignored", []field{{index: []int{0, 1}, tagged: true}, {index: []int{1, 0}, tagged: true}, {index: []int{2, 0, 0}}}, -1, false},
+	}
+	for _, tt := range tests {
+		candidates := make([]int, len(tt.fields))
+		for i := range candidates {
+			candidates[i] = i
+		}
+		winner, ok := dominantField(tt.fields, candidates)
+		if winner != tt.winner || ok != tt.ok {
+			t.Errorf("%s: got %d, %v, want %d, %v", tt.name, winner, ok, tt.winner, tt.ok)
+		}
+	}
+}
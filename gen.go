@@ -0,0 +1,315 @@
+package yjson
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 由示例 JSON 生成 Go 结构体定义时的选项
+type GenOptions struct {
+	// 生成代码的包名, 为空时使用 main
+	Package string
+
+	// 根类型的名字, 为空时使用 Root
+	Name string
+
+	// 结构体标签的键, 为空时使用 json. yjson 同时识别 yjson 和 json 标签
+	Tag string
+}
+
+// 同一位置上观察到的所有样本值
+type genShape struct {
+	null, boolean, integer, float, str bool
+
+	array bool
+	items *genShape // 所有数组的所有元素
+
+	objects int // 观察到的对象个数, 少于该数的键为可选字段
+	keys    []string
+	fields  map[string]*genShape
+	present map[string]int
+}
+
+func (s *genShape) add(v *Value) error {
+	if err := v.load(); err != nil {
+		return err
+	}
+	switch v.Type() {
+	case JSON_NULL:
+		s.null = true
+	case JSON_BOOLEAN:
+		s.boolean = true
+	case JSON_NUMBER:
+		if v.numberType == NUMBER_INT && v.repr == REPR_INT {
+			s.integer = true
+		} else {
+			s.float = true
+		}
+	case JSON_STRING:
+		s.str = true
+	case JSON_ARRAY:
+		s.array = true
+		for _, item := range v.arr {
+			if s.items == nil {
+				s.items = &genShape{}
+			}
+			if err := s.items.add(item); err != nil {
+				return err
+			}
+		}
+	case JSON_OBJECT:
+		s.objects++
+		if s.fields == nil {
+			s.fields = make(map[string]*genShape)
+			s.present = make(map[string]int)
+		}
+		for _, key := range v.keys {
+			f, ok := s.fields[key]
+			if !ok {
+				f = &genShape{}
+				s.fields[key] = f
+				s.keys = append(s.keys, key)
+			}
+			s.present[key]++
+			if err := f.add(v.obj[key]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// 非 null 的类型个数, 整数和小数算作一种
+func (s *genShape) kinds() int {
+	n := 0
+	for _, b := range []bool{s.boolean, s.integer || s.float, s.str, s.array, s.objects > 0} {
+		if b {
+			n++
+		}
+	}
+	return n
+}
+
+type generator struct {
+	tag   string
+	used  map[string]bool // 已经使用的类型名
+	decls []string        // 按生成顺序排列的类型定义
+}
+
+// 由示例 JSON 生成 Go 类型定义, 返回 gofmt 过的源码. 多个样本合并为同一个类型:
+// 对象生成结构体, 字段按键第一次出现的顺序排列, 嵌套对象和数组元素中的对象生成
+// 单独的结构体; 数组元素合并为一个元素类型; 整数为 int64, 出现过小数时为
+// float64; 类型不一致或只出现过 null 时为 interface{}. 有样本缺少的键是可选字段,
+// 加上 omitempty 并使用指针, 出现过 null 的标量和结构体也使用指针.
+// 字段名由键转换为导出的标识符, 常见缩写大写 (user_id -> UserID); 同一结构体中
+// 字段名冲突, 或者不同位置的结构体同名时, 先加上外层类型名, 再加数字后缀
+func GenerateGo(samples ...*Value) ([]byte, error) {
+	return GenerateGoWithOptions(samples, GenOptions{})
+}
+
+func GenerateGoWithOptions(samples []*Value, opts GenOptions) ([]byte, error) {
+	pkg, name, tag := opts.Package, opts.Name, opts.Tag
+	if pkg == "" {
+		pkg = "main"
+	}
+	if name == "" {
+		name = "Root"
+	}
+	if tag == "" {
+		tag = "json"
+	}
+	if !token.IsIdentifier(pkg) {
+		return nil, fmt.Errorf("generate: invalid package name %q", pkg)
+	}
+	if !token.IsIdentifier(name) || !token.IsExported(name) {
+		return nil, fmt.Errorf("generate: invalid type name %q", name)
+	}
+	if !token.IsIdentifier(tag) {
+		return nil, fmt.Errorf("generate: invalid tag key %q", tag)
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("generate: no samples")
+	}
+
+	root := &genShape{}
+	for _, v := range samples {
+		if err := root.add(v); err != nil {
+			return nil, err
+		}
+	}
+
+	g := &generator{tag: tag, used: map[string]bool{name: true}}
+	if root.kinds() == 1 && root.objects > 0 {
+		g.structType(root, name)
+	} else {
+		// 非对象的根定义为具名类型, 数组元素从 Root 派生名字
+		t := g.goType(root, name, name)
+		g.decls = append([]string{fmt.Sprintf("type %s %s\n", name, t)}, g.decls...)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "package %s\n", pkg)
+	for _, decl := range g.decls {
+		buf.WriteString("\n")
+		buf.WriteString(decl)
+	}
+	return format.Source(buf.Bytes())
+}
+
+// 返回 s 对应的 Go 类型, name 为需要生成结构体时使用的名字
+func (g *generator) goType(s *genShape, name, parent string) string {
+	if s.kinds() != 1 {
+		return "interface{}"
+	}
+	var t string
+	switch {
+	case s.boolean:
+		t = "bool"
+	case s.float:
+		t = "float64"
+	case s.integer:
+		t = "int64"
+	case s.str:
+		t = "string"
+	case s.array:
+		if s.items == nil {
+			return "[]interface{}"
+		}
+		return "[]" + g.goType(s.items, elemName(name), parent)
+	default:
+		t = g.structType(s, g.typeName(name, parent))
+	}
+	if s.null {
+		t = "*" + t
+	}
+	return t
+}
+
+func (g *generator) structType(s *genShape, name string) string {
+	// 先占位, 嵌套的结构体排在外层之后
+	at := len(g.decls)
+	g.decls = append(g.decls, "")
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "type %s struct {\n", name)
+	names := make(map[string]bool)
+	for _, key := range s.keys {
+		f := s.fields[key]
+		if !validTagName(key) {
+			fmt.Fprintf(&buf, "\t// %s cannot be expressed in a struct tag\n", strconv.Quote(key))
+			continue
+		}
+		field := exportName(key)
+		if names[field] {
+			field = uniqueName(field, names)
+		}
+		names[field] = true
+
+		t := g.goType(f, field, name)
+		tagName := key
+		if s.present[key] < s.objects {
+			if !strings.HasPrefix(t, "*") && !strings.HasPrefix(t, "[]") && t != "interface{}" {
+				t = "*" + t
+			}
+			tagName += ",omitempty"
+		} else if key == "-" {
+			// json:"-" 表示忽略字段
+			tagName = "-,"
+		}
+		fmt.Fprintf(&buf, "\t%s %s `%s:%s`\n", field, t, g.tag, strconv.Quote(tagName))
+	}
+	buf.WriteString("}\n")
+	g.decls[at] = buf.String()
+	return name
+}
+
+// 不同位置的同名结构体依次尝试 name, 外层类型名+name, name2, name3...
+func (g *generator) typeName(name, parent string) string {
+	if !g.used[name] {
+		g.used[name] = true
+		return name
+	}
+	if !g.used[parent+name] {
+		g.used[parent+name] = true
+		return parent + name
+	}
+	name = uniqueName(name, g.used)
+	g.used[name] = true
+	return name
+}
+
+func uniqueName(name string, used map[string]bool) string {
+	for i := 2; ; i++ {
+		if n := name + strconv.Itoa(i); !used[n] {
+			return n
+		}
+	}
+}
+
+// 生成的字段名中全部大写的缩写
+var commonInitialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true,
+	"EOF": true, "GUID": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true,
+	"IP": true, "JSON": true, "LHS": true, "QPS": true, "RAM": true, "RHS": true,
+	"RPC": true, "SLA": true, "SMTP": true, "SQL": true, "SSH": true, "TCP": true,
+	"TLS": true, "TTL": true, "UDP": true, "UI": true, "UID": true, "UUID": true,
+	"URI": true, "URL": true, "UTF8": true, "VM": true, "XML": true, "XSRF": true, "XSS": true,
+}
+
+// 把 JSON 键转换为导出的 Go 标识符, 不能以大写字母开头时加上前缀 X
+func exportName(key string) string {
+	parts := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, part := range parts {
+		for _, word := range splitWords(part) {
+			if commonInitialisms[strings.ToUpper(word)] {
+				b.WriteString(strings.ToUpper(word))
+				continue
+			}
+			runes := []rune(word)
+			runes[0] = unicode.ToUpper(runes[0])
+			b.WriteString(string(runes))
+		}
+	}
+	name := b.String()
+	if name == "" {
+		return "Field"
+	}
+	if !token.IsExported(name) {
+		name = "X" + name
+	}
+	return name
+}
+
+// 数组元素的类型名: Users -> User, Categories -> Category, 无法变为单数时加上 Item
+func elemName(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 4:
+		return name[:len(name)-3] + "y"
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss") && !strings.HasSuffix(name, "us") && len(name) > 3:
+		return name[:len(name)-1]
+	}
+	return name + "Item"
+}
+
+// 与 encoding/json 相同, 标签名中不能有引号, 反斜杠和逗号
+func validTagName(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", r):
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			return false
+		}
+	}
+	return true
+}
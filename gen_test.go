@@ -0,0 +1,219 @@
+package yjson
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+// 生成的源码必须能通过类型检查
+func checkGenerated(t *testing.T, src []byte) {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "gen.go", src, 0)
+	if err != nil {
+		t.Fatalf("parse generated code: %v\n%s", err, src)
+	}
+	if _, err := new(types.Config).Check("gen", fset, []*ast.File{f}, nil); err != nil {
+		t.Fatalf("type check generated code: %v\n%s", err, src)
+	}
+}
+
+func TestGenerateGo(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []string
+		want    string // package main 之后的部分
+	}{
+		{
+			"optional and nullable fields",
+			[]string{
+				`{"user_id":1,"name":"a","tags":["x"],"address":{"city":"c"},"items":[{"sku":"s","price":1.5}]}`,
+				`{"user_id":2,"extra":null,"address":null}`,
+			},
+			`
+type Root struct {
+	UserID  int64       ` + "`json:\"user_id\"`" + `
+	Name    *string     ` + "`json:\"name,omitempty\"`" + `
+	Tags    []string    ` + "`json:\"tags,omitempty\"`" + `
+	Address *Address    ` + "`json:\"address\"`" + `
+	Items   []Item      ` + "`json:\"items,omitempty\"`" + `
+	Extra   interface{} ` + "`json:\"extra,omitempty\"`" + `
+}
+
+type Address struct {
+	City string ` + "`json:\"city\"`" + `
+}
+
+type Item struct {
+	Sku   string  ` + "`json:\"sku\"`" + `
+	Price float64 ` + "`json:\"price\"`" + `
+}
+`,
+		},
+		{
+			"array root",
+			[]string{`[{"id":1},{"id":2,"x":true}]`},
+			`
+type Root []RootItem
+
+type RootItem struct {
+	ID int64 ` + "`json:\"id\"`" + `
+	X  *bool ` + "`json:\"x,omitempty\"`" + `
+}
+`,
+		},
+		{
+			"type name collisions",
+			[]string{`{"root":{"root":1},"user":{"a":1},"x":{"user":{"b":2}}}`},
+			`
+type Root struct {
+	Root RootRoot ` + "`json:\"root\"`" + `
+	User User     ` + "`json:\"user\"`" + `
+	X    X        ` + "`json:\"x\"`" + `
+}
+
+type RootRoot struct {
+	Root int64 ` + "`json:\"root\"`" + `
+}
+
+type User struct {
+	A int64 ` + "`json:\"a\"`" + `
+}
+
+type X struct {
+	User XUser ` + "`json:\"user\"`" + `
+}
+
+type XUser struct {
+	B int64 ` + "`json:\"b\"`" + `
+}
+`,
+		},
+		{
+			"field names",
+			[]string{`{"-":1,"a,b":2,"_":4,"__":5,"123":7,"HTTPServer":8,"userURL":9,"Id":10,"id":11}`},
+			`
+type Root struct {
+	Field int64 ` + "`json:\"-,\"`" + `
+	// "a,b" cannot be expressed in a struct tag
+	Field2     int64 ` + "`json:\"_\"`" + `
+	Field3     int64 ` + "`json:\"__\"`" + `
+	X123       int64 ` + "`json:\"123\"`" + `
+	HTTPServer int64 ` + "`json:\"HTTPServer\"`" + `
+	UserURL    int64 ` + "`json:\"userURL\"`" + `
+	ID         int64 ` + "`json:\"Id\"`" + `
+	ID2        int64 ` + "`json:\"id\"`" + `
+}
+`,
+		},
+		{
+			"element names",
+			[]string{`{"data":[{"a":1}],"categories":[{"b":1}],"status":[{"c":1}]}`},
+			`
+type Root struct {
+	Data       []DataItem   ` + "`json:\"data\"`" + `
+	Categories []Category   ` + "`json:\"categories\"`" + `
+	Status     []StatusItem ` + "`json:\"status\"`" + `
+}
+
+type DataItem struct {
+	A int64 ` + "`json:\"a\"`" + `
+}
+
+type Category struct {
+	B int64 ` + "`json:\"b\"`" + `
+}
+
+type StatusItem struct {
+	C int64 ` + "`json:\"c\"`" + `
+}
+`,
+		},
+		{"scalar root", []string{`1`, `2`}, "\ntype Root int64\n"},
+		{"mixed numbers", []string{`[[1],[2.5]]`}, "\ntype Root [][]float64\n"},
+		{"mixed types", []string{`[1,"a"]`}, "\ntype Root []interface{}\n"},
+		{"only null", []string{`null`}, "\ntype Root interface{}\n"},
+		{"empty array", []string{`{"a":[]}`}, "\ntype Root struct {\n\tA []interface{} `json:\"a\"`\n}\n"},
+		{"duplicate keys", []string{`{"a":1,"a":2}`}, "\ntype Root struct {\n\tA int64 `json:\"a\"`\n}\n"},
+		{"object or scalar", []string{`{"a":1}`, `"s"`}, "\ntype Root interface{}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var samples []*Value
+			for _, s := range tt.samples {
+				samples = append(samples, mustParse(t, s))
+			}
+			out, err := GenerateGo(samples...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := "package main\n" + tt.want; string(out) != want {
+				t.Errorf("got\n%s\nwant\n%s", out, want)
+			}
+			checkGenerated(t, out)
+		})
+	}
+}
+
+func TestGenerateGoOptions(t *testing.T) {
+	v := mustParse(t, `{"a":{"b":1}}`)
+	out, err := GenerateGoWithOptions([]*Value{v}, GenOptions{Package: "models", Name: "Doc", Tag: "yjson"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "package models\n\ntype Doc struct {\n\tA A `yjson:\"a\"`\n}\n\ntype A struct {\n\tB int64 `yjson:\"b\"`\n}\n"
+	if string(out) != want {
+		t.Errorf("got\n%s\nwant\n%s", out, want)
+	}
+	checkGenerated(t, out)
+
+	tests := []struct {
+		samples []*Value
+		opts    GenOptions
+		want    string
+	}{
+		{[]*Value{v}, GenOptions{Package: "a-b"}, `generate: invalid package name "a-b"`},
+		{[]*Value{v}, GenOptions{Name: "doc"}, `generate: invalid type name "doc"`},
+		{[]*Value{v}, GenOptions{Name: "1X"}, `generate: invalid type name "1X"`},
+		{[]*Value{v}, GenOptions{Tag: "a:b"}, `generate: invalid tag key "a:b"`},
+		{nil, GenOptions{}, `generate: no samples`},
+	}
+	for _, tt := range tests {
+		_, err := GenerateGoWithOptions(tt.samples, tt.opts)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%+v: got %v, want %s", tt.opts, err, tt.want)
+		}
+	}
+
+	lazy, err := ParseWithOptions([]byte(`{"a":{"b":[1]}}`), ParseOptions{Lazy: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out, err := GenerateGo(lazy); err != nil || !strings.Contains(string(out), "B []int64 `json:\"b\"`") {
+		t.Errorf("lazy: got %s, %v", out, err)
+	}
+}
+
+func TestExportName(t *testing.T) {
+	tests := []struct{ key, want string }{
+		{"name", "Name"},
+		{"user_id", "UserID"},
+		{"user-name", "UserName"},
+		{"userName", "UserName"},
+		{"HTTPServer", "HTTPServer"},
+		{"api_url", "APIURL"},
+		{"123abc", "X123abc"},
+		{"名字", "X名字"},
+		{"_", "Field"},
+		{"a.b c", "ABC"},
+	}
+	for _, tt := range tests {
+		if got := exportName(tt.key); got != tt.want {
+			t.Errorf("exportName(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package yjson
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
+// GetBytes 的结果. Raw 与输入共享内存, 不要修改
+type Result struct {
+	Type  Kind   // 路径不存在或输入不完整时为 JSON_MISSING
+	Raw   []byte // 值的源文本, 字符串包含引号
+	Index int    // Raw 在输入中的偏移, 不存在时为 -1
+}
+
+// 按 Get 的路径语法在原始 JSON 中查找, 只扫描到目标值为止, 路径之外的值只匹配
+// 括号和引号后跳过, 不构建 Value 也不分配内存. 不检查语法, 需要时先调用 Valid;
+// 不支持注释和 JSON5. 对象有重复的键时返回第一个, 而 Parse 保留最后一个.
+// 含有 * 或 .. 的路径返回第一个匹配
+func GetBytes(data []byte, path string) Result {
+	i := rawSpace(data, 0)
+	for p := pathStart(path); p < len(path); {
+		seg, next, err := nextPathSegment(path, p)
+		if err != nil {
+			return Result{Type: JSON_MISSING, Index: -1}
+		}
+		if seg.wildcard || seg.recursive {
+			segments, err := parsePath(path[p:])
+			if err != nil {
+				return Result{Type: JSON_MISSING, Index: -1}
+			}
+			return rawResult(data, rawMatch(data, i, segments))
+		}
+		if i = rawLookup(data, i, seg); i < 0 {
+			return Result{Type: JSON_MISSING, Index: -1}
+		}
+		p = next
+	}
+	return rawResult(data, i)
+}
+
+func getRaw(data []byte, segments []pathSegment) Result {
+	i := rawSpace(data, 0)
+	if multiMatch(segments) {
+		return rawResult(data, rawMatch(data, i, segments))
+	}
+	for _, seg := range segments {
+		if i = rawLookup(data, i, seg); i < 0 {
+			return Result{Type: JSON_MISSING, Index: -1}
+		}
+	}
+	return rawResult(data, i)
+}
+
+// data[i] 开始的值
+func rawResult(data []byte, i int) Result {
+	end := rawSkip(data, i)
+	if end < 0 {
+		return Result{Type: JSON_MISSING, Index: -1}
+	}
+	return Result{Type: rawKind(data[i]), Raw: data[i:end], Index: i}
+}
+
+func (r Result) Exists() bool {
+	return r.Type != JSON_MISSING
+}
+
+// 在结果中继续按路径查找, Index 仍是相对最初输入的偏移
+func (r Result) Get(path string) Result {
+	res := GetBytes(r.Raw, path)
+	if res.Exists() {
+		res.Index += r.Index
+	}
+	return res
+}
+
+// 完整解析 Raw
+func (r Result) Value() (*Value, error) {
+	if !r.Exists() {
+		return nil, fmt.Errorf("value not found")
+	}
+	return Parse(r.Raw)
+}
+
+func (r Result) expectKind(k Kind) error {
+	if r.Type != k {
+		return fmt.Errorf("expect %s, but get %s", k, r.Type)
+	}
+	return nil
+}
+
+// 没有转义字符时不需要解析
+func (r Result) String() (string, error) {
+	if err := r.expectKind(JSON_STRING); err != nil {
+		return "", err
+	}
+	body := r.Raw[1 : len(r.Raw)-1]
+	if stringRunEnd(body, 0, DQ) == len(body) && utf8.Valid(body) {
+		return string(body), nil
+	}
+	v, err := Parse(r.Raw)
+	if err != nil {
+		return "", err
+	}
+	return v.str, nil
+}
+
+func (r Result) Bool() (bool, error) {
+	if err := r.expectKind(JSON_BOOLEAN); err != nil {
+		return false, err
+	}
+	return r.Raw[0] == 't', nil
+}
+
+// 普通整数直接转换, 1e3 和超出范围的数字按 Value.Int64 的规则处理
+func (r Result) Int64() (int64, error) {
+	if err := r.expectKind(JSON_NUMBER); err != nil {
+		return 0, err
+	}
+	if n, err := strconv.ParseInt(string(r.Raw), 10, 64); err == nil {
+		return n, nil
+	}
+	v, err := Parse(r.Raw)
+	if err != nil {
+		return 0, err
+	}
+	return v.Int64()
+}
+
+func (r Result) Float64() (float64, error) {
+	if err := r.expectKind(JSON_NUMBER); err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(string(r.Raw), 64)
+}
+
+func rawKind(c byte) Kind {
+	switch {
+	case c == OB:
+		return JSON_OBJECT
+	case c == LB:
+		return JSON_ARRAY
+	case c == DQ:
+		return JSON_STRING
+	case c == 't' || c == 'f':
+		return JSON_BOOLEAN
+	case c == 'n':
+		return JSON_NULL
+	case c == MINUS || isDigit(c):
+		return JSON_NUMBER
+	}
+	return JSON_MISSING
+}
+
+// 以下函数从下标 i 开始扫描, 返回之后的下标, 输入不完整时返回 -1
+
+func rawSpace(data []byte, i int) int {
+	for i >= 0 && i < len(data) {
+		switch data[i] {
+		case BLANK_SPACE:
+			i = skipBlanks(data, i)
+		case HORIZONTAL_TAB, LINE_BREAK, CARRIAGE_RETURN:
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// 跳过 data[i] 开始的一个值
+func rawSkip(data []byte, i int) int {
+	if i < 0 || i >= len(data) {
+		return -1
+	}
+	switch c := data[i]; {
+	case c == DQ:
+		return rawStringEnd(data, i)
+	case c == OB || c == LB:
+		depth := 0
+		for i < len(data) {
+			switch data[i] {
+			case DQ:
+				if i = rawStringEnd(data, i); i < 0 {
+					return -1
+				}
+				continue
+			case OB, LB:
+				depth++
+			case CB, RB:
+				if depth--; depth == 0 {
+					return i + 1
+				}
+			}
+			i++
+		}
+		return -1
+	}
+	start := i
+	for i < len(data) && isScalarByte(data[i]) {
+		i++
+	}
+	if i == start {
+		return -1
+	}
+	return i
+}
+
+// data[i] 为引号, 返回结束引号之后的下标
+func rawStringEnd(data []byte, i int) int {
+	for i++; i < len(data); i++ {
+		i = stringRunEnd(data, i, DQ)
+		if i >= len(data) {
+			break
+		}
+		switch data[i] {
+		case BACKSLASH:
+			i++
+		case DQ:
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// 在 data[i] 开始的对象或数组中取一段, 返回成员值的起始下标
+func rawLookup(data []byte, i int, seg pathSegment) int {
+	if i < 0 || i >= len(data) {
+		return -1
+	}
+	switch data[i] {
+	case OB:
+		if seg.isIndex {
+			return -1
+		}
+		return rawMember(data, i, seg.key)
+	case LB:
+		index, err := seg.arrayIndex()
+		if err != nil {
+			return -1
+		}
+		return rawElement(data, i, index)
+	}
+	return -1
+}
+
+func rawMember(data []byte, i int, key string) int {
+	i = rawSpace(data, i+1)
+	if i < len(data) && data[i] == CB {
+		return -1
+	}
+	for i >= 0 && i < len(data) && data[i] == DQ {
+		end := rawStringEnd(data, i)
+		if end < 0 {
+			return -1
+		}
+		match := rawKeyEqual(data[i:end], key)
+		i = rawSpace(data, end)
+		if i < 0 || i >= len(data) || data[i] != VALUE_SEPARATOR {
+			return -1
+		}
+		i = rawSpace(data, i+1)
+		if match {
+			return i
+		}
+		i = rawSpace(data, rawSkip(data, i))
+		if i < 0 || i >= len(data) || data[i] != DOT {
+			return -1
+		}
+		i = rawSpace(data, i+1)
+	}
+	return -1
+}
+
+// quoted 包含引号, 只有带转义字符的键才需要解码
+func rawKeyEqual(quoted []byte, key string) bool {
+	body := quoted[1 : len(quoted)-1]
+	if stringRunEnd(body, 0, DQ) == len(body) {
+		return string(body) == key
+	}
+	v, err := Parse(quoted)
+	return err == nil && v.str == key
+}
+
+func rawElement(data []byte, i int, index int) int {
+	i = rawSpace(data, i+1)
+	if i < len(data) && data[i] == RB {
+		return -1
+	}
+	for n := 0; i >= 0 && i < len(data); n++ {
+		if n == index {
+			return i
+		}
+		i = rawSpace(data, rawSkip(data, i))
+		if i < 0 || i >= len(data) || data[i] != DOT {
+			return -1
+		}
+		i = rawSpace(data, i+1)
+	}
+	return -1
+}
+
+// 在 data[i] 开始的值中按文档顺序查找第一个匹配 segments 的值, 返回其起始下标.
+// 与 matchPath 的顺序相同: 先检查成员本身, 再检查成员的后代
+func rawMatch(data []byte, i int, segments []pathSegment) int {
+	if len(segments) == 0 {
+		return i
+	}
+	if i < 0 || i >= len(data) || (data[i] != OB && data[i] != LB) {
+		return -1
+	}
+	seg := segments[0]
+	closer := byte(RB)
+	if data[i] == OB {
+		closer = CB
+	}
+	i = rawSpace(data, i+1)
+	if i < len(data) && data[i] == closer {
+		return -1
+	}
+	for n := 0; i >= 0 && i < len(data); n++ {
+		match := false
+		if closer == CB {
+			if data[i] != DQ {
+				return -1
+			}
+			end := rawStringEnd(data, i)
+			if end < 0 {
+				return -1
+			}
+			match = seg.wildcard || (!seg.isIndex && rawKeyEqual(data[i:end], seg.key))
+			i = rawSpace(data, end)
+			if i < 0 || i >= len(data) || data[i] != VALUE_SEPARATOR {
+				return -1
+			}
+			i = rawSpace(data, i+1)
+		} else {
+			match = seg.matchIndex(n)
+		}
+		if match {
+			if found := rawMatch(data, i, segments[1:]); found >= 0 {
+				return found
+			}
+		}
+		if seg.recursive {
+			if found := rawMatch(data, i, segments); found >= 0 {
+				return found
+			}
+		}
+		i = rawSpace(data, rawSkip(data, i))
+		if i < 0 || i >= len(data) || data[i] != DOT {
+			return -1
+		}
+		i = rawSpace(data, i+1)
+	}
+	return -1
+}
@@ -0,0 +1,149 @@
+package yjson
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetBytes(t *testing.T) {
+	data := []byte(pathDoc)
+	v := mustParse(t, pathDoc)
+	// 与 Get 的结果一致
+	paths := []string{
+		"users", "users[1].address.city", "users.1.address.city", "$.users[0].name",
+		"users[1].tags[1]", `["a.b"]`, `a\.b`, "a.b", `['a'].b`, `\*`, `["*"]`, `[""]`,
+		`["q\"k"]`, "users[2]", "users[0].tags[0]", "users.x", "a[0]", "a.b.c",
+		"nope.deeper.still", "users[-1]", "users[", "users[*].name", "users.*.address.city",
+		"..city", "..tags[0]", "users..name", "*",
+	}
+	for _, path := range paths {
+		res := GetBytes(data, path)
+		want := v.Get(path)
+		if res.Exists() != want.Exists() {
+			t.Errorf("%s: Exists %v, Get %v", path, res.Exists(), want.Exists())
+			continue
+		}
+		if !res.Exists() {
+			if res.Raw != nil || res.Index != -1 {
+				t.Errorf("%s: missing result %+v", path, res)
+			}
+			continue
+		}
+		if res.Type != want.Type() {
+			t.Errorf("%s: Type %s, want %s", path, res.Type, want.Type())
+		}
+		if string(data[res.Index:res.Index+len(res.Raw)]) != string(res.Raw) {
+			t.Errorf("%s: Index %d does not point at Raw", path, res.Index)
+		}
+		got, err := res.Value()
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if mustEncode(t, got) != mustEncode(t, want) {
+			t.Errorf("%s: got %s, want %s", path, res.Raw, mustEncode(t, want))
+		}
+	}
+}
+
+func TestGetBytesRaw(t *testing.T) {
+	tests := []struct {
+		input string
+		path  string
+		raw   string // 空表示不存在
+		kind  Kind
+	}{
+		{` { "a" : [ 1 , { "b" : "x\"y" } ] } `, "a[1].b", `"x\"y"`, JSON_STRING},
+		{` { "a" : [ 1 , { "b" : "x\"y" } ] } `, "a", `[ 1 , { "b" : "x\"y" } ]`, JSON_ARRAY},
+		{"{\n\t\"a\":\r\n-1.5e3}", "a", `-1.5e3`, JSON_NUMBER},
+		{`{"a":true,"b":false,"c":null}`, "b", `false`, JSON_BOOLEAN},
+		{`{"a":true,"b":false,"c":null}`, "c", `null`, JSON_NULL},
+		{`{"s":"}]","a":{"k":"[{"},"b":2}`, "b", `2`, JSON_NUMBER},
+		{`{"ab":1}`, "ab", `1`, JSON_NUMBER},
+		{`{"a":1,"a":2}`, "a", `1`, JSON_NUMBER},
+		{`[[],{},[[0]]]`, "[2][0][0]", `0`, JSON_NUMBER},
+		{`{}`, "a", ``, JSON_MISSING},
+		{`[]`, "[0]", ``, JSON_MISSING},
+		{`[1,2]`, "[2]", ``, JSON_MISSING},
+		{`[1,2]`, "a", ``, JSON_MISSING},
+		{`{"a":1}`, "[0]", ``, JSON_MISSING},
+		{`"str"`, "a", ``, JSON_MISSING},
+		// 输入不完整
+		{`{"a":[1,2`, "a", ``, JSON_MISSING},
+		{`{"a":"x`, "a", ``, JSON_MISSING},
+		{`{"a":1,"b`, "b", ``, JSON_MISSING},
+		{`{"a":`, "a", ``, JSON_MISSING},
+		{``, "", ``, JSON_MISSING},
+		{`{"a":[1,2`, "a[0]", `1`, JSON_NUMBER},
+	}
+	for _, tt := range tests {
+		res := GetBytes([]byte(tt.input), tt.path)
+		if string(res.Raw) != tt.raw || res.Type != tt.kind {
+			t.Errorf("%s in %s: got %s %q, want %s %q", tt.path, tt.input, res.Type, res.Raw, tt.kind, tt.raw)
+		}
+		if tt.raw != "" && !strings.HasPrefix(tt.input[res.Index:], tt.raw) {
+			t.Errorf("%s in %s: wrong Index %d", tt.path, tt.input, res.Index)
+		}
+	}
+}
+
+func TestResultAccessors(t *testing.T) {
+	data := []byte(`{"s":"plain","e":"a\nbé","t":true,"n":42,"big":1e3,"f":-2.5,"o":{"x":[10,20]}}`)
+	if s, err := GetBytes(data, "s").String(); err != nil || s != "plain" {
+		t.Errorf("String: %q %v", s, err)
+	}
+	if s, err := GetBytes(data, "e").String(); err != nil || s != "a\nbé" {
+		t.Errorf("escaped String: %q %v", s, err)
+	}
+	if b, err := GetBytes(data, "t").Bool(); err != nil || !b {
+		t.Errorf("Bool: %v %v", b, err)
+	}
+	if n, err := GetBytes(data, "n").Int64(); err != nil || n != 42 {
+		t.Errorf("Int64: %v %v", n, err)
+	}
+	if n, err := GetBytes(data, "big").Int64(); err != nil || n != 1000 {
+		t.Errorf("Int64 of 1e3: %v %v", n, err)
+	}
+	if _, err := GetBytes(data, "f").Int64(); err == nil {
+		t.Errorf("Int64 of -2.5: expected an error")
+	}
+	if f, err := GetBytes(data, "f").Float64(); err != nil || f != -2.5 {
+		t.Errorf("Float64: %v %v", f, err)
+	}
+
+	wrongKind := []error{}
+	_, err := GetBytes(data, "n").String()
+	wrongKind = append(wrongKind, err)
+	_, err = GetBytes(data, "s").Bool()
+	wrongKind = append(wrongKind, err)
+	_, err = GetBytes(data, "s").Int64()
+	wrongKind = append(wrongKind, err)
+	_, err = GetBytes(data, "missing").Float64()
+	wrongKind = append(wrongKind, err)
+	_, err = GetBytes(data, "missing").Value()
+	wrongKind = append(wrongKind, err)
+	for i, err := range wrongKind {
+		if err == nil {
+			t.Errorf("case %d: expected an error", i)
+		}
+	}
+
+	// Result.Get 返回相对最初输入的偏移
+	o := GetBytes(data, "o")
+	x := o.Get("x[1]")
+	if string(x.Raw) != "20" || string(data[x.Index:x.Index+2]) != "20" {
+		t.Errorf("Result.Get: %+v", x)
+	}
+	if o.Get("y").Exists() || GetBytes(data, "missing").Get("a").Exists() {
+		t.Errorf("Result.Get on a missing path should not exist")
+	}
+}
+
+func TestGetBytesDoesNotAllocate(t *testing.T) {
+	data := []byte(pathDoc)
+	allocs := testing.AllocsPerRun(100, func() {
+		GetBytes(data, "users[1].tags[1]")
+	})
+	if allocs != 0 {
+		t.Errorf("GetBytes allocated %v times", allocs)
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/Yohox/yjson
+
+go 1.21
@@ -0,0 +1,81 @@
+package yjson
+
+import (
+	"crypto/sha256"
+	"hash"
+	"sort"
+	"strconv"
+)
+
+// 对值的规范形式计算 SHA-256: 对象的键排序, 数字按精确值比较 (1, 1.0 和 1e0 相同,
+// 2^53 和 2^53+1 不同), 字符串按解码后的内容, 因此 Equal 判断相等的文档总是得到
+// 相同的结果, 键的顺序和空白不影响结果, 可以用于去重和缓存的键.
+// NaN 和 ±Inf 各自有固定的形式
+func Hash(v *Value) [32]byte {
+	h := sha256.New()
+	hashValue(h, v, make([]byte, 0, 64))
+	var sum [32]byte
+	h.Sum(sum[:0])
+	return sum
+}
+
+// 每个值以类型字母开头, 字符串和键带长度前缀, 不同结构的值不会得到相同的字节序列.
+// buf 为复用的临时缓冲区
+func hashValue(h hash.Hash, v *Value, buf []byte) []byte {
+	if err := v.load(); err != nil {
+		// Lazy 模式解析时已经检查过语法, 不会走到这里; 万一出错按源文本计算
+		buf = append(strconv.AppendInt(append(buf[:0], 'x'), int64(len(v.raw)), 10), VALUE_SEPARATOR)
+		h.Write(buf)
+		h.Write(v.raw)
+		return buf
+	}
+	switch v.Type() {
+	case JSON_NUMBER:
+		buf = append(buf[:0], 'n')
+		if r, ok := v.exactNumber(); ok {
+			buf = append(buf, r.RatString()...)
+		} else {
+			// 只有 NaN 和 ±Inf 没有精确值
+			f, _ := v.Float64()
+			buf = strconv.AppendFloat(buf, f, 'g', -1, 64)
+		}
+		h.Write(buf)
+	case JSON_STRING:
+		buf = appendHashString(buf[:0], 's', v.str)
+		h.Write(buf)
+	case JSON_BOOLEAN:
+		if v.b {
+			h.Write([]byte{'t'})
+		} else {
+			h.Write([]byte{'f'})
+		}
+	case JSON_ARRAY:
+		buf = append(strconv.AppendInt(append(buf[:0], 'a'), int64(len(v.arr)), 10), VALUE_SEPARATOR)
+		h.Write(buf)
+		for _, item := range v.arr {
+			buf = hashValue(h, item, buf)
+		}
+	case JSON_OBJECT:
+		keys := make([]string, 0, len(v.obj))
+		for key := range v.obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		buf = append(strconv.AppendInt(append(buf[:0], 'o'), int64(len(keys)), 10), VALUE_SEPARATOR)
+		h.Write(buf)
+		for _, key := range keys {
+			buf = appendHashString(buf[:0], 'k', key)
+			h.Write(buf)
+			buf = hashValue(h, v.obj[key], buf)
+		}
+	default:
+		// null 和不存在的值
+		h.Write([]byte{'z'})
+	}
+	return buf
+}
+
+func appendHashString(dst []byte, tag byte, s string) []byte {
+	dst = append(strconv.AppendInt(append(dst, tag), int64(len(s)), 10), VALUE_SEPARATOR)
+	return append(dst, s...)
+}
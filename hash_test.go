@@ -0,0 +1,74 @@
+package yjson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{`{"a":1,"b":[true,null]}`, `{ "b" : [ true , null ] , "a" : 1 }`, true},
+		{`1`, `1.0`, true},
+		{`100`, `1e2`, true},
+		{`0.5`, `5e-1`, true},
+		{`-0`, `0`, true},
+		{`"A"`, `"A"`, true},
+		{`9007199254740993`, `9007199254740992`, false},
+		{`18446744073709551615`, `18446744073709551614`, false},
+		{`0.1`, `0.10000000000000001`, false},
+		{`[1,23]`, `[12,3]`, false},
+		{`["ab","c"]`, `["a","bc"]`, false},
+		{`{"a":"b"}`, `{"ab":""}`, false},
+		{`[[]]`, `[[],[]]`, false},
+		{`"1"`, `1`, false},
+		{`null`, `false`, false},
+		{`[]`, `{}`, false},
+	}
+	for _, tt := range tests {
+		a, b := mustParse(t, tt.a), mustParse(t, tt.b)
+		if got := Hash(a) == Hash(b); got != tt.same {
+			t.Errorf("Hash(%s) == Hash(%s) = %v, want %v", tt.a, tt.b, got, tt.same)
+		}
+		if tt.same != Equal(a, b) {
+			t.Errorf("Equal(%s, %s) disagrees with the test table", tt.a, tt.b)
+		}
+	}
+}
+
+func TestHashNumberRepresentations(t *testing.T) {
+	want := Hash(mustParse(t, `[12,0.25]`))
+	for _, opts := range []ParseOptions{{UseRawNumbers: true}, {UseBigNumbers: true}, {Lazy: true}} {
+		v, err := ParseWithOptions([]byte(`[ 12, 25e-2 ]`), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if Hash(v) != want {
+			t.Errorf("%+v: hash differs", opts)
+		}
+	}
+	built := NewArray()
+	built.Append(NewUint(12))
+	built.Append(NewFloat(0.25))
+	if Hash(built) != want {
+		t.Errorf("built value: hash differs")
+	}
+}
+
+// 不能规范化输出的数字也有确定的结果
+func TestHashNonFinite(t *testing.T) {
+	values := []*Value{NewFloat(math.NaN()), NewFloat(math.Inf(1)), NewFloat(math.Inf(-1)), NewFloat(0)}
+	seen := make(map[[32]byte]int)
+	for i, v := range values {
+		h := Hash(v)
+		if Hash(NewFloat(v.MustFloat64())) != h {
+			t.Errorf("value %d: hash is not deterministic", i)
+		}
+		if j, ok := seen[h]; ok {
+			t.Errorf("values %d and %d have the same hash", j, i)
+		}
+		seen[h] = i
+	}
+}
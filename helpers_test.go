@@ -0,0 +1,21 @@
+package yjson
+
+import "testing"
+
+func mustParse(t *testing.T, s string) *Value {
+	t.Helper()
+	v, err := Parse([]byte(s))
+	if err != nil {
+		t.Fatalf("parse %s: %v", s, err)
+	}
+	return v
+}
+
+func mustEncode(t *testing.T, v *Value) string {
+	t.Helper()
+	b, err := v.Encode()
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	return string(b)
+}
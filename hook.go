@@ -0,0 +1,97 @@
+package yjson
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// 解码每个值之前调用, from 是 JSON 值的类型, to 是目标 Go 类型. 返回值:
+//   - nil, nil: 不处理, 继续按内置规则解码
+//   - *Value: 用返回的值代替 v 继续解码
+//   - 其他 Go 值: 必须能赋值给 to, 或者与 to 同属一类 (有符号整数, 无符号整数,
+//     浮点数, 字符串 ...) 且转换后不溢出, 直接写入目标. int 不会转为字符串,
+//     float64 也不会截断为 int
+//
+// 例如把秒级时间戳字符串转为 time.Time:
+//
+//	func(from yjson.Kind, to reflect.Type, v *yjson.Value) (interface{}, error) {
+//		if from != yjson.JSON_STRING || to != reflect.TypeOf(time.Time{}) {
+//			return nil, nil
+//		}
+//		s, _ := v.String()
+//		sec, err := strconv.ParseInt(s, 10, 64)
+//		return time.Unix(sec, 0), err
+//	}
+type DecodeHook func(from Kind, to reflect.Type, v *Value) (interface{}, error)
+
+// 依次调用 Hooks, 第一个返回非 nil 的生效. done 表示目标已经写好
+func (d *decodeState) runHooks(path string, j *Value, rv reflect.Value) (*Value, bool, error) {
+	for _, hook := range d.opts.Hooks {
+		out, err := hook(j.Type(), rv.Type(), j)
+		if err != nil {
+			return j, true, hookError(path, err)
+		}
+		if out == nil {
+			continue
+		}
+
+		if next, ok := out.(*Value); ok {
+			return next, false, nil
+		}
+		ov := reflect.ValueOf(out)
+		switch {
+		case ov.Type().AssignableTo(rv.Type()):
+			rv.Set(ov)
+		case kindFamily(ov.Kind()) == kindFamily(rv.Kind()) && ov.Type().ConvertibleTo(rv.Type()):
+			if overflows(ov, rv.Type()) {
+				err = fmt.Errorf("decode hook returned %s %v, overflows %s", ov.Type(), ov, rv.Type())
+			} else {
+				rv.Set(ov.Convert(rv.Type()))
+			}
+		default:
+			err = fmt.Errorf("decode hook returned %s, cannot assign to %s", ov.Type(), rv.Type())
+		}
+		if err != nil {
+			return j, true, hookError(path, err)
+		}
+		return j, true, nil
+	}
+	return j, false, nil
+}
+
+func hookError(path string, err error) error {
+	if path == "" {
+		return fmt.Errorf("unmarshal: %v", err)
+	}
+	return fmt.Errorf("unmarshal: %v at %s", err, path)
+}
+
+// 转换只在同一类之间进行, 避免 int -> string 得到字符, float64 -> int 截断小数
+func kindFamily(k reflect.Kind) reflect.Kind {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflect.Int
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return reflect.Uint
+	case reflect.Float32, reflect.Float64:
+		return reflect.Float64
+	case reflect.Complex64, reflect.Complex128:
+		return reflect.Complex128
+	}
+	return k
+}
+
+func overflows(v reflect.Value, t reflect.Type) bool {
+	z := reflect.Zero(t)
+	switch kindFamily(t.Kind()) {
+	case reflect.Int:
+		return z.OverflowInt(v.Int())
+	case reflect.Uint:
+		return z.OverflowUint(v.Uint())
+	case reflect.Float64:
+		return z.OverflowFloat(v.Float())
+	case reflect.Complex128:
+		return z.OverflowComplex(v.Complex())
+	}
+	return false
+}
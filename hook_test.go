@@ -0,0 +1,111 @@
+package yjson
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type hookTarget struct {
+	IP    net.IP    `json:"ip"`
+	At    time.Time `json:"at"`
+	Small int8      `json:"small"`
+	Count int       `json:"count"`
+	Name  string    `json:"name"`
+	Ratio float32   `json:"ratio"`
+}
+
+type celsius float64
+
+func TestDecodeHooks(t *testing.T) {
+	ipHook := func(from Kind, to reflect.Type, v *Value) (interface{}, error) {
+		if from != JSON_STRING || to != reflect.TypeOf(net.IP{}) {
+			return nil, nil
+		}
+		s, _ := v.String()
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP %q", s)
+		}
+		return ip, nil
+	}
+	epochHook := func(from Kind, to reflect.Type, v *Value) (interface{}, error) {
+		if from != JSON_NUMBER || to != reflect.TypeOf(time.Time{}) {
+			return nil, nil
+		}
+		sec, err := v.Int64()
+		return time.Unix(sec, 0).UTC(), err
+	}
+	// 返回 *Value 时用它代替原值继续解码
+	stringNumberHook := func(from Kind, to reflect.Type, v *Value) (interface{}, error) {
+		if from != JSON_STRING || to.Kind() != reflect.Int {
+			return nil, nil
+		}
+		s, _ := v.String()
+		n, err := strconv.Atoi(s)
+		return NewInt(int64(n)), err
+	}
+	opts := UnmarshalOptions{Hooks: []DecodeHook{ipHook, epochHook, stringNumberHook}}
+
+	var got hookTarget
+	input := `{"ip":"10.0.0.1","at":1700000000,"count":"42","name":"n"}`
+	if err := UnmarshalWithOptions([]byte(input), &got, opts); err != nil {
+		t.Fatal(err)
+	}
+	want := hookTarget{IP: net.ParseIP("10.0.0.1"), At: time.Unix(1700000000, 0).UTC(), Count: 42, Name: "n"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	err := UnmarshalWithOptions([]byte(`{"ip":"nope"}`), &got, opts)
+	if err == nil || err.Error() != `unmarshal: invalid IP "nope" at ip` {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestDecodeHookConversions(t *testing.T) {
+	constant := func(out interface{}) DecodeHook {
+		return func(Kind, reflect.Type, *Value) (interface{}, error) { return out, nil }
+	}
+	tests := []struct {
+		name   string
+		out    interface{}
+		target interface{}
+		want   interface{}
+		err    string
+	}{
+		{"assignable", "x", new(string), "x", ""},
+		{"int64 to int8", int64(-5), new(int8), int8(-5), ""},
+		{"uint to uint16", uint(7), new(uint16), uint16(7), ""},
+		{"float64 to float32", 1.5, new(float32), float32(1.5), ""},
+		{"named float", 21.5, new(celsius), celsius(21.5), ""},
+		{"named string", "s", new(Kind), nil, "unmarshal: decode hook returned string, cannot assign to yjson.Kind"},
+		{"int to string", 65, new(string), nil, "unmarshal: decode hook returned int, cannot assign to string"},
+		{"float64 to int", 1.9, new(int), nil, "unmarshal: decode hook returned float64, cannot assign to int"},
+		{"int to uint", -1, new(uint), nil, "unmarshal: decode hook returned int, cannot assign to uint"},
+		{"int to float64", 3, new(float64), nil, "unmarshal: decode hook returned int, cannot assign to float64"},
+		{"overflow", 300, new(int8), nil, "unmarshal: decode hook returned int 300, overflows int8"},
+		{"float overflow", 1e300, new(float32), nil, "unmarshal: decode hook returned float64 1e+300, overflows float32"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := UnmarshalOptions{Hooks: []DecodeHook{constant(tt.out)}}
+			err := UnmarshalWithOptions([]byte(`0`), tt.target, opts)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("got error %v, want %s", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := reflect.ValueOf(tt.target).Elem().Interface(); got != tt.want {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package yjson
+
+import (
+	"fmt"
+	"io"
+)
+
+// 逐个读取顶层数组的元素, 同一时间只在内存中保留一个元素:
+//
+//	it := yjson.NewArrayIter(r)
+//	for it.Next() {
+//		var rec Record
+//		if err := it.Decode(&rec); err != nil { ... }
+//	}
+//	if err := it.Err(); err != nil { ... }
+type ArrayIter struct {
+	dec     *Decoder
+	started bool
+	done    bool
+	index   int
+	cur     *Value
+	err     error
+}
+
+func NewArrayIter(r io.Reader) *ArrayIter {
+	return &ArrayIter{dec: NewDecoder(r), index: -1}
+}
+
+func (it *ArrayIter) SetOptions(opts ParseOptions) {
+	it.dec.SetOptions(opts)
+}
+
+// 读取下一个元素, 数组结束或出错时返回 false
+func (it *ArrayIter) Next() bool {
+	if it.done {
+		return false
+	}
+	if !it.started {
+		it.started = true
+		tok, err := it.dec.Token()
+		if err != nil {
+			return it.fail(err)
+		}
+		if tok != Delim(LB) {
+			return it.fail(fmt.Errorf("expect array, but get %v", tok))
+		}
+	}
+
+	if !it.dec.More() {
+		if _, err := it.dec.Token(); err != nil {
+			return it.fail(err)
+		}
+		if err := it.dec.skipSpace(); err != io.EOF {
+			if err == nil {
+				err = fmt.Errorf("unexpected data after array at offset %d", it.dec.InputOffset())
+			}
+			return it.fail(err)
+		}
+		it.done, it.cur = true, nil
+		return false
+	}
+
+	v := &Value{}
+	if err := it.dec.Decode(v); err != nil {
+		return it.fail(fmt.Errorf("element %d: %v", it.index+1, err))
+	}
+	it.index++
+	it.cur = v
+	return true
+}
+
+func (it *ArrayIter) fail(err error) bool {
+	it.err, it.done, it.cur = err, true, nil
+	return false
+}
+
+// 当前元素, Next 返回 false 后为 nil
+func (it *ArrayIter) Value() *Value {
+	return it.cur
+}
+
+// 当前元素的下标, 从 0 开始
+func (it *ArrayIter) Index() int {
+	return it.index
+}
+
+// 把当前元素绑定到 v, 规则同 Unmarshal
+func (it *ArrayIter) Decode(v interface{}) error {
+	return it.DecodeWithOptions(v, UnmarshalOptions{})
+}
+
+func (it *ArrayIter) DecodeWithOptions(v interface{}, opts UnmarshalOptions) error {
+	if it.cur == nil {
+		return fmt.Errorf("array iter: no current element")
+	}
+	return it.cur.UnmarshalWithOptions(v, opts)
+}
+
+// 读取中遇到的错误, 正常结束时为 nil
+func (it *ArrayIter) Err() error {
+	return it.err
+}
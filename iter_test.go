@@ -0,0 +1,101 @@
+package yjson
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestArrayIter(t *testing.T) {
+	tests := []struct {
+		input string
+		opts  ParseOptions
+		want  string
+	}{
+		{`[]`, ParseOptions{}, ``},
+		{` [1, "a", {"b": [2]}, null] `, ParseOptions{}, `0:1 1:"a" 2:{"b":[2]} 3:null`},
+		{`[[1], []]`, ParseOptions{}, `0:[1] 1:[]`},
+		{"[1, /* c */ 2,]", ParseOptions{AllowComments: true, AllowTrailingCommas: true}, `0:1 1:2`},
+		{`{"a": 1}`, ParseOptions{}, "error: expect array, but get {"},
+		{`1`, ParseOptions{}, "error: expect array, but get 1"},
+		{``, ParseOptions{}, "error: EOF"},
+		{`[1, 2`, ParseOptions{}, "error: unexpected EOF"},
+		{`[1, {"a" 2}]`, ParseOptions{}, "error: element 1: "},
+		{`[1] 2`, ParseOptions{}, "error: unexpected data after array at offset 4"},
+		{`[1, 2}`, ParseOptions{}, "error: unexpected '}' at offset 5"},
+	}
+	for _, tt := range tests {
+		it := NewArrayIter(iotest.OneByteReader(strings.NewReader(tt.input)))
+		it.SetOptions(tt.opts)
+		var got []string
+		for it.Next() {
+			got = append(got, strconv.Itoa(it.Index())+":"+mustEncode(t, it.Value()))
+		}
+		if it.Value() != nil {
+			t.Errorf("%q: Value after the end is %v", tt.input, it.Value())
+		}
+		if it.Next() {
+			t.Errorf("%q: Next after the end returned true", tt.input)
+		}
+		if want, ok := strings.CutPrefix(tt.want, "error: "); ok {
+			if it.Err() == nil || !strings.HasPrefix(it.Err().Error(), want) {
+				t.Errorf("%q: got error %v, want %s", tt.input, it.Err(), want)
+			}
+			continue
+		}
+		if it.Err() != nil {
+			t.Fatalf("%q: %v", tt.input, it.Err())
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.input, strings.Join(got, " "), tt.want)
+		}
+	}
+}
+
+type iterRecord struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestArrayIterDecode(t *testing.T) {
+	it := NewArrayIter(strings.NewReader(`[{"id": 1, "name": "a"}, {"id": "x"}, {"id": 3, "extra": true}]`))
+	var got []iterRecord
+	var errs []string
+	for it.Next() {
+		var rec iterRecord
+		if err := it.DecodeWithOptions(&rec, UnmarshalOptions{DisallowUnknownFields: true}); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		got = append(got, rec)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != (iterRecord{1, "a"}) {
+		t.Errorf("got %+v", got)
+	}
+	if len(errs) != 2 {
+		t.Errorf("got errors %q", errs)
+	}
+
+	// 没有当前元素
+	var rec iterRecord
+	if err := it.Decode(&rec); err == nil || err.Error() != "array iter: no current element" {
+		t.Errorf("got %v", err)
+	}
+	if err := NewArrayIter(strings.NewReader(`[]`)).Decode(&rec); err == nil {
+		t.Errorf("before Next: expected an error")
+	}
+}
+
+// 读取错误从 Err 返回
+func TestArrayIterReadError(t *testing.T) {
+	it := NewArrayIter(iotest.TimeoutReader(iotest.OneByteReader(strings.NewReader(`[1, 2, 3]`))))
+	for it.Next() {
+	}
+	if it.Err() != iotest.ErrTimeout {
+		t.Errorf("got %v", it.Err())
+	}
+}
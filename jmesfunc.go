@@ -0,0 +1,439 @@
+package yjson
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// 函数参数: 普通参数为 v, 表达式引用 &expr 为 expr
+type jmesArg struct {
+	v    *Value
+	expr jmesNode
+}
+
+// 参数允许的类型, 可以按位组合
+type jmesArgType int
+
+const (
+	jmesTypeNumber jmesArgType = 1 << iota
+	jmesTypeString
+	jmesTypeBoolean
+	jmesTypeArray
+	jmesTypeObject
+	jmesTypeNull
+	jmesTypeExpref
+	jmesTypeArrayNumber // 元素都是数字的数组
+	jmesTypeArrayString // 元素都是字符串的数组
+
+	jmesTypeAny = jmesTypeNumber | jmesTypeString | jmesTypeBoolean | jmesTypeArray | jmesTypeObject | jmesTypeNull
+)
+
+func (t jmesArgType) accept(arg jmesArg) bool {
+	if arg.expr != nil {
+		return t&jmesTypeExpref != 0
+	}
+	switch arg.v.Type() {
+	case JSON_NUMBER:
+		return t&jmesTypeNumber != 0
+	case JSON_STRING:
+		return t&jmesTypeString != 0
+	case JSON_BOOLEAN:
+		return t&jmesTypeBoolean != 0
+	case JSON_OBJECT:
+		return t&jmesTypeObject != 0
+	case JSON_ARRAY:
+		if t&jmesTypeArray != 0 {
+			return true
+		}
+		arr, err := arg.v.Array()
+		if err != nil || t&(jmesTypeArrayNumber|jmesTypeArrayString) == 0 {
+			return false
+		}
+		for _, item := range arr {
+			if item.Type() == JSON_NUMBER && t&jmesTypeArrayNumber == 0 || item.Type() == JSON_STRING && t&jmesTypeArrayString == 0 ||
+				item.Type() != JSON_NUMBER && item.Type() != JSON_STRING {
+				return false
+			}
+		}
+		return true
+	}
+	return t&jmesTypeNull != 0
+}
+
+func (t jmesArgType) String() string {
+	names := []string{"number", "string", "boolean", "array", "object", "null", "expref", "array[number]", "array[string]"}
+	if t == jmesTypeAny {
+		return "any"
+	}
+	var parts []string
+	for i, name := range names {
+		if t&(1<<i) != 0 {
+			parts = append(parts, name)
+		}
+	}
+	return strings.Join(parts, "|")
+}
+
+// variadic 为 true 时最后一个参数可以重复任意次 (至少一次)
+type jmesFunction struct {
+	args     []jmesArgType
+	variadic bool
+	call     func(args []jmesArg) (*Value, error)
+}
+
+var jmesFunctions = map[string]*jmesFunction{
+	"abs":         {[]jmesArgType{jmesTypeNumber}, false, jmesMath(math.Abs)},
+	"avg":         {[]jmesArgType{jmesTypeArrayNumber}, false, jmesAvg},
+	"ceil":        {[]jmesArgType{jmesTypeNumber}, false, jmesMath(math.Ceil)},
+	"contains":    {[]jmesArgType{jmesTypeArray | jmesTypeString, jmesTypeAny}, false, jmesContains},
+	"ends_with":   {[]jmesArgType{jmesTypeString, jmesTypeString}, false, jmesEndsWith},
+	"floor":       {[]jmesArgType{jmesTypeNumber}, false, jmesMath(math.Floor)},
+	"join":        {[]jmesArgType{jmesTypeString, jmesTypeArrayString}, false, jmesJoin},
+	"keys":        {[]jmesArgType{jmesTypeObject}, false, jmesKeys},
+	"length":      {[]jmesArgType{jmesTypeString | jmesTypeArray | jmesTypeObject}, false, jmesLength},
+	"map":         {[]jmesArgType{jmesTypeExpref, jmesTypeArray}, false, jmesMap},
+	"max":         {[]jmesArgType{jmesTypeArrayNumber | jmesTypeArrayString}, false, jmesExtreme(false)},
+	"max_by":      {[]jmesArgType{jmesTypeArray, jmesTypeExpref}, false, jmesExtremeBy(false)},
+	"merge":       {[]jmesArgType{jmesTypeObject}, true, jmesMerge},
+	"min":         {[]jmesArgType{jmesTypeArrayNumber | jmesTypeArrayString}, false, jmesExtreme(true)},
+	"min_by":      {[]jmesArgType{jmesTypeArray, jmesTypeExpref}, false, jmesExtremeBy(true)},
+	"not_null":    {[]jmesArgType{jmesTypeAny}, true, jmesNotNull},
+	"reverse":     {[]jmesArgType{jmesTypeArray | jmesTypeString}, false, jmesReverse},
+	"sort":        {[]jmesArgType{jmesTypeArrayNumber | jmesTypeArrayString}, false, jmesSort},
+	"sort_by":     {[]jmesArgType{jmesTypeArray, jmesTypeExpref}, false, jmesSortBy},
+	"starts_with": {[]jmesArgType{jmesTypeString, jmesTypeString}, false, jmesStartsWith},
+	"sum":         {[]jmesArgType{jmesTypeArrayNumber}, false, jmesSum},
+	"to_array":    {[]jmesArgType{jmesTypeAny}, false, jmesToArray},
+	"to_number":   {[]jmesArgType{jmesTypeAny}, false, jmesToNumber},
+	"to_string":   {[]jmesArgType{jmesTypeAny}, false, jmesToString},
+	"type":        {[]jmesArgType{jmesTypeAny}, false, jmesType},
+	"values":      {[]jmesArgType{jmesTypeObject}, false, jmesValues},
+}
+
+// 整数结果用 NewInt 表示, 编码时不会出现小数点
+func numberFromFloat(f float64) *Value {
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return NewInt(int64(f))
+	}
+	return NewFloat(f)
+}
+
+// 参数已经通过类型检查, 数组和数字的错误只可能来自 Lazy 模式下的非法源文本
+func jmesFloats(v *Value) ([]float64, error) {
+	arr, err := v.Array()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]float64, len(arr))
+	for i, item := range arr {
+		if out[i], err = item.Float64(); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func jmesMath(fn func(float64) float64) func(args []jmesArg) (*Value, error) {
+	return func(args []jmesArg) (*Value, error) {
+		f, err := args[0].v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return numberFromFloat(fn(f)), nil
+	}
+}
+
+func jmesSum(args []jmesArg) (*Value, error) {
+	nums, err := jmesFloats(args[0].v)
+	if err != nil {
+		return nil, err
+	}
+	sum := 0.0
+	for _, f := range nums {
+		sum += f
+	}
+	return numberFromFloat(sum), nil
+}
+
+func jmesAvg(args []jmesArg) (*Value, error) {
+	nums, err := jmesFloats(args[0].v)
+	if err != nil || len(nums) == 0 {
+		return NewNull(), err
+	}
+	sum := 0.0
+	for _, f := range nums {
+		sum += f
+	}
+	return numberFromFloat(sum / float64(len(nums))), nil
+}
+
+func jmesContains(args []jmesArg) (*Value, error) {
+	subject, search := args[0].v, args[1].v
+	if subject.Type() == JSON_STRING {
+		return NewBool(search.Type() == JSON_STRING && strings.Contains(subject.str, search.str)), nil
+	}
+	arr, err := subject.Array()
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range arr {
+		if equalValues(item, search) {
+			return NewBool(true), nil
+		}
+	}
+	return NewBool(false), nil
+}
+
+func jmesStartsWith(args []jmesArg) (*Value, error) {
+	return NewBool(strings.HasPrefix(args[0].v.str, args[1].v.str)), nil
+}
+
+func jmesEndsWith(args []jmesArg) (*Value, error) {
+	return NewBool(strings.HasSuffix(args[0].v.str, args[1].v.str)), nil
+}
+
+func jmesJoin(args []jmesArg) (*Value, error) {
+	arr, err := args[1].v.Array()
+	if err != nil {
+		return nil, err
+	}
+	parts := make([]string, len(arr))
+	for i, item := range arr {
+		parts[i] = item.str
+	}
+	return NewString(strings.Join(parts, args[0].v.str)), nil
+}
+
+func jmesKeys(args []jmesArg) (*Value, error) {
+	keys := args[0].v.Keys()
+	out := make([]*Value, len(keys))
+	for i, k := range keys {
+		out[i] = NewString(k)
+	}
+	return NewArray(out...), nil
+}
+
+func jmesValues(args []jmesArg) (*Value, error) {
+	var out []*Value
+	args[0].v.Range(func(_ string, v *Value) bool {
+		out = append(out, v)
+		return true
+	})
+	return NewArray(out...), nil
+}
+
+// 字符串的长度按 Unicode 码点计
+func jmesLength(args []jmesArg) (*Value, error) {
+	v := args[0].v
+	if v.Type() == JSON_STRING {
+		return NewInt(int64(utf8.RuneCountInString(v.str))), nil
+	}
+	if err := v.load(); err != nil {
+		return nil, err
+	}
+	return NewInt(int64(v.Len())), nil
+}
+
+// 与投影不同, map 保留 null 结果
+func jmesMap(args []jmesArg) (*Value, error) {
+	arr, err := args[1].v.Array()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Value, len(arr))
+	for i, item := range arr {
+		if out[i], err = args[0].expr.eval(item); err != nil {
+			return nil, err
+		}
+	}
+	return NewArray(out...), nil
+}
+
+func jmesMerge(args []jmesArg) (*Value, error) {
+	obj := NewObject()
+	for _, arg := range args {
+		arg.v.Range(func(k string, v *Value) bool {
+			obj.setMember(k, v)
+			return true
+		})
+	}
+	return obj, nil
+}
+
+func jmesNotNull(args []jmesArg) (*Value, error) {
+	for _, arg := range args {
+		if !arg.v.IsNull() {
+			return arg.v, nil
+		}
+	}
+	return NewNull(), nil
+}
+
+func jmesReverse(args []jmesArg) (*Value, error) {
+	v := args[0].v
+	if v.Type() == JSON_STRING {
+		runes := []rune(v.str)
+		for i, k := 0, len(runes)-1; i < k; i, k = i+1, k-1 {
+			runes[i], runes[k] = runes[k], runes[i]
+		}
+		return NewString(string(runes)), nil
+	}
+	arr, err := v.Array()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Value, len(arr))
+	for i, item := range arr {
+		out[len(arr)-1-i] = item
+	}
+	return NewArray(out...), nil
+}
+
+func jmesToArray(args []jmesArg) (*Value, error) {
+	if args[0].v.Type() == JSON_ARRAY {
+		return args[0].v, nil
+	}
+	return NewArray(args[0].v), nil
+}
+
+// 字符串按 JSON 数字解析, 无法解析的字符串和其他类型返回 null
+func jmesToNumber(args []jmesArg) (*Value, error) {
+	v := args[0].v
+	switch v.Type() {
+	case JSON_NUMBER:
+		return v, nil
+	case JSON_STRING:
+		n, err := ParseWithOptions([]byte(v.str), ParseOptions{Strict: true})
+		if err == nil && n.Type() == JSON_NUMBER {
+			return n, nil
+		}
+	}
+	return NewNull(), nil
+}
+
+func jmesToString(args []jmesArg) (*Value, error) {
+	v := args[0].v
+	if v.Type() == JSON_STRING {
+		return v, nil
+	}
+	b, err := v.Encode()
+	if err != nil {
+		return nil, err
+	}
+	return NewString(string(b)), nil
+}
+
+func jmesType(args []jmesArg) (*Value, error) {
+	return NewString(args[0].v.Type().String()), nil
+}
+
+// 排序的键: 全部是数字或全部是字符串
+type jmesSortKeys struct {
+	nums []float64
+	strs []string
+}
+
+func jmesCollectKeys(fn string, items []*Value, expr jmesNode) (jmesSortKeys, error) {
+	var keys jmesSortKeys
+	for i, item := range items {
+		k := item
+		if expr != nil {
+			var err error
+			if k, err = expr.eval(item); err != nil {
+				return keys, err
+			}
+		}
+		switch {
+		case k.Type() == JSON_NUMBER && len(keys.strs) == 0:
+			f, err := k.Float64()
+			if err != nil {
+				return keys, err
+			}
+			keys.nums = append(keys.nums, f)
+		case k.Type() == JSON_STRING && len(keys.nums) == 0:
+			keys.strs = append(keys.strs, k.str)
+		default:
+			return keys, fmt.Errorf("%s(): invalid type %s for element %d, expect number or string of the same type", fn, k.Type(), i)
+		}
+	}
+	return keys, nil
+}
+
+func (k jmesSortKeys) less(a, b int) bool {
+	if k.nums != nil {
+		return k.nums[a] < k.nums[b]
+	}
+	return k.strs[a] < k.strs[b]
+}
+
+func jmesSort(args []jmesArg) (*Value, error) {
+	return jmesSortItems("sort", args[0].v, nil)
+}
+
+func jmesSortBy(args []jmesArg) (*Value, error) {
+	return jmesSortItems("sort_by", args[0].v, args[1].expr)
+}
+
+// 稳定排序, 返回新的数组
+func jmesSortItems(fn string, v *Value, expr jmesNode) (*Value, error) {
+	arr, err := v.Array()
+	if err != nil {
+		return nil, err
+	}
+	keys, err := jmesCollectKeys(fn, arr, expr)
+	if err != nil {
+		return nil, err
+	}
+	order := make([]int, len(arr))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return keys.less(order[a], order[b]) })
+	out := make([]*Value, len(arr))
+	for i, k := range order {
+		out[i] = arr[k]
+	}
+	return NewArray(out...), nil
+}
+
+func jmesExtreme(min bool) func(args []jmesArg) (*Value, error) {
+	return func(args []jmesArg) (*Value, error) {
+		name := "max"
+		if min {
+			name = "min"
+		}
+		return jmesPick(name, args[0].v, nil, min)
+	}
+}
+
+func jmesExtremeBy(min bool) func(args []jmesArg) (*Value, error) {
+	return func(args []jmesArg) (*Value, error) {
+		name := "max_by"
+		if min {
+			name = "min_by"
+		}
+		return jmesPick(name, args[0].v, args[1].expr, min)
+	}
+}
+
+// 返回键最小 (min) 或最大的元素, 空数组返回 null
+func jmesPick(fn string, v *Value, expr jmesNode, min bool) (*Value, error) {
+	arr, err := v.Array()
+	if err != nil {
+		return nil, err
+	}
+	keys, err := jmesCollectKeys(fn, arr, expr)
+	if err != nil || len(arr) == 0 {
+		return NewNull(), err
+	}
+	best := 0
+	for i := 1; i < len(arr); i++ {
+		if min && keys.less(i, best) || !min && keys.less(best, i) {
+			best = i
+		}
+	}
+	return arr[best], nil
+}
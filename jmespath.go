@@ -0,0 +1,1007 @@
+package yjson
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 按 JMESPath 表达式求值, 返回单个结果, 没有匹配时返回 JSON null. 支持:
+//
+//	foo.bar  "foo bar"        字段, 带引号的字段名
+//	[0]  [-1]  [1:3:2]        下标和切片
+//	[*]  *  []                列表投影, 对象值投影, 展平
+//	[?price < `10`]           过滤器: == != < <= > >= && || ! 和括号
+//	[a, b]  {x: a, y: b}      多选列表和多选哈希
+//	a | b                     管道, 结束左侧的投影
+//	@  `{"json": 1}`  'raw'   当前节点, JSON 字面量, 原始字符串
+//	sort_by(@, &age)          函数调用, & 为表达式引用
+//
+// 内置函数与规范一致: abs avg ceil contains ends_with floor join keys
+// length map max max_by merge min min_by not_null reverse sort sort_by
+// starts_with sum to_array to_number to_string type values.
+// 结果可能与 j 共享节点, 表达式非法或函数参数类型错误时返回错误
+func (j *Value) Search(expr string) (*Value, error) {
+	node, err := parseJMESPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	res, err := node.eval(j)
+	if err != nil {
+		return nil, fmt.Errorf("jmespath %q: %v", expr, err)
+	}
+	return res, nil
+}
+
+type jmesNode interface {
+	eval(cur *Value) (*Value, error)
+}
+
+// JMESPath 的真值: null false "" [] {} 为假, 其余为真
+func jmesTruthy(v *Value) bool {
+	switch v.Type() {
+	case JSON_MISSING, JSON_NULL:
+		return false
+	case JSON_BOOLEAN:
+		return v.b
+	case JSON_STRING:
+		return v.str != ""
+	case JSON_ARRAY, JSON_OBJECT:
+		return v.Len() > 0
+	}
+	return true
+}
+
+type jmesIdentity struct{}
+
+func (jmesIdentity) eval(cur *Value) (*Value, error) {
+	if !cur.Exists() {
+		return NewNull(), nil
+	}
+	return cur, nil
+}
+
+type jmesLiteral struct {
+	v *Value
+}
+
+func (n jmesLiteral) eval(_ *Value) (*Value, error) {
+	return n.v, nil
+}
+
+type jmesField struct {
+	name string
+}
+
+func (n jmesField) eval(cur *Value) (*Value, error) {
+	if cur.Type() != JSON_OBJECT {
+		return NewNull(), nil
+	}
+	m, err := cur.Map()
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := m[n.name]; ok {
+		return v, nil
+	}
+	return NewNull(), nil
+}
+
+// 在 left 的结果上求 right, [0] 和切片也表示为 left 与下标节点的组合
+type jmesSubexpr struct {
+	left, right jmesNode
+}
+
+func (n jmesSubexpr) eval(cur *Value) (*Value, error) {
+	l, err := n.left.eval(cur)
+	if err != nil {
+		return nil, err
+	}
+	return n.right.eval(l)
+}
+
+type jmesIndex struct {
+	index int
+}
+
+func (n jmesIndex) eval(cur *Value) (*Value, error) {
+	if cur.Type() != JSON_ARRAY {
+		return NewNull(), nil
+	}
+	arr, err := cur.Array()
+	if err != nil {
+		return nil, err
+	}
+	i := n.index
+	if i < 0 {
+		i += len(arr)
+	}
+	if i < 0 || i >= len(arr) {
+		return NewNull(), nil
+	}
+	return arr[i], nil
+}
+
+type jmesSlice struct {
+	start, end *int
+	step       int
+}
+
+func (n jmesSlice) eval(cur *Value) (*Value, error) {
+	if cur.Type() != JSON_ARRAY {
+		return NewNull(), nil
+	}
+	arr, err := cur.Array()
+	if err != nil {
+		return nil, err
+	}
+	size := len(arr)
+	bound := func(p *int, def int) int {
+		if p == nil {
+			return def
+		}
+		i := *p
+		if i < 0 {
+			i += size
+		}
+		if n.step > 0 {
+			return clampInt(i, 0, size)
+		}
+		return clampInt(i, -1, size-1)
+	}
+	out := make([]*Value, 0)
+	if n.step > 0 {
+		for i := bound(n.start, 0); i < bound(n.end, size); i += n.step {
+			out = append(out, arr[i])
+		}
+	} else {
+		for i := bound(n.start, size-1); i > bound(n.end, -1); i += n.step {
+			out = append(out, arr[i])
+		}
+	}
+	return NewArray(out...), nil
+}
+
+// 列表投影: left 为数组时在每个元素上求 right, 丢弃 null 结果
+type jmesProjection struct {
+	left, right jmesNode
+}
+
+func (n jmesProjection) eval(cur *Value) (*Value, error) {
+	l, err := n.left.eval(cur)
+	if err != nil {
+		return nil, err
+	}
+	if l.Type() != JSON_ARRAY {
+		return NewNull(), nil
+	}
+	arr, err := l.Array()
+	if err != nil {
+		return nil, err
+	}
+	return jmesProject(arr, nil, n.right)
+}
+
+// 对象值投影, 按键的顺序在每个成员值上求 right
+type jmesValueProjection struct {
+	left, right jmesNode
+}
+
+func (n jmesValueProjection) eval(cur *Value) (*Value, error) {
+	l, err := n.left.eval(cur)
+	if err != nil {
+		return nil, err
+	}
+	if l.Type() != JSON_OBJECT {
+		return NewNull(), nil
+	}
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	values := make([]*Value, 0, len(l.keys))
+	for _, k := range l.keys {
+		values = append(values, l.obj[k])
+	}
+	return jmesProject(values, nil, n.right)
+}
+
+// 过滤投影, 只保留 cond 为真的元素
+type jmesFilter struct {
+	left, cond, right jmesNode
+}
+
+func (n jmesFilter) eval(cur *Value) (*Value, error) {
+	l, err := n.left.eval(cur)
+	if err != nil {
+		return nil, err
+	}
+	if l.Type() != JSON_ARRAY {
+		return NewNull(), nil
+	}
+	arr, err := l.Array()
+	if err != nil {
+		return nil, err
+	}
+	return jmesProject(arr, n.cond, n.right)
+}
+
+func jmesProject(items []*Value, cond, right jmesNode) (*Value, error) {
+	out := make([]*Value, 0, len(items))
+	for _, item := range items {
+		if cond != nil {
+			c, err := cond.eval(item)
+			if err != nil {
+				return nil, err
+			}
+			if !jmesTruthy(c) {
+				continue
+			}
+		}
+		v, err := right.eval(item)
+		if err != nil {
+			return nil, err
+		}
+		if v.Exists() && !v.IsNull() {
+			out = append(out, v)
+		}
+	}
+	return NewArray(out...), nil
+}
+
+// 展平一层数组, 非数组元素原样保留
+type jmesFlatten struct {
+	child jmesNode
+}
+
+func (n jmesFlatten) eval(cur *Value) (*Value, error) {
+	v, err := n.child.eval(cur)
+	if err != nil {
+		return nil, err
+	}
+	if v.Type() != JSON_ARRAY {
+		return NewNull(), nil
+	}
+	arr, err := v.Array()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Value, 0, len(arr))
+	for _, item := range arr {
+		if item.Type() != JSON_ARRAY {
+			out = append(out, item)
+			continue
+		}
+		inner, err := item.Array()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, inner...)
+	}
+	return NewArray(out...), nil
+}
+
+type jmesCompare struct {
+	op          string
+	left, right jmesNode
+}
+
+// == 和 != 比较任意值, 大小比较只对两个数字有效, 否则结果为 null
+func (n jmesCompare) eval(cur *Value) (*Value, error) {
+	l, err := n.left.eval(cur)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.right.eval(cur)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return NewBool(equalValues(l, r)), nil
+	case "!=":
+		return NewBool(!equalValues(l, r)), nil
+	}
+	if l.Type() != JSON_NUMBER || r.Type() != JSON_NUMBER {
+		return NewNull(), nil
+	}
+	a, err := l.Float64()
+	if err != nil {
+		return nil, err
+	}
+	b, err := r.Float64()
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "<":
+		return NewBool(a < b), nil
+	case "<=":
+		return NewBool(a <= b), nil
+	case ">":
+		return NewBool(a > b), nil
+	}
+	return NewBool(a >= b), nil
+}
+
+type jmesOr struct {
+	left, right jmesNode
+}
+
+func (n jmesOr) eval(cur *Value) (*Value, error) {
+	l, err := n.left.eval(cur)
+	if err != nil || jmesTruthy(l) {
+		return l, err
+	}
+	return n.right.eval(cur)
+}
+
+type jmesAnd struct {
+	left, right jmesNode
+}
+
+func (n jmesAnd) eval(cur *Value) (*Value, error) {
+	l, err := n.left.eval(cur)
+	if err != nil || !jmesTruthy(l) {
+		return l, err
+	}
+	return n.right.eval(cur)
+}
+
+type jmesNot struct {
+	child jmesNode
+}
+
+func (n jmesNot) eval(cur *Value) (*Value, error) {
+	v, err := n.child.eval(cur)
+	if err != nil {
+		return nil, err
+	}
+	return NewBool(!jmesTruthy(v)), nil
+}
+
+// 管道: 在 left 的结果上求 right, 同时结束 left 中的投影
+type jmesPipe struct {
+	left, right jmesNode
+}
+
+func (n jmesPipe) eval(cur *Value) (*Value, error) {
+	l, err := n.left.eval(cur)
+	if err != nil {
+		return nil, err
+	}
+	return n.right.eval(l)
+}
+
+type jmesMultiList struct {
+	items []jmesNode
+}
+
+func (n jmesMultiList) eval(cur *Value) (*Value, error) {
+	if !cur.Exists() || cur.IsNull() {
+		return NewNull(), nil
+	}
+	out := make([]*Value, 0, len(n.items))
+	for _, item := range n.items {
+		v, err := item.eval(cur)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return NewArray(out...), nil
+}
+
+type jmesMultiHash struct {
+	keys  []string
+	items []jmesNode
+}
+
+func (n jmesMultiHash) eval(cur *Value) (*Value, error) {
+	if !cur.Exists() || cur.IsNull() {
+		return NewNull(), nil
+	}
+	obj := NewObject()
+	for i, item := range n.items {
+		v, err := item.eval(cur)
+		if err != nil {
+			return nil, err
+		}
+		obj.setMember(n.keys[i], v)
+	}
+	return obj, nil
+}
+
+// &expr, 只能作为函数参数, 由函数决定在哪些值上求值
+type jmesExpref struct {
+	expr jmesNode
+}
+
+func (jmesExpref) eval(_ *Value) (*Value, error) {
+	return nil, fmt.Errorf("expression reference used outside of a function argument")
+}
+
+type jmesCall struct {
+	name string
+	fn   *jmesFunction
+	args []jmesNode
+}
+
+func (n jmesCall) eval(cur *Value) (*Value, error) {
+	args := make([]jmesArg, len(n.args))
+	for i, a := range n.args {
+		if ref, ok := a.(jmesExpref); ok {
+			args[i].expr = ref.expr
+			continue
+		}
+		v, err := a.eval(cur)
+		if err != nil {
+			return nil, err
+		}
+		args[i].v = v
+	}
+	for i := range args {
+		t := n.fn.args[len(n.fn.args)-1]
+		if i < len(n.fn.args) {
+			t = n.fn.args[i]
+		}
+		if !t.accept(args[i]) {
+			got := "expref"
+			if args[i].expr == nil {
+				got = args[i].v.Type().String()
+			}
+			return nil, fmt.Errorf("%s(): invalid type %s for argument %d, expect %s", n.name, got, i+1, t)
+		}
+	}
+	return n.fn.call(args)
+}
+
+type jmesTokenKind int
+
+const (
+	jmesEOF jmesTokenKind = iota
+	jmesIdent
+	jmesQuoted
+	jmesNumber
+	jmesLiteralToken
+	jmesDot
+	jmesStar
+	jmesLbracket
+	jmesRbracket
+	jmesLbrace
+	jmesRbrace
+	jmesFlattenToken
+	jmesFilterToken
+	jmesPipeToken
+	jmesOrToken
+	jmesAndToken
+	jmesNotToken
+	jmesCompareToken
+	jmesComma
+	jmesColon
+	jmesLparen
+	jmesRparen
+	jmesAt
+	jmesAmp
+)
+
+// Pratt 解析的左结合力, 与 JMESPath 参考实现一致
+var jmesBindingPower = map[jmesTokenKind]int{
+	jmesPipeToken:    1,
+	jmesOrToken:      2,
+	jmesAndToken:     3,
+	jmesCompareToken: 5,
+	jmesFlattenToken: 9,
+	jmesStar:         20,
+	jmesFilterToken:  21,
+	jmesDot:          40,
+	jmesNotToken:     45,
+	jmesLbrace:       50,
+	jmesLbracket:     55,
+	jmesLparen:       60,
+}
+
+type jmesToken struct {
+	kind  jmesTokenKind
+	text  string // 标识符, 比较运算符, 字面量的源文本
+	num   int
+	value *Value // jmesLiteralToken 的值
+	pos   int
+	end   int
+}
+
+type jmesParser struct {
+	expr   string
+	tokens []jmesToken
+	i      int
+}
+
+func (p *jmesParser) errorf(pos int, format string, args ...interface{}) error {
+	return fmt.Errorf("invalid jmespath %q: %s at offset %d", p.expr, fmt.Sprintf(format, args...), pos)
+}
+
+func (p *jmesParser) current() jmesToken {
+	return p.tokens[p.i]
+}
+
+func (p *jmesParser) next() jmesToken {
+	tok := p.tokens[p.i]
+	if tok.kind != jmesEOF {
+		p.i++
+	}
+	return tok
+}
+
+func (p *jmesParser) unexpected(tok jmesToken) error {
+	if tok.kind == jmesEOF {
+		return p.errorf(tok.pos, "unexpected end of expression")
+	}
+	return p.errorf(tok.pos, "unexpected %q", p.expr[tok.pos:tok.end])
+}
+
+func (p *jmesParser) expect(kind jmesTokenKind) error {
+	if p.current().kind != kind {
+		return p.unexpected(p.current())
+	}
+	p.next()
+	return nil
+}
+
+func parseJMESPath(expr string) (jmesNode, error) {
+	p := &jmesParser{expr: expr}
+	if err := p.lex(); err != nil {
+		return nil, err
+	}
+	node, err := p.expression(0)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.current(); tok.kind != jmesEOF {
+		return nil, p.unexpected(tok)
+	}
+	return node, nil
+}
+
+func isJMESIdentChar(c byte, first bool) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || !first && c >= '0' && c <= '9'
+}
+
+func (p *jmesParser) lex() error {
+	s := p.expr
+	for i := 0; i < len(s); {
+		c := s[i]
+		start := i
+		tok := jmesToken{pos: start}
+		switch {
+		case c == BLANK_SPACE || c == HORIZONTAL_TAB || c == LINE_BREAK || c == CARRIAGE_RETURN:
+			i++
+			continue
+		case isJMESIdentChar(c, true):
+			for i < len(s) && isJMESIdentChar(s[i], false) {
+				i++
+			}
+			tok.kind, tok.text = jmesIdent, s[start:i]
+		case c == '-' || c >= '0' && c <= '9':
+			i++
+			for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+				i++
+			}
+			n, err := strconv.Atoi(s[start:i])
+			if err != nil {
+				return p.errorf(start, "invalid number %q", s[start:i])
+			}
+			tok.kind, tok.num = jmesNumber, n
+		case c == '"':
+			end, err := p.quotedEnd(start, '"')
+			if err != nil {
+				return err
+			}
+			v, err := ParseWithOptions([]byte(s[start:end]), ParseOptions{Strict: true})
+			if err != nil {
+				return p.errorf(start, "invalid quoted identifier: %v", err)
+			}
+			tok.kind, tok.text, i = jmesQuoted, v.str, end
+		case c == '\'':
+			end, err := p.quotedEnd(start, '\'')
+			if err != nil {
+				return err
+			}
+			raw := strings.ReplaceAll(s[start+1:end-1], `\'`, `'`)
+			tok.kind, tok.value, i = jmesLiteralToken, NewString(raw), end
+		case c == '`':
+			end, err := p.quotedEnd(start, '`')
+			if err != nil {
+				return err
+			}
+			text := strings.ReplaceAll(s[start+1:end-1], "\\`", "`")
+			v, err := ParseWithOptions([]byte(text), ParseOptions{Strict: true})
+			if err != nil {
+				return p.errorf(start, "invalid literal: %v", err)
+			}
+			tok.kind, tok.value, i = jmesLiteralToken, v, end
+		case c == '[':
+			i++
+			switch {
+			case i < len(s) && s[i] == ']':
+				tok.kind, i = jmesFlattenToken, i+1
+			case i < len(s) && s[i] == '?':
+				tok.kind, i = jmesFilterToken, i+1
+			default:
+				tok.kind = jmesLbracket
+			}
+		case c == '|' || c == '&':
+			i++
+			if i < len(s) && s[i] == c {
+				tok.kind, i = jmesOrToken, i+1
+				if c == '&' {
+					tok.kind = jmesAndToken
+				}
+			} else {
+				tok.kind = jmesPipeToken
+				if c == '&' {
+					tok.kind = jmesAmp
+				}
+			}
+		case c == '<' || c == '>' || c == '=' || c == '!':
+			i++
+			if i < len(s) && s[i] == '=' {
+				i++
+			} else if c == '=' {
+				return p.errorf(start, "unexpected %q, expect ==", c)
+			}
+			tok.kind, tok.text = jmesCompareToken, s[start:i]
+			if tok.text == "!" {
+				tok.kind = jmesNotToken
+			}
+		default:
+			kind, ok := jmesPunct[c]
+			if !ok {
+				return p.errorf(start, "unexpected %q", c)
+			}
+			tok.kind, i = kind, i+1
+		}
+		tok.end = i
+		p.tokens = append(p.tokens, tok)
+	}
+	p.tokens = append(p.tokens, jmesToken{kind: jmesEOF, pos: len(s), end: len(s)})
+	return nil
+}
+
+var jmesPunct = map[byte]jmesTokenKind{
+	'.': jmesDot,
+	'*': jmesStar,
+	']': jmesRbracket,
+	'{': jmesLbrace,
+	'}': jmesRbrace,
+	',': jmesComma,
+	':': jmesColon,
+	'(': jmesLparen,
+	')': jmesRparen,
+	'@': jmesAt,
+}
+
+// 从 start 处的引号开始, 返回匹配的结束引号之后的位置, 反斜杠转义下一个字节
+func (p *jmesParser) quotedEnd(start int, quote byte) (int, error) {
+	for i := start + 1; i < len(p.expr); i++ {
+		switch p.expr[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1, nil
+		}
+	}
+	return 0, p.errorf(start, "unterminated %c", quote)
+}
+
+func (p *jmesParser) expression(bp int) (jmesNode, error) {
+	left, err := p.nud(p.next())
+	if err != nil {
+		return nil, err
+	}
+	for bp < jmesBindingPower[p.current().kind] {
+		if left, err = p.led(p.next(), left); err != nil {
+			return nil, err
+		}
+	}
+	return left, nil
+}
+
+// 前缀位置的 token
+func (p *jmesParser) nud(tok jmesToken) (jmesNode, error) {
+	switch tok.kind {
+	case jmesLiteralToken:
+		return jmesLiteral{tok.value}, nil
+	case jmesIdent:
+		return jmesField{tok.text}, nil
+	case jmesQuoted:
+		if p.current().kind == jmesLparen {
+			return nil, p.errorf(tok.pos, "quoted identifier can not be a function name")
+		}
+		return jmesField{tok.text}, nil
+	case jmesStar:
+		right, err := p.projectionRHS(jmesBindingPower[jmesStar])
+		if err != nil {
+			return nil, err
+		}
+		return jmesValueProjection{jmesIdentity{}, right}, nil
+	case jmesFilterToken:
+		return p.filter(jmesIdentity{})
+	case jmesLbrace:
+		return p.multiHash()
+	case jmesFlattenToken:
+		right, err := p.projectionRHS(jmesBindingPower[jmesFlattenToken])
+		if err != nil {
+			return nil, err
+		}
+		return jmesProjection{jmesFlatten{jmesIdentity{}}, right}, nil
+	case jmesLbracket:
+		switch p.current().kind {
+		case jmesNumber, jmesColon:
+			return p.indexExpression(jmesIdentity{})
+		case jmesStar:
+			if p.tokens[p.i+1].kind == jmesRbracket {
+				p.next()
+				p.next()
+				right, err := p.projectionRHS(jmesBindingPower[jmesStar])
+				if err != nil {
+					return nil, err
+				}
+				return jmesProjection{jmesIdentity{}, right}, nil
+			}
+		}
+		return p.multiList()
+	case jmesNotToken:
+		child, err := p.expression(jmesBindingPower[jmesNotToken])
+		if err != nil {
+			return nil, err
+		}
+		return jmesNot{child}, nil
+	case jmesLparen:
+		node, err := p.expression(0)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(jmesRparen); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case jmesAt:
+		return jmesIdentity{}, nil
+	case jmesAmp:
+		node, err := p.expression(0)
+		if err != nil {
+			return nil, err
+		}
+		return jmesExpref{node}, nil
+	}
+	return nil, p.unexpected(tok)
+}
+
+// 中缀位置的 token, left 为已经解析的左侧表达式
+func (p *jmesParser) led(tok jmesToken, left jmesNode) (jmesNode, error) {
+	switch tok.kind {
+	case jmesDot:
+		if p.current().kind == jmesStar {
+			p.next()
+			right, err := p.projectionRHS(jmesBindingPower[jmesDot])
+			if err != nil {
+				return nil, err
+			}
+			return jmesValueProjection{left, right}, nil
+		}
+		right, err := p.dotRHS(jmesBindingPower[jmesDot])
+		if err != nil {
+			return nil, err
+		}
+		return jmesSubexpr{left, right}, nil
+	case jmesPipeToken, jmesOrToken, jmesAndToken, jmesCompareToken:
+		right, err := p.expression(jmesBindingPower[tok.kind])
+		if err != nil {
+			return nil, err
+		}
+		switch tok.kind {
+		case jmesPipeToken:
+			return jmesPipe{left, right}, nil
+		case jmesOrToken:
+			return jmesOr{left, right}, nil
+		case jmesAndToken:
+			return jmesAnd{left, right}, nil
+		}
+		return jmesCompare{tok.text, left, right}, nil
+	case jmesLparen:
+		return p.call(tok, left)
+	case jmesFilterToken:
+		return p.filter(left)
+	case jmesFlattenToken:
+		right, err := p.projectionRHS(jmesBindingPower[jmesFlattenToken])
+		if err != nil {
+			return nil, err
+		}
+		return jmesProjection{jmesFlatten{left}, right}, nil
+	case jmesLbracket:
+		if k := p.current().kind; k == jmesNumber || k == jmesColon {
+			return p.indexExpression(left)
+		}
+		if err := p.expect(jmesStar); err != nil {
+			return nil, err
+		}
+		if err := p.expect(jmesRbracket); err != nil {
+			return nil, err
+		}
+		right, err := p.projectionRHS(jmesBindingPower[jmesStar])
+		if err != nil {
+			return nil, err
+		}
+		return jmesProjection{left, right}, nil
+	}
+	return nil, p.unexpected(tok)
+}
+
+// 投影右侧: 结合力低于 10 的 token 结束投影
+func (p *jmesParser) projectionRHS(bp int) (jmesNode, error) {
+	switch tok := p.current(); {
+	case jmesBindingPower[tok.kind] < 10:
+		return jmesIdentity{}, nil
+	case tok.kind == jmesLbracket || tok.kind == jmesFilterToken:
+		return p.expression(bp)
+	case tok.kind == jmesDot:
+		p.next()
+		return p.dotRHS(bp)
+	default:
+		return nil, p.unexpected(tok)
+	}
+}
+
+func (p *jmesParser) dotRHS(bp int) (jmesNode, error) {
+	switch tok := p.current(); tok.kind {
+	case jmesIdent, jmesQuoted, jmesStar:
+		return p.expression(bp)
+	case jmesLbracket:
+		p.next()
+		return p.multiList()
+	case jmesLbrace:
+		p.next()
+		return p.multiHash()
+	default:
+		return nil, p.unexpected(tok)
+	}
+}
+
+func (p *jmesParser) filter(left jmesNode) (jmesNode, error) {
+	cond, err := p.expression(0)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(jmesRbracket); err != nil {
+		return nil, err
+	}
+	var right jmesNode = jmesIdentity{}
+	if p.current().kind != jmesFlattenToken {
+		if right, err = p.projectionRHS(jmesBindingPower[jmesFilterToken]); err != nil {
+			return nil, err
+		}
+	}
+	return jmesFilter{left, cond, right}, nil
+}
+
+// [ 之后的下标或切片, 切片会开始一个投影
+func (p *jmesParser) indexExpression(left jmesNode) (jmesNode, error) {
+	var parts [3]*int
+	colons := 0
+	for {
+		tok := p.next()
+		switch tok.kind {
+		case jmesNumber:
+			if parts[colons] != nil {
+				return nil, p.unexpected(tok)
+			}
+			n := tok.num
+			parts[colons] = &n
+			continue
+		case jmesColon:
+			if colons == 2 {
+				return nil, p.unexpected(tok)
+			}
+			colons++
+			continue
+		case jmesRbracket:
+		default:
+			return nil, p.unexpected(tok)
+		}
+		break
+	}
+	if colons == 0 {
+		return jmesSubexpr{left, jmesIndex{*parts[0]}}, nil
+	}
+	slice := jmesSlice{start: parts[0], end: parts[1], step: 1}
+	if parts[2] != nil {
+		if *parts[2] == 0 {
+			return nil, p.errorf(p.tokens[p.i-1].pos, "slice step can not be 0")
+		}
+		slice.step = *parts[2]
+	}
+	right, err := p.projectionRHS(jmesBindingPower[jmesStar])
+	if err != nil {
+		return nil, err
+	}
+	return jmesProjection{jmesSubexpr{left, slice}, right}, nil
+}
+
+func (p *jmesParser) multiList() (jmesNode, error) {
+	var items []jmesNode
+	for {
+		item, err := p.expression(0)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if p.current().kind != jmesComma {
+			break
+		}
+		p.next()
+	}
+	if err := p.expect(jmesRbracket); err != nil {
+		return nil, err
+	}
+	return jmesMultiList{items}, nil
+}
+
+func (p *jmesParser) multiHash() (jmesNode, error) {
+	var n jmesMultiHash
+	for {
+		tok := p.next()
+		if tok.kind != jmesIdent && tok.kind != jmesQuoted {
+			return nil, p.unexpected(tok)
+		}
+		if err := p.expect(jmesColon); err != nil {
+			return nil, err
+		}
+		item, err := p.expression(0)
+		if err != nil {
+			return nil, err
+		}
+		n.keys = append(n.keys, tok.text)
+		n.items = append(n.items, item)
+		if p.current().kind != jmesComma {
+			break
+		}
+		p.next()
+	}
+	if err := p.expect(jmesRbrace); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// 函数调用, left 必须是一个标识符, 参数个数在解析时检查
+func (p *jmesParser) call(paren jmesToken, left jmesNode) (jmesNode, error) {
+	name, ok := left.(jmesField)
+	if !ok {
+		return nil, p.errorf(paren.pos, "function name must be an identifier")
+	}
+	fn, ok := jmesFunctions[name.name]
+	if !ok {
+		return nil, p.errorf(paren.pos, "unknown function %s()", name.name)
+	}
+	var args []jmesNode
+	for p.current().kind != jmesRparen {
+		if len(args) > 0 {
+			if err := p.expect(jmesComma); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.expression(0)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	p.next()
+	if len(args) < len(fn.args) || !fn.variadic && len(args) > len(fn.args) {
+		want := strconv.Itoa(len(fn.args))
+		if fn.variadic {
+			want = "at least " + want
+		}
+		return nil, p.errorf(paren.pos, "wrong number of arguments to %s(): expect %s, got %d", name.name, want, len(args))
+	}
+	return jmesCall{name.name, fn, args}, nil
+}
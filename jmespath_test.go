@@ -0,0 +1,156 @@
+package yjson
+
+import (
+	"strings"
+	"testing"
+)
+
+type jmesTest struct {
+	input string
+	expr  string
+	want  string // 编码后的结果, 以 "error: " 开头时为错误信息中应包含的内容
+}
+
+func runJMESTests(t *testing.T, tests []jmesTest) {
+	t.Helper()
+	for _, tt := range tests {
+		res, err := mustParse(t, tt.input).Search(tt.expr)
+		if msg, ok := strings.CutPrefix(tt.want, "error: "); ok {
+			if err == nil || !strings.Contains(err.Error(), msg) {
+				t.Errorf("%s: got error %v, want %q", tt.expr, err, msg)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		if got := mustEncode(t, res); got != tt.want {
+			t.Errorf("%s on %s:\ngot  %s\nwant %s", tt.expr, tt.input, got, tt.want)
+		}
+	}
+}
+
+// 示例来自 JMESPath 规范和教程
+func TestSearchBasics(t *testing.T) {
+	runJMESTests(t, []jmesTest{
+		{`{"a": "foo", "b": "bar"}`, `a`, `"foo"`},
+		{`{"a": "foo"}`, `b`, `null`},
+		{`{"a": {"b": {"c": {"d": "value"}}}}`, `a.b.c.d`, `"value"`},
+		{`{"foo bar": 1, "a-b": 2}`, `"foo bar"`, `1`},
+		{`{"a-b": 2}`, `"a-b"`, `2`},
+		{`["a", "b", "c", "d", "e", "f"]`, `[1]`, `"b"`},
+		{`["a", "b", "c"]`, `[-1]`, `"c"`},
+		{`["a", "b", "c"]`, `[5]`, `null`},
+		{`{"a": {"b": {"c": [{"d": [0, [1, 2]]}, {"d": [3, 4]}]}}}`, `a.b.c[0].d[1][0]`, `1`},
+		{`[0, 1, 2, 3, 4, 5, 6, 7, 8, 9]`, `[0:5]`, `[0,1,2,3,4]`},
+		{`[0, 1, 2, 3, 4, 5, 6, 7, 8, 9]`, `[5:10]`, `[5,6,7,8,9]`},
+		{`[0, 1, 2, 3, 4, 5, 6, 7, 8, 9]`, `[::2]`, `[0,2,4,6,8]`},
+		{`[0, 1, 2, 3, 4, 5, 6, 7, 8, 9]`, `[::-1]`, `[9,8,7,6,5,4,3,2,1,0]`},
+		{`[0, 1, 2, 3]`, `[-2:]`, `[2,3]`},
+		{`{"a": 1}`, `[0:1]`, `null`},
+		{`{"a": 1}`, `@`, `{"a":1}`},
+		{`{"a": 1}`, "`[1, {\"b\": true}]`", `[1,{"b":true}]`},
+		{`{"a": 1}`, `'raw string'`, `"raw string"`},
+		{`{"a": 1}`, `'it\'s'`, `"it's"`},
+	})
+}
+
+func TestSearchProjections(t *testing.T) {
+	people := `{"people": [{"first": "James", "last": "d", "age": 30}, {"first": "Jacob", "last": "e", "age": 20}, {"first": "Jayden", "last": "f"}, {"missing": "different"}], "foo": {"bar": "baz"}}`
+	runJMESTests(t, []jmesTest{
+		{people, `people[*].first`, `["James","Jacob","Jayden"]`},
+		{people, `people[:2].first`, `["James","Jacob"]`},
+		{people, `people[*].age`, `[30,20]`},
+		{people, `people[?age > ` + "`20`" + `].first`, `["James"]`},
+		{people, `people[?age].first | [0]`, `"James"`},
+		{people, `people[*].first | [0]`, `"James"`},
+		{people, `people[*].[first, age]`, `[["James",30],["Jacob",20],["Jayden",null],[null,null]]`},
+		{people, `people[?first == 'Jacob'].{name: first, years: age}`, `[{"name":"Jacob","years":20}]`},
+		{`{"ops": {"a": {"n": [1, 2]}, "b": {"n": [3]}, "c": {"x": 1}}}`, `ops.*.n`, `[[1,2],[3]]`},
+		{`{"ops": {"a": {"n": [1, 2]}, "b": {"n": [3]}}}`, `ops.*.n[]`, `[1,2,3]`},
+		{`{"reservations": [{"instances": [{"state": "running"}, {"state": "stopped"}]}, {"instances": [{"state": "terminated"}]}]}`, `reservations[*].instances[*].state`, `[["running","stopped"],["terminated"]]`},
+		{`{"reservations": [{"instances": [{"state": "running"}, {"state": "stopped"}]}, {"instances": [{"state": "terminated"}]}]}`, `reservations[].instances[].state`, `["running","stopped","terminated"]`},
+		{`[[0, 1], 2, [3], 4, [5, [6, 7]]]`, `[]`, `[0,1,2,3,4,5,[6,7]]`},
+		{`[[0, 1], 2, [3], 4, [5, [6, 7]]]`, `[][]`, `[0,1,2,3,4,5,6,7]`},
+		{`{"a": "x"}`, `a[*]`, `null`},
+		{`{"a": [1, 2]}`, `*`, `[[1,2]]`},
+		{`{"machines": [{"name": "a", "state": "running"}, {"name": "b", "state": "stopped"}, {"name": "c", "state": "running"}]}`, `machines[?state=='running'].name`, `["a","c"]`},
+		{`{"a": [1, 2, 3]}`, `a[?@ > ` + "`1`" + `]`, `[2,3]`},
+		{`{"a": [{"b": 1, "c": 2}, {"b": 2, "c": 2}]}`, `a[?b == c]`, `[{"b":2,"c":2}]`},
+		{`{"a": [{"b": 1}, {"b": "x"}, {"c": 1}]}`, `a[?b < ` + "`2`" + `]`, `[{"b":1}]`},
+		{`{"a": [{"b": true, "c": false}, {"b": true, "c": true}, {"b": false}]}`, `a[?b && c]`, `[{"b":true,"c":true}]`},
+		{`{"a": [{"b": true, "c": false}, {"b": true, "c": true}, {"b": false}]}`, `a[?!b || c]`, `[{"b":true,"c":true},{"b":false}]`},
+		{`{"a": [{"b": 1, "c": 0}, {"b": 2}, {"c": 3}]}`, `a[?(b == ` + "`1`" + ` || c == ` + "`3`" + `)]`, `[{"b":1,"c":0},{"c":3}]`},
+	})
+}
+
+func TestSearchMultiSelectAndPipes(t *testing.T) {
+	runJMESTests(t, []jmesTest{
+		{`{"a": 1, "b": 2, "c": 3}`, `[a, c]`, `[1,3]`},
+		{`{"a": 1, "b": 2, "c": 3}`, `{x: a, y: b}`, `{"x":1,"y":2}`},
+		{`{"a": {"b": 1}}`, `a.[b, c]`, `[1,null]`},
+		{`{"a": null}`, `a.[b]`, `null`},
+		{`{"a": {"b": [1, 2]}}`, `a | b | [0]`, `1`},
+		{`{"a": [{"b": 1}, {"b": 2}]}`, `a[*].b | [1]`, `2`},
+		{`{"a": 0, "b": "", "c": [], "d": {}, "e": false, "f": "x"}`, `a || f`, `0`},
+		{`{"a": 0, "b": "", "c": [], "d": {}, "e": false, "f": "x"}`, `b || c || d || e || f`, `"x"`},
+		{`{"a": 0, "b": "", "f": "x"}`, `b && f`, `""`},
+		{`{"a": 0, "b": "", "f": "x"}`, `a && f`, `"x"`},
+		{`{"a": 0, "b": ""}`, `!b`, `true`},
+		{`{"a": 1}`, `a == ` + "`1.0`", `true`},
+		{`{"a": {"x": [1]}, "b": {"x": [1]}}`, `a == b`, `true`},
+		{`{"a": "x"}`, `a < 'y'`, `null`},
+	})
+}
+
+func TestSearchFunctions(t *testing.T) {
+	people := `{"people": [{"name": "b", "age": 30}, {"name": "a", "age": 50}, {"name": "c", "age": 40}]}`
+	runJMESTests(t, []jmesTest{
+		{`{"a": -1.5}`, `abs(a)`, `1.5`},
+		{`[1, 2, 3, 4]`, `avg(@)`, `2.5`},
+		{`[]`, `avg(@)`, `null`},
+		{`[1.5, -1.5]`, `[ceil([0]), floor([1])]`, `[2,-2]`},
+		{`{"a": "foobar", "b": ["x", "y"]}`, `[contains(a, 'oba'), contains(b, 'y'), contains(b, 'z')]`, `[true,true,false]`},
+		{`{"a": "foobar"}`, `[starts_with(a, 'foo'), ends_with(a, 'bar'), ends_with(a, 'foo')]`, `[true,true,false]`},
+		{`["a", "b"]`, `join(', ', @)`, `"a, b"`},
+		{`{"b": 1, "a": 2}`, `[keys(@), values(@)]`, `[["b","a"],[1,2]]`},
+		{`{"s": "héllo", "a": [1, 2], "o": {"k": 1}}`, `[length(s), length(a), length(o)]`, `[5,2,1]`},
+		{`[{"a": 1}, {"a": 2}]`, `map(&a, @)`, `[1,2]`},
+		{`[]`, "merge(`{\"a\": 1, \"b\": 2}`, `{\"b\": 3}`)", `{"a":1,"b":3}`},
+		{`[3, 1, 2]`, `[max(@), min(@), sum(@)]`, `[3,1,6]`},
+		{`["b", "a"]`, `[max(@), min(@)]`, `["b","a"]`},
+		{`[]`, `max(@)`, `null`},
+		{people, `max_by(people, &age).name`, `"a"`},
+		{people, `min_by(people, &age).name`, `"b"`},
+		{people, `sort_by(people, &age)[*].name`, `["b","c","a"]`},
+		{people, `sort_by(people, &name)[*].age`, `[50,30,40]`},
+		{people, `people[*].name | sort(@)`, `["a","b","c"]`},
+		{people, `reverse(people[*].name)`, `["c","a","b"]`},
+		{`{"a": null, "b": 2}`, `not_null(a, c, b)`, `2`},
+		{`{"s": "abc"}`, `reverse(s)`, `"cba"`},
+		{`{"a": 1}`, `[to_array(a), to_array([a])]`, `[[1],[1]]`},
+		{`{"a": "12.5", "b": "x", "c": 3}`, `[to_number(a), to_number(b), to_number(c)]`, `[12.5,null,3]`},
+		{`{"a": [1, "x"], "s": "y"}`, `[to_string(a), to_string(s)]`, `["[1,\"x\"]","y"]`},
+		{`{"n": 1, "s": "", "b": true, "a": [], "o": {}, "z": null}`, `[type(n), type(s), type(b), type(a), type(o), type(z)]`, `["number","string","boolean","array","object","null"]`},
+		// 参数类型和个数错误
+		{`{"a": "x"}`, `abs(a)`, "error: "},
+		{`{}`, `length()`, "error: "},
+		{`{}`, `unknown(@)`, "error: "},
+		{`[1, "a"]`, `sort(@)`, "error: "},
+		{`[{"a": 1}, {"a": "x"}]`, `sort_by(@, &a)`, "error: "},
+	})
+}
+
+func TestSearchErrors(t *testing.T) {
+	for _, expr := range []string{``, `a.`, `[`, `a[`, `a[?]`, `{a}`, `{a: b`, `foo bar`, "`[1`", `'open`, `a | `, `&`} {
+		if _, err := mustParse(t, `{}`).Search(expr); err == nil {
+			t.Errorf("%q: expected an error", expr)
+		}
+	}
+	// 求值错误带上表达式
+	_, err := mustParse(t, `{"a": "x"}`).Search(`abs(a)`)
+	if err == nil || !strings.HasPrefix(err.Error(), `jmespath "abs(a)": `) {
+		t.Errorf("got %v", err)
+	}
+}
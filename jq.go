@@ -0,0 +1,1406 @@
+package yjson
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// 编译后的 jq 程序, 可以在多个 goroutine 中重复执行. 支持 jq 的一个子集:
+//
+//	.  ..  .foo  ."foo"  .[0]  .[-1]  .["foo"]  .[1:3]  .[]  .foo?
+//	a | b  a, b  a // b   管道, 多个输出, 替代值
+//	[...]  {id, name, "k": v, (.k): v}   构造数组和对象
+//	== != < <= > >=  + - * / %  and or   与 jq 相同的比较顺序和运算
+//	if c then a elif d then b else e end  "x=\(.x)" 字符串插值
+//	select map length keys has sort_by ... 常用内置函数
+//
+// 不支持变量, reduce, def 和赋值运算
+type JQ struct {
+	expr string
+	root jqNode
+}
+
+func CompileJQ(expr string) (*JQ, error) {
+	p := &jqParser{expr: expr}
+	root, err := p.pipe(false)
+	if err != nil {
+		return nil, err
+	}
+	p.space()
+	if p.i < len(p.expr) {
+		return nil, p.unexpected()
+	}
+	return &JQ{expr: expr, root: root}, nil
+}
+
+// 在 v 上执行, 按顺序返回所有输出, 输出可能与 v 共享节点
+func (q *JQ) Run(v *Value) ([]*Value, error) {
+	out, err := q.root.eval(v)
+	if err != nil {
+		return nil, fmt.Errorf("jq %q: %v", q.expr, err)
+	}
+	return out, nil
+}
+
+// 在 NDJSON 的每一行文档上执行, 输出依次交给 fn. 解析或执行失败时返回 *LineError,
+// fn 返回错误时停止并原样返回该错误
+func (q *JQ) RunLines(r io.Reader, fn func(v *Value) error) error {
+	return q.RunLinesWithOptions(r, ParseOptions{}, fn)
+}
+
+func (q *JQ) RunLinesWithOptions(r io.Reader, opts ParseOptions, fn func(v *Value) error) error {
+	dec := NewLineDecoder(r)
+	dec.SetOptions(opts)
+	for {
+		var doc Value
+		if err := dec.Decode(&doc); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		out, err := q.root.eval(&doc)
+		if err != nil {
+			return &LineError{Line: dec.Line(), Err: fmt.Errorf("jq %q: %v", q.expr, err)}
+		}
+		for _, v := range out {
+			if err := fn(v); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// 编译并执行 jq 程序, 见 JQ
+func (j *Value) JQ(expr string) ([]*Value, error) {
+	q, err := CompileJQ(expr)
+	if err != nil {
+		return nil, err
+	}
+	return q.Run(j)
+}
+
+// 每个表达式把一个输入变成零个或多个输出
+type jqNode interface {
+	eval(in *Value) ([]*Value, error)
+}
+
+// jq 的真值: 只有 false 和 null 为假
+func jqTruthy(v *Value) bool {
+	switch v.Type() {
+	case JSON_MISSING, JSON_NULL:
+		return false
+	case JSON_BOOLEAN:
+		return v.b
+	}
+	return true
+}
+
+// jq 的全序: null < false < true < 数字 < 字符串 < 数组 < 对象.
+// 对象先比较排序后的键, 再按键依次比较值
+func jqCompare(a, b *Value) int {
+	ra, rb := jqRank(a), jqRank(b)
+	if ra != rb {
+		return ra - rb
+	}
+	switch a.Type() {
+	case JSON_NUMBER:
+		x, _ := a.Float64()
+		y, _ := b.Float64()
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	case JSON_STRING:
+		return strings.Compare(a.str, b.str)
+	case JSON_ARRAY:
+		x, _ := a.Array()
+		y, _ := b.Array()
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if c := jqCompare(x[i], y[i]); c != 0 {
+				return c
+			}
+		}
+		return len(x) - len(y)
+	case JSON_OBJECT:
+		ka, kb := a.Keys(), b.Keys()
+		sort.Strings(ka)
+		sort.Strings(kb)
+		for i := 0; i < len(ka) && i < len(kb); i++ {
+			if c := strings.Compare(ka[i], kb[i]); c != 0 {
+				return c
+			}
+		}
+		if len(ka) != len(kb) {
+			return len(ka) - len(kb)
+		}
+		for _, k := range ka {
+			if c := jqCompare(a.obj[k], b.obj[k]); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+func jqRank(v *Value) int {
+	switch v.Type() {
+	case JSON_BOOLEAN:
+		if v.b {
+			return 2
+		}
+		return 1
+	case JSON_NUMBER:
+		return 3
+	case JSON_STRING:
+		return 4
+	case JSON_ARRAY:
+		return 5
+	case JSON_OBJECT:
+		return 6
+	}
+	return 0
+}
+
+// 错误信息中的值, 过长时截断
+func jqDescribe(v *Value) string {
+	b, err := v.Encode()
+	if err != nil {
+		return v.Type().String()
+	}
+	if len(b) > 30 {
+		b = append(b[:27:27], "..."...)
+	}
+	return fmt.Sprintf("%s (%s)", v.Type(), b)
+}
+
+type jqIdentity struct{}
+
+func (jqIdentity) eval(in *Value) ([]*Value, error) {
+	if !in.Exists() {
+		return []*Value{NewNull()}, nil
+	}
+	return []*Value{in}, nil
+}
+
+// .. 按先序输出自身和所有后代
+type jqRecurse struct{}
+
+func (jqRecurse) eval(in *Value) ([]*Value, error) {
+	var out []*Value
+	var walk func(v *Value) error
+	walk = func(v *Value) error {
+		out = append(out, v)
+		switch v.Type() {
+		case JSON_ARRAY, JSON_OBJECT:
+			children, err := jqValues(v)
+			if err != nil {
+				return err
+			}
+			for _, c := range children {
+				if err := walk(c); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(in); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// 数组的元素或按键顺序的对象成员值
+func jqValues(v *Value) ([]*Value, error) {
+	if v.Type() == JSON_ARRAY {
+		return v.Array()
+	}
+	if err := v.load(); err != nil {
+		return nil, err
+	}
+	out := make([]*Value, 0, len(v.keys))
+	for _, k := range v.keys {
+		out = append(out, v.obj[k])
+	}
+	return out, nil
+}
+
+type jqLiteral struct {
+	v *Value
+}
+
+func (n jqLiteral) eval(_ *Value) ([]*Value, error) {
+	return []*Value{n.v}, nil
+}
+
+// target[key], key 在原始输入上求值, 与 jq 相同
+type jqIndex struct {
+	target, key jqNode
+}
+
+func (n jqIndex) eval(in *Value) ([]*Value, error) {
+	targets, err := n.target.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := n.key.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Value, 0, len(targets)*len(keys))
+	for _, t := range targets {
+		for _, k := range keys {
+			v, err := jqIndexValue(t, k)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+func jqIndexValue(t, k *Value) (*Value, error) {
+	switch {
+	case t.IsNull() && (k.Type() == JSON_STRING || k.Type() == JSON_NUMBER):
+		return NewNull(), nil
+	case t.Type() == JSON_OBJECT && k.Type() == JSON_STRING:
+		m, err := t.Map()
+		if err != nil {
+			return nil, err
+		}
+		if v, ok := m[k.str]; ok {
+			return v, nil
+		}
+		return NewNull(), nil
+	case t.Type() == JSON_ARRAY && k.Type() == JSON_NUMBER:
+		arr, err := t.Array()
+		if err != nil {
+			return nil, err
+		}
+		f, err := k.Float64()
+		if err != nil {
+			return nil, err
+		}
+		i := int(f)
+		if i < 0 {
+			i += len(arr)
+		}
+		if i < 0 || i >= len(arr) {
+			return NewNull(), nil
+		}
+		return arr[i], nil
+	}
+	return nil, fmt.Errorf("cannot index %s with %s", t.Type(), jqDescribe(k))
+}
+
+// target[from:to], 用于数组和字符串 (按码点), 省略的一端为 nil
+type jqSlice struct {
+	target, from, to jqNode
+}
+
+func (n jqSlice) eval(in *Value) ([]*Value, error) {
+	targets, err := n.target.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	bound := func(node jqNode) ([]*Value, error) {
+		if node == nil {
+			return []*Value{NewNull()}, nil
+		}
+		return node.eval(in)
+	}
+	froms, err := bound(n.from)
+	if err != nil {
+		return nil, err
+	}
+	tos, err := bound(n.to)
+	if err != nil {
+		return nil, err
+	}
+	var out []*Value
+	for _, t := range targets {
+		for _, to := range tos {
+			for _, from := range froms {
+				v, err := jqSliceValue(t, from, to)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, v)
+			}
+		}
+	}
+	return out, nil
+}
+
+func jqSliceValue(t, from, to *Value) (*Value, error) {
+	var size int
+	var runes []rune
+	switch t.Type() {
+	case JSON_NULL:
+		return NewNull(), nil
+	case JSON_ARRAY:
+		size = t.Len()
+	case JSON_STRING:
+		runes = []rune(t.str)
+		size = len(runes)
+	default:
+		return nil, fmt.Errorf("cannot slice %s", t.Type())
+	}
+	bound := func(b *Value, def int) (int, error) {
+		if b.IsNull() {
+			return def, nil
+		}
+		if b.Type() != JSON_NUMBER {
+			return 0, fmt.Errorf("slice bound must be a number, got %s", jqDescribe(b))
+		}
+		f, err := b.Float64()
+		if err != nil {
+			return 0, err
+		}
+		i := int(f)
+		if i < 0 {
+			i += size
+		}
+		return clampInt(i, 0, size), nil
+	}
+	start, err := bound(from, 0)
+	if err != nil {
+		return nil, err
+	}
+	end, err := bound(to, size)
+	if err != nil {
+		return nil, err
+	}
+	if end < start {
+		end = start
+	}
+	if t.Type() == JSON_STRING {
+		return NewString(string(runes[start:end])), nil
+	}
+	arr, err := t.Array()
+	if err != nil {
+		return nil, err
+	}
+	return NewArray(arr[start:end]...), nil
+}
+
+// target[], 输出数组的元素或对象的成员值
+type jqIterate struct {
+	target jqNode
+}
+
+func (n jqIterate) eval(in *Value) ([]*Value, error) {
+	targets, err := n.target.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	var out []*Value
+	for _, t := range targets {
+		if t.Type() != JSON_ARRAY && t.Type() != JSON_OBJECT {
+			return nil, fmt.Errorf("cannot iterate over %s", jqDescribe(t))
+		}
+		values, err := jqValues(t)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, values...)
+	}
+	return out, nil
+}
+
+// body?, 出错时没有输出
+type jqTry struct {
+	body jqNode
+}
+
+func (n jqTry) eval(in *Value) ([]*Value, error) {
+	out, err := n.body.eval(in)
+	if err != nil {
+		return nil, nil
+	}
+	return out, nil
+}
+
+type jqPipe struct {
+	left, right jqNode
+}
+
+func (n jqPipe) eval(in *Value) ([]*Value, error) {
+	mid, err := n.left.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	var out []*Value
+	for _, v := range mid {
+		res, err := n.right.eval(v)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, res...)
+	}
+	return out, nil
+}
+
+type jqComma struct {
+	left, right jqNode
+}
+
+func (n jqComma) eval(in *Value) ([]*Value, error) {
+	l, err := n.left.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.right.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	return append(l, r...), nil
+}
+
+// [body], 把所有输出收集为一个数组; body 为 nil 时是空数组
+type jqArray struct {
+	body jqNode
+}
+
+func (n jqArray) eval(in *Value) ([]*Value, error) {
+	if n.body == nil {
+		return []*Value{NewArray()}, nil
+	}
+	items, err := n.body.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	return []*Value{NewArray(items...)}, nil
+}
+
+type jqObjectEntry struct {
+	key, value jqNode
+}
+
+// {k: v, ...}, 键或值有多个输出时输出所有组合
+type jqObject struct {
+	entries []jqObjectEntry
+}
+
+func (n jqObject) eval(in *Value) ([]*Value, error) {
+	objs := []*Value{NewObject()}
+	for _, e := range n.entries {
+		keys, err := e.key.eval(in)
+		if err != nil {
+			return nil, err
+		}
+		values, err := e.value.eval(in)
+		if err != nil {
+			return nil, err
+		}
+		next := make([]*Value, 0, len(objs)*len(keys)*len(values))
+		for _, obj := range objs {
+			for _, k := range keys {
+				if k.Type() != JSON_STRING {
+					return nil, fmt.Errorf("object keys must be strings, got %s", jqDescribe(k))
+				}
+				for _, v := range values {
+					o := NewObject()
+					for _, key := range obj.keys {
+						o.setMember(key, obj.obj[key])
+					}
+					o.setMember(k.str, v)
+					next = append(next, o)
+				}
+			}
+		}
+		objs = next
+	}
+	return objs, nil
+}
+
+// 字符串插值, 字符串原样插入, 其他值插入其 JSON 编码
+type jqFormat struct {
+	parts []jqNode
+}
+
+func (n jqFormat) eval(in *Value) ([]*Value, error) {
+	strs := []string{""}
+	for _, part := range n.parts {
+		values, err := part.eval(in)
+		if err != nil {
+			return nil, err
+		}
+		next := make([]string, 0, len(strs)*len(values))
+		for _, s := range strs {
+			for _, v := range values {
+				text, err := jqToString(v)
+				if err != nil {
+					return nil, err
+				}
+				next = append(next, s+text)
+			}
+		}
+		strs = next
+	}
+	out := make([]*Value, len(strs))
+	for i, s := range strs {
+		out[i] = NewString(s)
+	}
+	return out, nil
+}
+
+func jqToString(v *Value) (string, error) {
+	if v.Type() == JSON_STRING {
+		return v.str, nil
+	}
+	b, err := v.Encode()
+	return string(b), err
+}
+
+// 二元运算, 两侧都有多个输出时右侧在外层循环, 与 jq 相同
+type jqBinary struct {
+	op          string
+	left, right jqNode
+}
+
+func (n jqBinary) eval(in *Value) ([]*Value, error) {
+	rs, err := n.right.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	ls, err := n.left.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Value, 0, len(ls)*len(rs))
+	for _, r := range rs {
+		for _, l := range ls {
+			v, err := jqArith(n.op, l, r)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+func jqArith(op string, l, r *Value) (*Value, error) {
+	switch op {
+	case "==":
+		return NewBool(jqCompare(l, r) == 0), nil
+	case "!=":
+		return NewBool(jqCompare(l, r) != 0), nil
+	case "<":
+		return NewBool(jqCompare(l, r) < 0), nil
+	case "<=":
+		return NewBool(jqCompare(l, r) <= 0), nil
+	case ">":
+		return NewBool(jqCompare(l, r) > 0), nil
+	case ">=":
+		return NewBool(jqCompare(l, r) >= 0), nil
+	}
+
+	if l.Type() == JSON_NUMBER && r.Type() == JSON_NUMBER {
+		x, err := l.Float64()
+		if err != nil {
+			return nil, err
+		}
+		y, err := r.Float64()
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "+":
+			return numberFromFloat(x + y), nil
+		case "-":
+			return numberFromFloat(x - y), nil
+		case "*":
+			return numberFromFloat(x * y), nil
+		case "/":
+			if y == 0 {
+				return nil, fmt.Errorf("%s and %s cannot be divided because the divisor is zero", jqDescribe(l), jqDescribe(r))
+			}
+			return numberFromFloat(x / y), nil
+		case "%":
+			if int64(y) == 0 {
+				return nil, fmt.Errorf("%s and %s cannot be divided because the divisor is zero", jqDescribe(l), jqDescribe(r))
+			}
+			return NewInt(int64(x) % int64(y)), nil
+		}
+	}
+
+	switch {
+	case op == "+" && l.IsNull():
+		return r, nil
+	case op == "+" && r.IsNull():
+		return l, nil
+	case op == "+" && l.Type() == JSON_STRING && r.Type() == JSON_STRING:
+		return NewString(l.str + r.str), nil
+	case (op == "+" || op == "-") && l.Type() == JSON_ARRAY && r.Type() == JSON_ARRAY:
+		a, err := l.Array()
+		if err != nil {
+			return nil, err
+		}
+		b, err := r.Array()
+		if err != nil {
+			return nil, err
+		}
+		if op == "+" {
+			return NewArray(append(append([]*Value{}, a...), b...)...), nil
+		}
+		out := make([]*Value, 0, len(a))
+	next:
+		for _, x := range a {
+			for _, y := range b {
+				if jqCompare(x, y) == 0 {
+					continue next
+				}
+			}
+			out = append(out, x)
+		}
+		return NewArray(out...), nil
+	case (op == "+" || op == "*") && l.Type() == JSON_OBJECT && r.Type() == JSON_OBJECT:
+		return jqMerge(l, r, op == "*")
+	case op == "/" && l.Type() == JSON_STRING && r.Type() == JSON_STRING:
+		return jqSplit(l.str, r.str), nil
+	case op == "*" && l.Type() == JSON_STRING && r.Type() == JSON_NUMBER:
+		return jqRepeat(l.str, r)
+	case op == "*" && l.Type() == JSON_NUMBER && r.Type() == JSON_STRING:
+		return jqRepeat(r.str, l)
+	}
+
+	verbs := map[string]string{"+": "added", "-": "subtracted", "*": "multiplied", "/": "divided", "%": "divided"}
+	return nil, fmt.Errorf("%s and %s cannot be %s", jqDescribe(l), jqDescribe(r), verbs[op])
+}
+
+// 对象合并, 右侧优先; deep 为 true 时两侧都是对象的成员递归合并
+func jqMerge(l, r *Value, deep bool) (*Value, error) {
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	obj := NewObject()
+	for _, k := range l.keys {
+		obj.setMember(k, l.obj[k])
+	}
+	for _, k := range r.keys {
+		v := r.obj[k]
+		if old, ok := obj.obj[k]; deep && ok && old.Type() == JSON_OBJECT && v.Type() == JSON_OBJECT {
+			merged, err := jqMerge(old, v, true)
+			if err != nil {
+				return nil, err
+			}
+			v = merged
+		}
+		obj.setMember(k, v)
+	}
+	return obj, nil
+}
+
+// 字符串乘以数字: 与 jq 相同, 重复 int(n-1)+1 次, 结果小于 1 次时为 null
+func jqRepeat(s string, n *Value) (*Value, error) {
+	f, err := n.Float64()
+	if err != nil {
+		return nil, err
+	}
+	count := int(f - 1)
+	if count < 0 {
+		return NewNull(), nil
+	}
+	return NewString(strings.Repeat(s, count+1)), nil
+}
+
+func jqSplit(s, sep string) *Value {
+	var parts []string
+	if s != "" {
+		parts = strings.Split(s, sep)
+	}
+	out := make([]*Value, len(parts))
+	for i, p := range parts {
+		out[i] = NewString(p)
+	}
+	return NewArray(out...)
+}
+
+// and / or 短路求值, 结果总是布尔值
+type jqLogic struct {
+	and         bool
+	left, right jqNode
+}
+
+func (n jqLogic) eval(in *Value) ([]*Value, error) {
+	ls, err := n.left.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	var out []*Value
+	for _, l := range ls {
+		if jqTruthy(l) != n.and {
+			out = append(out, NewBool(!n.and))
+			continue
+		}
+		rs, err := n.right.eval(in)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rs {
+			out = append(out, NewBool(jqTruthy(r)))
+		}
+	}
+	return out, nil
+}
+
+// a // b: a 中为真的输出, 没有时为 b 的输出; a 的错误被忽略
+type jqAlternative struct {
+	left, right jqNode
+}
+
+func (n jqAlternative) eval(in *Value) ([]*Value, error) {
+	ls, err := n.left.eval(in)
+	var out []*Value
+	if err == nil {
+		for _, l := range ls {
+			if jqTruthy(l) {
+				out = append(out, l)
+			}
+		}
+	}
+	if len(out) > 0 {
+		return out, nil
+	}
+	return n.right.eval(in)
+}
+
+// if cond then a else b end, elif 展开为嵌套的 jqIf
+type jqIf struct {
+	cond, then, otherwise jqNode
+}
+
+func (n jqIf) eval(in *Value) ([]*Value, error) {
+	conds, err := n.cond.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	var out []*Value
+	for _, c := range conds {
+		branch := n.otherwise
+		if jqTruthy(c) {
+			branch = n.then
+		}
+		res, err := branch.eval(in)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, res...)
+	}
+	return out, nil
+}
+
+type jqNegate struct {
+	child jqNode
+}
+
+func (n jqNegate) eval(in *Value) ([]*Value, error) {
+	values, err := n.child.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Value, len(values))
+	for i, v := range values {
+		if v.Type() != JSON_NUMBER {
+			return nil, fmt.Errorf("%s cannot be negated", jqDescribe(v))
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		out[i] = numberFromFloat(-f)
+	}
+	return out, nil
+}
+
+type jqCall struct {
+	fn   jqBuiltin
+	args []jqNode
+}
+
+func (n jqCall) eval(in *Value) ([]*Value, error) {
+	return n.fn(in, n.args)
+}
+
+type jqParser struct {
+	expr string
+	i    int
+}
+
+func (p *jqParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid jq expression %q: %s at offset %d", p.expr, fmt.Sprintf(format, args...), p.i)
+}
+
+func (p *jqParser) unexpected() error {
+	if p.i >= len(p.expr) {
+		return p.errorf("unexpected end of expression")
+	}
+	r, _ := utf8.DecodeRuneInString(p.expr[p.i:])
+	return p.errorf("unexpected %q", r)
+}
+
+func (p *jqParser) space() {
+	for p.i < len(p.expr) {
+		switch p.expr[p.i] {
+		case BLANK_SPACE, HORIZONTAL_TAB, LINE_BREAK, CARRIAGE_RETURN:
+			p.i++
+		case '#':
+			for p.i < len(p.expr) && p.expr[p.i] != LINE_BREAK {
+				p.i++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *jqParser) peek() byte {
+	p.space()
+	if p.i < len(p.expr) {
+		return p.expr[p.i]
+	}
+	return 0
+}
+
+// 跳过空白后匹配 s, 成功时前进
+func (p *jqParser) consume(s string) bool {
+	p.space()
+	if strings.HasPrefix(p.expr[p.i:], s) {
+		p.i += len(s)
+		return true
+	}
+	return false
+}
+
+// 匹配关键字, 后面不能紧跟标识符字符
+func (p *jqParser) keyword(word string) bool {
+	p.space()
+	end := p.i + len(word)
+	if !strings.HasPrefix(p.expr[p.i:], word) || end < len(p.expr) && isJQIdentChar(p.expr[end], false) {
+		return false
+	}
+	p.i = end
+	return true
+}
+
+func isJQIdentChar(c byte, first bool) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || !first && c >= '0' && c <= '9'
+}
+
+func (p *jqParser) ident() string {
+	p.space()
+	start := p.i
+	if p.i < len(p.expr) && isJQIdentChar(p.expr[p.i], true) {
+		p.i++
+		for p.i < len(p.expr) && isJQIdentChar(p.expr[p.i], false) {
+			p.i++
+		}
+	}
+	return p.expr[start:p.i]
+}
+
+// 优先级从低到高: | , // or and 比较 +- */% 后缀
+// noComma 用于对象的值, 其中的逗号分隔成员
+func (p *jqParser) pipe(noComma bool) (jqNode, error) {
+	left, err := p.comma(noComma)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != '|' || strings.HasPrefix(p.expr[p.i:], "|=") {
+		return left, nil
+	}
+	p.i++
+	right, err := p.pipe(noComma)
+	if err != nil {
+		return nil, err
+	}
+	return jqPipe{left, right}, nil
+}
+
+func (p *jqParser) comma(noComma bool) (jqNode, error) {
+	left, err := p.alternative()
+	if err != nil {
+		return nil, err
+	}
+	for !noComma && p.consume(",") {
+		right, err := p.alternative()
+		if err != nil {
+			return nil, err
+		}
+		left = jqComma{left, right}
+	}
+	return left, nil
+}
+
+func (p *jqParser) alternative() (jqNode, error) {
+	left, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != '/' || !strings.HasPrefix(p.expr[p.i:], "//") || strings.HasPrefix(p.expr[p.i:], "//=") {
+		return left, nil
+	}
+	p.i += 2
+	right, err := p.alternative()
+	if err != nil {
+		return nil, err
+	}
+	return jqAlternative{left, right}, nil
+}
+
+func (p *jqParser) or() (jqNode, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = jqLogic{false, left, right}
+	}
+	return left, nil
+}
+
+func (p *jqParser) and() (jqNode, error) {
+	left, err := p.comparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.comparison()
+		if err != nil {
+			return nil, err
+		}
+		left = jqLogic{true, left, right}
+	}
+	return left, nil
+}
+
+func (p *jqParser) comparison() (jqNode, error) {
+	left, err := p.additive()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(op) {
+			right, err := p.additive()
+			if err != nil {
+				return nil, err
+			}
+			return jqBinary{op, left, right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *jqParser) additive() (jqNode, error) {
+	left, err := p.multiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		c := p.peek()
+		if c != '+' && c != '-' || p.i+1 < len(p.expr) && p.expr[p.i+1] == '=' {
+			return left, nil
+		}
+		p.i++
+		right, err := p.multiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = jqBinary{string(c), left, right}
+	}
+}
+
+func (p *jqParser) multiplicative() (jqNode, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		c := p.peek()
+		if c != '*' && c != '/' && c != '%' || p.i+1 < len(p.expr) && (p.expr[p.i+1] == '=' || c == '/' && p.expr[p.i+1] == '/') {
+			return left, nil
+		}
+		p.i++
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = jqBinary{string(c), left, right}
+	}
+}
+
+func (p *jqParser) unary() (jqNode, error) {
+	if p.peek() != '-' {
+		return p.postfix()
+	}
+	p.i++
+	child, err := p.postfix()
+	if err != nil {
+		return nil, err
+	}
+	if lit, ok := child.(jqLiteral); ok && lit.v.Type() == JSON_NUMBER {
+		f, err := lit.v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return jqLiteral{numberFromFloat(-f)}, nil
+	}
+	return jqNegate{child}, nil
+}
+
+// 项之后的 .foo [..] 和 ?
+func (p *jqParser) postfix() (jqNode, error) {
+	node, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch p.peek() {
+		case '?':
+			p.i++
+			node = jqTry{node}
+		case '[':
+			p.i++
+			if node, err = p.bracketSuffix(node); err != nil {
+				return nil, err
+			}
+		case '.':
+			if p.i+1 >= len(p.expr) || p.expr[p.i+1] == '.' {
+				return node, nil
+			}
+			next := p.expr[p.i+1]
+			switch {
+			case next == '[':
+				p.i++
+			case next == '"' || isJQIdentChar(next, true):
+				p.i++
+				key, err := p.fieldName()
+				if err != nil {
+					return nil, err
+				}
+				node = jqIndex{node, key}
+			default:
+				return node, nil
+			}
+		default:
+			return node, nil
+		}
+	}
+}
+
+// . 之后的字段名: 标识符或字符串
+func (p *jqParser) fieldName() (jqNode, error) {
+	if p.i < len(p.expr) && p.expr[p.i] == '"' {
+		return p.stringLiteral()
+	}
+	name := p.ident()
+	return jqLiteral{NewString(name)}, nil
+}
+
+// [ 之后: [] 迭代, [e] 下标, [e:e] 切片
+func (p *jqParser) bracketSuffix(target jqNode) (jqNode, error) {
+	if p.consume("]") {
+		return jqIterate{target}, nil
+	}
+	var from jqNode
+	if p.peek() != ':' {
+		var err error
+		if from, err = p.pipe(false); err != nil {
+			return nil, err
+		}
+	}
+	if p.consume("]") {
+		if from == nil {
+			return nil, p.unexpected()
+		}
+		return jqIndex{target, from}, nil
+	}
+	if !p.consume(":") {
+		return nil, p.unexpected()
+	}
+	var to jqNode
+	if p.peek() != ']' {
+		var err error
+		if to, err = p.pipe(false); err != nil {
+			return nil, err
+		}
+	}
+	if !p.consume("]") {
+		return nil, p.unexpected()
+	}
+	return jqSlice{target, from, to}, nil
+}
+
+func (p *jqParser) term() (jqNode, error) {
+	switch c := p.peek(); {
+	case c == '.':
+		p.i++
+		if p.i < len(p.expr) && p.expr[p.i] == '.' {
+			p.i++
+			return jqRecurse{}, nil
+		}
+		if p.i < len(p.expr) && (p.expr[p.i] == '"' || isJQIdentChar(p.expr[p.i], true)) {
+			key, err := p.fieldName()
+			if err != nil {
+				return nil, err
+			}
+			return jqIndex{jqIdentity{}, key}, nil
+		}
+		return jqIdentity{}, nil
+	case c == '"':
+		return p.stringLiteral()
+	case c >= '0' && c <= '9':
+		return p.number()
+	case c == '(':
+		p.i++
+		node, err := p.pipe(false)
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(")") {
+			return nil, p.unexpected()
+		}
+		return node, nil
+	case c == '[':
+		p.i++
+		if p.consume("]") {
+			return jqArray{}, nil
+		}
+		body, err := p.pipe(false)
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume("]") {
+			return nil, p.unexpected()
+		}
+		return jqArray{body}, nil
+	case c == '{':
+		p.i++
+		return p.object()
+	case isJQIdentChar(c, true):
+		return p.identTerm()
+	}
+	return nil, p.unexpected()
+}
+
+func (p *jqParser) identTerm() (jqNode, error) {
+	start := p.i
+	name := p.ident()
+	switch name {
+	case "true", "false":
+		return jqLiteral{NewBool(name == "true")}, nil
+	case "null":
+		return jqLiteral{NewNull()}, nil
+	case "if":
+		return p.ifTerm()
+	case "reduce", "foreach", "def", "try", "label", "import", "include":
+		p.i = start
+		return nil, p.errorf("%s is not supported", name)
+	}
+	var args []jqNode
+	if p.consume("(") {
+		for {
+			arg, err := p.pipe(false)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.consume(")") {
+				break
+			}
+			if !p.consume(";") {
+				return nil, p.unexpected()
+			}
+		}
+	}
+	fn, ok := jqBuiltins[fmt.Sprintf("%s/%d", name, len(args))]
+	if !ok {
+		p.i = start
+		return nil, p.errorf("unknown function %s/%d", name, len(args))
+	}
+	return jqCall{fn, args}, nil
+}
+
+// if 之后的部分, elif 展开为 else 中嵌套的 if, 省略 else 时为 .
+func (p *jqParser) ifTerm() (jqNode, error) {
+	cond, err := p.pipe(false)
+	if err != nil {
+		return nil, err
+	}
+	if !p.keyword("then") {
+		return nil, p.errorf("expect then")
+	}
+	then, err := p.pipe(false)
+	if err != nil {
+		return nil, err
+	}
+	var otherwise jqNode = jqIdentity{}
+	switch {
+	case p.keyword("elif"):
+		return p.elseIf(cond, then)
+	case p.keyword("else"):
+		if otherwise, err = p.pipe(false); err != nil {
+			return nil, err
+		}
+	}
+	if !p.keyword("end") {
+		return nil, p.errorf("expect end")
+	}
+	return jqIf{cond, then, otherwise}, nil
+}
+
+func (p *jqParser) elseIf(cond, then jqNode) (jqNode, error) {
+	otherwise, err := p.ifTerm()
+	if err != nil {
+		return nil, err
+	}
+	return jqIf{cond, then, otherwise}, nil
+}
+
+// { 之后的成员: id  "k"  k: v  "k": v  (e): v
+func (p *jqParser) object() (jqNode, error) {
+	var entries []jqObjectEntry
+	if p.consume("}") {
+		return jqObject{}, nil
+	}
+	for {
+		var key jqNode
+		var shorthand bool
+		switch c := p.peek(); {
+		case c == '"':
+			k, err := p.stringLiteral()
+			if err != nil {
+				return nil, err
+			}
+			key, shorthand = k, true
+		case c == '(':
+			p.i++
+			k, err := p.pipe(false)
+			if err != nil {
+				return nil, err
+			}
+			if !p.consume(")") {
+				return nil, p.unexpected()
+			}
+			key = k
+		case isJQIdentChar(c, true):
+			key, shorthand = jqLiteral{NewString(p.ident())}, true
+		default:
+			return nil, p.unexpected()
+		}
+
+		var value jqNode
+		if p.consume(":") {
+			v, err := p.pipe(true)
+			if err != nil {
+				return nil, err
+			}
+			value = v
+		} else if shorthand {
+			value = jqIndex{jqIdentity{}, key}
+		} else {
+			return nil, p.errorf("expect :")
+		}
+		entries = append(entries, jqObjectEntry{key, value})
+
+		if p.consume("}") {
+			return jqObject{entries}, nil
+		}
+		if !p.consume(",") {
+			return nil, p.unexpected()
+		}
+	}
+}
+
+func (p *jqParser) number() (jqNode, error) {
+	start := p.i
+	for p.i < len(p.expr) && (p.expr[p.i] >= '0' && p.expr[p.i] <= '9' || p.expr[p.i] == '.') {
+		p.i++
+	}
+	if p.i < len(p.expr) && (p.expr[p.i] == 'e' || p.expr[p.i] == 'E') {
+		p.i++
+		if p.i < len(p.expr) && (p.expr[p.i] == '+' || p.expr[p.i] == '-') {
+			p.i++
+		}
+		for p.i < len(p.expr) && p.expr[p.i] >= '0' && p.expr[p.i] <= '9' {
+			p.i++
+		}
+	}
+	v, err := ParseWithOptions([]byte(p.expr[start:p.i]), ParseOptions{Strict: true})
+	if err != nil || v.Type() != JSON_NUMBER {
+		p.i = start
+		return nil, p.errorf("invalid number")
+	}
+	return jqLiteral{v}, nil
+}
+
+// 带 \(...) 插值的字符串, 没有插值时为字面量
+func (p *jqParser) stringLiteral() (jqNode, error) {
+	start := p.i
+	p.i++
+	var parts []jqNode
+	seg := p.i
+	flush := func(end int) error {
+		if end == seg && len(parts) > 0 {
+			return nil
+		}
+		v, err := ParseWithOptions([]byte(`"`+p.expr[seg:end]+`"`), ParseOptions{Strict: true})
+		if err != nil {
+			return p.errorf("invalid string: %v", err)
+		}
+		parts = append(parts, jqLiteral{v})
+		return nil
+	}
+	for p.i < len(p.expr) {
+		switch p.expr[p.i] {
+		case '"':
+			if err := flush(p.i); err != nil {
+				return nil, err
+			}
+			p.i++
+			if len(parts) == 1 {
+				return parts[0], nil
+			}
+			return jqFormat{parts}, nil
+		case '\\':
+			if p.i+1 < len(p.expr) && p.expr[p.i+1] == '(' {
+				if err := flush(p.i); err != nil {
+					return nil, err
+				}
+				p.i += 2
+				inner, err := p.pipe(false)
+				if err != nil {
+					return nil, err
+				}
+				if !p.consume(")") {
+					return nil, p.unexpected()
+				}
+				parts = append(parts, inner)
+				seg = p.i
+				continue
+			}
+			p.i += 2
+		default:
+			p.i++
+		}
+	}
+	p.i = start
+	return nil, p.errorf("unterminated string")
+}
@@ -0,0 +1,260 @@
+package yjson
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type jqCase struct {
+	input string
+	expr  string
+	want  string // 所有输出编码后以空格连接, 以 "error: " 开头时为错误信息中应包含的内容
+}
+
+func runJQTests(t *testing.T, tests []jqCase) {
+	t.Helper()
+	for _, tt := range tests {
+		out, err := mustParse(t, tt.input).JQ(tt.expr)
+		if msg, ok := strings.CutPrefix(tt.want, "error: "); ok {
+			if err == nil || !strings.Contains(err.Error(), msg) {
+				t.Errorf("%s: got error %v, want %q", tt.expr, err, msg)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		got := make([]string, len(out))
+		for i, v := range out {
+			got[i] = mustEncode(t, v)
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("%s on %s:\ngot  %s\nwant %s", tt.expr, tt.input, strings.Join(got, " "), tt.want)
+		}
+	}
+}
+
+// 期望结果均为 jq 1.6 的输出
+func TestJQ(t *testing.T) {
+	runJQTests(t, []jqCase{
+		{`{"a":{"b":[1,2,3]}}`, `.a.b`, `[1,2,3]`},
+		{`{"a":{"b":[1,2,3]}}`, `.a.b[1]`, `2`},
+		{`{"a":{"b":[1,2,3]}}`, `.a.b[-1]`, `3`},
+		{`{"a":{"b":[1,2,3]}}`, `.a.b[1:]`, `[2,3]`},
+		{`{"a":{"b":[1,2,3]}}`, `.a.b[]`, `1 2 3`},
+		{`{"a":{"b":[1,2,3]}}`, `.a["b"][0]`, `1`},
+		{`{"a":{"b":[1,2,3]}}`, `."a"`, `{"b":[1,2,3]}`},
+		{`{"a":{"b":[1,2,3]}}`, `.x.y`, `null`},
+		{`{"a":{"b":[1,2,3]}}`, `[..]`, `[{"a":{"b":[1,2,3]}},{"b":[1,2,3]},[1,2,3],1,2,3]`},
+		{`{"a":{"b":[1,2,3]}}`, `.a.b | map(. * 10)`, `[10,20,30]`},
+		{`{"a":{"b":[1,2,3]}}`, `.a.b[] | select(. > 1)`, `2 3`},
+		{`{"a":{"b":[1,2,3]}}`, `.a.b | length`, `3`},
+		{`{"a":1,"b":2}`, `.a, .b`, `1 2`},
+		{`{"a":1,"b":2}`, `[.a, .b]`, `[1,2]`},
+		{`{"a":1,"b":2}`, `{x: .a, y: .b}`, `{"x":1,"y":2}`},
+		{`{"a":1,"b":2}`, `{a, "c": .b}`, `{"a":1,"c":2}`},
+		{`{"a":1,"b":2}`, `{(.a|tostring): .b}`, `{"1":2}`},
+		{`{"a":1,"b":2}`, `.c // "default"`, `"default"`},
+		{`{"a":false}`, `.a // "default"`, `"default"`},
+		{`{"a":1,"b":2}`, `.a + .b`, `3`},
+		{`{"a":1,"b":2}`, `.a - .b`, `-1`},
+		{`{"a":6,"b":4}`, `.a / .b`, `1.5`},
+		{`{"a":7,"b":4}`, `.a % .b`, `3`},
+		{`{"a":"x","b":"y"}`, `.a + .b`, `"xy"`},
+		{`{"a":[1],"b":[2]}`, `.a + .b`, `[1,2]`},
+		{`{"a":{"k":1},"b":{"j":2}}`, `.a + .b`, `{"k":1,"j":2}`},
+		{`{"a":null,"b":1}`, `.a + .b`, `1`},
+		{`{"a":[1,2,3,2],"b":[2]}`, `.a - .b`, `[1,3]`},
+		{`{"a":1,"b":2}`, `.a < .b, .a == 1, .a != 1, .b >= 2`, `true true false true`},
+		{`{"a":1,"b":2}`, `.a and .b, (.a and false), (null or .b)`, `true false true`},
+		{`{"a":1,"b":2}`, `if .a == 1 then "one" elif .a == 2 then "two" else "many" end`, `"one"`},
+		{`{"a":3}`, `if .a == 1 then "one" elif .a == 2 then "two" else "many" end`, `"many"`},
+		{`{"a":1,"b":"x"}`, `"a=\(.a), b=\(.b)"`, `"a=1, b=x"`},
+		{`null`, `[1, "a", null, true, [], {}] | sort`, `[null,true,1,"a",[],{}]`},
+		{`null`, `[{"b":1},{"a":2}] | sort`, `[{"a":2},{"b":1}]`},
+		{`[3,1,2]`, `sort, reverse, min, max, add`, `[1,2,3] [2,1,3] 1 3 6`},
+		{`[{"n":"b","a":2},{"n":"a","a":1},{"n":"c","a":2}]`, `sort_by(.a) | map(.n)`, `["a","b","c"]`},
+		{`[{"n":"b","a":2},{"n":"a","a":1},{"n":"c","a":2}]`, `group_by(.a) | map(map(.n))`, `[["a"],["b","c"]]`},
+		{`[{"n":"b","a":2},{"n":"a","a":1},{"n":"c","a":2}]`, `unique_by(.a) | map(.n)`, `["a","b"]`},
+		{`[{"n":"b","a":2},{"n":"a","a":1},{"n":"c","a":2}]`, `min_by(.a).n, max_by(.a).n`, `"a" "c"`},
+		{`[1,2,1,3]`, `unique`, `[1,2,3]`},
+		{`{"b":1,"a":2}`, `keys, keys_unsorted`, `["a","b"] ["b","a"]`},
+		{`{"b":1,"a":2}`, `to_entries`, `[{"key":"b","value":1},{"key":"a","value":2}]`},
+		{`[{"key":"a","value":1},{"name":"b","value":2}]`, `from_entries`, `{"a":1,"b":2}`},
+		{`{"a":1,"b":2}`, `map_values(. * 2)`, `{"a":2,"b":4}`},
+		{`{"a":1,"b":null}`, `[.[] | values]`, `[1]`},
+		{`{"a":1}`, `has("a"), has("b")`, `true false`},
+		{`[1,2]`, `has(0), has(5)`, `true false`},
+		{`null`, `[range(3)], [range(1;4)]`, `[0,1,2] [1,2,3]`},
+		{`[1.5,-1.5]`, `map(floor), map(ceil), map(round)`, `[1,-2] [2,-1] [2,-2]`},
+		{`16`, `sqrt`, `4`},
+		{`"a,b,c"`, `split(",")`, `["a","b","c"]`},
+		{`["a","b"]`, `join("-")`, `"a-b"`},
+		{`"foobar"`, `startswith("foo"), endswith("bar"), ltrimstr("foo"), rtrimstr("bar")`, `true true "bar" "foo"`},
+		{`"AbC"`, `ascii_downcase, ascii_upcase`, `"abc" "ABC"`},
+		{`"foobar"`, `contains("oba")`, `true`},
+		{`{"a":[1,2],"b":"x"}`, `contains({"a":[1]})`, `true`},
+		{`"abc123"`, `test("[0-9]+")`, `true`},
+		{`[[1,[2]],3]`, `flatten`, `[1,2,3]`},
+		{`[1,2,3]`, `first, last, first(.[] | select(. > 1))`, `1 3 2`},
+		{`"12"`, `tonumber`, `12`},
+		{`[1,"x"]`, `tojson`, `"[1,\"x\"]"`},
+		{`"[1,{\"a\":2}]"`, `fromjson`, `[1,{"a":2}]`},
+		{`[1,2]`, `tostring`, `"[1,2]"`},
+		{`{"a":[1,{"b":2}]}`, `[recurse | type]`, `["object","array","number","object","number"]`},
+		{`[1,null,2]`, `map(values)`, `[1,2]`},
+		{`[true,false]`, `any, all`, `true false`},
+		{`[]`, `any, all, add`, `false true null`},
+		{`{"a":1}`, `.a?, .[0]?`, `1`},
+		{`[1,2,3]`, `.[] | empty`, ``},
+		{`{"a":"x"}`, `.a | length`, `1`},
+		{`{"a":-5}`, `.a | length`, `5`},
+		{`{"a":{"k":1,"j":2}}`, `.a | length`, `2`},
+		{`{"a":[{"b":1},{"b":2}]}`, `.a | map(select(.b == 2))`, `[{"b":2}]`},
+		{`null`, `not`, `true`},
+		{`{"a":1}`, `type`, `"object"`},
+		{`null`, `1, 2 | . * 3`, `3 6`},
+		{`null`, `"é" | length`, `1`},
+		{`null`, `[.[]?]`, `[]`},
+		{`null`, `"ab" * 0, "ab" * 1, "ab" * 3, "ab" * 1.5, "ab" * 0.5, 3 * "ab", "" * 2`, `null "ab" "ababab" "ab" "ab" "ababab" ""`},
+		{`null`, `{"a":{"b":1,"c":2}} * {"a":{"b":3},"d":4}`, `{"a":{"b":3,"c":2},"d":4}`},
+		{`null`, `"a,b,,c" / ","`, `["a","b","","c"]`},
+		{`null`, `7 % -3, -7 % 3, 5.9 % 2.1`, `1 -1 1`},
+		{`null`, `[null, false, true, 0, -1, "", "a", [], [0], {}, {"a":1}] | sort`, `[null,false,true,-1,0,"","a",[],[0],{},{"a":1}]`},
+		{`null`, `[{"a":1,"b":2},{"a":1},{"b":1}] | sort`, `[{"a":1},{"a":1,"b":2},{"b":1}]`},
+		{`null`, `[[1,2],[1],[0,5]] | sort`, `[[0,5],[1],[1,2]]`},
+		{`null`, `(1,null,2) // 3`, `1 2`},
+		{`null`, `(null,false) // 3`, `3`},
+		{`null`, `[empty] // "x"`, `[]`},
+		{`null`, `{"a":[1,2]} | [..]`, `[{"a":[1,2]},[1,2],1,2]`},
+		{`null`, `[1,[2]] | .[1]?[0]`, `2`},
+		{`null`, `"x" | .a?`, ``},
+		{`null`, `[1,2,3] | .[1:], .[:-1], .[5:], .[-2:]`, `[2,3] [1,2] [] [2,3]`},
+		{`null`, `"abcdef" | .[2:4]`, `"cd"`},
+		{`null`, `{"a":1} | .b.c`, `null`},
+		{`null`, `[1,2] | map(. , .)`, `[1,1,2,2]`},
+		{`null`, `[3,1] | min_by(.), max_by(.)`, `1 3`},
+		{`null`, `[] | min, max, first(empty)`, `null null`},
+		{`null`, `{"a":"x"} | has("a"), (keys | length)`, `true 1`},
+		{`null`, `[1,[2,[3,[4]]]] | flatten`, `[1,2,3,4]`},
+		{`null`, `[1,2,3] | add / length`, `2`},
+		{`null`, `"a\tbé" | length, tojson`, `4 "\"a\\tbé\""`},
+		{`null`, `{"a":1} | tojson, tostring`, `"{\"a\":1}" "{\"a\":1}"`},
+		{`null`, `[1, "1", null] | map(tostring)`, `["1","1","null"]`},
+		{`null`, `"1.50" | tonumber`, `1.5`},
+		{`null`, `[{"a":1},{"a":2}] | map(.a) | add`, `3`},
+		{`null`, `["a","b"] | add`, `"ab"`},
+		{`null`, `[[1],[2]] | add`, `[1,2]`},
+		{`null`, `{"a":1,"b":2} | to_entries | map(.key)`, `["a","b"]`},
+		{`null`, `{"a":1} | .["a"]`, `1`},
+		{`null`, `[1,2] | .[-3]`, `null`},
+		{`null`, `"abc" | ascii_upcase | ascii_downcase`, `"abc"`},
+		{`null`, `{"x":[{"y":1},{"y":2}]} | .x[] | .y`, `1 2`},
+		{`null`, `[true, 1, "a"] | map(type)`, `["boolean","number","string"]`},
+		{`null`, `1 == 1.0, "a" < "b", [1] < [1,0], {} < [], null < false`, `true true true false true`},
+		{`null`, `"abc" | test("B"), test("b")`, `false true`},
+		{`null`, `[1,2] | contains([1]), ("foo" | contains("bar"))`, `true false`},
+		{`null`, `[1,[2]] | tojson | fromjson`, `[1,[2]]`},
+	})
+}
+
+func TestJQErrors(t *testing.T) {
+	runJQTests(t, []jqCase{
+		// 不支持的语法在编译时报告
+		{`null`, `1 as $x | $x`, `error: invalid jq expression "1 as $x | $x"`},
+		{`[1]`, `.[0] = 1`, `error: unexpected '='`},
+		{`[1]`, `index(1)`, `error: unknown function index/1`},
+		{`null`, `.a |`, `error: invalid jq expression`},
+		{`null`, `[1, 2`, `error: invalid jq expression`},
+		// 运行时错误带有程序文本
+		{`{"a":"x"}`, `.a + 1`, `error: jq ".a + 1": `},
+		{`{"a":"x"}`, `.a[0]`, `error: jq ".a[0]": `},
+		{`[1]`, `.a`, `error: jq ".a": `},
+		{`{"a":1}`, `keys | .[0] - 1`, `error: jq "keys | .[0] - 1": `},
+		{`1`, `. / 0`, `error: jq ". / 0": `},
+		{`"x"`, `tonumber`, `error: jq "tonumber": `},
+	})
+}
+
+func TestCompileJQ(t *testing.T) {
+	q, err := CompileJQ(`.items[] | select(.n > 1) | .n * 2`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"items":[{"n":1},{"n":2},{"n":3}]}`, `4 6`},
+		{`{"items":[]}`, ``},
+		{`{"items":[{"n":5}]}`, `10`},
+	}
+	for _, tt := range tests {
+		out, err := q.Run(mustParse(t, tt.input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]string, len(out))
+		for i, v := range out {
+			got[i] = mustEncode(t, v)
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("%s: got %v, want %s", tt.input, got, tt.want)
+		}
+	}
+
+	if _, err := CompileJQ(`.a | foo`); err == nil {
+		t.Errorf("unknown function: expected an error")
+	}
+}
+
+func TestJQRunLines(t *testing.T) {
+	q, err := CompileJQ(`.n * 2`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	collect := func(input string) ([]string, error) {
+		var got []string
+		err := q.RunLines(strings.NewReader(input), func(v *Value) error {
+			got = append(got, mustEncode(t, v))
+			return nil
+		})
+		return got, err
+	}
+
+	got, err := collect("{\"n\":1}\n\n{\"n\":2}\r\n{\"n\":3}")
+	if err != nil || strings.Join(got, " ") != "2 4 6" {
+		t.Errorf("got %v, %v", got, err)
+	}
+
+	tests := []struct {
+		input string
+		line  int
+		want  string
+	}{
+		{"{\"n\":1}\n{\"n\":\n", 2, ""},
+		{"{\"n\":1}\n{\"n\":{}}\n{\"n\":3}\n", 2, `jq ".n * 2": `},
+	}
+	for _, tt := range tests {
+		got, err := collect(tt.input)
+		var le *LineError
+		if !errors.As(err, &le) || le.Line != tt.line || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: got %v, want a line %d error containing %q", tt.input, err, tt.line, tt.want)
+		}
+		if len(got) != 1 || got[0] != "2" {
+			t.Errorf("%q: outputs before the error: %v", tt.input, got)
+		}
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = q.RunLines(strings.NewReader("{\"n\":1}\n{\"n\":2}\n"), func(*Value) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("got %v after %d calls, want stop after 1", err, calls)
+	}
+}
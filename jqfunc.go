@@ -0,0 +1,740 @@
+package yjson
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// 内置函数, args 为未求值的参数表达式, 由函数决定在哪个输入上求值
+type jqBuiltin func(in *Value, args []jqNode) ([]*Value, error)
+
+// 键为 name/arity, 与 jq 相同, 同名函数可以有不同的参数个数
+var jqBuiltins = map[string]jqBuiltin{
+	"empty/0":          jqEmpty,
+	"not/0":            jqScalar(func(in *Value) (*Value, error) { return NewBool(!jqTruthy(in)), nil }),
+	"length/0":         jqScalar(jqLength),
+	"keys/0":           jqScalar(jqKeys(true)),
+	"keys_unsorted/0":  jqScalar(jqKeys(false)),
+	"values/0":         jqSelectValues,
+	"has/1":            jqWithArg(jqHas),
+	"select/1":         jqSelect,
+	"map/1":            jqMapArray,
+	"map_values/1":     jqMapValues,
+	"recurse/0":        func(in *Value, _ []jqNode) ([]*Value, error) { return jqRecurse{}.eval(in) },
+	"type/0":           jqScalar(func(in *Value) (*Value, error) { return NewString(in.Type().String()), nil }),
+	"add/0":            jqScalar(jqAdd),
+	"any/0":            jqScalar(jqAnyAll(true)),
+	"all/0":            jqScalar(jqAnyAll(false)),
+	"range/1":          jqRange1,
+	"range/2":          jqRange2,
+	"floor/0":          jqScalar(jqMath("floor", math.Floor)),
+	"ceil/0":           jqScalar(jqMath("ceil", math.Ceil)),
+	"round/0":          jqScalar(jqMath("round", math.Round)),
+	"sqrt/0":           jqScalar(jqMath("sqrt", math.Sqrt)),
+	"sort/0":           jqScalar(jqSort),
+	"sort_by/1":        jqSortBy,
+	"group_by/1":       jqGroupBy,
+	"unique/0":         jqScalar(jqUnique),
+	"unique_by/1":      jqUniqueBy,
+	"min/0":            jqScalar(jqExtreme(true)),
+	"max/0":            jqScalar(jqExtreme(false)),
+	"min_by/1":         jqExtremeBy(true),
+	"max_by/1":         jqExtremeBy(false),
+	"reverse/0":        jqScalar(jqReverse),
+	"first/0":          jqScalar(func(in *Value) (*Value, error) { return jqIndexValue(in, NewInt(0)) }),
+	"last/0":           jqScalar(func(in *Value) (*Value, error) { return jqIndexValue(in, NewInt(-1)) }),
+	"first/1":          jqFirst,
+	"flatten/0":        jqScalar(jqFlatten),
+	"tostring/0":       jqScalar(func(in *Value) (*Value, error) { s, err := jqToString(in); return NewString(s), err }),
+	"tonumber/0":       jqScalar(jqToNumber),
+	"tojson/0":         jqScalar(jqToJSON),
+	"fromjson/0":       jqScalar(jqFromJSON),
+	"to_entries/0":     jqScalar(jqToEntries),
+	"from_entries/0":   jqScalar(jqFromEntries),
+	"with_entries/1":   jqWithEntries,
+	"join/1":           jqWithArg(jqJoin),
+	"split/1":          jqWithArg(jqSplitBuiltin),
+	"startswith/1":     jqWithArg(jqAffix("startswith", strings.HasPrefix)),
+	"endswith/1":       jqWithArg(jqAffix("endswith", strings.HasSuffix)),
+	"ltrimstr/1":       jqWithArg(jqTrim(strings.TrimPrefix)),
+	"rtrimstr/1":       jqWithArg(jqTrim(strings.TrimSuffix)),
+	"ascii_downcase/0": jqScalar(jqASCIICase(false)),
+	"ascii_upcase/0":   jqScalar(jqASCIICase(true)),
+	"contains/1":       jqWithArg(func(in, a *Value) (*Value, error) { ok, err := jqContains(in, a); return NewBool(ok), err }),
+	"test/1":           jqWithArg(jqTest),
+}
+
+// 只依赖输入, 恰好一个输出的函数
+func jqScalar(fn func(in *Value) (*Value, error)) jqBuiltin {
+	return func(in *Value, _ []jqNode) ([]*Value, error) {
+		v, err := fn(in)
+		if err != nil {
+			return nil, err
+		}
+		return []*Value{v}, nil
+	}
+}
+
+// 一个值参数的函数, 参数有多个输出时依次调用
+func jqWithArg(fn func(in, arg *Value) (*Value, error)) jqBuiltin {
+	return func(in *Value, args []jqNode) ([]*Value, error) {
+		values, err := args[0].eval(in)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]*Value, 0, len(values))
+		for _, a := range values {
+			v, err := fn(in, a)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+		return out, nil
+	}
+}
+
+func jqEmpty(_ *Value, _ []jqNode) ([]*Value, error) {
+	return nil, nil
+}
+
+func jqArrayInput(name string, in *Value) ([]*Value, error) {
+	if in.Type() != JSON_ARRAY {
+		return nil, fmt.Errorf("%s cannot be %s, expect an array", jqDescribe(in), name)
+	}
+	return in.Array()
+}
+
+func jqLength(in *Value) (*Value, error) {
+	switch in.Type() {
+	case JSON_NULL:
+		return NewInt(0), nil
+	case JSON_NUMBER:
+		f, err := in.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return numberFromFloat(math.Abs(f)), nil
+	case JSON_STRING:
+		return NewInt(int64(utf8.RuneCountInString(in.str))), nil
+	case JSON_ARRAY, JSON_OBJECT:
+		if err := in.load(); err != nil {
+			return nil, err
+		}
+		return NewInt(int64(in.Len())), nil
+	}
+	return nil, fmt.Errorf("%s has no length", jqDescribe(in))
+}
+
+// 对象的键 (sorted 时排序), 数组的下标
+func jqKeys(sorted bool) func(in *Value) (*Value, error) {
+	return func(in *Value) (*Value, error) {
+		switch in.Type() {
+		case JSON_OBJECT:
+			keys := in.Keys()
+			if sorted {
+				sort.Strings(keys)
+			}
+			out := make([]*Value, len(keys))
+			for i, k := range keys {
+				out[i] = NewString(k)
+			}
+			return NewArray(out...), nil
+		case JSON_ARRAY:
+			out := make([]*Value, in.Len())
+			for i := range out {
+				out[i] = NewInt(int64(i))
+			}
+			return NewArray(out...), nil
+		}
+		return nil, fmt.Errorf("%s has no keys", jqDescribe(in))
+	}
+}
+
+func jqHas(in, key *Value) (*Value, error) {
+	switch {
+	case in.Type() == JSON_OBJECT && key.Type() == JSON_STRING:
+		m, err := in.Map()
+		if err != nil {
+			return nil, err
+		}
+		_, ok := m[key.str]
+		return NewBool(ok), nil
+	case in.Type() == JSON_ARRAY && key.Type() == JSON_NUMBER:
+		f, err := key.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return NewBool(f >= 0 && int(f) < in.Len()), nil
+	}
+	return nil, fmt.Errorf("cannot check whether %s has a key %s", in.Type(), jqDescribe(key))
+}
+
+func jqSelectValues(in *Value, _ []jqNode) ([]*Value, error) {
+	if in.IsNull() {
+		return nil, nil
+	}
+	return []*Value{in}, nil
+}
+
+// f 的每个为真的输出都输出一次 in
+func jqSelect(in *Value, args []jqNode) ([]*Value, error) {
+	conds, err := args[0].eval(in)
+	if err != nil {
+		return nil, err
+	}
+	var out []*Value
+	for _, c := range conds {
+		if jqTruthy(c) {
+			out = append(out, in)
+		}
+	}
+	return out, nil
+}
+
+// [.[] | f]
+func jqMapArray(in *Value, args []jqNode) ([]*Value, error) {
+	items, err := jqIterate{jqIdentity{}}.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Value, 0, len(items))
+	for _, item := range items {
+		res, err := args[0].eval(item)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, res...)
+	}
+	return []*Value{NewArray(out...)}, nil
+}
+
+// 每个成员替换为 f 的第一个输出, 没有输出的成员被删除
+func jqMapValues(in *Value, args []jqNode) ([]*Value, error) {
+	if in.Type() != JSON_ARRAY && in.Type() != JSON_OBJECT {
+		return nil, fmt.Errorf("cannot iterate over %s", jqDescribe(in))
+	}
+	if err := in.load(); err != nil {
+		return nil, err
+	}
+	if in.Type() == JSON_ARRAY {
+		out := make([]*Value, 0, len(in.arr))
+		for _, item := range in.arr {
+			res, err := args[0].eval(item)
+			if err != nil {
+				return nil, err
+			}
+			if len(res) > 0 {
+				out = append(out, res[0])
+			}
+		}
+		return []*Value{NewArray(out...)}, nil
+	}
+	obj := NewObject()
+	for _, k := range in.keys {
+		res, err := args[0].eval(in.obj[k])
+		if err != nil {
+			return nil, err
+		}
+		if len(res) > 0 {
+			obj.setMember(k, res[0])
+		}
+	}
+	return []*Value{obj}, nil
+}
+
+// 空数组为 null
+func jqAdd(in *Value) (*Value, error) {
+	items, err := jqValuesInput("added", in)
+	if err != nil {
+		return nil, err
+	}
+	acc := NewNull()
+	for _, item := range items {
+		if acc, err = jqArith("+", acc, item); err != nil {
+			return nil, err
+		}
+	}
+	return acc, nil
+}
+
+func jqValuesInput(name string, in *Value) ([]*Value, error) {
+	if in.Type() != JSON_ARRAY && in.Type() != JSON_OBJECT {
+		return nil, fmt.Errorf("%s cannot be %s, expect an array or object", jqDescribe(in), name)
+	}
+	return jqValues(in)
+}
+
+func jqAnyAll(any bool) func(in *Value) (*Value, error) {
+	return func(in *Value) (*Value, error) {
+		items, err := jqValuesInput("iterated", in)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if jqTruthy(item) == any {
+				return NewBool(any), nil
+			}
+		}
+		return NewBool(!any), nil
+	}
+}
+
+func jqNumberArg(name string, v *Value) (float64, error) {
+	if v.Type() != JSON_NUMBER {
+		return 0, fmt.Errorf("%s: %s is not a number", name, jqDescribe(v))
+	}
+	return v.Float64()
+}
+
+func jqRange1(in *Value, args []jqNode) ([]*Value, error) {
+	return jqRange2(in, []jqNode{jqLiteral{NewInt(0)}, args[0]})
+}
+
+// range(from; upto), 步长为 1, 不包含 upto
+func jqRange2(in *Value, args []jqNode) ([]*Value, error) {
+	froms, err := args[0].eval(in)
+	if err != nil {
+		return nil, err
+	}
+	uptos, err := args[1].eval(in)
+	if err != nil {
+		return nil, err
+	}
+	var out []*Value
+	for _, from := range froms {
+		for _, upto := range uptos {
+			a, err := jqNumberArg("range", from)
+			if err != nil {
+				return nil, err
+			}
+			b, err := jqNumberArg("range", upto)
+			if err != nil {
+				return nil, err
+			}
+			for f := a; f < b; f++ {
+				out = append(out, numberFromFloat(f))
+			}
+		}
+	}
+	return out, nil
+}
+
+func jqMath(name string, fn func(float64) float64) func(in *Value) (*Value, error) {
+	return func(in *Value) (*Value, error) {
+		f, err := jqNumberArg(name, in)
+		if err != nil {
+			return nil, err
+		}
+		return numberFromFloat(fn(f)), nil
+	}
+}
+
+func jqSort(in *Value) (*Value, error) {
+	items, err := jqArrayInput("sorted", in)
+	if err != nil {
+		return nil, err
+	}
+	out := append([]*Value{}, items...)
+	sort.SliceStable(out, func(a, b int) bool { return jqCompare(out[a], out[b]) < 0 })
+	return NewArray(out...), nil
+}
+
+// 元素与其排序键 [f], 按键稳定排序
+type jqKeyed struct {
+	key, item *Value
+}
+
+func jqSortedByKey(name string, in *Value, f jqNode) ([]jqKeyed, error) {
+	items, err := jqArrayInput(name, in)
+	if err != nil {
+		return nil, err
+	}
+	keyed := make([]jqKeyed, len(items))
+	for i, item := range items {
+		keys, err := f.eval(item)
+		if err != nil {
+			return nil, err
+		}
+		keyed[i] = jqKeyed{NewArray(keys...), item}
+	}
+	sort.SliceStable(keyed, func(a, b int) bool { return jqCompare(keyed[a].key, keyed[b].key) < 0 })
+	return keyed, nil
+}
+
+func jqSortBy(in *Value, args []jqNode) ([]*Value, error) {
+	keyed, err := jqSortedByKey("sorted", in, args[0])
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Value, len(keyed))
+	for i, k := range keyed {
+		out[i] = k.item
+	}
+	return []*Value{NewArray(out...)}, nil
+}
+
+// 按键排序后把键相等的相邻元素分为一组
+func jqGroups(name string, in *Value, f jqNode) ([][]*Value, error) {
+	keyed, err := jqSortedByKey(name, in, f)
+	if err != nil {
+		return nil, err
+	}
+	var groups [][]*Value
+	for i, k := range keyed {
+		if i == 0 || jqCompare(keyed[i-1].key, k.key) != 0 {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], k.item)
+	}
+	return groups, nil
+}
+
+func jqGroupBy(in *Value, args []jqNode) ([]*Value, error) {
+	groups, err := jqGroups("grouped", in, args[0])
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Value, len(groups))
+	for i, g := range groups {
+		out[i] = NewArray(g...)
+	}
+	return []*Value{NewArray(out...)}, nil
+}
+
+func jqUniqueBy(in *Value, args []jqNode) ([]*Value, error) {
+	groups, err := jqGroups("sorted", in, args[0])
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Value, len(groups))
+	for i, g := range groups {
+		out[i] = g[0]
+	}
+	return []*Value{NewArray(out...)}, nil
+}
+
+func jqUnique(in *Value) (*Value, error) {
+	out, err := jqUniqueBy(in, []jqNode{jqIdentity{}})
+	if err != nil {
+		return nil, err
+	}
+	return out[0], nil
+}
+
+func jqExtreme(min bool) func(in *Value) (*Value, error) {
+	return func(in *Value) (*Value, error) {
+		out, err := jqExtremeBy(min)(in, []jqNode{jqIdentity{}})
+		if err != nil {
+			return nil, err
+		}
+		return out[0], nil
+	}
+}
+
+// 空数组为 null, 键相等时 min 取第一个, max 取最后一个
+func jqExtremeBy(min bool) jqBuiltin {
+	return func(in *Value, args []jqNode) ([]*Value, error) {
+		keyed, err := jqSortedByKey("compared", in, args[0])
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case len(keyed) == 0:
+			return []*Value{NewNull()}, nil
+		case min:
+			return []*Value{keyed[0].item}, nil
+		}
+		return []*Value{keyed[len(keyed)-1].item}, nil
+	}
+}
+
+func jqReverse(in *Value) (*Value, error) {
+	switch in.Type() {
+	case JSON_NULL:
+		return NewArray(), nil
+	case JSON_STRING:
+		runes := []rune(in.str)
+		for i, k := 0, len(runes)-1; i < k; i, k = i+1, k-1 {
+			runes[i], runes[k] = runes[k], runes[i]
+		}
+		return NewString(string(runes)), nil
+	}
+	items, err := jqArrayInput("reversed", in)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Value, len(items))
+	for i, item := range items {
+		out[len(items)-1-i] = item
+	}
+	return NewArray(out...), nil
+}
+
+func jqFirst(in *Value, args []jqNode) ([]*Value, error) {
+	out, err := args[0].eval(in)
+	if err != nil || len(out) == 0 {
+		return nil, err
+	}
+	return out[:1], nil
+}
+
+// 递归展开所有嵌套的数组
+func jqFlatten(in *Value) (*Value, error) {
+	items, err := jqArrayInput("flattened", in)
+	if err != nil {
+		return nil, err
+	}
+	var out []*Value
+	var add func(items []*Value) error
+	add = func(items []*Value) error {
+		for _, item := range items {
+			if item.Type() != JSON_ARRAY {
+				out = append(out, item)
+				continue
+			}
+			inner, err := item.Array()
+			if err != nil {
+				return err
+			}
+			if err := add(inner); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := add(items); err != nil {
+		return nil, err
+	}
+	return NewArray(out...), nil
+}
+
+func jqToNumber(in *Value) (*Value, error) {
+	switch in.Type() {
+	case JSON_NUMBER:
+		return in, nil
+	case JSON_STRING:
+		v, err := ParseWithOptions([]byte(in.str), ParseOptions{Strict: true})
+		if err == nil && v.Type() == JSON_NUMBER {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("%s cannot be parsed as a number", jqDescribe(in))
+}
+
+func jqToJSON(in *Value) (*Value, error) {
+	b, err := in.Encode()
+	if err != nil {
+		return nil, err
+	}
+	return NewString(string(b)), nil
+}
+
+func jqFromJSON(in *Value) (*Value, error) {
+	if in.Type() != JSON_STRING {
+		return nil, fmt.Errorf("%s cannot be parsed as JSON", jqDescribe(in))
+	}
+	return ParseWithOptions([]byte(in.str), ParseOptions{Strict: true})
+}
+
+func jqToEntries(in *Value) (*Value, error) {
+	if in.Type() != JSON_OBJECT {
+		return nil, fmt.Errorf("%s has no keys", jqDescribe(in))
+	}
+	var out []*Value
+	in.Range(func(k string, v *Value) bool {
+		entry := NewObject()
+		entry.setMember("key", NewString(k))
+		entry.setMember("value", v)
+		out = append(out, entry)
+		return true
+	})
+	return NewArray(out...), nil
+}
+
+// 键可以是 key k name, 值可以是 value v, 与 jq 相同
+func jqFromEntries(in *Value) (*Value, error) {
+	items, err := jqArrayInput("converted from entries", in)
+	if err != nil {
+		return nil, err
+	}
+	lookup := func(entry *Value, names ...string) *Value {
+		if entry.Type() != JSON_OBJECT || entry.load() != nil {
+			return nil
+		}
+		for _, name := range names {
+			if v, ok := entry.obj[name]; ok {
+				return v
+			}
+		}
+		return nil
+	}
+	obj := NewObject()
+	for _, entry := range items {
+		key := lookup(entry, "key", "k", "name", "Name", "Key", "K")
+		var k string
+		switch key.Type() {
+		case JSON_STRING:
+			k = key.str
+		case JSON_NUMBER, JSON_BOOLEAN, JSON_NULL:
+			k, _ = jqToString(key)
+		default:
+			return nil, fmt.Errorf("cannot use %s as object key", jqDescribe(entry))
+		}
+		v := lookup(entry, "value", "v", "Value", "V")
+		if v == nil {
+			v = NewNull()
+		}
+		obj.setMember(k, v)
+	}
+	return obj, nil
+}
+
+// to_entries | map(f) | from_entries
+func jqWithEntries(in *Value, args []jqNode) ([]*Value, error) {
+	entries, err := jqToEntries(in)
+	if err != nil {
+		return nil, err
+	}
+	mapped, err := jqMapArray(entries, args)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := jqFromEntries(mapped[0])
+	if err != nil {
+		return nil, err
+	}
+	return []*Value{obj}, nil
+}
+
+// null 为空字符串, 数字和布尔值转为文本
+func jqJoin(in, sep *Value) (*Value, error) {
+	items, err := jqArrayInput("joined", in)
+	if err != nil {
+		return nil, err
+	}
+	if sep.Type() != JSON_STRING {
+		return nil, fmt.Errorf("join: separator %s is not a string", jqDescribe(sep))
+	}
+	parts := make([]string, len(items))
+	for i, item := range items {
+		switch item.Type() {
+		case JSON_NULL:
+		case JSON_STRING, JSON_NUMBER, JSON_BOOLEAN:
+			parts[i], _ = jqToString(item)
+		default:
+			return nil, fmt.Errorf("cannot join with %s", jqDescribe(item))
+		}
+	}
+	return NewString(strings.Join(parts, sep.str)), nil
+}
+
+func jqSplitBuiltin(in, sep *Value) (*Value, error) {
+	if in.Type() != JSON_STRING || sep.Type() != JSON_STRING {
+		return nil, fmt.Errorf("split input and separator must be strings")
+	}
+	return jqSplit(in.str, sep.str), nil
+}
+
+func jqAffix(name string, fn func(s, affix string) bool) func(in, arg *Value) (*Value, error) {
+	return func(in, arg *Value) (*Value, error) {
+		if in.Type() != JSON_STRING || arg.Type() != JSON_STRING {
+			return nil, fmt.Errorf("%s() requires string inputs", name)
+		}
+		return NewBool(fn(in.str, arg.str)), nil
+	}
+}
+
+// 输入或参数不是字符串时原样返回输入
+func jqTrim(fn func(s, affix string) string) func(in, arg *Value) (*Value, error) {
+	return func(in, arg *Value) (*Value, error) {
+		if in.Type() != JSON_STRING || arg.Type() != JSON_STRING {
+			return in, nil
+		}
+		return NewString(fn(in.str, arg.str)), nil
+	}
+}
+
+// 只转换 ASCII 字母
+func jqASCIICase(upper bool) func(in *Value) (*Value, error) {
+	return func(in *Value) (*Value, error) {
+		if in.Type() != JSON_STRING {
+			return nil, fmt.Errorf("%s cannot be case-converted, expect a string", jqDescribe(in))
+		}
+		b := []byte(in.str)
+		for i, c := range b {
+			if upper && c >= 'a' && c <= 'z' || !upper && c >= 'A' && c <= 'Z' {
+				b[i] = c ^ 0x20
+			}
+		}
+		return NewString(string(b)), nil
+	}
+}
+
+// 字符串为子串, 数组的每个元素都被某个元素包含, 对象的每个成员都被同名成员包含
+func jqContains(a, b *Value) (bool, error) {
+	if a.Type() != b.Type() {
+		return false, fmt.Errorf("%s and %s cannot have their containment checked", jqDescribe(a), jqDescribe(b))
+	}
+	switch a.Type() {
+	case JSON_STRING:
+		return strings.Contains(a.str, b.str), nil
+	case JSON_ARRAY:
+		x, err := a.Array()
+		if err != nil {
+			return false, err
+		}
+		y, err := b.Array()
+		if err != nil {
+			return false, err
+		}
+	next:
+		for _, want := range y {
+			for _, have := range x {
+				if want.Type() != have.Type() {
+					continue
+				}
+				if ok, _ := jqContains(have, want); ok {
+					continue next
+				}
+			}
+			return false, nil
+		}
+		return true, nil
+	case JSON_OBJECT:
+		x, err := a.Map()
+		if err != nil {
+			return false, err
+		}
+		y, err := b.Map()
+		if err != nil {
+			return false, err
+		}
+		for k, want := range y {
+			have, ok := x[k]
+			if !ok {
+				return false, nil
+			}
+			if ok, err := jqContains(have, want); !ok || err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+	return jqCompare(a, b) == 0, nil
+}
+
+func jqTest(in, re *Value) (*Value, error) {
+	if in.Type() != JSON_STRING || re.Type() != JSON_STRING {
+		return nil, fmt.Errorf("%s cannot be matched, as it is not a string", jqDescribe(in))
+	}
+	r, err := regexp.Compile(re.str)
+	if err != nil {
+		return nil, fmt.Errorf("test: %v", err)
+	}
+	return NewBool(r.MatchString(in.str)), nil
+}
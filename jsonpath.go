@@ -0,0 +1,885 @@
+package yjson
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 按 JSONPath 表达式查询, 返回所有匹配的节点, 顺序与文档顺序一致. 支持:
+//
+//	$  @                 根节点, 当前节点 (过滤器中)
+//	.name  ['name']      对象成员
+//	.*  [*]              所有成员或元素
+//	..name  ..*  ..[0]   递归下降
+//	[0]  [-1]  [0,2]     数组下标, 负数从末尾计
+//	[1:3]  [::-1]        切片
+//	[?(@.price < 10)]    过滤器: == != < <= > >= =~ && || ! 和括号,
+//	                     单独的 @.isbn 表示存在; 函数 length count match search
+//
+// 没有匹配时返回空切片, 表达式非法时返回错误
+func (j *Value) Query(expr string) ([]*Value, error) {
+	q, err := parseJSONPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	return q.eval(j, j), nil
+}
+
+// 一段: 若干个选择器的并集, descendant 表示 .. 递归下降
+type jsonPathSegment struct {
+	selectors  []jsonPathSelector
+	descendant bool
+}
+
+type jsonPathSelector interface {
+	// 把 node 中被选中的子节点追加到 out
+	selectChildren(root, node *Value, out []*Value) []*Value
+}
+
+type jsonPathQuery struct {
+	fromRoot bool // $ 开头, 否则为过滤器中的 @
+	segments []jsonPathSegment
+}
+
+func (q *jsonPathQuery) eval(root, cur *Value) []*Value {
+	nodes := []*Value{cur}
+	if q.fromRoot {
+		nodes[0] = root
+	}
+	for _, seg := range q.segments {
+		next := make([]*Value, 0)
+		for _, n := range nodes {
+			if !seg.descendant {
+				next = seg.apply(root, n, next)
+				continue
+			}
+			n.Walk(func(_ string, d *Value) bool {
+				next = seg.apply(root, d, next)
+				return true
+			})
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+func (seg jsonPathSegment) apply(root, node *Value, out []*Value) []*Value {
+	for _, sel := range seg.selectors {
+		out = sel.selectChildren(root, node, out)
+	}
+	return out
+}
+
+// 只有一个段且只包含名字或下标选择器, 结果最多一个节点, 可以用于比较
+func (q *jsonPathQuery) singular() bool {
+	for _, seg := range q.segments {
+		if seg.descendant || len(seg.selectors) != 1 {
+			return false
+		}
+		switch seg.selectors[0].(type) {
+		case jsonPathName, jsonPathIndex:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+type jsonPathName string
+
+func (s jsonPathName) selectChildren(_, node *Value, out []*Value) []*Value {
+	if node.Type() != JSON_OBJECT || node.load() != nil {
+		return out
+	}
+	if v, ok := node.obj[string(s)]; ok {
+		out = append(out, v)
+	}
+	return out
+}
+
+type jsonPathWildcard struct{}
+
+func (jsonPathWildcard) selectChildren(_, node *Value, out []*Value) []*Value {
+	switch node.Type() {
+	case JSON_OBJECT:
+		node.Range(func(_ string, v *Value) bool {
+			out = append(out, v)
+			return true
+		})
+	case JSON_ARRAY:
+		arr, _ := node.Array()
+		out = append(out, arr...)
+	}
+	return out
+}
+
+type jsonPathIndex int
+
+func (s jsonPathIndex) selectChildren(_, node *Value, out []*Value) []*Value {
+	arr, err := node.Array()
+	if err != nil {
+		return out
+	}
+	i := int(s)
+	if i < 0 {
+		i += len(arr)
+	}
+	if i >= 0 && i < len(arr) {
+		out = append(out, arr[i])
+	}
+	return out
+}
+
+// [start:end:step], 省略的部分为 nil
+type jsonPathSlice struct {
+	start, end, step *int
+}
+
+func (s jsonPathSlice) selectChildren(_, node *Value, out []*Value) []*Value {
+	arr, err := node.Array()
+	if err != nil {
+		return out
+	}
+
+	n := len(arr)
+	step := 1
+	if s.step != nil {
+		step = *s.step
+	}
+	if step == 0 {
+		return out
+	}
+	// 与 Python 切片相同的边界规则
+	bound := func(p *int, def int) int {
+		if p == nil {
+			return def
+		}
+		i := *p
+		if i < 0 {
+			i += n
+		}
+		if step > 0 {
+			return clampInt(i, 0, n)
+		}
+		return clampInt(i, -1, n-1)
+	}
+	if step > 0 {
+		for i, end := bound(s.start, 0), bound(s.end, n); i < end; i += step {
+			out = append(out, arr[i])
+		}
+	} else {
+		for i, end := bound(s.start, n-1), bound(s.end, -1); i > end; i += step {
+			out = append(out, arr[i])
+		}
+	}
+	return out
+}
+
+func clampInt(i, lo, hi int) int {
+	if i < lo {
+		return lo
+	}
+	if i > hi {
+		return hi
+	}
+	return i
+}
+
+type jsonPathFilter struct {
+	cond jsonPathCond
+}
+
+func (s jsonPathFilter) selectChildren(root, node *Value, out []*Value) []*Value {
+	test := func(v *Value) {
+		if s.cond.test(root, v) {
+			out = append(out, v)
+		}
+	}
+	switch node.Type() {
+	case JSON_OBJECT:
+		node.Range(func(_ string, v *Value) bool {
+			test(v)
+			return true
+		})
+	case JSON_ARRAY:
+		arr, _ := node.Array()
+		for _, v := range arr {
+			test(v)
+		}
+	}
+	return out
+}
+
+// 过滤器中的逻辑表达式
+type jsonPathCond interface {
+	test(root, cur *Value) bool
+}
+
+type jsonPathOr []jsonPathCond
+
+func (c jsonPathOr) test(root, cur *Value) bool {
+	for _, sub := range c {
+		if sub.test(root, cur) {
+			return true
+		}
+	}
+	return false
+}
+
+type jsonPathAnd []jsonPathCond
+
+func (c jsonPathAnd) test(root, cur *Value) bool {
+	for _, sub := range c {
+		if !sub.test(root, cur) {
+			return false
+		}
+	}
+	return true
+}
+
+type jsonPathNot struct {
+	cond jsonPathCond
+}
+
+func (c jsonPathNot) test(root, cur *Value) bool {
+	return !c.cond.test(root, cur)
+}
+
+// 单独出现的查询: 有匹配即为真
+type jsonPathExists struct {
+	query *jsonPathQuery
+}
+
+func (c jsonPathExists) test(root, cur *Value) bool {
+	return len(c.query.eval(root, cur)) > 0
+}
+
+type jsonPathCompare struct {
+	op          string
+	left, right jsonPathOperand
+}
+
+func (c jsonPathCompare) test(root, cur *Value) bool {
+	l, r := c.left.value(root, cur), c.right.value(root, cur)
+	switch c.op {
+	case "==":
+		return equalValues(l, r)
+	case "!=":
+		return !equalValues(l, r)
+	case "<":
+		return jsonPathLess(l, r)
+	case ">":
+		return jsonPathLess(r, l)
+	case "<=":
+		return jsonPathLess(l, r) || equalValues(l, r)
+	case ">=":
+		return jsonPathLess(r, l) || equalValues(l, r)
+	}
+	return false
+}
+
+// 两边都不存在时相等, 数字按数值精确比较, 对象忽略键的顺序, 数组按顺序逐个比较
+func equalValues(l, r *Value) bool {
+	if !l.Exists() || !r.Exists() {
+		return !l.Exists() && !r.Exists()
+	}
+	if l.Type() != r.Type() {
+		return false
+	}
+	switch l.Type() {
+	case JSON_NULL:
+		return true
+	case JSON_NUMBER:
+		return equalNumbers(l, r)
+	case JSON_STRING:
+		return l.str == r.str
+	case JSON_BOOLEAN:
+		return l.b == r.b
+	}
+	if l.load() != nil || r.load() != nil {
+		return false
+	}
+	if l.Type() == JSON_ARRAY {
+		if len(l.arr) != len(r.arr) {
+			return false
+		}
+		for i := range l.arr {
+			if !equalValues(l.arr[i], r.arr[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	if len(l.obj) != len(r.obj) {
+		return false
+	}
+	for key, lv := range l.obj {
+		rv, ok := r.obj[key]
+		if !ok || !equalValues(lv, rv) {
+			return false
+		}
+	}
+	return true
+}
+
+// 只比较两个数字或两个字符串
+func jsonPathLess(l, r *Value) bool {
+	switch {
+	case l.Type() == JSON_NUMBER && r.Type() == JSON_NUMBER:
+		cmp, ok := compareNumbers(l, r)
+		return ok && cmp < 0
+	case l.Type() == JSON_STRING && r.Type() == JSON_STRING:
+		return l.str < r.str
+	}
+	return false
+}
+
+type jsonPathRegexp struct {
+	left jsonPathOperand
+	re   *regexp.Regexp
+}
+
+func (c jsonPathRegexp) test(root, cur *Value) bool {
+	s, err := c.left.value(root, cur).String()
+	return err == nil && c.re.MatchString(s)
+}
+
+// 比较的操作数, 结果不存在时返回 JSON_MISSING 的值
+type jsonPathOperand interface {
+	value(root, cur *Value) *Value
+}
+
+type jsonPathLiteral struct {
+	v *Value
+}
+
+func (o jsonPathLiteral) value(_, _ *Value) *Value {
+	return o.v
+}
+
+type jsonPathSingular struct {
+	query *jsonPathQuery
+}
+
+func (o jsonPathSingular) value(root, cur *Value) *Value {
+	if res := o.query.eval(root, cur); len(res) == 1 {
+		return res[0]
+	}
+	return missingValue()
+}
+
+// length(x): 字符串的字符数, 数组或对象的成员数; count(q): 匹配的节点数
+type jsonPathLength struct {
+	arg jsonPathOperand
+}
+
+func (o jsonPathLength) value(root, cur *Value) *Value {
+	v := o.arg.value(root, cur)
+	switch v.Type() {
+	case JSON_STRING:
+		return NewInt(int64(utf8.RuneCountInString(v.str)))
+	case JSON_ARRAY, JSON_OBJECT:
+		return NewInt(int64(v.Len()))
+	}
+	return missingValue()
+}
+
+type jsonPathCount struct {
+	query *jsonPathQuery
+}
+
+func (o jsonPathCount) value(root, cur *Value) *Value {
+	return NewInt(int64(len(o.query.eval(root, cur))))
+}
+
+type jsonPathParser struct {
+	expr string
+	i    int
+}
+
+func (p *jsonPathParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid jsonpath %q: %s at offset %d", p.expr, fmt.Sprintf(format, args...), p.i)
+}
+
+func (p *jsonPathParser) unexpected() error {
+	if p.i >= len(p.expr) {
+		return p.errorf("unexpected end of expression")
+	}
+	return p.errorf("unexpected %q", p.expr[p.i])
+}
+
+func parseJSONPath(expr string) (*jsonPathQuery, error) {
+	p := &jsonPathParser{expr: expr}
+	p.space()
+	if !p.consume("$") {
+		return nil, p.errorf("expect $")
+	}
+	q, err := p.query(true)
+	if err != nil {
+		return nil, err
+	}
+	p.space()
+	if p.i < len(p.expr) {
+		return nil, p.unexpected()
+	}
+	return q, nil
+}
+
+func (p *jsonPathParser) space() {
+	for p.i < len(p.expr) && (p.expr[p.i] == BLANK_SPACE || p.expr[p.i] == HORIZONTAL_TAB || p.expr[p.i] == LINE_BREAK || p.expr[p.i] == CARRIAGE_RETURN) {
+		p.i++
+	}
+}
+
+func (p *jsonPathParser) peek() byte {
+	if p.i < len(p.expr) {
+		return p.expr[p.i]
+	}
+	return 0
+}
+
+func (p *jsonPathParser) consume(s string) bool {
+	if strings.HasPrefix(p.expr[p.i:], s) {
+		p.i += len(s)
+		return true
+	}
+	return false
+}
+
+// $ 或 @ 之后的段
+func (p *jsonPathParser) query(fromRoot bool) (*jsonPathQuery, error) {
+	q := &jsonPathQuery{fromRoot: fromRoot, segments: make([]jsonPathSegment, 0)}
+	for {
+		var seg jsonPathSegment
+		var err error
+		switch {
+		case p.consume(".."):
+			seg.descendant = true
+			if p.peek() == LB {
+				seg.selectors, err = p.bracket()
+			} else {
+				seg.selectors, err = p.dotSelector()
+			}
+		case p.consume("."):
+			seg.selectors, err = p.dotSelector()
+		case p.peek() == LB:
+			seg.selectors, err = p.bracket()
+		default:
+			return q, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		q.segments = append(q.segments, seg)
+	}
+}
+
+func isJSONPathNameChar(r rune, first bool) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || (!first && unicode.IsDigit(r))
+}
+
+// . 或 .. 之后的 * 或名字
+func (p *jsonPathParser) dotSelector() ([]jsonPathSelector, error) {
+	if p.consume("*") {
+		return []jsonPathSelector{jsonPathWildcard{}}, nil
+	}
+	start := p.i
+	for p.i < len(p.expr) {
+		r, size := utf8.DecodeRuneInString(p.expr[p.i:])
+		if !isJSONPathNameChar(r, p.i == start) {
+			break
+		}
+		p.i += size
+	}
+	if p.i == start {
+		return nil, p.errorf("expect member name")
+	}
+	return []jsonPathSelector{jsonPathName(p.expr[start:p.i])}, nil
+}
+
+// [...] 中逗号分隔的选择器
+func (p *jsonPathParser) bracket() ([]jsonPathSelector, error) {
+	p.i++
+	selectors := make([]jsonPathSelector, 0, 1)
+	for {
+		p.space()
+		sel, err := p.bracketSelector()
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, sel)
+		p.space()
+		switch {
+		case p.consume(","):
+		case p.consume("]"):
+			return selectors, nil
+		default:
+			return nil, p.errorf("expect , or ]")
+		}
+	}
+}
+
+func (p *jsonPathParser) bracketSelector() (jsonPathSelector, error) {
+	switch c := p.peek(); {
+	case c == DQ || c == SQ:
+		s, err := p.stringLiteral()
+		if err != nil {
+			return nil, err
+		}
+		return jsonPathName(s), nil
+	case c == '*':
+		p.i++
+		return jsonPathWildcard{}, nil
+	case c == '?':
+		p.i++
+		cond, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		return jsonPathFilter{cond: cond}, nil
+	case c == MINUS || c == VALUE_SEPARATOR || isDigit(c):
+		return p.indexOrSlice()
+	}
+	return nil, p.unexpected()
+}
+
+func (p *jsonPathParser) indexOrSlice() (jsonPathSelector, error) {
+	parts := make([]*int, 0, 3)
+	for {
+		p.space()
+		var n *int
+		if c := p.peek(); c == MINUS || isDigit(c) {
+			v, err := p.integer()
+			if err != nil {
+				return nil, err
+			}
+			n = &v
+		}
+		parts = append(parts, n)
+		p.space()
+		if len(parts) == 3 || !p.consume(":") {
+			break
+		}
+	}
+	if len(parts) == 1 {
+		if parts[0] == nil {
+			return nil, p.errorf("expect index")
+		}
+		return jsonPathIndex(*parts[0]), nil
+	}
+	for len(parts) < 3 {
+		parts = append(parts, nil)
+	}
+	return jsonPathSlice{start: parts[0], end: parts[1], step: parts[2]}, nil
+}
+
+func (p *jsonPathParser) integer() (int, error) {
+	start := p.i
+	if p.peek() == MINUS {
+		p.i++
+	}
+	for isDigit(p.peek()) {
+		p.i++
+	}
+	n, err := strconv.Atoi(p.expr[start:p.i])
+	if err != nil {
+		p.i = start
+		return 0, p.errorf("bad integer")
+	}
+	return n, nil
+}
+
+// 单引号或双引号字符串, 双引号按 Go 的规则处理转义, 单引号中反斜杠转义下一个字符
+func (p *jsonPathParser) stringLiteral() (string, error) {
+	quote := p.expr[p.i]
+	start := p.i
+	buf := make([]byte, 0)
+	for p.i++; p.i < len(p.expr); p.i++ {
+		c := p.expr[p.i]
+		if c == quote {
+			p.i++
+			if quote == SQ {
+				return string(buf), nil
+			}
+			s, err := strconv.Unquote(p.expr[start:p.i])
+			if err != nil {
+				p.i = start
+				return "", p.errorf("bad string literal")
+			}
+			return s, nil
+		}
+		if c == BACKSLASH && p.i+1 < len(p.expr) {
+			p.i++
+			c = p.expr[p.i]
+		}
+		buf = append(buf, c)
+	}
+	p.i = start
+	return "", p.errorf("unterminated string")
+}
+
+func (p *jsonPathParser) or() (jsonPathCond, error) {
+	cond, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	conds := jsonPathOr{cond}
+	for p.space(); p.consume("||"); p.space() {
+		cond, err = p.and()
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, cond)
+	}
+	if len(conds) == 1 {
+		return conds[0], nil
+	}
+	return conds, nil
+}
+
+func (p *jsonPathParser) and() (jsonPathCond, error) {
+	cond, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	conds := jsonPathAnd{cond}
+	for p.space(); p.consume("&&"); p.space() {
+		cond, err = p.unary()
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, cond)
+	}
+	if len(conds) == 1 {
+		return conds[0], nil
+	}
+	return conds, nil
+}
+
+func (p *jsonPathParser) unary() (jsonPathCond, error) {
+	p.space()
+	if p.peek() == '!' && !strings.HasPrefix(p.expr[p.i:], "!=") {
+		p.i++
+		cond, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return jsonPathNot{cond: cond}, nil
+	}
+	if p.consume("(") {
+		cond, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		p.space()
+		if !p.consume(")") {
+			return nil, p.errorf("expect )")
+		}
+		return cond, nil
+	}
+	return p.comparison()
+}
+
+var jsonPathOperators = []string{"==", "!=", "<=", ">=", "<", ">", "=~"}
+
+func (p *jsonPathParser) comparison() (jsonPathCond, error) {
+	start := p.i
+	left, test, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	p.space()
+
+	op := ""
+	for _, candidate := range jsonPathOperators {
+		if p.consume(candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		if test == nil {
+			p.i = start
+			return nil, p.errorf("expect comparison")
+		}
+		return test, nil
+	}
+
+	p.space()
+	if op == "=~" {
+		re, err := p.regexpLiteral()
+		if err != nil {
+			return nil, err
+		}
+		return jsonPathRegexp{left: left, re: re}, nil
+	}
+	right, _, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	if left == nil || right == nil {
+		p.i = start
+		return nil, p.errorf("operand of %s is not a single value", op)
+	}
+	return jsonPathCompare{op: op, left: left, right: right}, nil
+}
+
+// 返回用于比较的操作数和单独出现时的逻辑含义, 不能用于该场合时为 nil
+func (p *jsonPathParser) operand() (jsonPathOperand, jsonPathCond, error) {
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		p.i++
+		q, err := p.query(c == '$')
+		if err != nil {
+			return nil, nil, err
+		}
+		if q.singular() {
+			return jsonPathSingular{query: q}, jsonPathExists{query: q}, nil
+		}
+		return nil, jsonPathExists{query: q}, nil
+	case c == DQ || c == SQ:
+		s, err := p.stringLiteral()
+		if err != nil {
+			return nil, nil, err
+		}
+		return jsonPathLiteral{v: NewString(s)}, nil, nil
+	case c == MINUS || isDigit(c):
+		start := p.i
+		for p.i < len(p.expr) && strings.IndexByte("+-.eE0123456789", p.expr[p.i]) >= 0 {
+			p.i++
+		}
+		v, err := ParseWithOptions([]byte(p.expr[start:p.i]), ParseOptions{Strict: true})
+		if err != nil {
+			p.i = start
+			return nil, nil, p.errorf("bad number")
+		}
+		return jsonPathLiteral{v: v}, nil, nil
+	case p.consume(TRUE):
+		return jsonPathLiteral{v: NewBool(true)}, nil, nil
+	case p.consume(FALSE):
+		return jsonPathLiteral{v: NewBool(false)}, nil, nil
+	case p.consume(NULL):
+		return jsonPathLiteral{v: NewNull()}, nil, nil
+	}
+	return p.function()
+}
+
+func (p *jsonPathParser) function() (jsonPathOperand, jsonPathCond, error) {
+	start := p.i
+	for p.i < len(p.expr) && p.expr[p.i] >= 'a' && p.expr[p.i] <= 'z' {
+		p.i++
+	}
+	name := p.expr[start:p.i]
+	p.space()
+	if name == "" || !p.consume("(") {
+		p.i = start
+		return nil, nil, p.unexpected()
+	}
+	p.space()
+
+	var operand jsonPathOperand
+	var cond jsonPathCond
+	switch name {
+	case "length":
+		arg, _, err := p.operand()
+		if err != nil {
+			return nil, nil, err
+		}
+		if arg == nil {
+			return nil, nil, p.errorf("length() expects a single value")
+		}
+		operand = jsonPathLength{arg: arg}
+	case "count":
+		c := p.peek()
+		if c != '@' && c != '$' {
+			return nil, nil, p.errorf("count() expects a query")
+		}
+		p.i++
+		q, err := p.query(c == '$')
+		if err != nil {
+			return nil, nil, err
+		}
+		operand = jsonPathCount{query: q}
+	case "match", "search":
+		arg, _, err := p.operand()
+		if err != nil {
+			return nil, nil, err
+		}
+		if arg == nil {
+			return nil, nil, p.errorf("%s() expects a single value", name)
+		}
+		p.space()
+		if !p.consume(",") {
+			return nil, nil, p.errorf("expect ,")
+		}
+		p.space()
+		pattern, _, err := p.operand()
+		if err != nil {
+			return nil, nil, err
+		}
+		lit, ok := pattern.(jsonPathLiteral)
+		if !ok || lit.v.Type() != JSON_STRING {
+			return nil, nil, p.errorf("%s() expects a string pattern", name)
+		}
+		expr := lit.v.str
+		if name == "match" {
+			expr = "^(?:" + expr + ")$"
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, nil, p.errorf("bad pattern: %v", err)
+		}
+		cond = jsonPathRegexp{left: arg, re: re}
+	default:
+		p.i = start
+		return nil, nil, p.errorf("unknown function %s", name)
+	}
+
+	p.space()
+	if !p.consume(")") {
+		return nil, nil, p.errorf("expect )")
+	}
+	return operand, cond, nil
+}
+
+// =~ 右边的 /pattern/flags 或字符串
+func (p *jsonPathParser) regexpLiteral() (*regexp.Regexp, error) {
+	start := p.i
+	var pattern string
+	switch p.peek() {
+	case SLASH:
+		end := p.i + 1
+		for end < len(p.expr) && p.expr[end] != SLASH {
+			if p.expr[end] == BACKSLASH {
+				end++
+			}
+			end++
+		}
+		if end >= len(p.expr) {
+			return nil, p.errorf("unterminated regexp")
+		}
+		pattern = p.expr[p.i+1 : end]
+		p.i = end + 1
+		if p.consume("i") {
+			pattern = "(?i)" + pattern
+		}
+	case DQ, SQ:
+		s, err := p.stringLiteral()
+		if err != nil {
+			return nil, err
+		}
+		pattern = s
+	default:
+		return nil, p.errorf("expect regexp")
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		p.i = start
+		return nil, p.errorf("bad regexp: %v", err)
+	}
+	return re, nil
+}
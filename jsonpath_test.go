@@ -0,0 +1,193 @@
+package yjson
+
+import (
+	"strings"
+	"testing"
+)
+
+// RFC 9535 1.5 的示例文档
+const jsonPathStore = `{"store": {
+	"book": [
+		{"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+		{"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+		{"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99},
+		{"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99}
+	],
+	"bicycle": {"color": "red", "price": 399}
+}}`
+
+// 查询结果编码为数组, 便于比较
+func queryString(t *testing.T, v *Value, expr string) (string, error) {
+	t.Helper()
+	res, err := v.Query(expr)
+	if err != nil {
+		return "", err
+	}
+	return mustEncode(t, NewArray(res...)), nil
+}
+
+func TestQueryStore(t *testing.T) {
+	store := mustParse(t, jsonPathStore)
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{`$.store.book[*].author`, `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`},
+		{`$..author`, `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`},
+		{`$.store.*.color`, `["red"]`},
+		{`$.store..price`, `[8.95,12.99,8.99,22.99,399]`},
+		{`$..book[2].title`, `["Moby Dick"]`},
+		{`$..book[-1].title`, `["The Lord of the Rings"]`},
+		{`$..book[0,1].title`, `["Sayings of the Century","Sword of Honour"]`},
+		{`$..book[:2].title`, `["Sayings of the Century","Sword of Honour"]`},
+		{`$..book[?@.isbn].title`, `["Moby Dick","The Lord of the Rings"]`},
+		{`$..book[?(@.price < 10)].title`, `["Sayings of the Century","Moby Dick"]`},
+		{`$..book[?@.price<10 && @.category=='fiction'].title`, `["Moby Dick"]`},
+		{`$..book[?@.price > $.store.bicycle.price]`, `[]`},
+		{`$..book[?!@.isbn].price`, `[8.95,12.99]`},
+		{`$..book[?(@.author =~ /.*REES/i)].title`, `["Sayings of the Century"]`},
+		{`$..book[?match(@.author, "J.*")].title`, `["The Lord of the Rings"]`},
+		{`$..book[?search(@.title, "of")].title`, `["Sayings of the Century","Sword of Honour","The Lord of the Rings"]`},
+		{`$..book[?length(@.title) == 9].title`, `["Moby Dick"]`},
+		{`$.store[?count(@.*) > 2].*.title`, `["Sayings of the Century","Sword of Honour","Moby Dick","The Lord of the Rings"]`},
+		{`$.store[?count(@[*]) == 2].color`, `["red"]`},
+		{`$['store']["bicycle"].color`, `["red"]`},
+		{`$.store.book.length`, `[]`},
+		{`$`, `[` + mustEncode(t, store) + `]`},
+	}
+	for _, tt := range tests {
+		got, err := queryString(t, store, tt.expr)
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s:\ngot  %s\nwant %s", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestQuerySelectors(t *testing.T) {
+	tests := []struct {
+		input string
+		expr  string
+		want  string
+	}{
+		// 切片, 规则同 Python
+		{`[0,1,2,3,4,5,6]`, `$[1:3]`, `[1,2]`},
+		{`[0,1,2,3,4,5,6]`, `$[5:]`, `[5,6]`},
+		{`[0,1,2,3,4,5,6]`, `$[1:5:2]`, `[1,3]`},
+		{`[0,1,2,3,4,5,6]`, `$[5:1:-2]`, `[5,3]`},
+		{`[0,1,2,3,4,5,6]`, `$[::-1]`, `[6,5,4,3,2,1,0]`},
+		{`[0,1,2,3,4,5,6]`, `$[-2:]`, `[5,6]`},
+		{`[0,1,2,3,4,5,6]`, `$[-100:100]`, `[0,1,2,3,4,5,6]`},
+		{`[0,1,2,3,4,5,6]`, `$[::0]`, `[]`},
+		{`[0,1,2]`, `$[3]`, `[]`},
+		{`[0,1,2]`, `$[-4]`, `[]`},
+		{`[0,1,2]`, `$[0, 0, -1]`, `[0,0,2]`},
+		{`{"a": 1}`, `$[0]`, `[]`},
+		{`[1]`, `$.a`, `[]`},
+		// 名字
+		{`{"a.b": 1, "c d": 2, "'": 3, "é": 4}`, `$['a.b']`, `[1]`},
+		{`{"a.b": 1, "c d": 2, "'": 3, "é": 4}`, `$["c d"]`, `[2]`},
+		{`{"a.b": 1, "c d": 2, "'": 3, "é": 4}`, `$['\'']`, `[3]`},
+		{`{"a.b": 1, "c d": 2, "'": 3, "é": 4}`, `$.é`, `[4]`},
+		{`{"a.b": 1, "c d": 2, "'": 3, "é": 4}`, `$["\u00e9"]`, `[4]`},
+		{`{"a": 1, "b": 2}`, `$['b', 'a', 'x']`, `[2,1]`},
+		// 通配符按键的顺序
+		{`{"z": 1, "a": 2}`, `$.*`, `[1,2]`},
+		{`[[1], {"k": 2}, 3]`, `$[*][*]`, `[1,2]`},
+		// 递归下降包括节点本身, 先访问父节点
+		{`{"a": {"a": 1}, "b": [{"a": 2}]}`, `$..a`, `[{"a":1},1,2]`},
+		{`[[1, [2]], 3]`, `$..[0]`, `[[1,[2]],1,2]`},
+		{`{"a": [1, {"b": 2}]}`, `$..*`, `[[1,{"b":2}],1,{"b":2},2]`},
+	}
+	for _, tt := range tests {
+		got, err := queryString(t, mustParse(t, tt.input), tt.expr)
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s on %s: got %s, want %s", tt.expr, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestQueryFilters(t *testing.T) {
+	input := `[{"a": 1, "b": "x"}, {"a": 2.0, "b": "y", "c": null}, {"a": [1, 2]}, {"a": {"k": true}}, {"b": "z"}, 5, "s"]`
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{`$[?@.a == 2]`, `[{"a":2,"b":"y","c":null}]`},
+		{`$[?@.a != 1]`, `[{"a":2,"b":"y","c":null},{"a":[1,2]},{"a":{"k":true}},{"b":"z"},5,"s"]`},
+		{`$[?@.a >= 1]`, `[{"a":1,"b":"x"},{"a":2,"b":"y","c":null}]`},
+		{`$[?@.b < "y"]`, `[{"a":1,"b":"x"}]`},
+		{`$[?@.a == [1, 2]]`, "error: "},
+		{`$[?@.a == @.a]`, `[{"a":1,"b":"x"},{"a":2,"b":"y","c":null},{"a":[1,2]},{"a":{"k":true}},{"b":"z"},5,"s"]`},
+		{`$[?@.c == null]`, `[{"a":2,"b":"y","c":null}]`},
+		{`$[?@.c]`, `[{"a":2,"b":"y","c":null}]`},
+		{`$[?@.a.k == true]`, `[{"a":{"k":true}}]`},
+		{`$[?@ == 5]`, `[5]`},
+		{`$[?@ == 's' || @ == 5]`, `[5,"s"]`},
+		{`$[?(@.a == 1 || @.a == 2) && @.b == 'y']`, `[{"a":2,"b":"y","c":null}]`},
+		{`$[?!(@.a || @.b)]`, `[5,"s"]`},
+		{`$[?length(@.a) == 2]`, `[{"a":[1,2]}]`},
+		{`$[?length(@) == 1]`, `[{"a":[1,2]},{"a":{"k":true}},{"b":"z"},"s"]`},
+		{`$[?count(@.*) == 3]`, `[{"a":2,"b":"y","c":null}]`},
+		{`$[?@.a[1] == 2]`, `[{"a":[1,2]}]`},
+		{`$[?@.a > "1"]`, `[]`},
+		{`$[?@.b =~ "^[xy]$"]`, `[{"a":1,"b":"x"},{"a":2,"b":"y","c":null}]`},
+		{`$[?@.a == 1e0]`, `[{"a":1,"b":"x"}]`},
+		{`$[?@.a == -1]`, `[]`},
+	}
+	v := mustParse(t, input)
+	for _, tt := range tests {
+		got, err := queryString(t, v, tt.expr)
+		if msg, ok := strings.CutPrefix(tt.want, "error: "); ok {
+			if err == nil || !strings.Contains(err.Error(), msg) {
+				t.Errorf("%s: got error %v, want %q", tt.expr, err, msg)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s:\ngot  %s\nwant %s", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{``, `invalid jsonpath "": expect $ at offset 0`},
+		{`store`, `invalid jsonpath "store": expect $ at offset 0`},
+		{`$.`, `invalid jsonpath "$.": expect member name at offset 2`},
+		{`$[`, `invalid jsonpath "$[": unexpected end of expression at offset 2`},
+		{`$[1`, `invalid jsonpath "$[1": expect , or ] at offset 3`},
+		{`$['a`, `invalid jsonpath "$['a": unterminated string at offset 2`},
+		{`$[:`, `invalid jsonpath "$[:": expect , or ] at offset 3`},
+		{`$[?@.a ==]`, `invalid jsonpath "$[?@.a ==]": unexpected ']' at offset 9`},
+		{`$[?@.a == 1`, `invalid jsonpath "$[?@.a == 1": expect , or ] at offset 11`},
+		{`$[?@.* == 1]`, `invalid jsonpath "$[?@.* == 1]": operand of == is not a single value at offset 3`},
+		{`$[?foo(@)]`, `invalid jsonpath "$[?foo(@)]": unknown function foo at offset 3`},
+		{`$[?@.a =~ /(/]`, `invalid jsonpath "$[?@.a =~ /(/]": bad regexp: error parsing regexp: missing closing ): ` + "`(`" + ` at offset 10`},
+		{`$[?match(@.a, @.b)]`, `invalid jsonpath "$[?match(@.a, @.b)]": match() expects a string pattern at offset 17`},
+		{`$[?count(1) == 1]`, `invalid jsonpath "$[?count(1) == 1]": count() expects a query at offset 9`},
+		{`$[?1]`, `invalid jsonpath "$[?1]": expect comparison at offset 3`},
+		{`$ x`, `invalid jsonpath "$ x": unexpected 'x' at offset 2`},
+	}
+	v := mustParse(t, `{}`)
+	for _, tt := range tests {
+		_, err := v.Query(tt.expr)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s:\ngot  %v\nwant %s", tt.expr, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package yjson
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// RFC 7464 JSON 文本序列 (application/json-seq) 中每条记录前的分隔符
+const RECORD_SEPARATOR = 0x1E
+
+// JSON 文本序列中某条记录的错误
+type SeqError struct {
+	Record int // 从 1 开始
+	Err    error
+}
+
+func (e *SeqError) Error() string {
+	return fmt.Sprintf("record %d: %v", e.Record, e.Err)
+}
+
+func (e *SeqError) Unwrap() error {
+	return e.Err
+}
+
+// 读取 RS 分隔的文档. 某条记录损坏时返回 *SeqError, 之后从下一个 RS 继续读取.
+// 第一个 RS 之前的数据被忽略
+type SeqDecoder struct {
+	r      *bufio.Reader
+	opts   ParseOptions
+	record int
+	synced bool // 已经读到过 RS
+}
+
+func NewSeqDecoder(r io.Reader) *SeqDecoder {
+	return &SeqDecoder{r: bufio.NewReader(r)}
+}
+
+func (dec *SeqDecoder) SetOptions(opts ParseOptions) {
+	dec.opts = opts
+}
+
+// 读取下一条记录, 输入结束时返回 io.EOF
+func (dec *SeqDecoder) Decode(v *Value) error {
+	if !dec.synced {
+		if _, err := dec.r.ReadBytes(RECORD_SEPARATOR); err != nil {
+			return err
+		}
+		dec.synced = true
+	}
+
+	for {
+		rec, err := dec.r.ReadBytes(RECORD_SEPARATOR)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if len(rec) > 0 && rec[len(rec)-1] == RECORD_SEPARATOR {
+			rec = rec[:len(rec)-1]
+		}
+
+		text := bytes.TrimSpace(rec)
+		if len(text) == 0 {
+			// 连续的 RS 或结尾的空记录
+			if err == io.EOF {
+				return io.EOF
+			}
+			continue
+		}
+		dec.record++
+		j, perr := parseDocument(nil, text, dec.opts)
+		if perr == nil && truncatedScalar(j, rec) {
+			perr = fmt.Errorf("truncated %s", j.Type())
+		}
+		if perr != nil {
+			return &SeqError{Record: dec.record, Err: perr}
+		}
+		*v = *j
+		return nil
+	}
+}
+
+// RFC 7464 2.4: 顶层的数字和字面量之后没有空白时可能被截断了
+func truncatedScalar(j *Value, rec []byte) bool {
+	switch j.Type() {
+	case JSON_OBJECT, JSON_ARRAY, JSON_STRING:
+		return false
+	}
+	last := rec[len(rec)-1]
+	return last != BLANK_SPACE && last != HORIZONTAL_TAB && last != LINE_BREAK && last != CARRIAGE_RETURN
+}
+
+// 每条记录输出为 RS + 紧凑的文档 + LF, 忽略 Prefix 和 Indent
+type SeqEncoder struct {
+	w    io.Writer
+	opts EncodeOptions
+	buf  []byte
+}
+
+func NewSeqEncoder(w io.Writer) *SeqEncoder {
+	return &SeqEncoder{w: w}
+}
+
+func (enc *SeqEncoder) SetOptions(opts EncodeOptions) {
+	opts.Prefix, opts.Indent = "", ""
+	enc.opts = opts
+}
+
+func (enc *SeqEncoder) Encode(v *Value) error {
+	dst := append(enc.buf[:0], RECORD_SEPARATOR)
+	dst, err := AppendJSONWithOptions(dst, v, enc.opts)
+	if err != nil {
+		return err
+	}
+	dst = append(dst, LINE_BREAK)
+	_, err = enc.w.Write(dst)
+	enc.buf = dst[:0]
+	return err
+}
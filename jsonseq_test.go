@@ -0,0 +1,115 @@
+package yjson
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// 读取所有记录, 错误记为 "error@记录号"
+func seqRecords(t *testing.T, input string) []string {
+	t.Helper()
+	dec := NewSeqDecoder(iotest.OneByteReader(strings.NewReader(input)))
+	var out []string
+	for {
+		var v Value
+		err := dec.Decode(&v)
+		if err == io.EOF {
+			return out
+		}
+		var se *SeqError
+		if errors.As(err, &se) {
+			out = append(out, "error@"+strings.SplitN(se.Error(), ":", 2)[0])
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, mustEncode(t, &v))
+	}
+}
+
+func TestSeqDecoder(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"basic", "\x1e{\"a\":1}\n\x1e[2]\n", `{"a":1} [2]`},
+		{"no newline", "\x1e\"x\"\x1e{}", `"x" {}`},
+		{"multi-line record", "\x1e{\n  \"a\": [1,\n 2]\n}\n", `{"a":[1,2]}`},
+		{"leading garbage", "junk\n\x1e1\n", `1`},
+		{"no separator", "{\"a\":1}\n", ``},
+		{"empty records", "\x1e\x1e\n\x1e true\n\x1e\n", `true`},
+		{"empty", "", ``},
+		{"scalars with whitespace", "\x1e1\n\x1enull \x1efalse\t", `1 null false`},
+		{"truncated number", "\x1e123\x1e\"ok\"\n", `error@record 1 "ok"`},
+		{"truncated literal", "\x1etrue", `error@record 1`},
+		{"bad record continues", "\x1e{\"a\":\n\x1e[1]\n\x1e{]\n\x1e2\n", `error@record 1 [1] error@record 3 2`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Join(seqRecords(t, tt.input), " ")
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeqErrors(t *testing.T) {
+	dec := NewSeqDecoder(strings.NewReader("\x1e42"))
+	var v Value
+	err := dec.Decode(&v)
+	if err == nil || err.Error() != "record 1: truncated number" {
+		t.Errorf("got %v", err)
+	}
+	if !errors.Is(&SeqError{Record: 1, Err: io.ErrUnexpectedEOF}, io.ErrUnexpectedEOF) {
+		t.Errorf("SeqError does not unwrap")
+	}
+
+	// 读取错误原样返回
+	dec = NewSeqDecoder(iotest.ErrReader(iotest.ErrTimeout))
+	if err := dec.Decode(&v); err != iotest.ErrTimeout {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestSeqDecoderOptions(t *testing.T) {
+	dec := NewSeqDecoder(strings.NewReader("\x1e{a: 1, /* x */}\n"))
+	dec.SetOptions(ParseOptions{JSON5: true})
+	var v Value
+	if err := dec.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustEncode(t, &v); got != `{"a":1}` {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestSeqEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewSeqEncoder(&buf)
+	enc.SetOptions(EncodeOptions{Indent: "\t", SortKeys: true})
+	for _, s := range []string{`{"b": 1, "a": 2}`, `3`, `"s"`} {
+		if err := enc.Encode(mustParse(t, s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "\x1e{\"a\":2,\"b\":1}\n\x1e3\n\x1e\"s\"\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	// 结尾的换行使数字不会被当作截断
+	if got := strings.Join(seqRecords(t, buf.String()), " "); got != `{"a":2,"b":1} 3 "s"` {
+		t.Errorf("read back: got %s", got)
+	}
+
+	if err := NewSeqEncoder(failingWriter{}).Encode(NewInt(1)); err == nil {
+		t.Errorf("failing writer: expected an error")
+	}
+}
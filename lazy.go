@@ -0,0 +1,120 @@
+package yjson
+
+import (
+	"fmt"
+	"io"
+)
+
+// Lazy 模式下跳过一个对象或数组, 只检查括号配对, 字符串和注释的边界, 把源文本
+// 保存在 raw 中, 第一次访问时再展开
+func (p *Parser) skipContainer(j *Value) error {
+	start := p.i
+	stack := p.lazyStack[:0]
+	defer func() { p.lazyStack = stack[:0] }()
+
+	for p.i < p.len {
+		b := p.buf[p.i]
+		switch {
+		case b == OB || b == LB:
+			if p.depth+len(stack)+1 > p.opts.MaxDepth {
+				return fmt.Errorf("exceeded max depth %d at offset %d", p.opts.MaxDepth, p.i)
+			}
+			stack = append(stack, b+2) // '{'+2 == '}', '['+2 == ']'
+		case b == CB || b == RB:
+			if stack[len(stack)-1] != b {
+				return fmt.Errorf("unexpected %c at offset %d", b, p.i)
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				p.i++
+				j.valueType = JSON_OBJECT
+				if b == RB {
+					j.valueType = JSON_ARRAY
+				}
+				j.raw = p.buf[start:p.i]
+				j.lazy = p.lazyOpts
+				return nil
+			}
+		case b == DQ || (b == SQ && p.opts.JSON5):
+			if err := p.skipQuoted(b); err != nil {
+				return err
+			}
+			continue
+		case b == SLASH && p.opts.AllowComments:
+			if err := p.absorbComment(); err != nil {
+				return err
+			}
+			continue
+		}
+		p.i++
+	}
+	return io.EOF
+}
+
+// 跳过引号包围的字符串, 转义只识别反斜杠本身, 内容在展开时再检查
+func (p *Parser) skipQuoted(quote byte) error {
+	for p.i++; p.i < p.len; p.i++ {
+		p.i = stringRunEnd(p.buf[:p.len], p.i, quote)
+		if p.i >= p.len {
+			break
+		}
+		switch p.buf[p.i] {
+		case BACKSLASH:
+			p.i++
+		case quote:
+			p.i++
+			return nil
+		}
+	}
+	return io.EOF
+}
+
+// 展开 Lazy 模式下还没有解析的对象或数组, 子树中的对象和数组仍然延迟解析
+func (j *Value) load() error {
+	if j == nil || j.lazy == nil {
+		return nil
+	}
+	if j.lazyErr != nil {
+		return j.lazyErr
+	}
+
+	p := getParser(nil, j.raw, *j.lazy)
+	defer putParser(p)
+	p.lazyOpts = j.lazy
+
+	var v Value
+	if err := p.handle(&v); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		j.lazyErr = err
+		return err
+	}
+	j.obj, j.arr, j.keys = v.obj, v.arr, v.keys
+	j.raw, j.lazy = nil, nil
+	return nil
+}
+
+// 展开 Lazy 模式下所有还没有解析的子树, 返回遇到的第一个语法错误.
+// 非 Lazy 模式解析的值直接返回 nil
+func (j *Value) Expand() error {
+	if err := j.load(); err != nil {
+		return err
+	}
+
+	switch j.Type() {
+	case JSON_ARRAY:
+		for _, v := range j.arr {
+			if err := v.Expand(); err != nil {
+				return err
+			}
+		}
+	case JSON_OBJECT:
+		for _, k := range j.keys {
+			if err := j.obj[k].Expand(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
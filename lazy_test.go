@@ -0,0 +1,145 @@
+package yjson
+
+import (
+	"strings"
+	"testing"
+)
+
+// 对 Lazy 和普通模式解析的结果做相同的操作, 结果应该相同
+func TestLazyMatchesEager(t *testing.T) {
+	input := `{"a": {"b": [1, {"c": "x\"]}"}], "d": null}, "e": [[], {}], "f": 1.5, "g": "s"}`
+	tests := []struct {
+		name string
+		fn   func(v *Value) string
+	}{
+		{"encode", func(v *Value) string { return mustEncode(t, v) }},
+		{"indent", func(v *Value) string {
+			out, _ := v.EncodeWithOptions(EncodeOptions{Indent: " ", SortKeys: true})
+			return string(out)
+		}},
+		{"get", func(v *Value) string { return mustEncode(t, v.Get("a.b[1].c")) }},
+		{"keys", func(v *Value) string { return strings.Join(v.Get("a").Keys(), ",") }},
+		{"len", func(v *Value) string { return mustEncode(t, NewInt(int64(v.Get("a.b").Len()+v.Get("e").Len()))) }},
+		{"set", func(v *Value) string {
+			v.Set("a.b[0]", NewString("new"))
+			return mustEncode(t, v)
+		}},
+		{"delete", func(v *Value) string {
+			v.Delete("a.d")
+			return mustEncode(t, v)
+		}},
+		{"copy", func(v *Value) string {
+			c := copyValue(v)
+			c.Set("a.b[1].c", NewInt(0))
+			return mustEncode(t, v) + mustEncode(t, c)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eager := mustParse(t, input)
+			lazy, err := ParseWithOptions([]byte(input), ParseOptions{Lazy: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := tt.fn(lazy), tt.fn(eager); got != want {
+				t.Errorf("got %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestLazyExpand(t *testing.T) {
+	tests := []struct {
+		input string
+		opts  ParseOptions
+		parse string // Parse 的错误, 空表示成功
+		want  string // Expand 的结果或错误
+	}{
+		{`{"a": [1, {"b": 2}]}`, ParseOptions{}, "", `{"a":[1,{"b":2}]}`},
+		{`[{"a": [1, x]}]`, ParseOptions{}, "", "error: "},
+		{`{"a": {"b": 1,}}`, ParseOptions{}, "", "error: "},
+		{`{"a": {"b": 1,}}`, ParseOptions{AllowTrailingCommas: true}, "", `{"a":{"b":1}}`},
+		{"{\"a\": [1, /* ] */ 2]}", ParseOptions{AllowComments: true}, "", `{"a":[1,2]}`},
+		{`{"a": {'b': '}'}}`, ParseOptions{JSON5: true}, "", `{"a":{"b":"}"}}`},
+		// 括号配对在解析时就检查
+		{`{"a": [1, 2}}`, ParseOptions{}, "unexpected } at offset 11", ""},
+		{`{"a": [1, 2`, ParseOptions{}, "EOF", ""},
+		{`{"a": ["x]`, ParseOptions{}, "EOF", ""},
+	}
+	for _, tt := range tests {
+		tt.opts.Lazy = true
+		v, err := ParseWithOptions([]byte(tt.input), tt.opts)
+		if tt.parse != "" {
+			if err == nil || err.Error() != tt.parse {
+				t.Errorf("%s: got parse error %v, want %s", tt.input, err, tt.parse)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tt.input, err)
+		}
+		err = v.Expand()
+		if strings.HasPrefix(tt.want, "error: ") {
+			if err == nil {
+				t.Errorf("%s: expected an Expand error", tt.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tt.input, err)
+		}
+		if got := mustEncode(t, v); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.input, got, tt.want)
+		}
+	}
+
+	// 非 Lazy 模式的值 Expand 直接返回 nil
+	if err := mustParse(t, `[1]`).Expand(); err != nil {
+		t.Errorf("eager: got %v", err)
+	}
+}
+
+// 只展开访问到的子树
+func TestLazyOnlyExpandsAccessed(t *testing.T) {
+	v, err := ParseWithOptions([]byte(`{"a": {"x": 1}, "b": [1, 2], "c": {"d": {"e": 3}}}`), ParseOptions{Lazy: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.lazy != nil {
+		t.Fatalf("root is lazy")
+	}
+	v.Get("c.d.e")
+	if v.obj["a"].lazy == nil || v.obj["b"].lazy == nil {
+		t.Errorf("unaccessed subtrees were expanded")
+	}
+	if v.obj["c"].lazy != nil || v.obj["c"].obj["d"].lazy != nil {
+		t.Errorf("accessed subtrees are still lazy")
+	}
+}
+
+// 展开失败的错误记录在节点上, 之后的访问返回同一个错误
+func TestLazyLoadError(t *testing.T) {
+	v, err := ParseWithOptions([]byte(`{"a": [1,,2], "b": {"c": x}}`), ParseOptions{Lazy: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := v.Get("a")
+	_, err1 := a.Array()
+	_, err2 := a.Array()
+	if err1 == nil || err2 == nil || err1.Error() != err2.Error() {
+		t.Errorf("a: got %v then %v, want the same error twice", err1, err2)
+	}
+	if got := a.Interface(); got != nil {
+		t.Errorf("a: Interface got %v, want nil", got)
+	}
+	b := v.Get("b")
+	if got := b.Interface(); got != nil {
+		t.Errorf("b: Interface got %v, want nil", got)
+	}
+	if _, err := b.Map(); err == nil {
+		t.Error("b: expected a Map error after Interface")
+	}
+	if err := v.Expand(); err == nil {
+		t.Error("expected an Expand error")
+	}
+}
@@ -1,6 +0,0 @@
-package main
-
-
-func main(){
-
-}
\ No newline at end of file
@@ -0,0 +1,108 @@
+package yjson
+
+import (
+	"encoding/json"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type upperKey string
+
+func (k upperKey) MarshalText() ([]byte, error) { return []byte(strings.ToUpper(string(k))), nil }
+
+func (k *upperKey) UnmarshalText(b []byte) error {
+	*k = upperKey(strings.ToLower(string(b)))
+	return nil
+}
+
+type pointKey struct{ X, Y int }
+
+func (p pointKey) MarshalText() ([]byte, error) {
+	b, err := json.Marshal([]int{p.X, p.Y})
+	return b, err
+}
+
+func (p *pointKey) UnmarshalText(b []byte) error {
+	var xy [2]int
+	if err := json.Unmarshal(b, &xy); err != nil {
+		return err
+	}
+	p.X, p.Y = xy[0], xy[1]
+	return nil
+}
+
+func TestMapKeys(t *testing.T) {
+	// std 为 false 时不与 encoding/json 比较: 基于 v2 实现的 encoding/json 对字符串
+	// 类型的键也调用 MarshalText, 而 Go 1.21 的 encoding/json 直接使用字符串
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+		std  bool
+	}{
+		{"int", map[int]string{10: "a", -2: "b", 3: "c"}, `{"-2":"b","10":"a","3":"c"}`, true},
+		{"int64", map[int64]bool{1 << 40: true}, `{"1099511627776":true}`, true},
+		{"uint8", map[uint8]int{255: 1, 0: 2}, `{"0":2,"255":1}`, true},
+		{"string kind ignores MarshalText", map[upperKey]int{"b": 1, "a": 2}, `{"a":2,"b":1}`, false},
+		{"struct TextMarshaler", map[pointKey]int{{1, 2}: 3}, `{"[1,2]":3}`, true},
+		{"netip", map[netip.Addr]int{netip.MustParseAddr("10.0.0.1"): 1}, `{"10.0.0.1":1}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Marshal(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			back := reflect.New(reflect.TypeOf(tt.v))
+			if err := Unmarshal(got, back.Interface()); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(back.Elem().Interface(), tt.v) {
+				t.Errorf("round trip: got %v", back.Elem())
+			}
+			if !tt.std {
+				return
+			}
+			std, _ := json.Marshal(tt.v)
+			if string(std) != tt.want {
+				t.Errorf("encoding/json gives %s", std)
+			}
+
+			stdBack := reflect.New(reflect.TypeOf(tt.v))
+			if err := json.Unmarshal(got, stdBack.Interface()); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(back.Elem().Interface(), stdBack.Elem().Interface()) {
+				t.Errorf("unmarshal: got %v, encoding/json %v", back.Elem(), stdBack.Elem())
+			}
+		})
+	}
+}
+
+func TestMapKeyErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		v     interface{}
+		want  string
+	}{
+		{`{"x":1}`, &map[int]int{}, `unmarshal: invalid map key "x" for int at x`},
+		{`{"300":1}`, &map[uint8]int{}, `unmarshal: invalid map key "300" for uint8 at 300`},
+		{`{"-1":1}`, &map[uint]int{}, `unmarshal: invalid map key "-1" for uint at -1`},
+		{`{"1.5":1}`, &map[int64]int{}, `unmarshal: invalid map key "1.5" for int64 at ["1.5"]`},
+	}
+	for _, tt := range tests {
+		err := Unmarshal([]byte(tt.input), tt.v)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s into %T: got %v, want %s", tt.input, tt.v, err, tt.want)
+		}
+	}
+
+	if _, err := Marshal(map[[2]int]int{{1, 2}: 3}); err == nil {
+		t.Errorf("array keys: expected an error")
+	}
+}
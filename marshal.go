@@ -0,0 +1,341 @@
+package yjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// 从 Go 值生成 JSON 时的选项
+type MarshalOptions struct {
+	Encode EncodeOptions
+
+	// 没有标签名的字段按该策略生成键名
+	Naming NamingStrategy
+
+	// time.Time 的输出布局, 可以是 TimeUnix 等时间戳格式, 为空时使用 time.RFC3339Nano.
+	// 字段的 layout 标签优先
+	TimeLayout string
+
+	// 接口类型字段输出时补上判别字段, 为 nil 时使用 DefaultTypes
+	Types *TypeRegistry
+}
+
+// 序列化结构体, map, 切片, 指针和基本类型, 先转换为 Value 再输出
+func Marshal(v interface{}) ([]byte, error) {
+	return MarshalWithOptions(v, MarshalOptions{})
+}
+
+func MarshalIndent(v interface{}, prefix, indent string) ([]byte, error) {
+	return MarshalWithOptions(v, MarshalOptions{Encode: EncodeOptions{Prefix: prefix, Indent: indent}})
+}
+
+func MarshalWithOptions(v interface{}, opts MarshalOptions) ([]byte, error) {
+	j, err := ValueOfWithOptions(v, opts)
+	if err != nil {
+		return nil, err
+	}
+	return j.EncodeWithOptions(opts.Encode)
+}
+
+// 把 Go 值转换为 Value 树, *Value 原样返回
+func ValueOf(v interface{}) (*Value, error) {
+	return ValueOfWithOptions(v, MarshalOptions{})
+}
+
+func ValueOfWithOptions(v interface{}, opts MarshalOptions) (*Value, error) {
+	if j, ok := v.(*Value); ok && j != nil {
+		return j, nil
+	}
+	m := &marshalState{opts: opts, visiting: make(map[uintptr]bool)}
+	return m.marshal("", reflect.ValueOf(v))
+}
+
+type marshalState struct {
+	opts     MarshalOptions
+	visiting map[uintptr]bool // 用于发现循环引用
+}
+
+var (
+	bigIntReflectType     = reflect.TypeOf(big.Int{})
+	bigFloatReflectType   = reflect.TypeOf(big.Float{})
+	numberReflectType     = reflect.TypeOf(Number(""))
+	jsonNumberReflectType = reflect.TypeOf(json.Number(""))
+)
+
+func (m *marshalState) marshal(path string, rv reflect.Value) (*Value, error) {
+	if !rv.IsValid() {
+		return NewNull(), nil
+	}
+
+	switch rv.Type() {
+	case valueReflectType:
+		v := rv.Interface().(Value)
+		return &v, nil
+	case numberReflectType, jsonNumberReflectType:
+		n := Number(rv.String())
+		if n == "" {
+			n = "0"
+		}
+		return &Value{valueType: JSON_NUMBER, numberType: NUMBER_FLOAT, repr: REPR_OTHER, num: n}, nil
+	case bigIntReflectType:
+		b := rv.Interface().(big.Int)
+		n := new(big.Int).Set(&b)
+		return &Value{valueType: JSON_NUMBER, numberType: NUMBER_INT, repr: REPR_OTHER, num: n}, nil
+	case bigFloatReflectType:
+		b := rv.Interface().(big.Float)
+		n := new(big.Float).Copy(&b)
+		return &Value{valueType: JSON_NUMBER, numberType: NUMBER_FLOAT, repr: REPR_OTHER, num: n}, nil
+	case timeReflectType:
+		return m.marshalTime(rv, ""), nil
+	}
+	if n, ok := rv.Interface().(nullableEncoder); ok {
+		v, valid := n.encodeSource()
+		if !valid {
+			return NewNull(), nil
+		}
+		return m.marshal(path, v)
+	}
+	if j, ok, err := m.marshalCustom(path, rv); ok {
+		return j, err
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		return NewBool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return NewInt(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return NewUint(rv.Uint()), nil
+	case reflect.Float32:
+		// 按 float32 的最短表示转换, 避免 0.1 变成 0.10000000149011612
+		f, _ := strconv.ParseFloat(strconv.FormatFloat(rv.Float(), 'g', -1, 32), 64)
+		return NewFloat(f), nil
+	case reflect.Float64:
+		return NewFloat(rv.Float()), nil
+	case reflect.String:
+		return NewString(rv.String()), nil
+	case reflect.Interface:
+		if rv.IsNil() {
+			return NewNull(), nil
+		}
+		if u := m.types().lookup(rv.Type()); u != nil {
+			return m.marshalUnion(path, rv, u)
+		}
+		return m.marshal(path, rv.Elem())
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return NewNull(), nil
+		}
+		if rv.Type() == reflect.TypeOf((*Value)(nil)) {
+			return rv.Interface().(*Value), nil
+		}
+		return m.marshalRef(path, rv, func() (*Value, error) {
+			return m.marshal(path, rv.Elem())
+		})
+	case reflect.Slice:
+		if isByteSlice(rv.Type()) {
+			return marshalBytes(path, rv, "")
+		}
+		if rv.IsNil() {
+			return NewNull(), nil
+		}
+		return m.marshalRef(path, rv, func() (*Value, error) {
+			return m.marshalArray(path, rv)
+		})
+	case reflect.Array:
+		return m.marshalArray(path, rv)
+	case reflect.Map:
+		if rv.IsNil() {
+			return NewNull(), nil
+		}
+		return m.marshalRef(path, rv, func() (*Value, error) {
+			return m.marshalMap(path, rv)
+		})
+	case reflect.Struct:
+		return m.marshalStruct(path, rv)
+	}
+	return nil, m.unsupported(path, rv.Type())
+}
+
+func (m *marshalState) unsupported(path string, t reflect.Type) error {
+	if path == "" {
+		return fmt.Errorf("marshal: unsupported type %s", t)
+	}
+	return fmt.Errorf("marshal: unsupported type %s at %s", t, path)
+}
+
+// 指针, 切片和 map 可能形成环, 正在访问的地址再次出现时报错
+func (m *marshalState) marshalRef(path string, rv reflect.Value, fn func() (*Value, error)) (*Value, error) {
+	ptr := rv.Pointer()
+	if rv.Kind() == reflect.Slice && rv.Len() == 0 {
+		return fn()
+	}
+	if m.visiting[ptr] {
+		if path == "" {
+			return nil, fmt.Errorf("marshal: encountered a cycle via %s", rv.Type())
+		}
+		return nil, fmt.Errorf("marshal: encountered a cycle via %s at %s", rv.Type(), path)
+	}
+	m.visiting[ptr] = true
+	defer delete(m.visiting, ptr)
+	return fn()
+}
+
+func (m *marshalState) marshalArray(path string, rv reflect.Value) (*Value, error) {
+	arr := make([]*Value, rv.Len())
+	for i := range arr {
+		v, err := m.marshal(joinIndexPath(path, i), rv.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		arr[i] = v
+	}
+	return &Value{valueType: JSON_ARRAY, arr: arr}, nil
+}
+
+// map 的键转换为字符串后按字典序输出, 保证结果稳定
+func (m *marshalState) marshalMap(path string, rv reflect.Value) (*Value, error) {
+	if !isMarshalKeyType(rv.Type().Key()) {
+		return nil, m.unsupported(path, rv.Type())
+	}
+
+	type entry struct {
+		key string
+		v   reflect.Value
+	}
+	entries := make([]entry, 0, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		k, err := mapKeyString(iter.Key())
+		if err != nil {
+			if path == "" {
+				return nil, fmt.Errorf("marshal: %v", err)
+			}
+			return nil, fmt.Errorf("marshal: %v at %s", err, path)
+		}
+		entries = append(entries, entry{k, iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+	obj := NewObject()
+	for _, e := range entries {
+		v, err := m.marshal(joinKeyPath(path, e.key), e.v)
+		if err != nil {
+			return nil, err
+		}
+		obj.setMember(e.key, v)
+	}
+	return obj, nil
+}
+
+func (m *marshalState) marshalStruct(path string, rv reflect.Value) (*Value, error) {
+	obj := NewObject()
+	for _, f := range cachedFields(rv.Type(), m.opts.Naming).list {
+		fv, ok := fieldByIndex(rv, f.index)
+		if !ok {
+			continue
+		}
+		if (f.omitEmpty && isEmptyValue(fv)) || (f.omitZero && isZeroValue(fv)) {
+			continue
+		}
+		v, err := m.marshalField(joinKeyPath(path, f.name), fv, &f)
+		if err != nil {
+			return nil, err
+		}
+		obj.setMember(f.name, v)
+	}
+	return obj, nil
+}
+
+// 字段标签中的格式选项只作用于该字段 (及其指针)
+func (m *marshalState) marshalField(path string, rv reflect.Value, f *field) (*Value, error) {
+	if !f.hasFormat() {
+		return m.marshal(path, rv)
+	}
+	if f.quoted {
+		return m.marshalQuoted(path, rv)
+	}
+	if isByteSlice(rv.Type()) {
+		return marshalBytes(path, rv, f.encoding)
+	}
+
+	if t := rv.Type(); t.Kind() == reflect.Pointer && (t.Elem() == timeReflectType || t.Elem() == durationReflectType) {
+		if rv.IsNil() {
+			return NewNull(), nil
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Type() {
+	case timeReflectType:
+		return m.marshalTime(rv, f.layout), nil
+	case durationReflectType:
+		v, err := marshalDuration(rv, f.unit)
+		if err != nil {
+			return nil, fmt.Errorf("marshal: %v at %s", err, path)
+		}
+		return v, nil
+	}
+	return m.marshal(path, rv)
+}
+
+// string 选项: 先按原类型编码, 再把得到的 JSON 文本作为字符串, 如 1.5 -> "1.5",
+// "a" -> "\"a\"". nil 指针仍为 null
+func (m *marshalState) marshalQuoted(path string, rv reflect.Value) (*Value, error) {
+	v, err := m.marshal(path, rv)
+	if err != nil {
+		return nil, err
+	}
+	switch v.Type() {
+	case JSON_NUMBER, JSON_BOOLEAN, JSON_STRING:
+	default:
+		return v, nil
+	}
+	b, err := v.EncodeWithOptions(EncodeOptions{EscapeHTML: m.opts.Encode.EscapeHTML})
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %v at %s", err, path)
+	}
+	return NewString(string(b)), nil
+}
+
+// omitempty 的判断与 encoding/json 相同
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+type isZeroer interface {
+	IsZero() bool
+}
+
+// omitzero: 类型有 IsZero() bool 方法 (如 time.Time) 时以它为准, 否则等于类型的零值
+func isZeroValue(v reflect.Value) bool {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return true
+	}
+	if z, ok := v.Interface().(isZeroer); ok {
+		return z.IsZero()
+	}
+	if v.CanAddr() {
+		if z, ok := v.Addr().Interface().(isZeroer); ok {
+			return z.IsZero()
+		}
+	}
+	return v.IsZero()
+}
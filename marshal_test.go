@@ -0,0 +1,144 @@
+package yjson
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+type marshalInner struct {
+	N int `json:"n"`
+}
+
+type marshalOuter struct {
+	Name     string            `json:"name"`
+	Skip     string            `json:"-"`
+	Empty    string            `json:"empty,omitempty"`
+	Inner    marshalInner      `json:"inner"`
+	Ptr      *marshalInner     `json:"ptr"`
+	List     []int             `json:"list"`
+	Tags     map[string]string `json:"tags"`
+	Untagged bool
+	private  int
+}
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"nil", nil, `null`},
+		{"string", "a\"b\n", `"a\"b\n"`},
+		{"int", -7, `-7`},
+		{"uint64", uint64(18446744073709551615), `18446744073709551615`},
+		{"float", 1.5, `1.5`},
+		{"bool", true, `true`},
+		{"nil slice", []int(nil), `null`},
+		{"empty slice", []int{}, `[]`},
+		{"bytes", []byte("hi"), `"aGk="`},
+		{"array", [2]string{"a", "b"}, `["a","b"]`},
+		{"map", map[string]int{"b": 2, "a": 1}, `{"a":1,"b":2}`},
+		{"struct", marshalOuter{Name: "x", Skip: "s", List: []int{1}, Tags: map[string]string{"k": "v"}, Untagged: true},
+			`{"name":"x","inner":{"n":0},"ptr":null,"list":[1],"tags":{"k":"v"},"Untagged":true}`},
+		{"pointer", &marshalInner{N: 3}, `{"n":3}`},
+		{"value", mustParse(t, `{"z":1}`), `{"z":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Marshal(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			if _, ok := tt.v.(*Value); ok {
+				return
+			}
+			if std, _ := json.Marshal(tt.v); string(std) != tt.want {
+				t.Errorf("encoding/json gives %s", std)
+			}
+		})
+	}
+
+	if _, err := Marshal(make(chan int)); err == nil {
+		t.Errorf("chan: expected an error")
+	}
+	if _, err := Marshal(func() {}); err == nil {
+		t.Errorf("func: expected an error")
+	}
+}
+
+type marshalNode struct {
+	Name string       `json:"name"`
+	Next *marshalNode `json:"next"`
+}
+
+func TestMarshalValues(t *testing.T) {
+	n := 5
+	pn := &n
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"float32", float32(0.1), `0.1`},
+		{"float32 slice", []float32{1.5, 3.4028235e38}, `[1.5,3.4028235e+38]`},
+		{"pointer to pointer", &pn, `5`},
+		{"nil pointer", (*int)(nil), `null`},
+		{"nil map", map[string]int(nil), `null`},
+		{"interface slice", []interface{}{1, "a", nil, []int{2}, map[string]bool{"t": true}}, `[1,"a",null,[2],{"t":true}]`},
+		{"shared pointer is not a cycle", []*int{&n, &n}, `[5,5]`},
+		{"linked list", &marshalNode{"a", &marshalNode{"b", nil}}, `{"name":"a","next":{"name":"b","next":null}}`},
+		{"empty struct", struct{}{}, `{}`},
+		{"anonymous struct", struct {
+			A int `json:"a"`
+		}{1}, `{"a":1}`},
+	}
+	for _, tt := range tests {
+		got, err := Marshal(tt.v)
+		if err != nil || string(got) != tt.want {
+			t.Errorf("%s: got %s, %v, want %s", tt.name, got, err, tt.want)
+			continue
+		}
+		if std, _ := json.Marshal(tt.v); string(std) != tt.want {
+			t.Errorf("%s: encoding/json gives %s", tt.name, std)
+		}
+	}
+
+	// ValueOf 返回可以继续修改的树
+	v, err := ValueOf(map[string]int{"a": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.Set("b", NewInt(2))
+	if got := mustEncode(t, v); got != `{"a":1,"b":2}` {
+		t.Errorf("ValueOf: got %s", got)
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	cycle := &marshalNode{Name: "a"}
+	cycle.Next = cycle
+	loop := map[string]interface{}{}
+	loop["self"] = loop
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"chan", make(chan int), `marshal: unsupported type chan int`},
+		{"nested func", map[string]interface{}{"f": func() {}}, `marshal: unsupported type func() at f`},
+		{"complex field", struct{ C complex128 }{}, `marshal: unsupported type complex128 at C`},
+		{"pointer cycle", cycle, `marshal: encountered a cycle via *yjson.marshalNode at next`},
+		{"map cycle", loop, `marshal: encountered a cycle via map[string]interface {} at self`},
+		{"NaN", math.NaN(), `cannot encode NaN without AllowNaN`},
+	}
+	for _, tt := range tests {
+		_, err := Marshal(tt.v)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: got %v, want %s", tt.name, err, tt.want)
+		}
+	}
+}
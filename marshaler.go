@@ -0,0 +1,130 @@
+package yjson
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// 类型自己决定序列化结果. 同时实现 json.Marshaler 时优先使用 Marshaler
+type Marshaler interface {
+	MarshalYJSON() (*Value, error)
+}
+
+// 类型自己处理反序列化, 传入的值不会为 nil, 可能是 null
+type Unmarshaler interface {
+	UnmarshalYJSON(*Value) error
+}
+
+var (
+	marshalerType       = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	unmarshalerType     = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func implementsMarshaler(t reflect.Type) bool {
+	return t.Implements(marshalerType) || t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType)
+}
+
+// rv 或其地址实现了 Marshaler / json.Marshaler 时调用它, 都没有时退回到
+// encoding.TextMarshaler 并输出为字符串. 第二个返回值表示是否已处理
+func (m *marshalState) marshalCustom(path string, rv reflect.Value) (*Value, bool, error) {
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, false, nil
+		}
+		switch rv.Type().Elem() {
+		case bigIntReflectType, bigFloatReflectType:
+			return nil, false, nil // 解引用后按数字处理
+		}
+	}
+	t := rv.Type()
+	if !implementsMarshaler(t) {
+		if !rv.CanAddr() {
+			return nil, false, nil
+		}
+		rv = rv.Addr()
+		t = rv.Type()
+	}
+
+	var (
+		j      *Value
+		err    error
+		method string
+	)
+	switch {
+	case t.Implements(marshalerType):
+		method = "MarshalYJSON"
+		j, err = rv.Interface().(Marshaler).MarshalYJSON()
+		if err == nil && j == nil {
+			j = NewNull()
+		}
+	case t.Implements(jsonMarshalerType):
+		method = "MarshalJSON"
+		var b []byte
+		b, err = rv.Interface().(json.Marshaler).MarshalJSON()
+		if err == nil {
+			j, err = Parse(b)
+		}
+	case t.Implements(textMarshalerType):
+		method = "MarshalText"
+		var b []byte
+		b, err = rv.Interface().(encoding.TextMarshaler).MarshalText()
+		if err == nil {
+			j = NewString(string(b))
+		}
+	default:
+		return nil, false, nil
+	}
+	if err != nil {
+		if path == "" {
+			return nil, true, fmt.Errorf("marshal: error calling %s for type %s: %v", method, t, err)
+		}
+		return nil, true, fmt.Errorf("marshal: error calling %s for type %s at %s: %v", method, t, path, err)
+	}
+	return j, true, nil
+}
+
+// rv 的地址实现了 Unmarshaler / json.Unmarshaler 时调用它, 都没有时对字符串
+// 退回到 encoding.TextUnmarshaler, null 保持原值不变. 返回是否已处理
+func (d *decodeState) decodeCustom(path string, j *Value, rv reflect.Value) (bool, error) {
+	if rv.Kind() == reflect.Pointer || !rv.CanAddr() {
+		return false, nil
+	}
+	switch rv.Type() {
+	case bigIntReflectType, bigFloatReflectType:
+		return false, nil // 由 decodeNumber 按数字处理
+	}
+
+	var err error
+	switch u := rv.Addr().Interface().(type) {
+	case Unmarshaler:
+		err = u.UnmarshalYJSON(j)
+	case json.Unmarshaler:
+		var b []byte
+		if b, err = j.Encode(); err == nil {
+			err = u.UnmarshalJSON(b)
+		}
+	case encoding.TextUnmarshaler:
+		switch j.Type() {
+		case JSON_NULL:
+			return true, nil
+		case JSON_STRING:
+			err = u.UnmarshalText([]byte(j.str))
+		default:
+			return true, d.typeError(path, j, rv.Type())
+		}
+	default:
+		return false, nil
+	}
+	if err != nil {
+		if path == "" {
+			return true, fmt.Errorf("unmarshal: %v", err)
+		}
+		return true, fmt.Errorf("unmarshal: %v at %s", err, path)
+	}
+	return true, nil
+}
@@ -0,0 +1,230 @@
+package yjson
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// 同时实现两种接口时优先使用 MarshalYJSON
+type level int
+
+func (l level) MarshalYJSON() (*Value, error) {
+	return NewString([]string{"low", "high"}[l]), nil
+}
+
+func (l level) MarshalJSON() ([]byte, error) { return []byte(`"json"`), nil }
+
+func (l *level) UnmarshalYJSON(v *Value) error {
+	if v.IsNull() {
+		*l = -1
+		return nil
+	}
+	s, err := v.String()
+	if err != nil {
+		return err
+	}
+	switch s {
+	case "low":
+		*l = 0
+	case "high":
+		*l = 1
+	default:
+		return errors.New("unknown level " + s)
+	}
+	return nil
+}
+
+// 只实现 encoding/json 的接口, 指针接收者
+type upperName string
+
+func (n *upperName) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strings.ToUpper(string(*n)))
+}
+
+func (n *upperName) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	*n = upperName(strings.ToLower(s))
+	return nil
+}
+
+type failing struct{}
+
+func (failing) MarshalYJSON() (*Value, error) { return nil, errors.New("boom") }
+
+type nilMarshaler struct{}
+
+func (nilMarshaler) MarshalYJSON() (*Value, error) { return nil, nil }
+
+type badJSON struct{}
+
+func (badJSON) MarshalJSON() ([]byte, error) { return []byte(`{bad`), nil }
+
+type custom struct {
+	Level level       `json:"level"`
+	Ptr   *level      `json:"ptr"`
+	Name  upperName   `json:"name"`
+	Names []upperName `json:"names"`
+}
+
+func TestMarshaler(t *testing.T) {
+	high := level(1)
+	v := custom{Level: 0, Ptr: &high, Name: "ab", Names: []upperName{"c"}}
+	got, err := Marshal(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"level":"low","ptr":"high","name":"AB","names":["C"]}`; string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	// 不可寻址时指针接收者的方法不可用, 与 encoding/json 相同
+	got, _ = Marshal(v)
+	std, _ := json.Marshal(struct {
+		Name upperName `json:"name"`
+	}{"ab"})
+	if !strings.Contains(string(got), `"name":"ab"`) || string(std) != `{"name":"ab"}` {
+		t.Errorf("by value: got %s, encoding/json %s", got, std)
+	}
+
+	if got, err := Marshal(nilMarshaler{}); err != nil || string(got) != `null` {
+		t.Errorf("nil result: got %s, %v", got, err)
+	}
+}
+
+func TestMarshalerErrors(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{failing{}, `marshal: error calling MarshalYJSON for type yjson.failing: boom`},
+		{map[string]failing{"k": {}}, `marshal: error calling MarshalYJSON for type yjson.failing at k: boom`},
+		{badJSON{}, `marshal: error calling MarshalJSON for type yjson.badJSON: expect: ", but get: b`},
+	}
+	for _, tt := range tests {
+		_, err := Marshal(tt.v)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%T: got %v, want %s", tt.v, err, tt.want)
+		}
+	}
+}
+
+func TestUnmarshaler(t *testing.T) {
+	var v custom
+	if err := Unmarshal([]byte(`{"level":"high","ptr":"low","name":"XY","names":["A","B"]}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Level != 1 || v.Ptr == nil || *v.Ptr != 0 || v.Name != "xy" || len(v.Names) != 2 || v.Names[1] != "b" {
+		t.Errorf("got %+v", v)
+	}
+
+	// UnmarshalYJSON 也会收到 null, 指针字段为 null 时置为 nil
+	v = custom{Ptr: new(level)}
+	if err := Unmarshal([]byte(`{"level":null,"ptr":null}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Level != -1 || v.Ptr != nil {
+		t.Errorf("null: got %+v", v)
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"level":"mid"}`, `unmarshal: unknown level mid at level`},
+		{`{"level":3}`, `unmarshal: expect string, but get number at level`},
+		{`{"name":1}`, `unmarshal: json: cannot unmarshal number into Go value of type string at name`},
+	}
+	for _, tt := range tests {
+		var c custom
+		err := Unmarshal([]byte(tt.input), &c)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: got %v, want %s", tt.input, err, tt.want)
+		}
+	}
+}
+
+type color int
+
+func (c color) MarshalText() ([]byte, error) {
+	if c < 0 {
+		return nil, errors.New("negative color")
+	}
+	return []byte([]string{"red", "green"}[c]), nil
+}
+
+func (c *color) UnmarshalText(b []byte) error {
+	switch string(b) {
+	case "red":
+		*c = 0
+	case "green":
+		*c = 1
+	default:
+		return errors.New("unknown color " + string(b))
+	}
+	return nil
+}
+
+type textFields struct {
+	IP    net.IP           `json:"ip"`
+	Addr  netip.Addr       `json:"addr"`
+	Color color            `json:"color"`
+	Ptr   *color           `json:"ptr"`
+	Map   map[string]color `json:"map"`
+}
+
+func TestTextMarshaler(t *testing.T) {
+	green := color(1)
+	v := textFields{IP: net.ParseIP("10.0.0.1"), Addr: netip.MustParseAddr("::1"), Color: 1, Ptr: &green, Map: map[string]color{"k": 0}}
+	got, err := Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	std, _ := json.Marshal(v)
+	if string(got) != string(std) {
+		t.Errorf("got %s, encoding/json %s", got, std)
+	}
+
+	var back, stdBack textFields
+	if err := Unmarshal(got, &back); err != nil {
+		t.Fatal(err)
+	}
+	json.Unmarshal(got, &stdBack)
+	if !reflect.DeepEqual(back, stdBack) || !back.IP.Equal(v.IP) || *back.Ptr != 1 {
+		t.Errorf("got %+v, encoding/json %+v", back, stdBack)
+	}
+
+	// null 保持原值
+	keep := textFields{Color: 1}
+	if err := Unmarshal([]byte(`{"color":null}`), &keep); err != nil || keep.Color != 1 {
+		t.Errorf("null: got %+v, %v", keep, err)
+	}
+}
+
+func TestTextMarshalerErrors(t *testing.T) {
+	if _, err := Marshal(color(-1)); err == nil || err.Error() != "marshal: error calling MarshalText for type yjson.color: negative color" {
+		t.Errorf("marshal: got %v", err)
+	}
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"color":"blue"}`, `unmarshal: unknown color blue at color`},
+		{`{"color":1}`, `unmarshal: cannot unmarshal number into yjson.color at color`},
+		{`{"addr":"nope"}`, `unmarshal: ParseAddr("nope"): unable to parse IP at addr`},
+	}
+	for _, tt := range tests {
+		var v textFields
+		err := Unmarshal([]byte(tt.input), &v)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: got %v, want %s", tt.input, err, tt.want)
+		}
+	}
+}
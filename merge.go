@@ -0,0 +1,144 @@
+package yjson
+
+import "fmt"
+
+// Merge 遇到两个数组时的处理方式
+type ArrayMergeStrategy int
+
+const (
+	ArrayReplace      ArrayMergeStrategy = iota // src 的数组替换 dst 的数组
+	ArrayConcat                                 // src 的元素追加到 dst 的数组之后
+	ArrayMergeByIndex                           // 相同下标的元素递归合并, src 多出的元素追加到末尾
+	ArrayMergeByKey                             // 对象元素按 MergeOptions.Key 字段的值匹配后递归合并, 没有匹配的追加到末尾
+)
+
+type MergeOptions struct {
+	Arrays ArrayMergeStrategy
+
+	// ArrayMergeByKey 用来匹配元素的字段, 如 "name". 字段的值按 test 操作的规则比较,
+	// 没有这个字段的元素和非对象元素总是追加
+	Key string
+
+	// src 中值为 null 的成员删除 dst 中对应的成员, 与 RFC 7386 JSON Merge Patch 相同.
+	// 否则 null 和其他值一样覆盖 dst
+	NullDeletes bool
+}
+
+// 把 src 深度合并到 dst 中, 直接修改 dst, 写入的值是 src 的拷贝.
+// 两个对象逐个成员合并, 两个数组按 opts.Arrays 处理, 其他情况用 src 替换 dst.
+// 适合按顺序叠加多层配置, 如 Merge(base, override, opts)
+func Merge(dst, src *Value, opts MergeOptions) error {
+	if opts.Arrays == ArrayMergeByKey && opts.Key == "" {
+		return fmt.Errorf("merge: ArrayMergeByKey requires MergeOptions.Key")
+	}
+	return merge(dst, src, opts)
+}
+
+func merge(dst, src *Value, opts MergeOptions) error {
+	if err := dst.load(); err != nil {
+		return err
+	}
+	if err := src.load(); err != nil {
+		return err
+	}
+	switch {
+	case dst.Type() == JSON_OBJECT && src.Type() == JSON_OBJECT:
+		for _, key := range src.keys {
+			v := src.obj[key]
+			if opts.NullDeletes && v.Type() == JSON_NULL {
+				dst.deleteMember(key)
+				continue
+			}
+			if old, ok := dst.obj[key]; ok {
+				if err := merge(old, v, opts); err != nil {
+					return err
+				}
+				continue
+			}
+			dst.setMember(key, copyValue(v))
+		}
+		return nil
+	case dst.Type() == JSON_ARRAY && src.Type() == JSON_ARRAY:
+		return mergeArray(dst, src, opts)
+	}
+	*dst = *copyValue(src)
+	return nil
+}
+
+func mergeArray(dst, src *Value, opts MergeOptions) error {
+	switch opts.Arrays {
+	case ArrayReplace:
+		*dst = *copyValue(src)
+	case ArrayConcat:
+		for _, v := range src.arr {
+			dst.arr = append(dst.arr, copyValue(v))
+		}
+	case ArrayMergeByIndex:
+		for i, v := range src.arr {
+			if i < len(dst.arr) {
+				if err := merge(dst.arr[i], v, opts); err != nil {
+					return err
+				}
+				continue
+			}
+			dst.arr = append(dst.arr, copyValue(v))
+		}
+	case ArrayMergeByKey:
+		return mergeByKey(dst, src, opts)
+	default:
+		return fmt.Errorf("merge: unknown array strategy %d", opts.Arrays)
+	}
+	return nil
+}
+
+func mergeByKey(dst, src *Value, opts MergeOptions) error {
+	// 键的比较键 -> dst 中第一个带有该键的元素
+	index := make(map[string]*Value)
+	for _, v := range dst.arr {
+		id, ok, err := mergeKey(v, opts.Key)
+		if err != nil {
+			return err
+		}
+		if _, seen := index[id]; ok && !seen {
+			index[id] = v
+		}
+	}
+	for _, v := range src.arr {
+		id, ok, err := mergeKey(v, opts.Key)
+		if err != nil {
+			return err
+		}
+		if old, found := index[id]; ok && found {
+			if err := merge(old, v, opts); err != nil {
+				return err
+			}
+			continue
+		}
+		c := copyValue(v)
+		dst.arr = append(dst.arr, c)
+		if ok {
+			index[id] = c
+		}
+	}
+	return nil
+}
+
+// 元素 key 字段的比较键, 与 CreatePatch 对齐数组元素的方式相同, 大整数不会混淆.
+// 不是对象或没有该字段时 ok 为 false
+func mergeKey(v *Value, key string) (string, bool, error) {
+	if v.Type() != JSON_OBJECT {
+		return "", false, nil
+	}
+	if err := v.load(); err != nil {
+		return "", false, err
+	}
+	field, ok := v.obj[key]
+	if !ok {
+		return "", false, nil
+	}
+	b, err := appendExactKey(nil, field)
+	if err != nil {
+		return "", false, err
+	}
+	return string(b), true, nil
+}
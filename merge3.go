@@ -0,0 +1,122 @@
+package yjson
+
+// Merge3 无法自动合并的位置. 某一边不存在该值时对应的字段为 nil
+type Conflict struct {
+	Path   string // Get 的路径语法, 根节点为空字符串
+	Base   *Value
+	Ours   *Value
+	Theirs *Value
+}
+
+// 三方合并: 以共同祖先 base 为准, 把 ours 和 theirs 各自的修改合并到新文档中.
+// 只有一边修改的值取修改后的值, 两边改成相同的值时直接采用; 两边都是对象时逐个成员
+// 合并, 三个数组长度相同时逐个元素合并, 否则数组整体比较. 两边做了不同的修改时记为
+// 冲突, 结果中保留 ours 的值. 成员的顺序以 ours 为准, 只在 theirs 中新增的成员追加到
+// 末尾. 没有冲突时返回空切片; 结果中的值是输入的拷贝, 输入本身不变.
+// 没有共同祖先时 base 可以为 nil
+func Merge3(base, ours, theirs *Value) (*Value, []Conflict, error) {
+	m := &merger3{conflicts: make([]Conflict, 0)}
+	out, err := m.merge("", base, ours, theirs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if out == nil {
+		// ours 和 theirs 都为 nil
+		out = NewNull()
+	}
+	return out, m.conflicts, nil
+}
+
+type merger3 struct {
+	conflicts []Conflict
+}
+
+// nil 表示不存在
+func sameValue(a, b *Value) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return equalValues(a, b)
+}
+
+func copyOrNil(v *Value) *Value {
+	if v == nil {
+		return nil
+	}
+	return copyValue(v)
+}
+
+// 返回合并后的值, nil 表示合并后不存在
+func (m *merger3) merge(path string, base, ours, theirs *Value) (*Value, error) {
+	for _, v := range []*Value{base, ours, theirs} {
+		if err := v.load(); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case sameValue(ours, theirs):
+		return copyOrNil(ours), nil
+	case sameValue(base, ours):
+		return copyOrNil(theirs), nil
+	case sameValue(base, theirs):
+		return copyOrNil(ours), nil
+	}
+
+	if ours != nil && theirs != nil && ours.Type() == theirs.Type() {
+		// 类型改变过的 base 不能作为逐个成员合并的依据
+		b := base
+		if b != nil && b.Type() != ours.Type() {
+			b = nil
+		}
+		switch ours.Type() {
+		case JSON_OBJECT:
+			return m.mergeObject(path, b, ours, theirs)
+		case JSON_ARRAY:
+			if b != nil && len(b.arr) == len(ours.arr) && len(ours.arr) == len(theirs.arr) {
+				return m.mergeArray(path, b, ours, theirs)
+			}
+		}
+	}
+	m.conflicts = append(m.conflicts, Conflict{Path: path, Base: base, Ours: ours, Theirs: theirs})
+	return copyOrNil(ours), nil
+}
+
+// base 为 nil 时视为空对象
+func (m *merger3) mergeObject(path string, base, ours, theirs *Value) (*Value, error) {
+	member := func(v *Value, key string) *Value {
+		if v == nil {
+			return nil
+		}
+		return v.obj[key]
+	}
+	out := NewObject()
+	keys := append([]string(nil), ours.keys...)
+	for _, key := range theirs.keys {
+		if _, ok := ours.obj[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	// 两边都删除的成员不在 keys 中, 不需要合并
+	for _, key := range keys {
+		v, err := m.merge(joinKeyPath(path, key), member(base, key), member(ours, key), member(theirs, key))
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			out.setMember(key, v)
+		}
+	}
+	return out, nil
+}
+
+func (m *merger3) mergeArray(path string, base, ours, theirs *Value) (*Value, error) {
+	out := NewArray()
+	for i := range ours.arr {
+		v, err := m.merge(joinIndexPath(path, i), base.arr[i], ours.arr[i], theirs.arr[i])
+		if err != nil {
+			return nil, err
+		}
+		out.arr = append(out.arr, v)
+	}
+	return out, nil
+}
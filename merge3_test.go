@@ -0,0 +1,69 @@
+package yjson
+
+import "testing"
+
+func TestMerge3(t *testing.T) {
+	tests := []struct {
+		base, ours, theirs string
+		want               string
+		conflicts          []string
+	}{
+		{`{"a":1}`, `{"a":1}`, `{"a":2}`, `{"a":2}`, nil},
+		{`{"a":1}`, `{"a":2}`, `{"a":1}`, `{"a":2}`, nil},
+		{`{"a":1}`, `{"a":2}`, `{"a":2}`, `{"a":2}`, nil},
+		{`{"a":1}`, `{"a":2}`, `{"a":3}`, `{"a":2}`, []string{"a"}},
+		{`{"a":1,"b":1}`, `{"a":2,"b":1}`, `{"a":1,"b":2}`, `{"a":2,"b":2}`, nil},
+		{`{"a":1,"b":1}`, `{"b":1}`, `{"a":1,"b":1,"c":1}`, `{"b":1,"c":1}`, nil},
+		{`{"a":1}`, `{}`, `{"a":2}`, `{}`, []string{"a"}},
+		{`{"a":{"x":1,"y":1}}`, `{"a":{"x":2,"y":1}}`, `{"a":{"x":1,"y":2}}`, `{"a":{"x":2,"y":2}}`, nil},
+		{`[1,2,3]`, `[9,2,3]`, `[1,2,9]`, `[9,2,9]`, nil},
+		{`[1,2]`, `[1,2,3]`, `[1]`, `[1,2,3]`, []string{""}},
+		{`{"id":9007199254740992}`, `{"id":9007199254740992}`, `{"id":9007199254740993}`, `{"id":9007199254740993}`, nil},
+		{`{"id":9007199254740992}`, `{"id":9007199254740993}`, `{"id":9007199254740994}`, `{"id":9007199254740993}`, []string{"id"}},
+		{`{"a":1}`, `{"a":1.0}`, `{"a":2}`, `{"a":2}`, nil},
+	}
+	for _, tt := range tests {
+		out, conflicts, err := Merge3(mustParse(t, tt.base), mustParse(t, tt.ours), mustParse(t, tt.theirs))
+		if err != nil {
+			t.Fatalf("Merge3(%s, %s, %s): %v", tt.base, tt.ours, tt.theirs, err)
+		}
+		if got := mustEncode(t, out); got != tt.want {
+			t.Errorf("Merge3(%s, %s, %s) = %s, want %s", tt.base, tt.ours, tt.theirs, got, tt.want)
+		}
+		var paths []string
+		for _, c := range conflicts {
+			paths = append(paths, c.Path)
+		}
+		if len(paths) != len(tt.conflicts) {
+			t.Errorf("Merge3(%s, %s, %s) conflicts = %q, want %q", tt.base, tt.ours, tt.theirs, paths, tt.conflicts)
+			continue
+		}
+		for i := range paths {
+			if paths[i] != tt.conflicts[i] {
+				t.Errorf("Merge3(%s, %s, %s) conflicts = %q, want %q", tt.base, tt.ours, tt.theirs, paths, tt.conflicts)
+			}
+		}
+	}
+}
+
+func TestMerge3Inputs(t *testing.T) {
+	base, ours, theirs := mustParse(t, `{"a":1}`), mustParse(t, `{"a":1}`), mustParse(t, `{"a":2}`)
+	out, _, err := Merge3(base, ours, theirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := out.Set("a", NewInt(5)); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustEncode(t, theirs); got != `{"a":2}` {
+		t.Errorf("result shares values with theirs: %s", got)
+	}
+
+	out, conflicts, err := Merge3(nil, mustParse(t, `{"a":1}`), mustParse(t, `{"b":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mustEncode(t, out); got != `{"a":1,"b":1}` || len(conflicts) != 0 {
+		t.Errorf("nil base: got %s, %v", got, conflicts)
+	}
+}
@@ -0,0 +1,50 @@
+package yjson
+
+import "testing"
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		dst, src string
+		opts     MergeOptions
+		want     string
+	}{
+		{`{"a":1,"b":{"c":1}}`, `{"b":{"d":2},"e":3}`, MergeOptions{}, `{"a":1,"b":{"c":1,"d":2},"e":3}`},
+		{`{"a":1}`, `{"a":{"x":1}}`, MergeOptions{}, `{"a":{"x":1}}`},
+		{`{"a":1,"b":2}`, `{"a":null}`, MergeOptions{}, `{"a":null,"b":2}`},
+		{`{"a":1,"b":2}`, `{"a":null}`, MergeOptions{NullDeletes: true}, `{"b":2}`},
+		{`{"l":[1,2]}`, `{"l":[3]}`, MergeOptions{Arrays: ArrayReplace}, `{"l":[3]}`},
+		{`{"l":[1,2]}`, `{"l":[3]}`, MergeOptions{Arrays: ArrayConcat}, `{"l":[1,2,3]}`},
+		{`{"l":[{"a":1},2]}`, `{"l":[{"b":1},5,6]}`, MergeOptions{Arrays: ArrayMergeByIndex}, `{"l":[{"a":1,"b":1},5,6]}`},
+		{`[{"id":1,"v":1},{"id":2,"v":2}]`, `[{"id":2,"v":3},{"id":3}]`, MergeOptions{Arrays: ArrayMergeByKey, Key: "id"}, `[{"id":1,"v":1},{"id":2,"v":3},{"id":3}]`},
+		{`[{"id":1.0,"v":1}]`, `[{"id":1,"w":2}]`, MergeOptions{Arrays: ArrayMergeByKey, Key: "id"}, `[{"id":1,"v":1,"w":2}]`},
+		{`[{"id":9007199254740993,"v":1}]`, `[{"id":9007199254740992,"v":2}]`, MergeOptions{Arrays: ArrayMergeByKey, Key: "id"}, `[{"id":9007199254740993,"v":1},{"id":9007199254740992,"v":2}]`},
+		{`[{"v":1},3]`, `[{"v":2},3]`, MergeOptions{Arrays: ArrayMergeByKey, Key: "id"}, `[{"v":1},3,{"v":2},3]`},
+	}
+	for _, tt := range tests {
+		dst := mustParse(t, tt.dst)
+		if err := Merge(dst, mustParse(t, tt.src), tt.opts); err != nil {
+			t.Fatalf("Merge(%s, %s): %v", tt.dst, tt.src, err)
+		}
+		if got := mustEncode(t, dst); got != tt.want {
+			t.Errorf("Merge(%s, %s, %+v) = %s, want %s", tt.dst, tt.src, tt.opts, got, tt.want)
+		}
+	}
+
+	if err := Merge(NewObject(), NewObject(), MergeOptions{Arrays: ArrayMergeByKey}); err == nil {
+		t.Error("ArrayMergeByKey without Key: got nil, want error")
+	}
+}
+
+// 写入 dst 的是拷贝, 之后修改 src 不影响 dst
+func TestMergeCopies(t *testing.T) {
+	dst, src := mustParse(t, `{}`), mustParse(t, `{"a":{"b":1}}`)
+	if err := Merge(dst, src, MergeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Set("a.b", NewInt(2)); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustEncode(t, dst); got != `{"a":{"b":1}}` {
+		t.Errorf("dst shares values with src: %s", got)
+	}
+}
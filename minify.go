@@ -0,0 +1,82 @@
+package yjson
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// 去掉原始 JSON 中无意义的空白, 不构建 Value 树. 只检查字符串和注释是否闭合,
+// 不做完整的语法校验
+func Minify(src []byte) ([]byte, error) {
+	return MinifyWithOptions(src, ParseOptions{})
+}
+
+// 同 Minify, opts 开启 AllowComments 时同时去掉注释, 开启 JSON5 时识别单引号字符串
+func MinifyWithOptions(src []byte, opts ParseOptions) ([]byte, error) {
+	opts = opts.normalize()
+	dst := make([]byte, 0, len(src))
+	for i := 0; i < len(src); {
+		b := src[i]
+		switch {
+		case b == BLANK_SPACE || b == HORIZONTAL_TAB || b == LINE_BREAK || b == CARRIAGE_RETURN:
+			i++
+		case (b == '\v' || b == '\f') && opts.JSON5:
+			i++
+		case b == DQ || (b == SQ && opts.JSON5):
+			end, err := scanString(src, i)
+			if err != nil {
+				return nil, err
+			}
+			dst = append(dst, src[i:end]...)
+			i = end
+		case b == SLASH:
+			if !opts.AllowComments {
+				return nil, fmt.Errorf("unexpected %c at offset %d", SLASH, i)
+			}
+			end, err := scanComment(src, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+		default:
+			dst = append(dst, b)
+			i++
+		}
+	}
+	return dst, nil
+}
+
+// 返回从 src[i] 开始的字符串字面量之后的位置
+func scanString(src []byte, i int) (int, error) {
+	quote := src[i]
+	for j := i + 1; j < len(src); j++ {
+		switch src[j] {
+		case BACKSLASH:
+			j++
+		case quote:
+			return j + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated string at offset %d", i)
+}
+
+// 返回从 src[i] 开始的注释之后的位置
+func scanComment(src []byte, i int) (int, error) {
+	if i+1 < len(src) {
+		switch src[i+1] {
+		case SLASH:
+			end := bytes.IndexByte(src[i:], LINE_BREAK)
+			if end < 0 {
+				return len(src), nil
+			}
+			return i + end, nil
+		case '*':
+			end := bytes.Index(src[i+2:], []byte("*/"))
+			if end < 0 {
+				return 0, fmt.Errorf("unterminated block comment at offset %d", i)
+			}
+			return i + 2 + end + 2, nil
+		}
+	}
+	return 0, fmt.Errorf("unexpected %c at offset %d", SLASH, i)
+}
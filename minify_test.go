@@ -0,0 +1,68 @@
+package yjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMinify(t *testing.T) {
+	docs := []string{
+		`{}`,
+		" {\n\t\"a\" : [ 1 , 2.5e3 , true , null ] ,\r\n \"b\" : { } }\n",
+		`[ "  spaced  string  " , "escaped \" quote \\" ]`,
+		`"only a string"`,
+		`  -12  `,
+	}
+	for _, doc := range docs {
+		got, err := Minify([]byte(doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var std bytes.Buffer
+		if err := json.Compact(&std, []byte(doc)); err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != std.String() {
+			t.Errorf("%q: got %s, want %s", doc, got, std.String())
+		}
+	}
+}
+
+func TestMinifyWithOptions(t *testing.T) {
+	tests := []struct {
+		input string
+		opts  ParseOptions
+		want  string
+	}{
+		{"{\"a\": 1, // line\n \"b\": /* block */ 2}", ParseOptions{AllowComments: true}, `{"a":1,"b":2}`},
+		{"[1, 2] // at the end", ParseOptions{AllowComments: true}, `[1,2]`},
+		{`["// not a comment", "/* nor this */"]`, ParseOptions{AllowComments: true}, `["// not a comment","/* nor this */"]`},
+		{"{'a b': 'c\\'d'}", ParseOptions{JSON5: true}, `{'a b':'c\'d'}`},
+		{"[1,\v\f2]", ParseOptions{JSON5: true}, `[1,2]`},
+		{"{'a': 1 /* c */}", ParseOptions{JSON5: true}, `{'a':1}`},
+		{`[1, /* x */ 2]`, ParseOptions{}, `error: unexpected / at offset 4`},
+		{`["abc`, ParseOptions{}, `error: unterminated string at offset 1`},
+		{`["abc\"]`, ParseOptions{}, `error: unterminated string at offset 1`},
+		{`[1 /* x`, ParseOptions{AllowComments: true}, `error: unterminated block comment at offset 3`},
+		{`[1 /`, ParseOptions{AllowComments: true}, `error: unexpected / at offset 3`},
+		{`['a']`, ParseOptions{}, `['a']`},
+	}
+	for _, tt := range tests {
+		got, err := MinifyWithOptions([]byte(tt.input), tt.opts)
+		if msg, ok := strings.CutPrefix(tt.want, "error: "); ok {
+			if err == nil || err.Error() != msg {
+				t.Errorf("%q: got error %v, want %s", tt.input, err, msg)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.input, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
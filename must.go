@@ -0,0 +1,84 @@
+package yjson
+
+import (
+	"fmt"
+	"math"
+)
+
+// Must* 系列在类型不匹配或转换失败时 panic, 适合测试和脚本
+
+func (j *Value) MustString() string {
+	s, err := j.String()
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func (j *Value) MustBool() bool {
+	b, err := j.Bool()
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func (j *Value) MustInt() int {
+	i, err := j.Int64()
+	if err != nil {
+		panic(err)
+	}
+	if i < math.MinInt || i > math.MaxInt {
+		panic(fmt.Errorf("number %d overflows int", i))
+	}
+	return int(i)
+}
+
+func (j *Value) MustInt64() int64 {
+	i, err := j.Int64()
+	if err != nil {
+		panic(err)
+	}
+	return i
+}
+
+func (j *Value) MustUint64() uint64 {
+	u, err := j.Uint64()
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+func (j *Value) MustFloat64() float64 {
+	f, err := j.Float64()
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+func (j *Value) MustArray() []*Value {
+	a, err := j.Array()
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+func (j *Value) MustMap() map[string]*Value {
+	m, err := j.Map()
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// 路径非法时 panic, 用于初始化包级变量
+func MustCompilePath(expr string) *Path {
+	p, err := CompilePath(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
@@ -0,0 +1,59 @@
+package yjson
+
+import "testing"
+
+func TestMust(t *testing.T) {
+	v := mustParse(t, `{"s":"x","b":true,"i":-3,"u":18446744073709551615,"f":0.5,"a":[1],"o":{"k":null}}`)
+	if got := v.Get("s").MustString(); got != "x" {
+		t.Errorf("MustString: got %q", got)
+	}
+	if !v.Get("b").MustBool() {
+		t.Errorf("MustBool: got false")
+	}
+	if got := v.Get("i").MustInt(); got != -3 {
+		t.Errorf("MustInt: got %d", got)
+	}
+	if got := v.Get("i").MustInt64(); got != -3 {
+		t.Errorf("MustInt64: got %d", got)
+	}
+	if got := v.Get("u").MustUint64(); got != 18446744073709551615 {
+		t.Errorf("MustUint64: got %d", got)
+	}
+	if got := v.Get("f").MustFloat64(); got != 0.5 {
+		t.Errorf("MustFloat64: got %v", got)
+	}
+	if got := v.Get("a").MustArray(); len(got) != 1 {
+		t.Errorf("MustArray: got %v", got)
+	}
+	if got := v.Get("o").MustMap(); len(got) != 1 {
+		t.Errorf("MustMap: got %v", got)
+	}
+
+	panics := []struct {
+		name string
+		fn   func()
+		want string
+	}{
+		{"MustString", func() { v.Get("i").MustString() }, "expect string, but get number"},
+		{"MustBool", func() { v.Get("s").MustBool() }, "expect boolean, but get string"},
+		{"MustInt", func() { v.Get("u").MustInt() }, "number 18446744073709551615 overflows int64"},
+		{"MustInt64", func() { v.Get("f").MustInt64() }, "number 0.5 is not an integer"},
+		{"MustUint64", func() { v.Get("i").MustUint64() }, "number -3 overflows uint64"},
+		{"MustFloat64", func() { v.Get("missing").MustFloat64() }, "expect number, but get missing"},
+		{"MustArray", func() { v.Get("o").MustArray() }, "expect array, but get object"},
+		{"MustMap", func() { v.Get("a").MustMap() }, "expect object, but get array"},
+		{"MustCompilePath", func() { MustCompilePath("a..") }, `invalid path "a..": empty key at offset 3`},
+	}
+	for _, tt := range panics {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				err, ok := r.(error)
+				if !ok || err.Error() != tt.want {
+					t.Errorf("got panic %v, want %s", r, tt.want)
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
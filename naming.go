@@ -0,0 +1,63 @@
+package yjson
+
+import (
+	"strings"
+	"unicode"
+)
+
+// 没有在标签中指定名字的字段如何由 Go 字段名得到 JSON 键名
+type NamingStrategy int
+
+const (
+	NamingDefault  NamingStrategy = iota // 使用 Go 字段名
+	SnakeCase                            // UserID -> user_id
+	KebabCase                            // UserID -> user-id
+	LowerCamelCase                       // UserID -> userID
+)
+
+func (n NamingStrategy) apply(name string) string {
+	switch n {
+	case SnakeCase:
+		return strings.ToLower(strings.Join(splitWords(name), "_"))
+	case KebabCase:
+		return strings.ToLower(strings.Join(splitWords(name), "-"))
+	case LowerCamelCase:
+		words := splitWords(name)
+		if len(words) == 0 {
+			return name
+		}
+		words[0] = strings.ToLower(words[0])
+		return strings.Join(words, "")
+	}
+	return name
+}
+
+// 按大小写切分单词, 连续大写视为一个缩写: HTTPServer -> HTTP, Server
+func splitWords(name string) []string {
+	runes := []rune(name)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		switch {
+		case cur == '_':
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		case unicode.IsUpper(cur) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+		case unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+		default:
+			continue
+		}
+		if i > start {
+			words = append(words, string(runes[start:i]))
+		}
+		start = i
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
@@ -0,0 +1,70 @@
+package yjson
+
+import "testing"
+
+func TestNamingStrategy(t *testing.T) {
+	tests := []struct {
+		name                     string
+		snake, kebab, lowerCamel string
+	}{
+		{"Name", "name", "name", "name"},
+		{"UserID", "user_id", "user-id", "userID"},
+		{"HTTPServer", "http_server", "http-server", "httpServer"},
+		{"APIKey2", "api_key2", "api-key2", "apiKey2"},
+		{"Version2Beta", "version2_beta", "version2-beta", "version2Beta"},
+		{"ID", "id", "id", "id"},
+		{"Already_Snake", "already_snake", "already-snake", "alreadySnake"},
+		{"X", "x", "x", "x"},
+		{"ÜberCount", "über_count", "über-count", "überCount"},
+	}
+	for _, tt := range tests {
+		if got := SnakeCase.apply(tt.name); got != tt.snake {
+			t.Errorf("SnakeCase(%s) = %s, want %s", tt.name, got, tt.snake)
+		}
+		if got := KebabCase.apply(tt.name); got != tt.kebab {
+			t.Errorf("KebabCase(%s) = %s, want %s", tt.name, got, tt.kebab)
+		}
+		if got := LowerCamelCase.apply(tt.name); got != tt.lowerCamel {
+			t.Errorf("LowerCamelCase(%s) = %s, want %s", tt.name, got, tt.lowerCamel)
+		}
+		if got := NamingDefault.apply(tt.name); got != tt.name {
+			t.Errorf("NamingDefault(%s) = %s", tt.name, got)
+		}
+	}
+}
+
+type namedFields struct {
+	UserID    int
+	HTTPProxy string
+	Explicit  string `json:"ExplicitName"`
+}
+
+func TestNamingOptions(t *testing.T) {
+	v := namedFields{UserID: 1, HTTPProxy: "p", Explicit: "e"}
+	tests := []struct {
+		naming NamingStrategy
+		want   string
+	}{
+		{NamingDefault, `{"UserID":1,"HTTPProxy":"p","ExplicitName":"e"}`},
+		{SnakeCase, `{"user_id":1,"http_proxy":"p","ExplicitName":"e"}`},
+		{KebabCase, `{"user-id":1,"http-proxy":"p","ExplicitName":"e"}`},
+		{LowerCamelCase, `{"userID":1,"httpProxy":"p","ExplicitName":"e"}`},
+	}
+	for _, tt := range tests {
+		got, err := MarshalWithOptions(v, MarshalOptions{Naming: tt.naming})
+		if err != nil || string(got) != tt.want {
+			t.Errorf("%d: got %s, %v, want %s", tt.naming, got, err, tt.want)
+			continue
+		}
+		var back namedFields
+		if err := UnmarshalWithOptions(got, &back, UnmarshalOptions{Naming: tt.naming}); err != nil || back != v {
+			t.Errorf("%d: round trip got %+v, %v", tt.naming, back, err)
+		}
+	}
+
+	// 同一个类型按不同策略缓存的字段互不影响
+	var back namedFields
+	if err := UnmarshalWithOptions([]byte(`{"user_id":7}`), &back, UnmarshalOptions{}); err != nil || back.UserID != 0 {
+		t.Errorf("default naming should not match user_id: %+v", back)
+	}
+}
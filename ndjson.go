@@ -0,0 +1,98 @@
+package yjson
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// 换行分隔的 JSON (NDJSON / JSON Lines) 中某一行的错误
+type LineError struct {
+	Line int // 从 1 开始
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// 按行读取 NDJSON, 每行一个文档, 跳过空行. 某行解析失败时返回 *LineError,
+// 之后可以继续读取下一行
+type LineDecoder struct {
+	r    *bufio.Reader
+	opts ParseOptions
+	line int
+}
+
+func NewLineDecoder(r io.Reader) *LineDecoder {
+	return &LineDecoder{r: bufio.NewReader(r)}
+}
+
+func (dec *LineDecoder) SetOptions(opts ParseOptions) {
+	dec.opts = opts
+}
+
+// 最近读取的行号
+func (dec *LineDecoder) Line() int {
+	return dec.line
+}
+
+// 读取下一行的文档, 输入结束时返回 io.EOF
+func (dec *LineDecoder) Decode(v *Value) error {
+	for {
+		line, err := dec.r.ReadBytes(LINE_BREAK)
+		if len(line) == 0 && err != nil {
+			return err
+		}
+		dec.line++
+		if err != nil && err != io.EOF {
+			return &LineError{Line: dec.line, Err: err}
+		}
+
+		line = bytes.TrimRight(line, "\r\n")
+		if len(bytes.TrimSpace(line)) == 0 {
+			if err == io.EOF {
+				return io.EOF
+			}
+			continue
+		}
+		j, perr := parseDocument(nil, line, dec.opts)
+		if perr != nil {
+			return &LineError{Line: dec.line, Err: perr}
+		}
+		*v = *j
+		return nil
+	}
+}
+
+// 每个文档输出为紧凑的一行, 忽略 Prefix 和 Indent
+type LineEncoder struct {
+	w    io.Writer
+	opts EncodeOptions
+	buf  []byte
+}
+
+func NewLineEncoder(w io.Writer) *LineEncoder {
+	return &LineEncoder{w: w}
+}
+
+func (enc *LineEncoder) SetOptions(opts EncodeOptions) {
+	opts.Prefix, opts.Indent = "", ""
+	enc.opts = opts
+}
+
+func (enc *LineEncoder) Encode(v *Value) error {
+	dst, err := AppendJSONWithOptions(enc.buf[:0], v, enc.opts)
+	if err != nil {
+		return err
+	}
+	dst = append(dst, LINE_BREAK)
+	_, err = enc.w.Write(dst)
+	enc.buf = dst[:0]
+	return err
+}
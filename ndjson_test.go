@@ -0,0 +1,156 @@
+package yjson
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestLineDecoder(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+		lines []int
+	}{
+		{"basic", "{\"a\":1}\n[2]\n\"x\"\n", []string{`{"a":1}`, `[2]`, `"x"`}, []int{1, 2, 3}},
+		{"no trailing newline", "1\n2", []string{`1`, `2`}, []int{1, 2}},
+		{"crlf", "{\"a\":1}\r\n{\"b\":2}\r\n", []string{`{"a":1}`, `{"b":2}`}, []int{1, 2}},
+		{"blank lines", "\n  \n1\n\t\n2\n\n", []string{`1`, `2`}, []int{3, 5}},
+		{"surrounding spaces", "  {\"a\": [1, 2]}  \n", []string{`{"a":[1,2]}`}, []int{1}},
+		{"empty", "", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := NewLineDecoder(iotest.OneByteReader(strings.NewReader(tt.input)))
+			var got []string
+			var lines []int
+			for {
+				var v Value
+				err := dec.Decode(&v)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, mustEncode(t, &v))
+				lines = append(lines, dec.Line())
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if len(lines) != len(tt.lines) {
+				t.Fatalf("lines: got %v, want %v", lines, tt.lines)
+			}
+			for i := range lines {
+				if lines[i] != tt.lines[i] {
+					t.Errorf("lines: got %v, want %v", lines, tt.lines)
+				}
+			}
+		})
+	}
+}
+
+// 某行出错后可以继续读取下一行
+func TestLineDecoderErrors(t *testing.T) {
+	dec := NewLineDecoder(strings.NewReader("1\n{\"a\":\n[3]\n4 5\n"))
+	var got []string
+	for {
+		var v Value
+		err := dec.Decode(&v)
+		if err == io.EOF {
+			break
+		}
+		var le *LineError
+		if errors.As(err, &le) {
+			if le.Line != dec.Line() {
+				t.Errorf("LineError.Line %d, Line() %d", le.Line, dec.Line())
+			}
+			got = append(got, "error@"+strings.SplitN(le.Error(), ":", 2)[0])
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, mustEncode(t, &v))
+	}
+	want := "1 error@line 2 [3] error@line 4"
+	if strings.Join(got, " ") != want {
+		t.Errorf("got %q, want %s", got, want)
+	}
+
+	err := (&LineError{Line: 2, Err: io.ErrUnexpectedEOF}).Error()
+	if err != "line 2: unexpected EOF" {
+		t.Errorf("got %s", err)
+	}
+	if !errors.Is(&LineError{Line: 1, Err: io.ErrUnexpectedEOF}, io.ErrUnexpectedEOF) {
+		t.Errorf("LineError does not unwrap")
+	}
+
+	// 读取错误附带行号
+	dec = NewLineDecoder(iotest.TimeoutReader(iotest.OneByteReader(strings.NewReader("1"))))
+	var v Value
+	if err := dec.Decode(&v); !errors.As(err, new(*LineError)) || !errors.Is(err, iotest.ErrTimeout) {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestLineDecoderOptions(t *testing.T) {
+	dec := NewLineDecoder(strings.NewReader("{a: 1, // c\n}\n{'b': 2,}\n"))
+	dec.SetOptions(ParseOptions{JSON5: true})
+	var first Value
+	// 文档不能跨行
+	if err := dec.Decode(&first); err == nil {
+		t.Errorf("multi-line document: expected an error")
+	}
+	if err := dec.Decode(&first); err == nil {
+		t.Errorf("line 2: expected an error")
+	}
+	var v Value
+	if err := dec.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustEncode(t, &v); got != `{"b":2}` {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestLineEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewLineEncoder(&buf)
+	enc.SetOptions(EncodeOptions{Indent: "  ", Prefix: ">", SortKeys: true})
+	for _, s := range []string{`{"b": 1, "a": [1, 2]}`, `"x"`, `{"c": {"d": null}}`} {
+		if err := enc.Encode(mustParse(t, s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "{\"a\":[1,2],\"b\":1}\n\"x\"\n{\"c\":{\"d\":null}}\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	// 输出可以被 LineDecoder 读回
+	dec := NewLineDecoder(&buf)
+	n := 0
+	for {
+		var v Value
+		if err := dec.Decode(&v); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		n++
+	}
+	if n != 3 {
+		t.Errorf("read back %d documents", n)
+	}
+
+	enc = NewLineEncoder(failingWriter{})
+	if err := enc.Encode(mustParse(t, `1`)); err == nil {
+		t.Errorf("failing writer: expected an error")
+	}
+}
@@ -0,0 +1,71 @@
+package yjson
+
+import "reflect"
+
+// 解码 null 时如何处理目标
+type NullPolicy int
+
+const (
+	NullDefault NullPolicy = iota // 指针, map, 切片, 接口置为 nil, 其他保持不变, 与 encoding/json 相同
+	NullKeep                      // 全部保持原值
+	NullZero                      // 全部置为零值
+)
+
+// 区分字段缺失, 为 null 和有值三种状态:
+//
+//	缺失: Set == false
+//	null: Set && !Valid
+//	有值: Set && Valid
+//
+// 编码时缺失和 null 都输出 null, 配合 omitzero 可以在缺失时省略字段
+type Nullable[T any] struct {
+	Value T
+	Valid bool // 不为 null
+	Set   bool // 输入中出现过
+}
+
+func NewNullable[T any](v T) Nullable[T] {
+	return Nullable[T]{Value: v, Valid: true, Set: true}
+}
+
+func (n Nullable[T]) IsZero() bool {
+	return !n.Set
+}
+
+func (n Nullable[T]) IsNull() bool {
+	return n.Set && !n.Valid
+}
+
+// 由 decodeState 使用当前的选项解码到 Value 字段
+type nullableDecoder interface {
+	decodeTarget(null bool) reflect.Value
+}
+
+type nullableEncoder interface {
+	encodeSource() (reflect.Value, bool)
+}
+
+func (n *Nullable[T]) decodeTarget(null bool) reflect.Value {
+	var zero T
+	n.Value, n.Valid, n.Set = zero, !null, true
+	return reflect.ValueOf(&n.Value).Elem()
+}
+
+func (n Nullable[T]) encodeSource() (reflect.Value, bool) {
+	return reflect.ValueOf(&n.Value).Elem(), n.Valid
+}
+
+// 按 NullPolicy 处理 null, 返回 false 表示交给默认规则.
+// 实现了 Unmarshaler 的类型自己处理 null, 不受影响
+func (d *decodeState) decodeNull(rv reflect.Value) bool {
+	if d.opts.Nulls == NullDefault {
+		return false
+	}
+	if rv.CanAddr() && rv.Kind() != reflect.Pointer && reflect.PointerTo(rv.Type()).Implements(unmarshalerType) {
+		return false
+	}
+	if d.opts.Nulls == NullZero {
+		rv.Set(reflect.Zero(rv.Type()))
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+package yjson
+
+import (
+	"testing"
+)
+
+type nullTarget struct {
+	Ptr   *int           `json:"ptr"`
+	Int   int            `json:"int"`
+	Str   string         `json:"str"`
+	Slice []int          `json:"slice"`
+	Map   map[string]int `json:"map"`
+	Level level          `json:"level"` // 实现了 Unmarshaler, 自己处理 null
+}
+
+func filledNullTarget() nullTarget {
+	n := 1
+	return nullTarget{Ptr: &n, Int: 2, Str: "s", Slice: []int{3}, Map: map[string]int{"k": 4}, Level: 1}
+}
+
+func TestNullPolicy(t *testing.T) {
+	input := `{"ptr":null,"int":null,"str":null,"slice":null,"map":null,"level":null}`
+	tests := []struct {
+		policy NullPolicy
+		check  func(v nullTarget) bool
+	}{
+		{NullDefault, func(v nullTarget) bool {
+			return v.Ptr == nil && v.Int == 2 && v.Str == "s" && v.Slice == nil && v.Map == nil && v.Level == -1
+		}},
+		{NullKeep, func(v nullTarget) bool {
+			return v.Ptr != nil && *v.Ptr == 1 && v.Int == 2 && v.Str == "s" && len(v.Slice) == 1 && len(v.Map) == 1 && v.Level == -1
+		}},
+		{NullZero, func(v nullTarget) bool {
+			return v.Ptr == nil && v.Int == 0 && v.Str == "" && v.Slice == nil && v.Map == nil && v.Level == -1
+		}},
+	}
+	for _, tt := range tests {
+		v := filledNullTarget()
+		if err := UnmarshalWithOptions([]byte(input), &v, UnmarshalOptions{Nulls: tt.policy}); err != nil {
+			t.Fatal(err)
+		}
+		if !tt.check(v) {
+			t.Errorf("policy %d: got %+v", tt.policy, v)
+		}
+	}
+
+	// 切片元素和顶层值同样适用
+	s := []int{1, 2}
+	if err := UnmarshalWithOptions([]byte(`[null, 5]`), &s, UnmarshalOptions{Nulls: NullZero}); err != nil || s[0] != 0 || s[1] != 5 {
+		t.Errorf("slice elements: got %v, %v", s, err)
+	}
+	n := 7
+	if err := UnmarshalWithOptions([]byte(`null`), &n, UnmarshalOptions{Nulls: NullZero}); err != nil || n != 0 {
+		t.Errorf("top level: got %d, %v", n, err)
+	}
+}
+
+type nullableFields struct {
+	Name  Nullable[string]        `json:"name,omitzero"`
+	Count Nullable[int]           `json:"count,omitzero"`
+	Inner Nullable[decodeAddress] `json:"inner,omitzero"`
+	Ptr   Nullable[*int]          `json:"ptr"`
+}
+
+func TestNullable(t *testing.T) {
+	var v nullableFields
+	if err := Unmarshal([]byte(`{"name":"a","count":null,"inner":{"city":"c"}}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Name != NewNullable("a") {
+		t.Errorf("name: got %+v", v.Name)
+	}
+	if !v.Count.Set || v.Count.Valid || !v.Count.IsNull() || v.Count.IsZero() {
+		t.Errorf("count: got %+v", v.Count)
+	}
+	if !v.Inner.Valid || v.Inner.Value.City != "c" {
+		t.Errorf("inner: got %+v", v.Inner)
+	}
+	if v.Ptr.Set || v.Ptr.IsNull() || !v.Ptr.IsZero() {
+		t.Errorf("ptr: got %+v", v.Ptr)
+	}
+
+	got, err := Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"name":"a","count":null,"inner":{"city":"c","zip":null},"ptr":null}`; string(got) != want {
+		t.Errorf("marshal: got %s, want %s", got, want)
+	}
+
+	// 解码错误的路径不变
+	err = Unmarshal([]byte(`{"count":"x"}`), &v)
+	if err == nil || err.Error() != "unmarshal: cannot unmarshal string into int at count" {
+		t.Errorf("error: got %v", err)
+	}
+
+	// NullPolicy 不影响 Nullable
+	var keep nullableFields
+	keep.Name = NewNullable("old")
+	if err := UnmarshalWithOptions([]byte(`{"name":null}`), &keep, UnmarshalOptions{Nulls: NullKeep}); err != nil || !keep.Name.IsNull() || keep.Name.Value != "" {
+		t.Errorf("NullKeep: got %+v, %v", keep.Name, err)
+	}
+}
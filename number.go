@@ -0,0 +1,217 @@
+package yjson
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// 保留源文本的数字, 由调用方按需转换, 见 ParseOptions.UseRawNumbers
+type Number string
+
+func (n Number) String() string {
+	return string(n)
+}
+
+// 拆分出符号和十六进制数字, 非十六进制时 ok 为 false
+func (n Number) hex() (neg bool, digits string, ok bool) {
+	s := string(n)
+	if strings.HasPrefix(s, "-") {
+		neg, s = true, s[1:]
+	}
+	if len(s) > 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		return neg, s[2:], true
+	}
+	return false, "", false
+}
+
+func (n Number) Int64() (int64, error) {
+	if neg, digits, ok := n.hex(); ok {
+		u, err := strconv.ParseUint(digits, 16, 64)
+		if err != nil || (!neg && u > math.MaxInt64) || (neg && u > 1<<63) {
+			return 0, fmt.Errorf("number %s overflows int64", n)
+		}
+		if neg {
+			return -int64(u), nil
+		}
+		return int64(u), nil
+	}
+
+	i, err := strconv.ParseInt(string(n), 10, 64)
+	if err != nil {
+		if e, ok := err.(*strconv.NumError); ok && e.Err == strconv.ErrRange {
+			return 0, fmt.Errorf("number %s overflows int64", n)
+		}
+		return 0, fmt.Errorf("number %s is not an integer", n)
+	}
+	return i, nil
+}
+
+func (n Number) Uint64() (uint64, error) {
+	if neg, digits, ok := n.hex(); ok {
+		u, err := strconv.ParseUint(digits, 16, 64)
+		if err != nil || (neg && u != 0) {
+			return 0, fmt.Errorf("number %s overflows uint64", n)
+		}
+		return u, nil
+	}
+
+	u, err := strconv.ParseUint(string(n), 10, 64)
+	if err != nil {
+		if e, ok := err.(*strconv.NumError); ok && e.Err == strconv.ErrRange {
+			return 0, fmt.Errorf("number %s overflows uint64", n)
+		}
+		// 负整数, 包括超出 int64 范围的
+		if strings.HasPrefix(string(n), "-") {
+			_, err := strconv.ParseInt(string(n), 10, 64)
+			if e, ok := err.(*strconv.NumError); err == nil || (ok && e.Err == strconv.ErrRange) {
+				return 0, fmt.Errorf("number %s overflows uint64", n)
+			}
+		}
+		return 0, fmt.Errorf("number %s is not an integer", n)
+	}
+	return u, nil
+}
+
+func (n Number) Float64() (float64, error) {
+	if neg, digits, ok := n.hex(); ok {
+		u, err := strconv.ParseUint(digits, 16, 64)
+		if err != nil {
+			return 0, fmt.Errorf("number %s overflows float64", n)
+		}
+		if neg {
+			return -float64(u), nil
+		}
+		return float64(u), nil
+	}
+
+	switch n {
+	case NAN:
+		return math.NaN(), nil
+	case INFINITY:
+		return math.Inf(1), nil
+	case "-" + INFINITY:
+		return math.Inf(-1), nil
+	}
+
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		if e, ok := err.(*strconv.NumError); ok && e.Err == strconv.ErrRange {
+			return f, fmt.Errorf("number %s overflows float64", n)
+		}
+		return 0, fmt.Errorf("invalid number %s", n)
+	}
+	return f, nil
+}
+
+// 按数值精确比较两个数字, 返回 -1, 0 或 1. 两边都是整数时直接比较; 否则先比较
+// float64 近似值, 舍入是单调的, 近似值不同时大小关系与精确值相同, 相同时再按
+// 精确值比较. NaN 与任何数字都不可比较, ok 为 false
+func compareNumbers(l, r *Value) (cmp int, ok bool) {
+	if l.isInteger() && r.isInteger() {
+		return compareIntegers(l, r), true
+	}
+
+	a, err1 := l.Float64()
+	b, err2 := r.Float64()
+	if err1 == nil && err2 == nil {
+		switch {
+		case math.IsNaN(a) || math.IsNaN(b):
+			return 0, false
+		case a < b:
+			return -1, true
+		case a > b:
+			return 1, true
+		}
+	}
+	x, okx := l.exactNumber()
+	y, oky := r.exactNumber()
+	if okx && oky {
+		return x.Cmp(y), true
+	}
+	// ±Inf 没有精确值, 只能与相同的无穷大相等
+	if err1 == nil && err2 == nil && a == b {
+		return 0, true
+	}
+	return 0, false
+}
+
+func equalNumbers(l, r *Value) bool {
+	cmp, ok := compareNumbers(l, r)
+	return ok && cmp == 0
+}
+
+func (j *Value) isInteger() bool {
+	return j.repr == REPR_INT || j.repr == REPR_UINT
+}
+
+// 两边都是 REPR_INT 或 REPR_UINT
+func compareIntegers(l, r *Value) int {
+	switch {
+	case l.repr == REPR_INT && r.repr == REPR_INT:
+		return compareOrdered(l.i, r.i)
+	case l.repr == REPR_UINT && r.repr == REPR_UINT:
+		return compareOrdered(uint64(l.i), uint64(r.i))
+	case l.repr == REPR_INT:
+		if l.i < 0 {
+			return -1
+		}
+		return compareOrdered(uint64(l.i), uint64(r.i))
+	}
+	if r.i < 0 {
+		return 1
+	}
+	return compareOrdered(uint64(l.i), uint64(r.i))
+}
+
+func compareOrdered[T int64 | uint64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// 数字的精确值. 源文本是 RFC 8259 数字时按源文本, 否则按存放的值; NaN 和 ±Inf
+// 没有精确值, ok 为 false
+func (j *Value) exactNumber() (*big.Rat, bool) {
+	switch j.repr {
+	case REPR_INT:
+		return new(big.Rat).SetInt64(j.i), true
+	case REPR_UINT:
+		return new(big.Rat).SetUint64(uint64(j.i)), true
+	}
+	if len(j.raw) > 0 && isDecimalNumber(j.raw) {
+		return new(big.Rat).SetString(string(j.raw))
+	}
+
+	switch n := j.num.(type) {
+	case Number:
+		if len(n) > 0 && isDecimalNumber([]byte(n)) {
+			return new(big.Rat).SetString(string(n))
+		}
+	case *big.Int:
+		return new(big.Rat).SetInt(n), true
+	case *big.Float:
+		if n.IsInf() {
+			return nil, false
+		}
+		r, _ := n.Rat(nil)
+		return r, true
+	}
+	f, err := j.Float64()
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, false
+	}
+	return new(big.Rat).SetFloat64(f), true
+}
+
+// 符合 RFC 8259 number 语法, 不包括十六进制, NaN 等扩展形式
+func isDecimalNumber(b []byte) bool {
+	v := validator{data: b}
+	return v.number(0) == len(b)
+}
@@ -0,0 +1,422 @@
+package yjson
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestParseRawNumbers(t *testing.T) {
+	input := `[1.10, 1e2, -0, 12345678901234567890123, 0.1000000000000000000001]`
+	v, err := ParseWithOptions([]byte(input), ParseOptions{UseRawNumbers: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"1.10", "1e2", "-0", "12345678901234567890123", "0.1000000000000000000001"}
+	for i, item := range v.MustArray() {
+		n, ok := item.Interface().(Number)
+		if !ok {
+			t.Fatalf("item %d: got %T", i, item.Interface())
+		}
+		if string(n) != want[i] {
+			t.Errorf("item %d: got %s, want %s", i, n, want[i])
+		}
+	}
+	// 编码时原样输出
+	if got := mustEncode(t, v); got != `[1.10,1e2,-0,12345678901234567890123,0.1000000000000000000001]` {
+		t.Errorf("encode: got %s", got)
+	}
+
+	// 之后再决定如何解释
+	items := v.MustArray()
+	if i, err := items[1].Int64(); err == nil {
+		t.Errorf("1e2 as int64: got %d, want an error", i)
+	}
+	if f, err := items[1].Float64(); err != nil || f != 100 {
+		t.Errorf("1e2 as float64: got %v, %v", f, err)
+	}
+	if _, err := items[3].Int64(); err == nil || err.Error() != "number 12345678901234567890123 overflows int64" {
+		t.Errorf("big as int64: got %v", err)
+	}
+	if items[0].NumberType() != NUMBER_FLOAT || items[3].NumberType() != NUMBER_INT {
+		t.Errorf("number types: got %v, %v", items[0].NumberType(), items[3].NumberType())
+	}
+}
+
+func TestParseExactIntegers(t *testing.T) {
+	tests := []struct {
+		input  string
+		i      int64
+		iErr   string
+		u      uint64
+		uErr   string
+		encode string
+	}{
+		{`9007199254740993`, 9007199254740993, "", 9007199254740993, "", `9007199254740993`},
+		{`9223372036854775807`, math.MaxInt64, "", math.MaxInt64, "", `9223372036854775807`},
+		{`-9223372036854775808`, math.MinInt64, "", 0, "number -9223372036854775808 overflows uint64", `-9223372036854775808`},
+		{`18446744073709551615`, 0, "number 18446744073709551615 overflows int64", math.MaxUint64, "", `18446744073709551615`},
+		{`-1`, -1, "", 0, "number -1 overflows uint64", `-1`},
+		{`2.5`, 0, "number 2.5 is not an integer", 0, "number 2.5 is not an integer", `2.5`},
+		{`1e3`, 1000, "", 1000, "", `1000`},
+	}
+	for _, tt := range tests {
+		v := mustParse(t, tt.input)
+		i, err := v.Int64()
+		checkNumberResult(t, tt.input+" Int64", i, tt.i, err, tt.iErr)
+		u, err := v.Uint64()
+		checkNumberResult(t, tt.input+" Uint64", u, tt.u, err, tt.uErr)
+		if got := mustEncode(t, v); got != tt.encode {
+			t.Errorf("%s: encode got %s, want %s", tt.input, got, tt.encode)
+		}
+	}
+
+	// 超出 uint64 的整数按 float64 保存
+	v := mustParse(t, `18446744073709551616`)
+	if _, ok := v.Interface().(float64); !ok {
+		t.Errorf("got %T, want float64", v.Interface())
+	}
+	if _, ok := mustParse(t, `9007199254740993`).Interface().(int64); !ok {
+		t.Errorf("want int64")
+	}
+	if _, ok := mustParse(t, `18446744073709551615`).Interface().(uint64); !ok {
+		t.Errorf("want uint64")
+	}
+}
+
+func checkNumberResult[T comparable](t *testing.T, name string, got, want T, err error, wantErr string) {
+	t.Helper()
+	if wantErr != "" {
+		if err == nil || err.Error() != wantErr {
+			t.Errorf("%s: got error %v, want %s", name, err, wantErr)
+		}
+		return
+	}
+	if err != nil || got != want {
+		t.Errorf("%s: got %v, %v, want %v", name, got, err, want)
+	}
+}
+
+func TestParseBigNumbers(t *testing.T) {
+	opts := ParseOptions{UseBigNumbers: true}
+	v, err := ParseWithOptions([]byte(`[12345678901234567890123, -98765432109876543210, 1e400, 0.1, 3.141592653589793238462643383279, 5]`), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := v.MustArray()
+
+	b, ok := items[0].Interface().(*big.Int)
+	if !ok || b.String() != "12345678901234567890123" {
+		t.Errorf("item 0: got %T %v", items[0].Interface(), items[0].Interface())
+	}
+	if b, ok := items[1].Interface().(*big.Int); !ok || b.String() != "-98765432109876543210" {
+		t.Errorf("item 1: got %v", items[1].Interface())
+	}
+	f, ok := items[2].Interface().(*big.Float)
+	if !ok || f.Text('g', 5) != "1e+400" {
+		t.Errorf("item 2: got %v", items[2].Interface())
+	}
+	// 0.1 能由 float64 的最短表示还原, 不需要 big.Float
+	if _, ok := items[3].Interface().(float64); !ok {
+		t.Errorf("item 3: got %T", items[3].Interface())
+	}
+	pi, ok := items[4].Interface().(*big.Float)
+	if !ok || pi.Prec() != BIG_FLOAT_PREC || !strings.HasPrefix(pi.Text('f', 30), "3.141592653589793238462643383279") {
+		t.Errorf("item 4: got %v", items[4].Interface())
+	}
+	if _, ok := items[5].Interface().(int64); !ok {
+		t.Errorf("item 5: got %T", items[5].Interface())
+	}
+
+	if got := mustEncode(t, v); got != `[12345678901234567890123,-98765432109876543210,1e+400,0.1,3.141592653589793238462643383279,5]` {
+		t.Errorf("encode: got %s", got)
+	}
+	if _, err := items[2].Float64(); err == nil {
+		t.Errorf("1e400 as float64: want an error")
+	}
+
+	// 没有 UseBigNumbers 时超出范围的小数是错误
+	if _, err := Parse([]byte(`1e400`)); err == nil || err.Error() != "invalid number: 1e400" {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestParseNaN(t *testing.T) {
+	v, err := ParseWithOptions([]byte(`[NaN, Infinity, -Infinity, 1]`), ParseOptions{AllowNaN: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := v.MustArray()
+	if f := items[0].MustFloat64(); !math.IsNaN(f) {
+		t.Errorf("NaN: got %v", f)
+	}
+	if f := items[1].MustFloat64(); !math.IsInf(f, 1) {
+		t.Errorf("Infinity: got %v", f)
+	}
+	if f := items[2].MustFloat64(); !math.IsInf(f, -1) {
+		t.Errorf("-Infinity: got %v", f)
+	}
+	if items[0].NumberType() != NUMBER_FLOAT {
+		t.Errorf("NaN should be a float")
+	}
+
+	if _, err := v.Encode(); err == nil || err.Error() != "cannot encode NaN without AllowNaN" {
+		t.Errorf("encode without AllowNaN: got %v", err)
+	}
+	b, err := v.EncodeWithOptions(EncodeOptions{AllowNaN: true})
+	if err != nil || string(b) != `[NaN,Infinity,-Infinity,1]` {
+		t.Errorf("encode: got %s, %v", b, err)
+	}
+	b, err = NewFloat(math.Inf(-1)).EncodeWithOptions(EncodeOptions{AllowNaN: true})
+	if err != nil || string(b) != `-Infinity` {
+		t.Errorf("encode -Inf: got %s, %v", b, err)
+	}
+
+	runParseTests(t, ParseOptions{AllowNaN: true}, []parseTest{
+		{`NaNa`, `NaN`},
+		{`[Nan]`, `error: expect: NaN, but get: Nan`},
+		{`[-NaN]`, `error: expect: Infinity, but get: NaN]`},
+		{`[Inf]`, `error: expect: Infinity, but get: Inf]`},
+		{`Infin`, `error: EOF`},
+	})
+	runParseTests(t, ParseOptions{}, []parseTest{
+		{`NaN`, `error: not match`},
+		{`[Infinity]`, `error: not match`},
+		{`-Infinity`, `error: invalid number: expect digit, but get: I`},
+	})
+
+	// UseRawNumbers 保留原文
+	v, err = ParseWithOptions([]byte(`-Infinity`), ParseOptions{AllowNaN: true, UseRawNumbers: true})
+	if err != nil || v.Interface() != Number("-Infinity") {
+		t.Errorf("raw: got %v, %v", v.Interface(), err)
+	}
+	if f, err := v.Float64(); err != nil || !math.IsInf(f, -1) {
+		t.Errorf("raw Float64: got %v, %v", f, err)
+	}
+}
+
+func TestParseHexNumbers(t *testing.T) {
+	opts := ParseOptions{AllowHexNumbers: true}
+	runParseTests(t, opts, []parseTest{
+		{`0x1F`, `31`},
+		{`0XaB`, `171`},
+		{`-0xff`, `-255`},
+		{`[0x0, 0x7FFFFFFFFFFFFFFF]`, `[0,9223372036854775807]`},
+		{`0xFFFFFFFFFFFFFFFF`, `18446744073709551615`},
+		{`-0x8000000000000000`, `-9223372036854775808`},
+		{`0x`, `error: invalid number: expect hex digit`},
+		{`[0xG]`, `error: invalid number: expect hex digit`},
+		{`0x10000000000000000`, `error: invalid number: 0x10000000000000000`},
+		{`0.5`, `0.5`},
+	})
+
+	for _, input := range []string{`0x1F`, `-0x10`} {
+		v, err := ParseWithOptions([]byte(input), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.NumberType() != NUMBER_INT {
+			t.Errorf("%s: got number type %v", input, v.NumberType())
+		}
+		if _, ok := v.Interface().(int64); !ok {
+			t.Errorf("%s: got %T", input, v.Interface())
+		}
+	}
+
+	v, err := ParseWithOptions([]byte(`-0x10000000000000000`), ParseOptions{AllowHexNumbers: true, UseBigNumbers: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, ok := v.Interface().(*big.Int); !ok || b.String() != "-18446744073709551616" {
+		t.Errorf("big: got %v", v.Interface())
+	}
+
+	// UseRawNumbers 保留十六进制原文, Number 的方法能识别
+	v, err = ParseWithOptions([]byte(`-0x1f`), ParseOptions{AllowHexNumbers: true, UseRawNumbers: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i, err := v.Int64(); err != nil || i != -31 {
+		t.Errorf("raw Int64: got %d, %v", i, err)
+	}
+	if _, err := v.Uint64(); err == nil {
+		t.Errorf("raw Uint64: want an error")
+	}
+
+	runParseTests(t, ParseOptions{}, []parseTest{
+		{`[0x1F]`, `error: expect: , or ], but get: x`},
+	})
+}
+
+func TestNumberType(t *testing.T) {
+	tests := []struct {
+		input string
+		want  NumberKind
+	}{
+		{`1`, NUMBER_INT},
+		{`-0`, NUMBER_INT},
+		{`18446744073709551616`, NUMBER_INT},
+		{`1.0`, NUMBER_FLOAT},
+		{`1e0`, NUMBER_FLOAT},
+		{`-2.5E-3`, NUMBER_FLOAT},
+	}
+	for _, tt := range tests {
+		for _, opts := range []ParseOptions{{}, {UseRawNumbers: true}, {UseBigNumbers: true}} {
+			v, err := ParseWithOptions([]byte(tt.input), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := v.NumberType(); got != tt.want {
+				t.Errorf("%s %+v: got %v, want %v", tt.input, opts, got, tt.want)
+			}
+		}
+	}
+
+	// 1 和 1.0 的值相等, 只是子类型不同
+	v := mustParse(t, `[1, 1.0]`).MustArray()
+	if v[0].NumberType() == v[1].NumberType() || !Equal(v[0], v[1]) {
+		t.Errorf("1 and 1.0: got %v, %v", v[0].NumberType(), v[1].NumberType())
+	}
+	if mustParse(t, `"1"`).NumberType() != 0 || mustParse(t, `null`).NumberType() != 0 {
+		t.Errorf("non-numbers should have number type 0")
+	}
+	if NewInt(1).NumberType() != NUMBER_INT || NewFloat(1).NumberType() != NUMBER_FLOAT {
+		t.Errorf("constructors: got %v, %v", NewInt(1).NumberType(), NewFloat(1).NumberType())
+	}
+}
+
+func TestNumberAccessors(t *testing.T) {
+	tests := []struct {
+		n    Number
+		i    int64
+		iErr string
+		u    uint64
+		uErr string
+		f    float64
+		fErr string
+	}{
+		{"42", 42, "", 42, "", 42, ""},
+		{"-7", -7, "", 0, "number -7 overflows uint64", -7, ""},
+		{"9223372036854775808", 0, "number 9223372036854775808 overflows int64", 1 << 63, "", 1 << 63, ""},
+		{"18446744073709551616", 0, "number 18446744073709551616 overflows int64", 0, "number 18446744073709551616 overflows uint64", 1 << 64, ""},
+		{"-9223372036854775809", 0, "number -9223372036854775809 overflows int64", 0, "number -9223372036854775809 overflows uint64", -(1 << 63), ""},
+		{"1.5", 0, "number 1.5 is not an integer", 0, "number 1.5 is not an integer", 1.5, ""},
+		{"1e2", 0, "number 1e2 is not an integer", 0, "number 1e2 is not an integer", 100, ""},
+		{"1e400", 0, "number 1e400 is not an integer", 0, "number 1e400 is not an integer", math.Inf(1), "number 1e400 overflows float64"},
+		{"abc", 0, "number abc is not an integer", 0, "number abc is not an integer", 0, "invalid number abc"},
+		{"0x10", 16, "", 16, "", 16, ""},
+		{"-1.5", 0, "number -1.5 is not an integer", 0, "number -1.5 is not an integer", -1.5, ""},
+	}
+	for _, tt := range tests {
+		i, err := tt.n.Int64()
+		checkNumberResult(t, string(tt.n)+" Int64", i, tt.i, err, tt.iErr)
+		u, err := tt.n.Uint64()
+		checkNumberResult(t, string(tt.n)+" Uint64", u, tt.u, err, tt.uErr)
+		f, err := tt.n.Float64()
+		checkNumberResult(t, string(tt.n)+" Float64", f, tt.f, err, tt.fErr)
+		if tt.n.String() != string(tt.n) {
+			t.Errorf("%s: String got %s", tt.n, tt.n.String())
+		}
+	}
+}
+
+func TestNumberHook(t *testing.T) {
+	var seen []Number
+	hook := func(n Number) (interface{}, error) {
+		seen = append(seen, n)
+		r, ok := new(big.Rat).SetString(string(n))
+		if !ok {
+			return nil, fmt.Errorf("not a decimal")
+		}
+		return r, nil
+	}
+	// 优先于 UseRawNumbers 和 UseBigNumbers
+	opts := ParseOptions{NumberHook: hook, UseRawNumbers: true, UseBigNumbers: true}
+	v, err := ParseWithOptions([]byte(`{"price":19.99,"qty":3,"total":59.97}`), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(numberStrings(seen), ",") != "19.99,3,59.97" {
+		t.Errorf("hook calls: got %v", seen)
+	}
+	price, ok := v.Get("price").Interface().(*big.Rat)
+	if !ok || price.RatString() != "1999/100" {
+		t.Fatalf("price: got %T %v", v.Get("price").Interface(), v.Get("price").Interface())
+	}
+	qty := v.Get("qty").Interface().(*big.Rat)
+	total := v.Get("total").Interface().(*big.Rat)
+	if new(big.Rat).Mul(price, qty).Cmp(total) != 0 {
+		t.Errorf("19.99*3 != 59.97")
+	}
+	if v.Get("price").NumberType() != NUMBER_FLOAT || v.Get("qty").NumberType() != NUMBER_INT {
+		t.Errorf("number types are kept")
+	}
+	if got := mustEncode(t, v); got != `{"price":19.99,"qty":3,"total":59.97}` {
+		t.Errorf("encode: got %s", got)
+	}
+	// *big.Rat 的 String() 不是 JSON 数字, 编码时使用源文本
+	if b, err := v.Canonical(); err != nil || string(b) != `{"price":19.99,"qty":3,"total":59.97}` {
+		t.Errorf("canonical: got %s, %v", b, err)
+	}
+
+	_, err = ParseWithOptions([]byte(`[1]`), ParseOptions{NumberHook: func(Number) (interface{}, error) {
+		return nil, fmt.Errorf("rejected")
+	}})
+	if err == nil || err.Error() != "number hook: 1: rejected" {
+		t.Errorf("got %v", err)
+	}
+}
+
+func numberStrings(ns []Number) []string {
+	s := make([]string, len(ns))
+	for i, n := range ns {
+		s[i] = string(n)
+	}
+	return s
+}
+
+func TestParseLenientNumbers(t *testing.T) {
+	runParseTests(t, ParseOptions{LenientNumbers: true}, []parseTest{
+		{`+5`, `5`},
+		{`007`, `7`},
+		{`-007.50`, `-7.5`},
+		{`.5`, `0.5`},
+		{`5.`, `5`},
+		{`-.25e2`, `-25`},
+		{`[+1, 00, .0]`, `[1,0,0]`},
+		{`.`, `error: invalid number: expect digit after decimal point`},
+		{`+`, `error: EOF`},
+		{`[+]`, `error: invalid number: expect digit, but get: ]`},
+		{`++1`, `error: invalid number: expect digit, but get: +`},
+	})
+
+	// 宽松写法按数值分类
+	for input, want := range map[string]NumberKind{`+5`: NUMBER_INT, `007`: NUMBER_INT, `5.`: NUMBER_FLOAT, `.5`: NUMBER_FLOAT} {
+		v, err := ParseWithOptions([]byte(input), ParseOptions{LenientNumbers: true})
+		if err != nil || v.NumberType() != want {
+			t.Errorf("%s: got %v, %v", input, v.NumberType(), err)
+		}
+	}
+
+	// UseRawNumbers 保留原文, 编码时转换为合法的 JSON 数字
+	v, err := ParseWithOptions([]byte(`[+5, 007, .5]`), ParseOptions{LenientNumbers: true, UseRawNumbers: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.MustArray()[1].Interface() != Number("007") {
+		t.Errorf("raw: got %v", v.MustArray()[1].Interface())
+	}
+	if got := mustEncode(t, v); got != `[5,7,0.5]` {
+		t.Errorf("encode: got %s", got)
+	}
+
+	for _, input := range []string{`[+5]`, `[007]`, `[.5]`, `[5.]`} {
+		for _, opts := range []ParseOptions{{}, {Strict: true, LenientNumbers: true}} {
+			if _, err := ParseWithOptions([]byte(input), opts); err == nil {
+				t.Errorf("%s strict=%v: expected an error", input, opts.Strict)
+			}
+		}
+	}
+}
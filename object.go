@@ -0,0 +1,48 @@
+package yjson
+
+// 按顺序返回对象的键, 非对象返回 nil
+func (j *Value) Keys() []string {
+	if j.Type() != JSON_OBJECT || j.load() != nil {
+		return nil
+	}
+	keys := make([]string, len(j.keys))
+	copy(keys, j.keys)
+	return keys
+}
+
+// 按键的顺序遍历对象成员, fn 返回 false 时停止
+func (j *Value) Range(fn func(key string, v *Value) bool) {
+	if j.Type() != JSON_OBJECT || j.load() != nil {
+		return
+	}
+	m := j.obj
+	for _, k := range j.keys {
+		if !fn(k, m[k]) {
+			return
+		}
+	}
+}
+
+// 写入对象成员, 新的键追加到末尾, 已有的键保持原位置. 调用方负责先 load
+func (j *Value) setMember(key string, v *Value) {
+	m := j.obj
+	if _, ok := m[key]; !ok {
+		j.keys = append(j.keys, key)
+	}
+	m[key] = v
+}
+
+func (j *Value) deleteMember(key string) bool {
+	m := j.obj
+	if _, ok := m[key]; !ok {
+		return false
+	}
+	delete(m, key)
+	for i, k := range j.keys {
+		if k == key {
+			j.keys = append(j.keys[:i], j.keys[i+1:]...)
+			break
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package yjson
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestKeys(t *testing.T) {
+	tests := []struct {
+		doc  string
+		want []string
+	}{
+		{`{"z":1,"a":2,"m":3}`, []string{"z", "a", "m"}},
+		{`{"b":1,"a":2,"b":3}`, []string{"b", "a"}},
+		{`{}`, []string{}},
+		{`[1,2]`, nil},
+		{`"s"`, nil},
+	}
+	for _, opts := range []ParseOptions{{}, {Lazy: true}} {
+		for _, tt := range tests {
+			v, err := ParseWithOptions([]byte(`{"x":`+tt.doc+`}`), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := v.Get("x").Keys(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%+v %s: got %q, want %q", opts, tt.doc, got, tt.want)
+			}
+		}
+	}
+
+	// 返回的是副本
+	v := mustParse(t, `{"a":1,"b":2}`)
+	v.Keys()[0] = "changed"
+	if got := v.Keys(); got[0] != "a" {
+		t.Errorf("Keys should return a copy, got %q", got)
+	}
+}
+
+func TestRange(t *testing.T) {
+	v := mustParse(t, `{"c":1,"a":2,"b":3}`)
+	var visited []string
+	v.Range(func(key string, v *Value) bool {
+		visited = append(visited, key+"="+mustEncode(t, v))
+		return true
+	})
+	if got := strings.Join(visited, ","); got != "c=1,a=2,b=3" {
+		t.Errorf("got %s", got)
+	}
+
+	visited = visited[:0]
+	v.Range(func(key string, v *Value) bool {
+		visited = append(visited, key)
+		return key != "a"
+	})
+	if got := strings.Join(visited, ","); got != "c,a" {
+		t.Errorf("stop: got %s", got)
+	}
+
+	calls := 0
+	mustParse(t, `[1,2]`).Range(func(string, *Value) bool { calls++; return true })
+	if calls != 0 {
+		t.Errorf("array: fn called %d times", calls)
+	}
+}
+
+// 编码按源文本中键的顺序输出, Set 追加的键在最后
+func TestKeyOrderRoundTrip(t *testing.T) {
+	input := `{"zeta":1,"alpha":{"y":true,"x":false},"mid":[{"b":1,"a":2}]}`
+	v := mustParse(t, input)
+	if got := mustEncode(t, v); got != input {
+		t.Errorf("got %s", got)
+	}
+	if err := v.Set("alpha.w", NewNull()); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Set("zeta", NewInt(0)); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"zeta":0,"alpha":{"y":true,"x":false,"w":null},"mid":[{"b":1,"a":2}]}`
+	if got := mustEncode(t, v); got != want {
+		t.Errorf("after set: got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,108 @@
+package yjson
+
+// 解析选项, 零值为默认的宽松模式
+type ParseOptions struct {
+	// 严格遵循 RFC 8259: 拒绝未转义的控制字符, 非法 UTF-8, 孤立的代理项,
+	// 以及文档结束之后多余的内容. 开启后下面的扩展选项全部失效
+	Strict bool
+
+	// 允许 // 行注释和 /* */ 块注释
+	AllowComments bool
+
+	// 允许数组和对象最后一个成员之后的逗号, 如 [1,2,] 和 {"a":1,}
+	AllowTrailingCommas bool
+
+	// JSON5 方言: 不加引号的键, 单引号字符串, 多行字符串, 数字前的 +, .5 和 5.
+	// 形式的小数, 同时隐含 AllowComments, AllowTrailingCommas, AllowNaN 和 AllowHexNumbers
+	JSON5 bool
+
+	// 对象中出现重复键时的处理方式, 默认 LastWins
+	DuplicateKeys DuplicateKeyPolicy
+
+	// 数组和对象允许的最大嵌套层数, 0 表示使用 DEFAULT_MAX_DEPTH
+	MaxDepth int
+
+	// 数字保留为源文本 (Number), 不转换为 float64, 由调用方自行决定如何解释
+	UseRawNumbers bool
+
+	// 超出 int64/uint64 范围的整数保存为 *big.Int, 超出 float64 范围或
+	// 无法用 float64 精确表示的小数保存为 *big.Float
+	UseBigNumbers bool
+
+	// 接受 NaN, Infinity, -Infinity 字面量, 解析为对应的 float64
+	AllowNaN bool
+
+	// 接受 0x1F 形式的十六进制整数, 按整数保存
+	AllowHexNumbers bool
+
+	// 宽松的数字语法: 前导 +, 前导零 (007), 以及 .5 和 5. 形式的小数
+	LenientNumbers bool
+
+	// 每个数字都交给该函数构造值 (如 *big.Rat 或十进制定点数), 优先于
+	// UseRawNumbers 和 UseBigNumbers, 返回错误时解析失败
+	NumberHook func(n Number) (interface{}, error)
+
+	// 相同的对象键共享同一份字符串, 解析大量结构相同的对象时显著减少内存.
+	// 复用同一个 Parser 时键表在多次解析之间保留
+	InternKeys bool
+
+	// 只完整解析最外层, 内部的对象和数组先记录源文本, 第一次访问时才展开,
+	// 从大文档中取少量字段时可以跳过其余部分. 内部的语法错误 (括号配对除外)
+	// 推迟到展开时才发现, 需要提前检查时调用 Expand.
+	// 第一次读取时展开会修改节点本身, 因此调用 Expand 之前不能在多个 goroutine 中
+	// 同时读取同一棵树, 包括 Get, Interface 和编译后的 Path
+	Lazy bool
+
+	// 大于 1 时, 根为数组且不小于 PARALLEL_MIN_SIZE 的输入先扫描出每个元素的边界,
+	// 再用这么多个 goroutine 并行解析元素, 结果与顺序解析相同. Lazy 模式和
+	// Arena 解析时不生效
+	Workers int
+
+	// 新建对象 map 和数组切片时预分配的容量, 已知文档结构时可以减少扩容
+	ObjectCapacity int
+	ArrayCapacity  int
+
+	// 按上一个同一深度的对象或数组的大小预分配, 适合由结构相同的元素组成的大数组,
+	// 以及用同一个 Parser 解析结构相同的文档. 优先于 ObjectCapacity 和 ArrayCapacity
+	AdaptiveCapacity bool
+}
+
+// *big.Float 的精度 (bit)
+const BIG_FLOAT_PREC = 256
+
+const DEFAULT_MAX_DEPTH = 10000
+
+type DuplicateKeyPolicy int
+
+const (
+	LastWins         DuplicateKeyPolicy = iota // 保留最后一个值
+	FirstWins                                  // 保留第一个值
+	ErrorOnDuplicate                           // 返回错误
+)
+
+func (o ParseOptions) normalize() ParseOptions {
+	if o.MaxDepth <= 0 {
+		o.MaxDepth = DEFAULT_MAX_DEPTH
+	}
+	if o.Strict {
+		o.AllowComments = false
+		o.AllowTrailingCommas = false
+		o.JSON5 = false
+		o.AllowNaN = false
+		o.AllowHexNumbers = false
+		o.LenientNumbers = false
+		return o
+	}
+	if o.JSON5 {
+		o.AllowComments = true
+		o.AllowTrailingCommas = true
+		o.AllowNaN = true
+		o.AllowHexNumbers = true
+	}
+	return o
+}
+
+// 前导 + 以及 .5 和 5. 形式的小数. JSON5 允许这些写法, 但和 JSON 一样不允许前导零
+func (o ParseOptions) lenientDecimals() bool {
+	return o.LenientNumbers || o.JSON5
+}
@@ -0,0 +1,142 @@
+package yjson
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// 小于这个大小的输入即使设置了 Workers 也按顺序解析, 并行的开销不划算
+const PARALLEL_MIN_SIZE = 1 << 20
+
+// 根数组中一个对象或数组元素在输入中的范围
+type elementSpan struct {
+	index      int
+	start, end int
+}
+
+// 先用 skipContainer 找出根数组每个元素的边界 (标量直接解析), 再把对象和数组
+// 元素按顺序分成 Workers 段并行解析. 根不是数组时返回 ok == false
+func (p *Parser) parseParallel(res *Value) (ok bool, err error) {
+	if err := p.absorbLack(); err != nil {
+		return false, nil
+	}
+	if b, _ := p.peak(); b != LB {
+		return false, nil
+	}
+
+	elems, spans, err := p.splitArray()
+	if err != nil {
+		return true, err
+	}
+	if p.opts.Strict {
+		if err := p.absorbLack(); err != io.EOF {
+			return true, fmt.Errorf("unexpected data after document at offset %d", p.i)
+		}
+	}
+
+	workers := p.opts.Workers
+	if workers > len(spans) {
+		workers = len(spans)
+	}
+	errs := make([]error, workers)
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		chunk := spans[len(spans)*w/workers : len(spans)*(w+1)/workers]
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			errs[w] = p.parseSpans(chunk, elems, &failed)
+			if errs[w] != nil {
+				failed.Store(true)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	// 出错后其它段会提前结束, 报告段号最小的真实错误
+	for _, err := range errs {
+		if err != nil && err != errParallelAborted {
+			return true, err
+		}
+	}
+
+	res.valueType = JSON_ARRAY
+	res.arr = elems
+	return true, nil
+}
+
+// 读取根数组, 标量元素直接解析, 只记录对象和数组元素的范围
+func (p *Parser) splitArray() ([]*Value, []elementSpan, error) {
+	p.i++
+	if err := p.enter(); err != nil {
+		return nil, nil, err
+	}
+	defer func() { p.depth-- }()
+
+	elems := make([]*Value, 0)
+	spans := make([]elementSpan, 0)
+	if err := p.absorbLack(); err != nil {
+		return nil, nil, err
+	}
+	if b, _ := p.peak(); b == RB {
+		p.i++
+		return elems, spans, nil
+	}
+
+	for {
+		if err := p.absorbLack(); err != nil {
+			return nil, nil, err
+		}
+		v := &Value{}
+		start := p.i
+		if b, _ := p.peak(); b == OB || b == LB {
+			if err := p.skipContainer(v); err != nil {
+				return nil, nil, err
+			}
+			spans = append(spans, elementSpan{index: len(elems), start: start, end: p.i})
+		} else if err := p.handle(v); err != nil {
+			return nil, nil, err
+		} else if p.opts.Strict && !utf8.Valid(p.buf[start:p.i]) {
+			return nil, nil, fmt.Errorf("invalid utf-8 in input")
+		}
+		elems = append(elems, v)
+
+		end, err := p.absorbSeparator(RB)
+		if err != nil {
+			return nil, nil, err
+		}
+		if end {
+			return elems, spans, nil
+		}
+	}
+}
+
+// 其它 goroutine 已经出错, 提前结束
+var errParallelAborted = errors.New("parallel parse aborted")
+
+func (p *Parser) parseSpans(spans []elementSpan, elems []*Value, failed *atomic.Bool) error {
+	wp := getParser(p.ctx, p.buf, p.opts)
+	defer putParser(wp)
+
+	for _, span := range spans {
+		if failed.Load() {
+			return errParallelAborted
+		}
+		if p.opts.Strict && !utf8.Valid(p.buf[span.start:span.end]) {
+			return fmt.Errorf("invalid utf-8 in input")
+		}
+		// 在完整输入上解析, 错误中的偏移与顺序解析一致
+		wp.i, wp.len, wp.depth = span.start, span.end, 1
+		v := &Value{}
+		if err := wp.handle(v); err != nil {
+			return err
+		}
+		elems[span.index] = v
+	}
+	return nil
+}
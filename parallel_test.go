@@ -0,0 +1,118 @@
+package yjson
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// 构造不小于 PARALLEL_MIN_SIZE 的根数组, 元素由 elem(i) 生成
+func largeArray(elem func(i int) string) string {
+	var b strings.Builder
+	b.WriteString("[")
+	for i := 0; b.Len() < PARALLEL_MIN_SIZE+1024; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString(elem(i))
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+func TestParseParallel(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  ParseOptions
+	}{
+		{"objects", largeArray(func(i int) string {
+			return fmt.Sprintf(`{"id": %d, "name": "n\"%d", "tags": ["a", "]"], "nested": {"x": [%d, 1.5]}}`, i, i, i)
+		}), ParseOptions{}},
+		{"mixed", largeArray(func(i int) string {
+			switch i % 4 {
+			case 0:
+				return fmt.Sprint(i)
+			case 1:
+				return `"s"`
+			case 2:
+				return `[null, true]`
+			}
+			return `{}`
+		}), ParseOptions{}},
+		{"comments", largeArray(func(i int) string { return `{"a": /* } */ 1}` }), ParseOptions{AllowComments: true}},
+		{"strict", largeArray(func(i int) string { return `{"é": "ü"}` }), ParseOptions{Strict: true}},
+		{"not an array", `{"a": ` + largeArray(func(i int) string { return `[1]` }) + `}`, ParseOptions{}},
+		{"small", `[{"a": 1}, [2]]`, ParseOptions{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := ParseWithOptions([]byte(tt.input), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, workers := range []int{2, 3, 8} {
+				opts := tt.opts
+				opts.Workers = workers
+				got, err := ParseWithOptions([]byte(tt.input), opts)
+				if err != nil {
+					t.Fatalf("workers %d: %v", workers, err)
+				}
+				if mustEncode(t, got) != mustEncode(t, want) {
+					t.Errorf("workers %d: result differs", workers)
+				}
+			}
+		})
+	}
+}
+
+// 只有一处错误时, 错误信息和偏移与顺序解析相同
+func TestParseParallelErrors(t *testing.T) {
+	item := `{"a": [1, 2], "b": "x"}`
+	tests := []struct {
+		name  string
+		input string
+		opts  ParseOptions
+	}{
+		{"bad element", largeArray(func(i int) string {
+			if i == 20000 {
+				return `{"a": [1, x]}`
+			}
+			return item
+		}), ParseOptions{}},
+		{"bad string", largeArray(func(i int) string {
+			if i == 100 {
+				return `{"a": "\q"}`
+			}
+			return item
+		}), ParseOptions{}},
+		{"bad separator", largeArray(func(i int) string {
+			if i == 30000 {
+				return item + ` 1`
+			}
+			return item
+		}), ParseOptions{}},
+		{"unclosed", strings.TrimSuffix(largeArray(func(i int) string { return item }), "]"), ParseOptions{}},
+		{"trailing data", largeArray(func(i int) string { return item }) + ` 1`, ParseOptions{Strict: true}},
+		{"invalid utf-8", largeArray(func(i int) string {
+			if i == 5000 {
+				return "{\"a\": \"\xff\"}"
+			}
+			return item
+		}), ParseOptions{Strict: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, want := ParseWithOptions([]byte(tt.input), tt.opts)
+			if want == nil {
+				t.Fatal("sequential parse succeeded")
+			}
+			opts := tt.opts
+			opts.Workers = 4
+			_, err := ParseWithOptions([]byte(tt.input), opts)
+			if err == nil || err.Error() != want.Error() {
+				t.Errorf("got %v, want %v", err, want)
+			}
+		})
+	}
+}
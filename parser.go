@@ -1,63 +1,188 @@
-package main
+package yjson
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
 const (
-	OB = '{'
-	CB = '}'
-	LB = '['
-	RB = ']'
-	DQ = '"'
-	BLANK_SPACE = ' '
-	HORIZONTAL_TAB = '\t'
-	LINE_BREAK = '\n'
+	OB              = '{'
+	CB              = '}'
+	LB              = '['
+	RB              = ']'
+	DQ              = '"'
+	SQ              = '\''
+	SLASH           = '/'
+	BACKSLASH       = '\\'
+	BLANK_SPACE     = ' '
+	HORIZONTAL_TAB  = '\t'
+	LINE_BREAK      = '\n'
 	CARRIAGE_RETURN = '\r'
 	VALUE_SEPARATOR = ':'
-	FALSE = "false"
-	TRUE = "true"
-	NULL = "null"
-	DOT = ','
+	FALSE           = "false"
+	TRUE            = "true"
+	NULL            = "null"
+	NAN             = "NaN"
+	INFINITY        = "Infinity"
+	DOT             = ','
+	MINUS           = '-'
+	PLUS            = '+'
+	DECIMAL_POINT   = '.'
 )
 
 type Parser struct {
-	buf []byte
-	i int
-	len int
+	buf   []byte
+	i     int
+	len   int
+	depth int
+	opts  ParseOptions
+
+	ctx   context.Context // 为 nil 时不检查
+	steps int
+
+	scratch []byte // 解析字符串时的缓冲区, 复用 Parser 时一起复用
+	arena   *Arena // 为 nil 时节点直接在堆上分配
+
+	// InternKeys 使用的键表, Reset 时保留, 同一个 Parser 解析结构相同的文档时继续命中
+	interned map[string]string
+
+	lazyOpts  *ParseOptions // Lazy 模式下一次解析得到的值共享的选项
+	lazyStack []byte
+
+	// AdaptiveCapacity 记住的每一层上一个对象和数组的大小, Reset 时保留
+	objectSizes []int
+	arraySizes  []int
 }
 
-const (
-	JSON_NUMBER = iota
-	JSON_STRING
-	JSON_BOOLEAN
-	JSON_OBJECT
-	JSON_ARRAY
-	JSON_NULL
-)
+// 放回 parserPool 时 scratch 超过这个大小就丢弃, 避免池中长期持有大块内存
+const MAX_POOLED_SCRATCH = 64 << 10
+
+// 键表最多保存的键数, 超过后新的键不再加入, 避免把键当作数据的对象撑大键表
+const MAX_INTERNED_KEYS = 4096
+
+var parserPool = sync.Pool{New: func() interface{} { return &Parser{} }}
+
+// 创建解析 data 的 Parser, 使用默认选项. Parser 不是并发安全的, 但可以通过 Reset
+// 解析新的输入, 适合放进 sync.Pool 在请求之间复用
+func NewParser(data []byte) *Parser {
+	p := &Parser{opts: ParseOptions{}.normalize()}
+	p.Reset(data)
+	return p
+}
+
+// 改为解析 data, 保留选项和内部缓冲区. 之前解析得到的 Value 不受影响
+func (p *Parser) Reset(data []byte) {
+	p.buf = data
+	p.len = len(data)
+	p.i = 0
+	p.depth = 0
+	p.steps = 0
+	p.ctx = nil
+	p.lazyOpts = nil
+}
+
+func (p *Parser) SetOptions(opts ParseOptions) {
+	p.opts = opts.normalize()
+}
+
+// 之后解析的节点从 a 中分配, 为 nil 时恢复堆分配. Reset 不会清除
+func (p *Parser) SetArena(a *Arena) {
+	p.arena = a
+}
+
+// 从头解析 Reset 传入的数据, 语义与 ParseWithOptions 相同
+func (p *Parser) Parse() (*Value, error) {
+	return p.ParseContext(nil)
+}
 
-type JsonValue struct {
-	valueType int
-	value interface{}
+// 同 Parse, ctx 被取消或超时后尽快停止并返回 ctx.Err()
+func (p *Parser) ParseContext(ctx context.Context) (*Value, error) {
+	if ctx != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	p.Reset(p.buf)
+	p.ctx = ctx
+	defer func() { p.ctx = nil }()
+	return p.parse()
 }
 
+func (p *Parser) parse() (*Value, error) {
+	if p.opts.Lazy {
+		opts := p.opts
+		p.lazyOpts = &opts
+	}
+	res := p.arena.alloc()
+	if p.opts.Workers > 1 && p.arena == nil && !p.opts.Lazy && p.len >= PARALLEL_MIN_SIZE {
+		if ok, err := p.parseParallel(res); ok {
+			if err != nil {
+				return nil, err
+			}
+			return res, nil
+		}
+	}
+	if err := p.init(res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func getParser(ctx context.Context, data []byte, opts ParseOptions) *Parser {
+	p := parserPool.Get().(*Parser)
+	p.Reset(data)
+	p.SetOptions(opts)
+	p.ctx = ctx
+	return p
+}
+
+func putParser(p *Parser) {
+	// 不持有调用方的数据
+	p.Reset(nil)
+	p.arena = nil
+	if cap(p.scratch) > MAX_POOLED_SCRATCH {
+		p.scratch = nil
+	}
+	// 池中的 Parser 被不相关的调用方共享, 不保留键表和学到的容量
+	p.interned = nil
+	p.objectSizes = p.objectSizes[:0]
+	p.arraySizes = p.arraySizes[:0]
+	parserPool.Put(p)
+}
+
+// 每解析这么多个值检查一次 ctx
+const CONTEXT_CHECK_INTERVAL = 1024
+
 func (p *Parser) expect(b byte) error {
 	peak, err := p.peak()
 	if err != nil {
 		return err
 	}
 	if peak != b {
-		return fmt.Errorf("expect: %b, but get: %b", b, peak)
+		return fmt.Errorf("expect: %c, but get: %c", b, peak)
 	}
 	return nil
 }
 
+// 剩余的输入是 str 的前缀时为 EOF, 否则报告不匹配
 func (p *Parser) expectString(str string) error {
-	if p.i + len(str) >= p.len {
+	if p.i+len(str) > p.len {
+		if s := p.buf[p.i:]; !strings.HasPrefix(str, string(s)) {
+			return fmt.Errorf("expect: %s, but get: %s", str, s)
+		}
 		return io.EOF
 	}
-	s := p.buf[p.i: p.i+len(str)]
+	s := p.buf[p.i : p.i+len(str)]
 	if string(s) != str {
 		return fmt.Errorf("expect: %s, but get: %s", str, s)
 	}
@@ -73,7 +198,7 @@ func (p *Parser) peak() (byte, error) {
 }
 
 func (p *Parser) readAt(buf []byte, pos int) (int, error) {
-	if pos + len(buf) >= p.len {
+	if pos+len(buf) >= p.len {
 		return 0, io.EOF
 	}
 
@@ -81,19 +206,26 @@ func (p *Parser) readAt(buf []byte, pos int) (int, error) {
 	return n, nil
 }
 
-func (p *Parser) init(j *JsonValue) error {
-	err := p.expect(LB)
+func (p *Parser) init(j *Value) error {
+	if p.opts.Strict && !utf8.Valid(p.buf) {
+		return fmt.Errorf("invalid utf-8 in input")
+	}
+
+	err := p.handle(j)
 	if err != nil {
-		err = p.expect(LB)
-		if err != nil {
-			return fmt.Errorf("not found { or [")
-		}
+		return err
 	}
 
-	return p.handle(j)
+	if p.opts.Strict {
+		err = p.absorbLack()
+		if err != io.EOF {
+			return fmt.Errorf("unexpected data after document at offset %d", p.i)
+		}
+	}
+	return nil
 }
 
-func (p* Parser) readByte() (byte, error) {
+func (p *Parser) readByte() (byte, error) {
 	if p.i >= p.len {
 		return 0, io.EOF
 	}
@@ -103,16 +235,51 @@ func (p* Parser) readByte() (byte, error) {
 }
 
 func (p *Parser) absorbLack() error {
-	b, err := p.peak()
-	if err != nil {
-		return err
+	for true {
+		if p.i >= p.len {
+			return io.EOF
+		}
+
+		switch b := p.buf[p.i]; {
+		case b == BLANK_SPACE:
+			p.i = skipBlanks(p.buf[:p.len], p.i+1)
+		case b == HORIZONTAL_TAB || b == LINE_BREAK || b == CARRIAGE_RETURN:
+			p.i++
+		case (b == '\v' || b == '\f') && p.opts.JSON5:
+			p.i++
+		case b == SLASH && p.opts.AllowComments:
+			if err := p.absorbComment(); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
 	}
+	return nil
+}
 
-	for b == BLANK_SPACE || b == HORIZONTAL_TAB || b == LINE_BREAK  || b == CARRIAGE_RETURN {
-		_, err := p.readByte()
-		if err != nil {
-			return err
+// 跳过 // 行注释或 /* */ 块注释
+func (p *Parser) absorbComment() error {
+	if p.i+1 >= p.len {
+		return fmt.Errorf("unexpected %c at offset %d", SLASH, p.i)
+	}
+
+	switch p.buf[p.i+1] {
+	case SLASH:
+		p.i += 2
+		if end := bytes.IndexByte(p.buf[p.i:p.len], LINE_BREAK); end >= 0 {
+			p.i += end
+		} else {
+			p.i = p.len
+		}
+	case '*':
+		end := bytes.Index(p.buf[p.i+2:], []byte("*/"))
+		if end < 0 {
+			return fmt.Errorf("unterminated block comment at offset %d", p.i)
 		}
+		p.i += 2 + end + 2
+	default:
+		return fmt.Errorf("unexpected %c at offset %d", SLASH, p.i)
 	}
 	return nil
 }
@@ -127,64 +294,259 @@ func (p *Parser) absorbByte(b byte) error {
 	return nil
 }
 
-func (p *Parser) parseString(j *JsonValue) error {
-	err := p.absorbByte(DQ)
+func (p *Parser) parseString(j *Value) error {
+	str, err := p.readString()
 	if err != nil {
 		return err
 	}
+	j.valueType = JSON_STRING
+	j.str = string(str)
+	return nil
+}
 
-	str := make([]byte, 0)
+// 读取引号包围的字符串并处理转义, 返回的切片在下一次读取字符串之前有效
+func (p *Parser) readString() ([]byte, error) {
+	quote := byte(DQ)
+	if b, err := p.peak(); err == nil && b == SQ && p.opts.JSON5 {
+		quote = SQ
+	}
 
-	b, err := p.peak()
+	err := p.absorbByte(quote)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	// 没有转义时直接返回输入中的一段, 否则把各段拼接到 scratch 中
+	str := p.scratch[:0]
+	copied := false
+
 	for true {
-		b, err = p.peak()
-		if err != nil {
-			return err
-		} else if b == DQ {
-			p.i++
+		run := p.i
+		end := stringRunEnd(p.buf[:p.len], run, quote)
+		if end >= p.len {
+			p.i = p.len
+			return nil, io.EOF
+		}
+
+		b := p.buf[end]
+		p.i = end + 1
+		if b == quote && !copied {
+			return p.buf[run:end], nil
+		}
+		str = append(str, p.buf[run:end]...)
+		copied = true
+
+		if b == quote {
 			break
 		}
 
-		b, err = p.readByte()
+		if b == BACKSLASH {
+			str, err = p.parseEscape(str)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// 控制字符
+		if p.opts.Strict {
+			return nil, fmt.Errorf("unescaped control character %#x in string", b)
+		}
+		str = append(str, b)
+	}
+
+	p.scratch = str[:0]
+	return str, nil
+}
+
+// 反斜杠之后的转义字符
+func (p *Parser) parseEscape(str []byte) ([]byte, error) {
+	b, err := p.readByte()
+	if err != nil {
+		return str, err
+	}
+
+	switch b {
+	case DQ, BACKSLASH, '/':
+		str = append(str, b)
+	case 'b':
+		str = append(str, '\b')
+	case 'f':
+		str = append(str, '\f')
+	case 'n':
+		str = append(str, '\n')
+	case 'r':
+		str = append(str, '\r')
+	case 't':
+		str = append(str, '\t')
+	case 'u':
+		r, err := p.parseUnicodeEscape()
 		if err != nil {
-			return err
+			return str, err
+		}
+		str = utf8.AppendRune(str, r)
+	default:
+		if p.opts.JSON5 {
+			return p.parseJSON5Escape(str, b)
+		}
+		return str, fmt.Errorf("invalid escape: \\%c", b)
+	}
+	return str, nil
+}
+
+// JSON5 额外的转义: \' \v \0 \xHH, 反斜杠续行, 其余字符转义为自身
+func (p *Parser) parseJSON5Escape(str []byte, b byte) ([]byte, error) {
+	switch {
+	case b == 'v':
+		str = append(str, '\v')
+	case b == '0' && (p.i >= p.len || !isDigit(p.buf[p.i])):
+		str = append(str, 0)
+	case b == 'x':
+		if p.i+2 > p.len {
+			return str, io.EOF
+		}
+		n, err := strconv.ParseUint(string(p.buf[p.i:p.i+2]), 16, 8)
+		if err != nil {
+			return str, fmt.Errorf("invalid escape: \\x%s", p.buf[p.i:p.i+2])
+		}
+		p.i += 2
+		str = utf8.AppendRune(str, rune(n))
+	case b == CARRIAGE_RETURN:
+		if p.i < p.len && p.buf[p.i] == LINE_BREAK {
+			p.i++
 		}
+	case b == LINE_BREAK:
+	case isDigit(b):
+		return str, fmt.Errorf("invalid escape: \\%c", b)
+	default:
 		str = append(str, b)
+	}
+	return str, nil
+}
 
+func isIdentifierStart(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r)
+}
+
+// JSON5 中不加引号的对象键
+func (p *Parser) parseIdentifier(j *Value) error {
+	start := p.i
+	for p.i < p.len {
+		r, size := utf8.DecodeRune(p.buf[p.i:])
+		if !isIdentifierStart(r) && (p.i == start || !unicode.IsDigit(r)) {
+			break
+		}
+		p.i += size
 	}
 
+	if p.i == start {
+		return fmt.Errorf("expect object key at offset %d", p.i)
+	}
 
 	j.valueType = JSON_STRING
-	j.value = string(str)
+	j.str = p.keyString(p.buf[start:p.i])
 	return nil
 }
 
-func (p *Parser) handle(j *JsonValue) error {
+// 不足 4 位时, 已有的字符中出现非十六进制字符报告非法转义, 否则为 EOF
+func (p *Parser) readHex4() (rune, error) {
+	end := p.i + 4
+	if end > p.len {
+		end = p.len
+	}
+
+	var r rune
+	for _, b := range p.buf[p.i:end] {
+		switch {
+		case b >= '0' && b <= '9':
+			r = r<<4 | rune(b-'0')
+		case b >= 'a' && b <= 'f':
+			r = r<<4 | rune(b-'a'+10)
+		case b >= 'A' && b <= 'F':
+			r = r<<4 | rune(b-'A'+10)
+		default:
+			return 0, fmt.Errorf("invalid unicode escape: \\u%s", p.buf[p.i:end])
+		}
+	}
+	if end < p.i+4 {
+		return 0, io.EOF
+	}
+	p.i += 4
+	return r, nil
+}
+
+// \uXXXX, 代理对合并为一个码点, 孤立的代理项替换为 U+FFFD, 严格模式下报错
+func (p *Parser) parseUnicodeEscape() (rune, error) {
+	r, err := p.readHex4()
+	if err != nil {
+		return 0, err
+	}
+
+	if !utf16.IsSurrogate(r) {
+		return r, nil
+	}
+
+	if r < 0xDC00 && p.i+6 <= p.len && p.buf[p.i] == BACKSLASH && p.buf[p.i+1] == 'u' {
+		save := p.i
+		p.i += 2
+		r2, err := p.readHex4()
+		if err != nil {
+			return 0, err
+		}
+		if c := utf16.DecodeRune(r, r2); c != utf8.RuneError {
+			return c, nil
+		}
+		p.i = save
+	}
+
+	if p.opts.Strict {
+		return 0, fmt.Errorf("lone surrogate \\u%04x in string", r)
+	}
+	return utf8.RuneError, nil
+}
+
+func (p *Parser) handle(j *Value) error {
+	if p.ctx != nil {
+		p.steps++
+		if p.steps%CONTEXT_CHECK_INTERVAL == 0 {
+			if err := p.ctx.Err(); err != nil {
+				return err
+			}
+		}
+	}
+
 	err := p.absorbLack()
 	if err != nil {
 		return err
 	}
 
+	start := p.i
 	b, err := p.peak()
 	switch b {
-	case OB: // 左花括号
-		err := p.parseObject(j)
+	case OB, LB:
+		if p.lazyOpts != nil && p.depth > 0 {
+			return p.skipContainer(j)
+		}
+		if b == OB {
+			err = p.parseObject(j) // 左花括号
+		} else {
+			err = p.parseArray(j) // 左中括号
+		}
 		if err != nil {
 			return err
 		}
-	case LB: // 左中括号
-		err := p.parseArray(j)
+	case DQ: // 字符串
+		err := p.parseString(j)
 		if err != nil {
 			return err
 		}
-	case DQ: // 字符串
+	case SQ:
+		if !p.opts.JSON5 {
+			return fmt.Errorf("not match")
+		}
 		err := p.parseString(j)
 		if err != nil {
-			return  err
+			return err
 		}
 	case 'f':
 		err := p.expectString(FALSE)
@@ -193,7 +555,7 @@ func (p *Parser) handle(j *JsonValue) error {
 		}
 		p.i += len(FALSE)
 		j.valueType = JSON_BOOLEAN
-		j.value = true
+		j.b = false
 	case 'n':
 		err := p.expectString(NULL)
 		if err != nil {
@@ -208,134 +570,542 @@ func (p *Parser) handle(j *JsonValue) error {
 		}
 		p.i += len(TRUE)
 		j.valueType = JSON_BOOLEAN
-		j.value = false
+		j.b = true
+	case 'N', 'I':
+		if !p.opts.AllowNaN {
+			return fmt.Errorf("not match")
+		}
+		err := p.parseNumber(j)
+		if err != nil {
+			return err
+		}
+	case PLUS, DECIMAL_POINT:
+		if !p.opts.lenientDecimals() {
+			return fmt.Errorf("not match")
+		}
+		err := p.parseNumber(j)
+		if err != nil {
+			return err
+		}
+	case MINUS, '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		err := p.parseNumber(j)
+		if err != nil {
+			return err
+		}
 	default:
 		return fmt.Errorf("not match")
 	}
 
+	if j.valueType != JSON_OBJECT && j.valueType != JSON_ARRAY {
+		j.raw = p.buf[start:p.i]
+	}
 	return nil
 }
 
-func (p *Parser) parseObject(j *JsonValue) error {
-	err := p.absorbByte(OB)
-	if err != nil {
-		return err
-	}
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
 
-	jsonObjectMap := make(map[string]*JsonValue)
+func isHexDigit(b byte) bool {
+	return isDigit(b) || (b >= 'a' && b <= 'f') || (b >= 'A' && b <= 'F')
+}
 
-	for true {
+// 十进制文本经 float64 转换后, 最短的十进制表示是否仍等于原文本的值
+func exactFloat(raw string, f float64) bool {
+	g := strconv.FormatFloat(f, 'g', -1, 64)
+	if g == raw {
+		return true
+	}
+	a, _, err := big.ParseFloat(raw, 10, BIG_FLOAT_PREC, big.ToNearestEven)
+	if err != nil {
+		return false
+	}
+	b, _, err := big.ParseFloat(g, 10, BIG_FLOAT_PREC, big.ToNearestEven)
+	if err != nil {
+		return false
+	}
+	return a.Cmp(b) == 0
+}
 
-		err = p.absorbLack()
+// NaN, Infinity, -Infinity
+func (p *Parser) parseNaN(j *Value, start int) error {
+	var f float64
+	if p.buf[p.i] == 'N' && p.i == start {
+		err := p.expectString(NAN)
 		if err != nil {
 			return err
 		}
-
-		b, err := p.peak()
+		p.i += len(NAN)
+		f = math.NaN()
+	} else {
+		err := p.expectString(INFINITY)
 		if err != nil {
 			return err
 		}
-
-		if b == CB {
-			p.i++
-			break
+		p.i += len(INFINITY)
+		f = math.Inf(1)
+		if p.buf[start] == MINUS {
+			f = math.Inf(-1)
 		}
+	}
+
+	j.numberType = NUMBER_FLOAT
+	if ok, err := p.rawNumber(j, start); ok {
+		return err
+	}
+	j.setFloat(f)
+	return nil
+}
 
-		key := &JsonValue{}
-		value := &JsonValue{}
-		err = p.parseString(key)
+// 交给 NumberHook 或按 UseRawNumbers 原样保存的数字, 返回 false 表示需要继续转换
+func (p *Parser) rawNumber(j *Value, start int) (bool, error) {
+	raw := Number(p.buf[start:p.i])
+	if p.opts.NumberHook != nil {
+		v, err := p.opts.NumberHook(raw)
 		if err != nil {
-			return err
+			return true, fmt.Errorf("number hook: %s: %v", raw, err)
 		}
+		j.setNumber(v)
+		return true, nil
+	}
 
-		err = p.absorbLack()
-		if err != nil {
-			return err
+	if p.opts.UseRawNumbers {
+		j.setNumber(raw)
+		return true, nil
+	}
+	return false, nil
+}
+
+// 0x1F 形式的十六进制整数, start 指向可选的负号
+func (p *Parser) parseHexNumber(j *Value, start int) error {
+	p.i += 2
+	digits := p.i
+	for p.i < p.len && isHexDigit(p.buf[p.i]) {
+		p.i++
+	}
+	if p.i == digits {
+		return fmt.Errorf("invalid number: expect hex digit")
+	}
+	j.numberType = NUMBER_INT
+
+	if ok, err := p.rawNumber(j, start); ok {
+		return err
+	}
+
+	n, err := strconv.ParseUint(string(p.buf[digits:p.i]), 16, 64)
+	if err != nil {
+		b, ok := new(big.Int).SetString(string(p.buf[digits:p.i]), 16)
+		if !ok || !p.opts.UseBigNumbers {
+			return fmt.Errorf("invalid number: %s", p.buf[start:p.i])
+		}
+		if p.buf[start] == MINUS {
+			b.Neg(b)
 		}
+		j.setNumber(b)
+		return nil
+	}
 
-		err = p.absorbByte(VALUE_SEPARATOR)
+	if p.buf[start] != MINUS {
+		if n <= math.MaxInt64 {
+			j.setInt(int64(n))
+		} else {
+			j.setUint(n)
+		}
+	} else if n <= 1<<63 {
+		j.setInt(-int64(n))
+	} else {
+		j.setFloat(-float64(n))
+	}
+	return nil
+}
 
+func (p *Parser) absorbDigits() int {
+	n := 0
+	for p.i < p.len && isDigit(p.buf[p.i]) {
+		p.i++
+		n++
+	}
+	return n
+}
+
+// number = [ minus ] int [ frac ] [ exp ]
+// 没有小数和指数部分的整数优先保存为 int64, 其次 uint64, 否则为 float64
+func (p *Parser) parseNumber(j *Value) error {
+	start := p.i
 
+	b, err := p.peak()
+	if err != nil {
+		return err
+	}
+	if b == MINUS || (b == PLUS && p.opts.lenientDecimals()) {
+		p.i++
+		b, err = p.peak()
 		if err != nil {
 			return err
 		}
+	}
 
-		err = p.absorbLack()
-		if err != nil {
-			return err
+	if (b == 'N' || b == 'I') && p.opts.AllowNaN {
+		return p.parseNaN(j, start)
+	}
+
+	if b == '0' && p.opts.AllowHexNumbers && p.i+1 < p.len && (p.buf[p.i+1] == 'x' || p.buf[p.i+1] == 'X') {
+		return p.parseHexNumber(j, start)
+	}
+
+	intDigits := 0
+	if b == '0' && !p.opts.LenientNumbers {
+		p.i++
+		intDigits = 1
+	} else if intDigits = p.absorbDigits(); intDigits == 0 && !(b == DECIMAL_POINT && p.opts.lenientDecimals()) {
+		return fmt.Errorf("invalid number: expect digit, but get: %c", b)
+	}
+
+	integer := true
+	b, err = p.peak()
+	if err == nil && b == DECIMAL_POINT {
+		integer = false
+		p.i++
+		if p.absorbDigits() == 0 && (intDigits == 0 || !p.opts.lenientDecimals()) {
+			return fmt.Errorf("invalid number: expect digit after decimal point")
 		}
+		b, err = p.peak()
+	}
 
-		err = p.handle(value)
+	if err == nil && (b == 'e' || b == 'E') {
+		integer = false
+		p.i++
+		b, err = p.peak()
+		if err == nil && (b == PLUS || b == MINUS) {
+			p.i++
+		}
+		if p.absorbDigits() == 0 {
+			return fmt.Errorf("invalid number: expect digit in exponent")
+		}
+	}
+
+	j.numberType = NUMBER_FLOAT
+	if integer {
+		j.numberType = NUMBER_INT
+	}
+
+	if ok, err := p.rawNumber(j, start); ok {
+		return err
+	}
+
+	raw := string(p.buf[start:p.i])
+	negativeZero := raw[0] == MINUS && strings.Trim(raw, "-0") == ""
+	if integer && !negativeZero {
+		if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			j.setInt(n)
+			return nil
+		}
+		if n, err := strconv.ParseUint(raw, 10, 64); err == nil {
+			j.setUint(n)
+			return nil
+		}
+		if p.opts.UseBigNumbers {
+			n, _ := new(big.Int).SetString(raw, 10)
+			j.setNumber(n)
+			return nil
+		}
+	}
+
+	f, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		if !p.opts.UseBigNumbers {
+			return fmt.Errorf("invalid number: %s", p.buf[start:p.i])
+		}
+		n, _, err := big.ParseFloat(raw, 10, BIG_FLOAT_PREC, big.ToNearestEven)
 		if err != nil {
-			return err
+			return fmt.Errorf("invalid number: %s", p.buf[start:p.i])
 		}
+		j.setNumber(n)
+		return nil
+	}
 
-		jsonObjectMap[key.value.(string)] = value
+	if p.opts.UseBigNumbers && !integer && !exactFloat(raw, f) {
+		n, _, _ := big.ParseFloat(raw, 10, BIG_FLOAT_PREC, big.ToNearestEven)
+		j.setNumber(n)
+		return nil
 	}
 
-	j.valueType = JSON_OBJECT
-	j.value = jsonObjectMap
+	j.setFloat(f)
 	return nil
 }
 
-func (p *Parser) parseArray(j *JsonValue) error {
-	arr := make([]interface{}, 0)
-	err := p.absorbByte(LB)
+func (p *Parser) parseKey(key *Value) error {
+	b, err := p.peak()
+	if err != nil {
+		return err
+	}
+
+	if p.opts.JSON5 && b != DQ && b != SQ {
+		return p.parseIdentifier(key)
+	}
+
+	str, err := p.readString()
+	if err != nil {
+		return err
+	}
+	key.valueType = JSON_STRING
+	key.str = p.keyString(str)
+	return nil
+}
+
+// InternKeys 开启时相同的键共享同一个字符串
+func (p *Parser) keyString(b []byte) string {
+	if !p.opts.InternKeys {
+		return string(b)
+	}
+	if s, ok := p.interned[string(b)]; ok {
+		return s
+	}
+	s := string(b)
+	if len(p.interned) < MAX_INTERNED_KEYS {
+		if p.interned == nil {
+			p.interned = make(map[string]string)
+		}
+		p.interned[s] = s
+	}
+	return s
+}
+
+// 读取容器成员之后的分隔符, 返回 true 表示遇到了结束符
+func (p *Parser) absorbSeparator(end byte) (bool, error) {
+	err := p.absorbLack()
+	if err != nil {
+		return false, err
+	}
+
+	b, err := p.peak()
+	if err != nil {
+		return false, err
+	}
+
+	switch b {
+	case end:
+		p.i++
+		return true, nil
+	case DOT:
+		p.i++
+	default:
+		return false, fmt.Errorf("expect: %c or %c, but get: %c", DOT, end, b)
+	}
+
+	err = p.absorbLack()
+	if err != nil {
+		return false, err
+	}
+
+	b, err = p.peak()
+	if err != nil {
+		return false, err
+	}
+	if b == end {
+		if p.opts.AllowTrailingCommas {
+			p.i++
+			return true, nil
+		}
+		return false, fmt.Errorf("trailing comma before %c", end)
+	}
+	return false, nil
+}
+
+// 在读入 { 或 [ 之后调用, 错误的位置指向这个括号, 与 Lazy 模式相同
+func (p *Parser) enter() error {
+	p.depth++
+	if p.depth > p.opts.MaxDepth {
+		return fmt.Errorf("exceeded max depth %d at offset %d", p.opts.MaxDepth, p.i-1)
+	}
+	return nil
+}
+
+func (p *Parser) parseObject(j *Value) error {
+	err := p.absorbByte(OB)
+	if err != nil {
+		return err
+	}
+
+	err = p.enter()
+	if err != nil {
+		return err
+	}
+	defer func() { p.depth-- }()
+
+	n := p.capacityHint(p.opts.ObjectCapacity, p.objectSizes)
+	jsonObjectMap := make(map[string]*Value, n)
+	keys := make([]string, 0, n)
+
+	err = p.absorbLack()
 	if err != nil {
 		return err
 	}
 
+	b, err := p.peak()
+	if err != nil {
+		return err
+	}
+
+	if b == CB {
+		p.i++
+		j.valueType = JSON_OBJECT
+		j.obj = jsonObjectMap
+		j.keys = keys
+		return nil
+	}
 
 	for true {
+		key := &Value{}
+		value := p.arena.alloc()
+		err = p.parseKey(key)
+		if err != nil {
+			return err
+		}
 
 		err = p.absorbLack()
 		if err != nil {
 			return err
 		}
 
-		b, err := p.peak()
+		err = p.absorbByte(VALUE_SEPARATOR)
 		if err != nil {
 			return err
 		}
 
-		if b == RB {
-			p.i++
-			break
-		}
-		value := &JsonValue{}
 		err = p.handle(value)
 		if err != nil {
 			return err
 		}
 
-		err = p.absorbLack()
+		k := key.str
+		if _, ok := jsonObjectMap[k]; !ok {
+			jsonObjectMap[k] = value
+			keys = append(keys, k)
+		} else if p.opts.DuplicateKeys == LastWins {
+			jsonObjectMap[k] = value
+		} else if p.opts.DuplicateKeys == ErrorOnDuplicate {
+			return fmt.Errorf("duplicate key %q in object", k)
+		}
+
+		end, err := p.absorbSeparator(CB)
 		if err != nil {
 			return err
 		}
+		if end {
+			break
+		}
+	}
 
-		b, err = p.peak()
+	p.learnCapacity(&p.objectSizes, len(keys))
+	j.valueType = JSON_OBJECT
+	j.obj = jsonObjectMap
+	j.keys = keys
+	return nil
+}
+
+func (p *Parser) parseArray(j *Value) error {
+	err := p.absorbByte(LB)
+	if err != nil {
+		return err
+	}
+
+	err = p.enter()
+	if err != nil {
+		return err
+	}
+	defer func() { p.depth-- }()
+
+	arr := make([]*Value, 0, p.capacityHint(p.opts.ArrayCapacity, p.arraySizes))
+
+	err = p.absorbLack()
+	if err != nil {
+		return err
+	}
+
+	b, err := p.peak()
+	if err != nil {
+		return err
+	}
+
+	if b == RB {
+		p.i++
+		j.valueType = JSON_ARRAY
+		j.arr = arr
+		return nil
+	}
+
+	for true {
+		value := p.arena.alloc()
+		err = p.handle(value)
 		if err != nil {
 			return err
 		}
+		arr = append(arr, value)
 
-		if b == DOT {
-			p.i++
+		end, err := p.absorbSeparator(RB)
+		if err != nil {
+			return err
+		}
+		if end {
+			break
 		}
 	}
 
+	p.learnCapacity(&p.arraySizes, len(arr))
 	j.valueType = JSON_ARRAY
-	j.value = arr
+	j.arr = arr
 	return nil
 }
 
+// 使用默认选项解析 JSON 文档
+func Parse(data []byte) (*Value, error) {
+	return ParseWithOptions(data, ParseOptions{})
+}
 
-func Marshal(data []byte) (*JsonValue, error) {
-	parser := &Parser{buf: data, len: len(data)}
-	res := &JsonValue{}
-	err := parser.init(res)
-	if err != nil {
+// 按 JSON5 方言解析
+func ParseJSON5(data []byte) (*Value, error) {
+	return ParseWithOptions(data, ParseOptions{JSON5: true})
+}
+
+func ParseWithOptions(data []byte, opts ParseOptions) (*Value, error) {
+	return parseWithContext(nil, data, opts)
+}
+
+// 同 Parse, ctx 被取消或超时后尽快停止并返回 ctx.Err()
+func ParseContext(ctx context.Context, data []byte) (*Value, error) {
+	return ParseContextWithOptions(ctx, data, ParseOptions{})
+}
+
+func ParseContextWithOptions(ctx context.Context, data []byte, opts ParseOptions) (*Value, error) {
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
+	return parseWithContext(ctx, data, opts)
+}
 
+func parseWithContext(ctx context.Context, data []byte, opts ParseOptions) (*Value, error) {
+	parser := getParser(ctx, data, opts)
+	defer putParser(parser)
+	return parser.parse()
+}
+
+// 解析恰好一个文档, 不论是否 Strict 都不允许文档之后还有数据, 用于流式读取时
+// 已经切分好的文档
+func parseDocument(ctx context.Context, data []byte, opts ParseOptions) (*Value, error) {
+	parser := getParser(ctx, data, opts)
+	defer putParser(parser)
+	res := &Value{}
+	if err := parser.init(res); err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if !parser.opts.Strict {
+		if err := parser.absorbLack(); err != io.EOF {
+			return nil, fmt.Errorf("unexpected data after document at offset %d", parser.i)
+		}
+	}
 	return res, nil
-}
\ No newline at end of file
+}
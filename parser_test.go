@@ -0,0 +1,620 @@
+package yjson
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// want 以 "error: " 开头时表示期望的错误信息, 否则为编码后的结果
+type parseTest struct {
+	input string
+	want  string
+}
+
+func runParseTests(t *testing.T, opts ParseOptions, tests []parseTest) {
+	t.Helper()
+	for _, tt := range tests {
+		v, err := ParseWithOptions([]byte(tt.input), opts)
+		if msg, ok := strings.CutPrefix(tt.want, "error: "); ok {
+			if err == nil || err.Error() != msg {
+				t.Errorf("%q: got error %v, want %s", tt.input, err, msg)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.input, err)
+			continue
+		}
+		b, err := v.EncodeWithOptions(EncodeOptions{AllowNaN: true})
+		if err != nil {
+			t.Errorf("%q: encode: %v", tt.input, err)
+			continue
+		}
+		if string(b) != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.input, b, tt.want)
+		}
+	}
+}
+
+func TestParseNumbers(t *testing.T) {
+	runParseTests(t, ParseOptions{}, []parseTest{
+		{`0`, `0`},
+		{`-0`, `-0`},
+		{`12`, `12`},
+		{`-7`, `-7`},
+		{`3.25`, `3.25`},
+		{`-3.25e2`, `-325`},
+		{`1E-2`, `0.01`},
+		{`2e+3`, `2000`},
+		{`[0.5,-1e-7,123456.789]`, `[0.5,-1e-7,123456.789]`},
+		{`{"n":-12.5E1}`, `{"n":-125}`},
+		{`1e400`, `error: invalid number: 1e400`},
+		{`1.`, `error: invalid number: expect digit after decimal point`},
+		{`1e`, `error: invalid number: expect digit in exponent`},
+		{`1e+`, `error: invalid number: expect digit in exponent`},
+		{`[-]`, `error: invalid number: expect digit, but get: ]`},
+		{`-`, `error: EOF`},
+		{`.5`, `error: not match`},
+		{`[01]`, `error: expect: , or ], but get: 1`},
+	})
+
+	v := mustParse(t, `[1, 2.5]`)
+	for i, want := range []float64{1, 2.5} {
+		item := v.MustArray()[i]
+		if item.Type() != JSON_NUMBER {
+			t.Fatalf("item %d: got %s", i, item.Type())
+		}
+		if f, err := item.Float64(); err != nil || f != want {
+			t.Errorf("item %d: got %v, %v", i, f, err)
+		}
+	}
+}
+
+func TestParseStringEscapes(t *testing.T) {
+	runParseTests(t, ParseOptions{}, []parseTest{
+		{`"plain"`, `"plain"`},
+		{`""`, `""`},
+		{`"say \"hi\""`, `"say \"hi\""`},
+		{`"a\\b"`, `"a\\b"`},
+		{`"\\"`, `"\\"`},
+		{`"a\/b"`, `"a/b"`},
+		{`"\n\t\r"`, `"\n\t\r"`},
+		{`"\b\f"`, `"\b\f"`},
+		{`["\"",1]`, `["\"",1]`},
+		{`{"k\"ey":"v"}`, `{"k\"ey":"v"}`},
+		{`"\x"`, `error: invalid escape: \x`},
+		{`"\'"`, `error: invalid escape: \'`},
+		{`"abc`, `error: EOF`},
+		{`"abc\`, `error: EOF`},
+	})
+
+	v := mustParse(t, `"tab\there\nnew \\ \" \/"`)
+	if s := v.MustString(); s != "tab\there\nnew \\ \" /" {
+		t.Errorf("got %q", s)
+	}
+}
+
+func TestParseUnicodeEscapes(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`"\u00e9"`, "é"},
+		{`"\u00e9t\u00e9"`, "été"},
+		{`"\u4e2d\u6587"`, "中文"},
+		{`"\ud83d\ude00"`, "😀"},
+		{`"\uD83D\uDE00!"`, "😀!"},
+		{`"\u0000"`, "\x00"},
+		{`"A\u00e9\u4E2D\ud83d\ude00"`, "Aé中😀"},
+		// 宽松模式下孤立的代理项替换为 U+FFFD
+		{`"\ud83d"`, "�"},
+		{`"\ude00x"`, "�x"},
+		{`"\ud83dA"`, "�A"},
+	}
+	for _, tt := range tests {
+		v := mustParse(t, tt.input)
+		if s := v.MustString(); s != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.input, s, tt.want)
+		}
+	}
+
+	runParseTests(t, ParseOptions{}, []parseTest{
+		{`"\u12G4"`, `error: invalid unicode escape: \u12G4`},
+		{`"\u12"`, `error: invalid unicode escape: \u12"`},
+	})
+	runParseTests(t, ParseOptions{Strict: true}, []parseTest{
+		{`"\ud83d\ude00"`, `"😀"`},
+		{`"\ud83d"`, `error: lone surrogate \ud83d in string`},
+		{`"\ude00"`, `error: lone surrogate \ude00 in string`},
+	})
+}
+
+func TestParseTopLevelScalars(t *testing.T) {
+	tests := []struct {
+		input string
+		kind  Kind
+		want  string
+	}{
+		{`"hello"`, JSON_STRING, `"hello"`},
+		{`42`, JSON_NUMBER, `42`},
+		{`-1.5`, JSON_NUMBER, `-1.5`},
+		{`true`, JSON_BOOLEAN, `true`},
+		{`false`, JSON_BOOLEAN, `false`},
+		{`null`, JSON_NULL, `null`},
+		{" \n\t 7 \r\n", JSON_NUMBER, `7`},
+		{`{}`, JSON_OBJECT, `{}`},
+		{`[]`, JSON_ARRAY, `[]`},
+	}
+	for _, tt := range tests {
+		for _, opts := range []ParseOptions{{}, {Strict: true}} {
+			v, err := ParseWithOptions([]byte(tt.input), opts)
+			if err != nil {
+				t.Errorf("%q strict=%v: %v", tt.input, opts.Strict, err)
+				continue
+			}
+			if v.Type() != tt.kind || mustEncode(t, v) != tt.want {
+				t.Errorf("%q strict=%v: got %s %s", tt.input, opts.Strict, v.Type(), mustEncode(t, v))
+			}
+		}
+	}
+
+	runParseTests(t, ParseOptions{}, []parseTest{
+		{``, `error: EOF`},
+		{`   `, `error: EOF`},
+		{`tru`, `error: EOF`},
+		{`nul`, `error: EOF`},
+		{`trUe`, `error: expect: true, but get: trUe`},
+		{`x`, `error: not match`},
+	})
+}
+
+// JSONTestSuite 中 n_ 开头的用例的代表, 严格模式全部拒绝
+func TestParseStrict(t *testing.T) {
+	rejected := []string{
+		`[1,]`,
+		`{"a":1,}`,
+		`[1 2]`,
+		`{"a":1 "b":2}`,
+		`{"a" 1}`,
+		`[01]`,
+		`[-01]`,
+		`[.5]`,
+		`[+1]`,
+		`[1.]`,
+		`[NaN]`,
+		`[0x1]`,
+		`['a']`,
+		`{a:1}`,
+		`[1] /* c */`,
+		"[\"a\x01\"]",
+		"[\"\t\"]",
+		"[\"\xff\"]",
+		`["\ud800"]`,
+		`[1] 2`,
+		`{} x`,
+		`[`,
+		`[1`,
+		`{"a":`,
+		`]`,
+		``,
+	}
+	for _, input := range rejected {
+		if _, err := ParseWithOptions([]byte(input), ParseOptions{Strict: true}); err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
+	}
+
+	accepted := []string{
+		`[]`, `{}`, `[1,-0,1e5,0.5e-3,-1E+2]`, `{"a":[{"b":null}],"c":"é"}`,
+		`"😀"`, " [1] \n", `[true,false,null]`, `"日本"`,
+	}
+	for _, input := range accepted {
+		if _, err := ParseWithOptions([]byte(input), ParseOptions{Strict: true}); err != nil {
+			t.Errorf("%q: %v", input, err)
+		}
+	}
+
+	runParseTests(t, ParseOptions{Strict: true}, []parseTest{
+		{"\"a\x01\"", `error: unescaped control character 0x1 in string`},
+		{"\"\xff\"", `error: invalid utf-8 in input`},
+		{`1 2`, `error: unexpected data after document at offset 2`},
+	})
+
+	// 严格模式关闭所有扩展选项
+	opts := ParseOptions{Strict: true, AllowComments: true, AllowTrailingCommas: true, JSON5: true, AllowNaN: true,
+		AllowHexNumbers: true, LenientNumbers: true}
+	for _, input := range []string{`[1,]`, `// c` + "\n1", `NaN`, `0x1`, `+1`, `{a:1}`} {
+		if _, err := ParseWithOptions([]byte(input), opts); err == nil {
+			t.Errorf("%q: extension options should be ignored in strict mode", input)
+		}
+	}
+
+	// 宽松模式忽略文档之后的内容
+	runParseTests(t, ParseOptions{}, []parseTest{
+		{`1 2`, `1`},
+		{"\"a\x01\"", `"a\u0001"`},
+	})
+}
+
+func TestParseComments(t *testing.T) {
+	opts := ParseOptions{AllowComments: true}
+	runParseTests(t, opts, []parseTest{
+		{"// head\n{\"a\":1}", `{"a":1}`},
+		{"{\"a\": /* inline */ 1}", `{"a":1}`},
+		{"[1, // one\n 2 /* two */]", `[1,2]`},
+		{"/* multi\nline */ [ /**/ ]", `[]`},
+		{"{/* k */\"k\"/* : */:/* v */\"v\"}", `{"k":"v"}`},
+		{"1 // trailing", `1`},
+		{`["// not a comment", "/* nor this */"]`, `["// not a comment","/* nor this */"]`},
+		{"[1 /* x", `error: unterminated block comment at offset 3`},
+		{"[1 / 2]", `error: unexpected / at offset 3`},
+	})
+	runParseTests(t, ParseOptions{}, []parseTest{
+		{"[1, // c\n 2]", `error: not match`},
+		{"/* c */ 1", `error: not match`},
+	})
+}
+
+func TestParseTrailingCommas(t *testing.T) {
+	runParseTests(t, ParseOptions{AllowTrailingCommas: true}, []parseTest{
+		{`[1,2,]`, `[1,2]`},
+		{`{"a":1,}`, `{"a":1}`},
+		{`[[1,],{"b":[],},]`, `[[1],{"b":[]}]`},
+		{"[1 , \n]", `[1]`},
+		{`[,]`, `error: not match`},
+		{`{,}`, `error: expect: ", but get: ,`},
+		{`[1,,2]`, `error: not match`},
+		{`[1,,]`, `error: not match`},
+	})
+	runParseTests(t, ParseOptions{}, []parseTest{
+		{`[1,2,]`, `error: trailing comma before ]`},
+		{`{"a":1,}`, `error: trailing comma before }`},
+	})
+	runParseTests(t, ParseOptions{AllowTrailingCommas: true, AllowComments: true}, []parseTest{
+		{"[1, // last\n]", `[1]`},
+	})
+}
+
+func TestParseJSON5(t *testing.T) {
+	tests := []parseTest{
+		{`{a: 1, $b_1: 2, _c: 3}`, `{"a":1,"$b_1":2,"_c":3}`},
+		{`{'single': 'quoted'}`, `{"single":"quoted"}`},
+		{`'it\'s "fine"'`, `"it's \"fine\""`},
+		{`"it's"`, `"it's"`},
+		{"'line \\\n continued'", `"line  continued"`},
+		{`'\x41\v\0'`, `"A\u000b\u0000"`},
+		{`[1, 2,]`, `[1,2]`},
+		{`{a: 1,}`, `{"a":1}`},
+		{"// comment\n{a /* here */ : 1}", `{"a":1}`},
+		{`0x1F`, `31`},
+		{`-0XfF`, `-255`},
+		{`[NaN, Infinity, -Infinity, +Infinity]`, `[NaN,Infinity,-Infinity,Infinity]`},
+		{`+1`, `1`},
+		{`.5`, `0.5`},
+		{`5.`, `5`},
+		{`-.5e1`, `-5`},
+		{`[+.25]`, `[0.25]`},
+		{`[007]`, `error: expect: , or ], but get: 0`},
+		{`{a b: 1}`, `error: expect: :, but get: b`},
+		{`{1a: 1}`, `error: expect object key at offset 1`},
+	}
+	runParseTests(t, ParseOptions{JSON5: true}, tests)
+
+	for _, tt := range tests[:5] {
+		if _, err := ParseJSON5([]byte(tt.input)); err != nil {
+			t.Errorf("ParseJSON5(%q): %v", tt.input, err)
+		}
+	}
+	runParseTests(t, ParseOptions{}, []parseTest{
+		{`{a:1}`, `error: expect: ", but get: a`},
+		{`'x'`, `error: not match`},
+		{`+1`, `error: not match`},
+	})
+}
+
+func TestParseDuplicateKeys(t *testing.T) {
+	input := `{"a":1,"b":2,"a":3}`
+	tests := []struct {
+		policy DuplicateKeyPolicy
+		want   string
+	}{
+		{LastWins, `{"a":3,"b":2}`},
+		{FirstWins, `{"a":1,"b":2}`},
+		{ErrorOnDuplicate, `error: duplicate key "a" in object`},
+	}
+	for _, tt := range tests {
+		runParseTests(t, ParseOptions{DuplicateKeys: tt.policy}, []parseTest{{input, tt.want}})
+	}
+
+	// 不同对象中的同名键不算重复
+	runParseTests(t, ParseOptions{DuplicateKeys: ErrorOnDuplicate}, []parseTest{
+		{`[{"a":1},{"a":2}]`, `[{"a":1},{"a":2}]`},
+		{`{"a":{"a":1}}`, `{"a":{"a":1}}`},
+		{`{"a":1,"A":2}`, `{"a":1,"A":2}`},
+		{`{"x":{"b":1,"b":2}}`, `error: duplicate key "b" in object`},
+	})
+
+	v, err := ParseWithOptions([]byte(input), ParseOptions{DuplicateKeys: LastWins})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := v.Keys(); strings.Join(keys, ",") != "a,b" {
+		t.Errorf("keys: got %v", keys)
+	}
+}
+
+func TestParseMaxDepth(t *testing.T) {
+	runParseTests(t, ParseOptions{MaxDepth: 3}, []parseTest{
+		{`[[[1]]]`, `[[[1]]]`},
+		{`[[[[1]]]]`, `error: exceeded max depth 3 at offset 3`},
+		{`{"a":{"b":{"c":{}}}}`, `error: exceeded max depth 3 at offset 15`},
+		{`[{"a":[1]}]`, `[{"a":[1]}]`},
+		{`[[[]],[[]],[[]]]`, `[[[]],[[]],[[]]]`},
+	})
+
+	deep := strings.Repeat("[", DEFAULT_MAX_DEPTH+1) + strings.Repeat("]", DEFAULT_MAX_DEPTH+1)
+	_, err := Parse([]byte(deep))
+	if err == nil || !strings.HasPrefix(err.Error(), "exceeded max depth 10000") {
+		t.Errorf("default depth: got %v", err)
+	}
+	ok := strings.Repeat("[", DEFAULT_MAX_DEPTH) + strings.Repeat("]", DEFAULT_MAX_DEPTH)
+	if _, err := Parse([]byte(ok)); err != nil {
+		t.Errorf("depth %d: %v", DEFAULT_MAX_DEPTH, err)
+	}
+}
+
+func TestParseMaxDepthLazy(t *testing.T) {
+	for _, opts := range []ParseOptions{{MaxDepth: 3}, {MaxDepth: 3, Lazy: true}} {
+		runParseTests(t, opts, []parseTest{
+			{`[[[[1]]]]`, `error: exceeded max depth 3 at offset 3`},
+			{`{"a":[{"b":[]}]}`, `error: exceeded max depth 3 at offset 11`},
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		kind  Kind
+		want  interface{}
+	}{
+		{`"s"`, JSON_STRING, "s"},
+		{`-3`, JSON_NUMBER, int64(-3)},
+		{`18446744073709551615`, JSON_NUMBER, uint64(18446744073709551615)},
+		{`2.5`, JSON_NUMBER, 2.5},
+		{`true`, JSON_BOOLEAN, true},
+		{`null`, JSON_NULL, nil},
+	}
+	for _, tt := range tests {
+		v := mustParse(t, tt.input)
+		if v.Type() != tt.kind {
+			t.Errorf("%s: got kind %s, want %s", tt.input, v.Type(), tt.kind)
+		}
+		if got := v.Interface(); got != tt.want {
+			t.Errorf("%s: got %#v, want %#v", tt.input, got, tt.want)
+		}
+	}
+
+	v := mustParse(t, `{"a": [1, "x"], "b": {}}`)
+	m, err := v.Map()
+	if err != nil || len(m) != 2 {
+		t.Fatalf("got %v, %v", m, err)
+	}
+	arr, err := m["a"].Array()
+	if err != nil || len(arr) != 2 || arr[1].MustString() != "x" {
+		t.Errorf("a: got %v, %v", arr, err)
+	}
+	if m["b"].Type() != JSON_OBJECT {
+		t.Errorf("b: got %s", m["b"].Type())
+	}
+
+	for _, input := range []string{``, `   `, `{`, `[1,`, `{"a" 1}`, `tru`} {
+		if _, err := Parse([]byte(input)); err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
+	}
+}
+
+// 前 n 次 Err 返回 nil, 之后返回 context.Canceled. 并行解析时会被多个 goroutine 调用
+type cancelAfter struct {
+	context.Context
+	n atomic.Int64
+}
+
+func newCancelAfter(n int64) *cancelAfter {
+	c := &cancelAfter{Context: context.Background()}
+	c.n.Store(n)
+	return c
+}
+
+func (c *cancelAfter) Err() error {
+	if c.n.Add(-1) < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestParseContext(t *testing.T) {
+	large := "[" + strings.Repeat(`{"a":[1,2]},`, 10*CONTEXT_CHECK_INTERVAL) + "0]"
+	huge := "[" + strings.Repeat(`{"a":[1,2]},`, PARALLEL_MIN_SIZE/10) + "0]"
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		input string
+		opts  ParseOptions
+		err   error
+	}{
+		{"background", context.Background(), large, ParseOptions{}, nil},
+		{"canceled before", canceled, `1`, ParseOptions{}, context.Canceled},
+		{"deadline", expired, `1`, ParseOptions{}, context.DeadlineExceeded},
+		{"parallel background", context.Background(), huge, ParseOptions{Workers: 4}, nil},
+		{"canceled during", newCancelAfter(3), large, ParseOptions{}, context.Canceled},
+		{"small document", newCancelAfter(1), `{"a":[1,2,3]}`, ParseOptions{}, nil},
+		{"parallel", newCancelAfter(3), huge, ParseOptions{Workers: 4}, context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := ParseContextWithOptions(tt.ctx, []byte(tt.input), tt.opts)
+			if err != tt.err {
+				t.Fatalf("got %v, want %v", err, tt.err)
+			}
+			if err == nil && v == nil {
+				t.Errorf("got nil value")
+			}
+		})
+	}
+
+	// 取消后 Parser 可以继续使用, 之后的 Parse 不再检查 ctx
+	p := NewParser([]byte(large))
+	if _, err := p.ParseContext(newCancelAfter(2)); err != context.Canceled {
+		t.Fatalf("got %v", err)
+	}
+	if _, err := p.Parse(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseContext(canceled, []byte(`1`)); err != context.Canceled {
+		t.Errorf("ParseContext: got %v", err)
+	}
+}
+
+// 复用 Parser 时之前得到的值不受影响, 选项保留
+func TestParserReset(t *testing.T) {
+	docs := []parseTest{
+		{`{"a": "x\ty", "b": [1, 2]}`, `{"a":"x\ty","b":[1,2]}`},
+		{`["p\"q", "r\\s"]`, `["p\"q","r\\s"]`},
+		{`"short"`, `"short"`},
+		{`{"a": 1,}`, `{"a":1}`},
+		{`{"a" 1}`, "error: "},
+		{`[true, null]`, `[true,null]`},
+	}
+	p := NewParser(nil)
+	p.SetOptions(ParseOptions{AllowTrailingCommas: true})
+	var values []*Value
+	for _, tt := range docs {
+		p.Reset([]byte(tt.input))
+		v, err := p.Parse()
+		if strings.HasPrefix(tt.want, "error: ") {
+			if err == nil {
+				t.Errorf("%q: expected an error", tt.input)
+			}
+			values = append(values, nil)
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%q: %v", tt.input, err)
+		}
+		values = append(values, v)
+	}
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		if got := mustEncode(t, v); got != docs[i].want {
+			t.Errorf("%q: got %s after reuse, want %s", docs[i].input, got, docs[i].want)
+		}
+	}
+
+	// 同一个输入可以解析多次
+	p.Reset([]byte(`[1]`))
+	a, err1 := p.Parse()
+	b, err2 := p.Parse()
+	if err1 != nil || err2 != nil || a == b || mustEncode(t, a) != `[1]` || mustEncode(t, b) != `[1]` {
+		t.Errorf("parse twice: got %v %v, %v %v", a, b, err1, err2)
+	}
+}
+
+// 池中的 Parser 不会把上一次调用的选项或大块的缓冲区带给下一次
+func TestParserPool(t *testing.T) {
+	big := `"` + strings.Repeat(`\n`, MAX_POOLED_SCRATCH) + `"`
+	if _, err := ParseWithOptions([]byte(big), ParseOptions{AllowTrailingCommas: true, InternKeys: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Parse([]byte(`[1,]`)); err == nil {
+		t.Errorf("options leaked from a pooled parser")
+	}
+
+	p := getParser(nil, []byte(big), ParseOptions{})
+	if _, err := p.Parse(); err != nil {
+		t.Fatal(err)
+	}
+	putParser(p)
+	if p.buf != nil || p.scratch != nil || p.interned != nil || p.arena != nil {
+		t.Errorf("pooled parser keeps buf %d, scratch %d, interned %v", len(p.buf), cap(p.scratch), p.interned)
+	}
+}
+
+func TestInternKeys(t *testing.T) {
+	input := []byte(`[{"id": 1, "name": "a"}, {"id": 2, "name": "b"}, {name: "c", "id": 3}]`)
+	shared := func(v *Value) bool {
+		first := v.Get("[0]").Keys()
+		for i := 1; i < 3; i++ {
+			keys := v.Get(fmt.Sprintf("[%d]", i)).Keys()
+			for _, k := range keys {
+				found := false
+				for _, f := range first {
+					if k == f && unsafe.StringData(k) == unsafe.StringData(f) {
+						found = true
+					}
+				}
+				if !found {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	v, err := ParseWithOptions(input, ParseOptions{JSON5: true, InternKeys: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !shared(v) {
+		t.Errorf("keys are not shared")
+	}
+	if got := mustEncode(t, v); got != `[{"id":1,"name":"a"},{"id":2,"name":"b"},{"name":"c","id":3}]` {
+		t.Errorf("got %s", got)
+	}
+	// 键的字符串不引用输入, 修改输入不影响结果
+	input[3] = 'X'
+	if v.Get("[0]").Keys()[0] != "id" {
+		t.Errorf("key changed with the input")
+	}
+
+	v, _ = ParseWithOptions([]byte(`[{"id": 1}, {"id": 2}, {"id": 3}]`), ParseOptions{})
+	if shared(v) {
+		t.Errorf("keys are shared without InternKeys")
+	}
+
+	// 键表最多保存 MAX_INTERNED_KEYS 个键, 同一个 Parser 的多次解析之间保留
+	var b strings.Builder
+	b.WriteString("{")
+	for i := 0; i < MAX_INTERNED_KEYS+10; i++ {
+		fmt.Fprintf(&b, `"k%d": %d,`, i, i)
+	}
+	b.WriteString(`"last": 0}`)
+	p := NewParser([]byte(b.String()))
+	p.SetOptions(ParseOptions{InternKeys: true})
+	if _, err := p.Parse(); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.interned) != MAX_INTERNED_KEYS {
+		t.Errorf("got %d interned keys", len(p.interned))
+	}
+	p.Reset([]byte(`{"k0": 1}`))
+	first, _ := p.Parse()
+	p.Reset([]byte(`{"k0": 2}`))
+	second, _ := p.Parse()
+	if unsafe.StringData(first.Keys()[0]) != unsafe.StringData(second.Keys()[0]) {
+		t.Errorf("key table not kept across Reset")
+	}
+}
@@ -0,0 +1,315 @@
+package yjson
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RFC 6902 JSON Patch 中的一个操作, 路径已经按 JSON Pointer 拆分
+type patchOp struct {
+	op       string
+	path     string
+	from     string
+	pathKeys []string
+	fromKeys []string
+	value    *Value
+}
+
+// test 操作比较的值不相等, 用 errors.Is 判断
+var ErrPatchTestFailed = errors.New("test failed")
+
+// 执行某个操作失败, Err 为具体原因
+type PatchError struct {
+	Index int // 操作在 patch 中的下标, 从 0 开始
+	Op    string
+	Path  string
+	Err   error
+}
+
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("patch operation %d (%s %q): %v", e.Index, e.Op, e.Path, e.Err)
+}
+
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// 按 RFC 6902 依次执行 patch 中的 add, remove, replace, move, copy, test 操作,
+// 返回修改后的新文档, doc 本身不变. 任何一个操作失败时返回错误, 不会得到只执行了
+// 一部分的结果. 路径是 RFC 6901 JSON Pointer, 如 /items/0/name, 键中的 ~ 和 /
+// 写作 ~0 和 ~1, add 的数组下标 - 表示追加到末尾. patch 格式错误时返回普通的错误,
+// 执行失败时返回 *PatchError
+func ApplyPatch(doc, patch *Value) (*Value, error) {
+	ops, err := parsePatch(patch)
+	if err != nil {
+		return nil, err
+	}
+	out := copyValue(doc)
+	for i, op := range ops {
+		if out, err = op.apply(out); err != nil {
+			return nil, &PatchError{Index: i, Op: op.op, Path: op.path, Err: err}
+		}
+	}
+	return out, nil
+}
+
+// 检查 patch 中的所有操作 (包括 test) 能否依次成功执行, 不修改 doc. 返回的错误与
+// ApplyPatch 相同, 可以在真正修改之前整体拒绝有问题的 patch
+func ValidatePatch(doc, patch *Value) error {
+	_, err := ApplyPatch(doc, patch)
+	return err
+}
+
+func parsePatch(patch *Value) ([]patchOp, error) {
+	if patch.Type() != JSON_ARRAY {
+		return nil, fmt.Errorf("invalid patch: expect array, but get %s", patch.Type())
+	}
+	items, err := patch.Array()
+	if err != nil {
+		return nil, err
+	}
+	ops := make([]patchOp, 0, len(items))
+	for i, item := range items {
+		op, err := parsePatchOp(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid patch operation %d: %v", i, err)
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+func parsePatchOp(item *Value) (patchOp, error) {
+	var op patchOp
+	if item.Type() != JSON_OBJECT {
+		return op, fmt.Errorf("expect object, but get %s", item.Type())
+	}
+	name, err := item.Get("op").String()
+	if err != nil {
+		return op, fmt.Errorf("op: %v", err)
+	}
+	if op.path, err = item.Get("path").String(); err != nil {
+		return op, fmt.Errorf("path: %v", err)
+	}
+	if op.pathKeys, err = parsePointer(op.path); err != nil {
+		return op, err
+	}
+	op.op = name
+
+	switch name {
+	case "add", "replace", "test":
+		op.value = item.Get("value")
+		if !op.value.Exists() {
+			return op, fmt.Errorf("%s requires value", name)
+		}
+	case "move", "copy":
+		if op.from, err = item.Get("from").String(); err != nil {
+			return op, fmt.Errorf("from: %v", err)
+		}
+		if op.fromKeys, err = parsePointer(op.from); err != nil {
+			return op, err
+		}
+	case "remove":
+	default:
+		return op, fmt.Errorf("unknown op %q", name)
+	}
+	return op, nil
+}
+
+// 拆分 JSON Pointer, 空字符串表示整个文档
+func parsePointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+	if ptr[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must start with /", ptr)
+	}
+	keys := strings.Split(ptr[1:], "/")
+	for i, key := range keys {
+		if !strings.Contains(key, "~") {
+			continue
+		}
+		for k := 0; k < len(key); k++ {
+			if key[k] == '~' && (k+1 == len(key) || (key[k+1] != '0' && key[k+1] != '1')) {
+				return nil, fmt.Errorf("invalid JSON pointer %q: bad escape in %q", ptr, key)
+			}
+		}
+		keys[i] = strings.ReplaceAll(strings.ReplaceAll(key, "~1", "/"), "~0", "~")
+	}
+	return keys, nil
+}
+
+// 返回根节点, 根节点可能被 add 或 replace 整个替换
+func (op patchOp) apply(doc *Value) (*Value, error) {
+	switch op.op {
+	case "add":
+		return pointerAdd(doc, op.pathKeys, copyValue(op.value))
+	case "remove":
+		_, err := pointerRemove(doc, op.pathKeys)
+		return doc, err
+	case "replace":
+		return pointerReplace(doc, op.pathKeys, copyValue(op.value))
+	case "move":
+		if op.from == op.path {
+			_, err := pointerGet(doc, op.fromKeys)
+			return doc, err
+		}
+		if strings.HasPrefix(op.path, op.from+"/") {
+			return nil, fmt.Errorf("cannot move %q into one of its children", op.from)
+		}
+		v, err := pointerRemove(doc, op.fromKeys)
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(doc, op.pathKeys, v)
+	case "copy":
+		v, err := pointerGet(doc, op.fromKeys)
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(doc, op.pathKeys, copyValue(v))
+	case "test":
+		v, err := pointerGet(doc, op.pathKeys)
+		if err != nil {
+			return nil, err
+		}
+		if !equalValues(v, op.value) {
+			return nil, ErrPatchTestFailed
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("unknown op %q", op.op)
+}
+
+func pointerGet(doc *Value, keys []string) (*Value, error) {
+	cur := doc
+	for _, key := range keys {
+		if err := cur.load(); err != nil {
+			return nil, err
+		}
+		switch cur.Type() {
+		case JSON_OBJECT:
+			next, ok := cur.obj[key]
+			if !ok {
+				return nil, fmt.Errorf("member %q not found", key)
+			}
+			cur = next
+		case JSON_ARRAY:
+			i, err := pointerIndex(key, len(cur.arr), false)
+			if err != nil {
+				return nil, err
+			}
+			cur = cur.arr[i]
+		default:
+			return nil, fmt.Errorf("cannot get %q on %s", key, cur.Type())
+		}
+	}
+	return cur, nil
+}
+
+// 数组下标不能有前导零, end 为 true 时接受等于长度的下标和 -
+func pointerIndex(key string, n int, end bool) (int, error) {
+	if key == "-" && end {
+		return n, nil
+	}
+	i, err := strconv.Atoi(key)
+	if err != nil || i < 0 || (len(key) > 1 && key[0] == '0') || key[0] == '+' {
+		return 0, fmt.Errorf("invalid array index %q", key)
+	}
+	if i > n || (i == n && !end) {
+		return 0, fmt.Errorf("index %d out of range, array length is %d", i, n)
+	}
+	return i, nil
+}
+
+// 返回值所在的容器和最后一个键, keys 不能为空
+func pointerParent(doc *Value, keys []string) (*Value, string, error) {
+	parent, err := pointerGet(doc, keys[:len(keys)-1])
+	if err != nil {
+		return nil, "", err
+	}
+	if err := parent.load(); err != nil {
+		return nil, "", err
+	}
+	return parent, keys[len(keys)-1], nil
+}
+
+func pointerAdd(doc *Value, keys []string, v *Value) (*Value, error) {
+	if len(keys) == 0 {
+		return v, nil
+	}
+	parent, key, err := pointerParent(doc, keys)
+	if err != nil {
+		return nil, err
+	}
+	switch parent.Type() {
+	case JSON_OBJECT:
+		parent.setMember(key, v)
+	case JSON_ARRAY:
+		i, err := pointerIndex(key, len(parent.arr), true)
+		if err != nil {
+			return nil, err
+		}
+		if err := parent.InsertAt(i, v); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("cannot add %q on %s", key, parent.Type())
+	}
+	return doc, nil
+}
+
+// 返回被删除的值
+func pointerRemove(doc *Value, keys []string) (*Value, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("cannot remove the whole document")
+	}
+	parent, key, err := pointerParent(doc, keys)
+	if err != nil {
+		return nil, err
+	}
+	switch parent.Type() {
+	case JSON_OBJECT:
+		v, ok := parent.obj[key]
+		if !ok {
+			return nil, fmt.Errorf("member %q not found", key)
+		}
+		parent.deleteMember(key)
+		return v, nil
+	case JSON_ARRAY:
+		i, err := pointerIndex(key, len(parent.arr), false)
+		if err != nil {
+			return nil, err
+		}
+		return parent.RemoveAt(i)
+	}
+	return nil, fmt.Errorf("cannot remove %q on %s", key, parent.Type())
+}
+
+func pointerReplace(doc *Value, keys []string, v *Value) (*Value, error) {
+	if len(keys) == 0 {
+		return v, nil
+	}
+	parent, key, err := pointerParent(doc, keys)
+	if err != nil {
+		return nil, err
+	}
+	switch parent.Type() {
+	case JSON_OBJECT:
+		if _, ok := parent.obj[key]; !ok {
+			return nil, fmt.Errorf("member %q not found", key)
+		}
+		parent.setMember(key, v)
+	case JSON_ARRAY:
+		i, err := pointerIndex(key, len(parent.arr), false)
+		if err != nil {
+			return nil, err
+		}
+		parent.arr[i] = v
+	default:
+		return nil, fmt.Errorf("cannot replace %q on %s", key, parent.Type())
+	}
+	return doc, nil
+}
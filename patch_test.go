@@ -0,0 +1,130 @@
+package yjson
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		doc, patch string
+		want       string
+	}{
+		// RFC 6902 附录 A
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"foo":"bar","baz":"qux"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{`{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{`{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10}]`, `{"/":9,"~1":10}`},
+		{`{"foo":1}`, `[{"op":"copy","from":"/foo","path":"/bar"}]`, `{"foo":1,"bar":1}`},
+		{`{"foo":1}`, `[{"op":"replace","path":"","value":[1]}]`, `[1]`},
+		{`{"n":1.0}`, `[{"op":"test","path":"/n","value":1}]`, `{"n":1}`},
+		{`{"id":9007199254740993}`, `[{"op":"test","path":"/id","value":9007199254740993}]`, `{"id":9007199254740993}`},
+	}
+	for _, tt := range tests {
+		out, err := ApplyPatch(mustParse(t, tt.doc), mustParse(t, tt.patch))
+		if err != nil {
+			t.Errorf("ApplyPatch(%s, %s): %v", tt.doc, tt.patch, err)
+			continue
+		}
+		if !Equal(out, mustParse(t, tt.want)) {
+			t.Errorf("ApplyPatch(%s, %s) = %s, want %s", tt.doc, tt.patch, mustEncode(t, out), tt.want)
+		}
+	}
+}
+
+func TestApplyPatchErrors(t *testing.T) {
+	tests := []struct {
+		doc, patch string
+		err        string
+	}{
+		{`{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, `patch operation 0 (test "/baz"): test failed`},
+		{`{"id":9007199254740993}`, `[{"op":"test","path":"/id","value":9007199254740992}]`, `patch operation 0 (test "/id"): test failed`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, `patch operation 0 (add "/baz/bat"): member "baz" not found`},
+		{`{"foo":[1]}`, `[{"op":"add","path":"/foo/01","value":2}]`, `patch operation 0 (add "/foo/01"): invalid array index "01"`},
+		{`{"foo":[1]}`, `[{"op":"remove","path":"/foo/1"}]`, `patch operation 0 (remove "/foo/1"): index 1 out of range, array length is 1`},
+		{`{"foo":[1]}`, `[{"op":"remove","path":"/foo/-"}]`, `patch operation 0 (remove "/foo/-"): invalid array index "-"`},
+		{`{"a":{"b":1}}`, `[{"op":"move","from":"/a","path":"/a/b/c"}]`, `patch operation 0 (move "/a/b/c"): cannot move "/a" into one of its children`},
+		{`{}`, `[{"op":"remove","path":""}]`, `patch operation 0 (remove ""): cannot remove the whole document`},
+		{`{}`, `{"op":"add"}`, `invalid patch: expect array, but get object`},
+		{`{}`, `[{"op":"frob","path":"/a"}]`, `invalid patch operation 0: unknown op "frob"`},
+		{`{}`, `[{"op":"add","path":"/a"}]`, `invalid patch operation 0: add requires value`},
+		{`{}`, `[{"op":"add","path":"a","value":1}]`, `invalid patch operation 0: invalid JSON pointer "a": must start with /`},
+		{`{}`, `[{"op":"add","path":"/a~2","value":1}]`, `invalid patch operation 0: invalid JSON pointer "/a~2": bad escape in "a~2"`},
+	}
+	for _, tt := range tests {
+		_, err := ApplyPatch(mustParse(t, tt.doc), mustParse(t, tt.patch))
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("ApplyPatch(%s, %s): got %v, want %s", tt.doc, tt.patch, err, tt.err)
+		}
+	}
+}
+
+// 失败的 patch 不修改原文档, 也不返回执行了一部分的结果
+func TestApplyPatchAtomic(t *testing.T) {
+	doc := mustParse(t, `{"a":1}`)
+	out, err := ApplyPatch(doc, mustParse(t, `[{"op":"add","path":"/b","value":2},{"op":"remove","path":"/c"}]`))
+	if err == nil || out != nil {
+		t.Fatalf("got %v, %v; want error", out, err)
+	}
+	if got := mustEncode(t, doc); got != `{"a":1}` {
+		t.Errorf("doc modified: %s", got)
+	}
+}
+
+func TestValidatePatch(t *testing.T) {
+	const doc = `{"a":1,"list":[1,2],"obj":{"k":"v"}}`
+	tests := []struct {
+		patch    string
+		index    int // 失败的操作下标, -1 表示成功, -2 表示 patch 格式错误
+		op       string
+		testFail bool
+	}{
+		{`[]`, -1, "", false},
+		{`[{"op":"test","path":"/a","value":1},{"op":"remove","path":"/a"},{"op":"add","path":"/a","value":2},{"op":"test","path":"/a","value":2}]`, -1, "", false},
+		{`[{"op":"move","from":"/obj/k","path":"/list/-"},{"op":"test","path":"/list/2","value":"v"}]`, -1, "", false},
+		// 后面的操作依赖前面操作的结果
+		{`[{"op":"remove","path":"/a"},{"op":"test","path":"/a","value":1}]`, 1, "test", false},
+		{`[{"op":"test","path":"/a","value":2}]`, 0, "test", true},
+		{`[{"op":"add","path":"/b","value":1},{"op":"test","path":"/b","value":"1"}]`, 1, "test", true},
+		{`[{"op":"add","path":"/b","value":1},{"op":"replace","path":"/nope","value":1}]`, 1, "replace", false},
+		{`[{"op":"copy","from":"/missing","path":"/x"}]`, 0, "copy", false},
+		{`[{"op":"add","path":"/list/5","value":1}]`, 0, "add", false},
+		{`[{"op":"test","path":"/a"}]`, -2, "", false},
+		{`{"op":"test"}`, -2, "", false},
+	}
+	for _, tt := range tests {
+		v := mustParse(t, doc)
+		err := ValidatePatch(v, mustParse(t, tt.patch))
+		if got := mustEncode(t, v); got != doc {
+			t.Errorf("%s: doc modified to %s", tt.patch, got)
+		}
+		var pe *PatchError
+		switch {
+		case tt.index == -1:
+			if err != nil {
+				t.Errorf("%s: %v", tt.patch, err)
+			}
+		case tt.index == -2:
+			if err == nil || errors.As(err, &pe) {
+				t.Errorf("%s: got %v, want a plain error", tt.patch, err)
+			}
+		case !errors.As(err, &pe) || pe.Index != tt.index || pe.Op != tt.op:
+			t.Errorf("%s: got %v, want operation %d (%s) to fail", tt.patch, err, tt.index, tt.op)
+		case errors.Is(err, ErrPatchTestFailed) != tt.testFail:
+			t.Errorf("%s: errors.Is(ErrPatchTestFailed) = %v", tt.patch, !tt.testFail)
+		}
+
+		// 与 ApplyPatch 的结果一致
+		_, applyErr := ApplyPatch(v, mustParse(t, tt.patch))
+		if (err == nil) != (applyErr == nil) || (err != nil && err.Error() != applyErr.Error()) {
+			t.Errorf("%s: ValidatePatch %v, ApplyPatch %v", tt.patch, err, applyErr)
+		}
+	}
+}
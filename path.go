@@ -0,0 +1,501 @@
+package yjson
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// 路径中的一段: 对象的键, 数组下标或匹配所有成员的 *.
+// recursive 表示段之前是 .., 在当前节点及其所有后代中匹配这一段
+type pathSegment struct {
+	key       string
+	index     int
+	isIndex   bool
+	wildcard  bool
+	recursive bool
+}
+
+// 解析 a.b[3].c, ["key.with.dot"], a\.b 形式的路径, 空路径表示自身.
+// * 和 [*] 匹配对象的所有成员或数组的所有元素, ..key 匹配任意深度的 key,
+// 开头可以加 $ 表示根节点, 如 $..price, items.*.id. 名为 * 的键写作 \* 或 ["*"]
+func parsePath(path string) ([]pathSegment, error) {
+	segments := make([]pathSegment, 0)
+	for i := pathStart(path); i < len(path); {
+		seg, n, err := nextPathSegment(path, i)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, seg)
+		i = n
+	}
+	return segments, nil
+}
+
+// 解析从 path[i] 开始的一段, path[i] 可以是段之前的 '.', 返回下一段的起始位置.
+// 不含转义的键直接引用 path, 不分配内存
+func nextPathSegment(path string, i int) (pathSegment, int, error) {
+	recursive := false
+	if path[i] == '.' {
+		i++
+		if i < len(path) && path[i] == '.' {
+			recursive = true
+			i++
+		}
+		if i == len(path) || path[i] == '.' {
+			return pathSegment{}, 0, fmt.Errorf("invalid path %q: empty key at offset %d", path, i)
+		}
+	}
+	seg, next, err := nextPathStep(path, i)
+	seg.recursive = recursive
+	return seg, next, err
+}
+
+// 开头的 $ 表示根节点, 后面必须是路径结束, . 或 [, 否则 $ 是键的一部分
+func pathStart(path string) int {
+	if len(path) > 0 && path[0] == '$' && (len(path) == 1 || path[1] == '.' || path[1] == '[') {
+		return 1
+	}
+	return 0
+}
+
+func nextPathStep(path string, i int) (pathSegment, int, error) {
+	if path[i] == '[' {
+		return parseBracket(path, i)
+	}
+
+	start, escaped := i, false
+	for i < len(path) && path[i] != '.' && path[i] != '[' {
+		if path[i] == BACKSLASH && i+1 < len(path) {
+			escaped = true
+			i++
+		}
+		i++
+	}
+	if !escaped {
+		key := path[start:i]
+		return pathSegment{key: key, wildcard: key == "*"}, i, nil
+	}
+	key := make([]byte, 0, i-start)
+	for k := start; k < i; k++ {
+		if path[k] == BACKSLASH && k+1 < len(path) {
+			k++
+		}
+		key = append(key, path[k])
+	}
+	return pathSegment{key: string(key)}, i, nil
+}
+
+// 解析从 path[i] == '[' 开始的 [3] 或 ["key"], 返回下一段的起始位置
+func parseBracket(path string, i int) (pathSegment, int, error) {
+	end := strings.IndexByte(path[i:], ']')
+	if i+1 < len(path) && (path[i+1] == DQ || path[i+1] == SQ) {
+		quote := path[i+1]
+		key := make([]byte, 0)
+		j := i + 2
+		for j < len(path) && path[j] != quote {
+			if path[j] == BACKSLASH && j+1 < len(path) {
+				j++
+			}
+			key = append(key, path[j])
+			j++
+		}
+		if j+1 >= len(path) || path[j+1] != ']' {
+			return pathSegment{}, 0, fmt.Errorf("invalid path %q: unterminated bracket at offset %d", path, i)
+		}
+		if quote == DQ {
+			unquoted, err := strconv.Unquote(path[i+1 : j+1])
+			if err != nil {
+				return pathSegment{}, 0, fmt.Errorf("invalid path %q: bad quoted key at offset %d", path, i)
+			}
+			return pathSegment{key: unquoted}, j + 2, nil
+		}
+		return pathSegment{key: string(key)}, j + 2, nil
+	}
+
+	if end < 0 {
+		return pathSegment{}, 0, fmt.Errorf("invalid path %q: unterminated bracket at offset %d", path, i)
+	}
+	if path[i+1:i+end] == "*" {
+		return pathSegment{key: "*", wildcard: true}, i + end + 1, nil
+	}
+	n, err := strconv.Atoi(path[i+1 : i+end])
+	if err != nil || n < 0 {
+		return pathSegment{}, 0, fmt.Errorf("invalid path %q: bad index %q", path, path[i+1:i+end])
+	}
+	return pathSegment{index: n, isIndex: true}, i + end + 1, nil
+}
+
+// 在对象或数组中取一段, 不存在时返回 nil. 数组也接受纯数字的键, 如 users.3
+func (seg pathSegment) lookup(j *Value) *Value {
+	if j.load() != nil {
+		return nil
+	}
+	switch j.Type() {
+	case JSON_OBJECT:
+		if seg.isIndex {
+			return nil
+		}
+		return j.obj[seg.key]
+	case JSON_ARRAY:
+		index := seg.index
+		if !seg.isIndex {
+			n, err := strconv.Atoi(seg.key)
+			if err != nil {
+				return nil
+			}
+			index = n
+		}
+		arr := j.arr
+		if index < 0 || index >= len(arr) {
+			return nil
+		}
+		return arr[index]
+	}
+	return nil
+}
+
+// 对象的键 key 是否匹配这一段
+func (seg pathSegment) matchKey(key string) bool {
+	return seg.wildcard || (!seg.isIndex && seg.key == key)
+}
+
+// 数组下标 i 是否匹配这一段
+func (seg pathSegment) matchIndex(i int) bool {
+	if seg.wildcard {
+		return true
+	}
+	index, err := seg.arrayIndex()
+	return err == nil && index == i
+}
+
+// 是否含有 * 或 .., 这样的路径可能有多个匹配
+func multiMatch(segments []pathSegment) bool {
+	for _, seg := range segments {
+		if seg.wildcard || seg.recursive {
+			return true
+		}
+	}
+	return false
+}
+
+// 按文档顺序把 j 中匹配 segments 的每个值传给 emit, emit 返回 false 时停止,
+// 此时也返回 false. 先检查成员本身, 再检查成员的后代
+func matchPath(j *Value, segments []pathSegment, emit func(v *Value) bool) bool {
+	if len(segments) == 0 {
+		return emit(j)
+	}
+	if j.load() != nil {
+		return true
+	}
+
+	seg := segments[0]
+	switch j.Type() {
+	case JSON_OBJECT:
+		for _, key := range j.keys {
+			v := j.obj[key]
+			if seg.matchKey(key) && !matchPath(v, segments[1:], emit) {
+				return false
+			}
+			if seg.recursive && !matchPath(v, segments, emit) {
+				return false
+			}
+		}
+	case JSON_ARRAY:
+		for i, v := range j.arr {
+			if seg.matchIndex(i) && !matchPath(v, segments[1:], emit) {
+				return false
+			}
+			if seg.recursive && !matchPath(v, segments, emit) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func missingValue() *Value {
+	return &Value{valueType: JSON_MISSING}
+}
+
+// 按路径查找, 如 Get("users[3].address.city"). 路径不存在或非法时返回
+// Type() 为 JSON_MISSING 的值而不是 nil, 可以继续链式调用.
+// 含有 * 或 .. 的路径返回第一个匹配, 全部匹配见 GetAll
+func (j *Value) Get(path string) *Value {
+	segments, err := parsePath(path)
+	if err != nil {
+		return missingValue()
+	}
+	return getPath(j, segments)
+}
+
+func getPath(j *Value, segments []pathSegment) *Value {
+	if multiMatch(segments) {
+		var found *Value
+		matchPath(j, segments, func(v *Value) bool {
+			found = v
+			return false
+		})
+		if found == nil {
+			return missingValue()
+		}
+		return found
+	}
+
+	cur := j
+	for _, seg := range segments {
+		cur = seg.lookup(cur)
+		if cur == nil {
+			return missingValue()
+		}
+	}
+	if cur == nil {
+		return missingValue()
+	}
+	return cur
+}
+
+// 按文档顺序返回所有匹配路径的值, 路径不存在或非法时返回空切片.
+// 不含 * 和 .. 的路径最多只有一个匹配
+func (j *Value) GetAll(path string) []*Value {
+	segments, err := parsePath(path)
+	if err != nil {
+		return make([]*Value, 0)
+	}
+	return getAll(j, segments)
+}
+
+func getAll(j *Value, segments []pathSegment) []*Value {
+	values := make([]*Value, 0)
+	matchPath(j, segments, func(v *Value) bool {
+		values = append(values, v)
+		return true
+	})
+	return values
+}
+
+// 在 j 中写入一段, 数组下标等于长度时追加
+func (seg pathSegment) store(j *Value, v *Value) error {
+	if err := j.load(); err != nil {
+		return err
+	}
+	switch j.Type() {
+	case JSON_OBJECT:
+		if seg.isIndex {
+			return fmt.Errorf("cannot use index [%d] on object", seg.index)
+		}
+		j.setMember(seg.key, v)
+		return nil
+	case JSON_ARRAY:
+		index, err := seg.arrayIndex()
+		if err != nil {
+			return err
+		}
+		arr := j.arr
+		if index == len(arr) {
+			j.arr = append(arr, v)
+			return nil
+		}
+		if index > len(arr) {
+			return fmt.Errorf("index [%d] out of range, array length is %d", index, len(arr))
+		}
+		arr[index] = v
+		return nil
+	}
+	return fmt.Errorf("cannot set %s on %s", seg, j.Type())
+}
+
+func (seg pathSegment) remove(j *Value) error {
+	if err := j.load(); err != nil {
+		return err
+	}
+	switch j.Type() {
+	case JSON_OBJECT:
+		if !seg.isIndex && j.deleteMember(seg.key) {
+			return nil
+		}
+	case JSON_ARRAY:
+		index, err := seg.arrayIndex()
+		if err != nil {
+			return err
+		}
+		arr := j.arr
+		if index < len(arr) {
+			j.arr = append(arr[:index], arr[index+1:]...)
+			return nil
+		}
+	default:
+		return fmt.Errorf("cannot delete %s on %s", seg, j.Type())
+	}
+	return fmt.Errorf("%s not found", seg)
+}
+
+func (seg pathSegment) arrayIndex() (int, error) {
+	if seg.isIndex {
+		return seg.index, nil
+	}
+	n, err := strconv.Atoi(seg.key)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("cannot use key %q on array", seg.key)
+	}
+	return n, nil
+}
+
+func (seg pathSegment) String() string {
+	prefix := ""
+	if seg.recursive {
+		prefix = ".."
+	}
+	switch {
+	case seg.wildcard:
+		return prefix + "*"
+	case seg.isIndex:
+		return fmt.Sprintf("%s[%d]", prefix, seg.index)
+	}
+	return prefix + strconv.Quote(seg.key)
+}
+
+// 按路径写入 v, 缺失的中间节点按下一段的类型创建为对象或数组;
+// 中间节点类型不符时返回错误. 空路径替换 j 自身, v 为 nil 时写入 null
+func (j *Value) Set(path string, v *Value) error {
+	segments, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	return setPath(j, path, segments, v)
+}
+
+func setPath(j *Value, path string, segments []pathSegment, v *Value) error {
+	if multiMatch(segments) {
+		return fmt.Errorf("set %q: cannot use * or .. in path", path)
+	}
+	if v == nil {
+		v = NewNull()
+	}
+	if len(segments) == 0 {
+		*j = *v
+		return nil
+	}
+
+	cur := j
+	for i, seg := range segments[:len(segments)-1] {
+		next := seg.lookup(cur)
+		if next == nil {
+			if segments[i+1].isIndex {
+				next = NewArray()
+			} else {
+				next = NewObject()
+			}
+			if err := seg.store(cur, next); err != nil {
+				return fmt.Errorf("set %q: %v", path, err)
+			}
+		}
+		cur = next
+	}
+
+	if err := segments[len(segments)-1].store(cur, v); err != nil {
+		return fmt.Errorf("set %q: %v", path, err)
+	}
+	return nil
+}
+
+// 按路径删除, 路径不存在时返回错误
+func (j *Value) Delete(path string) error {
+	segments, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	return deletePath(j, path, segments)
+}
+
+func deletePath(j *Value, path string, segments []pathSegment) error {
+	if len(segments) == 0 {
+		return fmt.Errorf("delete: empty path")
+	}
+	if multiMatch(segments) {
+		return fmt.Errorf("delete %q: cannot use * or .. in path", path)
+	}
+
+	cur := j
+	for _, seg := range segments[:len(segments)-1] {
+		cur = seg.lookup(cur)
+		if cur == nil {
+			return fmt.Errorf("delete %q: %s not found", path, seg)
+		}
+	}
+
+	if err := segments[len(segments)-1].remove(cur); err != nil {
+		return fmt.Errorf("delete %q: %v", path, err)
+	}
+	return nil
+}
+
+// 编译后的路径, 语法与 Get 相同. 只读, 可以在多个 goroutine 中同时使用,
+// 重复查找时省去每次解析路径字符串. 被查找的 Value 是否可以同时读取见 ParseOptions.Lazy
+type Path struct {
+	expr     string
+	segments []pathSegment
+}
+
+func CompilePath(expr string) (*Path, error) {
+	segments, err := parsePath(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &Path{expr: expr, segments: segments}, nil
+}
+
+func (p *Path) String() string {
+	return p.expr
+}
+
+// 与 j.Get(p.String()) 相同
+func (p *Path) Get(j *Value) *Value {
+	return getPath(j, p.segments)
+}
+
+func (p *Path) GetAll(j *Value) []*Value {
+	return getAll(j, p.segments)
+}
+
+func (p *Path) Set(j *Value, v *Value) error {
+	return setPath(j, p.expr, p.segments, v)
+}
+
+func (p *Path) Delete(j *Value) error {
+	return deletePath(j, p.expr, p.segments)
+}
+
+// 与 GetBytes(data, p.String()) 相同
+func (p *Path) GetBytes(data []byte) Result {
+	return getRaw(data, p.segments)
+}
+
+func (p *Path) SetBytes(data []byte, value interface{}) ([]byte, error) {
+	return setBytes(data, p.expr, p.segments, value)
+}
+
+// 与 StreamGet(r, p.String(), fn) 相同
+func (p *Path) StreamGet(r io.Reader, fn func(v *Value)) error {
+	return streamGet(r, p.segments, ParseOptions{}, fn)
+}
+
+// 具体的路径 concrete 是否匹配可能含有 * 和 .. 的 pattern, concrete 中只有键和下标
+func matchSegments(pattern, concrete []pathSegment) bool {
+	if len(pattern) == 0 {
+		return len(concrete) == 0
+	}
+	if len(concrete) == 0 {
+		return false
+	}
+	seg, c := pattern[0], concrete[0]
+	ok := seg.matchKey(c.key)
+	if c.isIndex {
+		ok = seg.matchIndex(c.index)
+	}
+	if ok && matchSegments(pattern[1:], concrete[1:]) {
+		return true
+	}
+	return seg.recursive && matchSegments(pattern, concrete[1:])
+}
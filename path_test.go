@@ -0,0 +1,351 @@
+package yjson
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+const pathDoc = `{
+	"users": [
+		{"name": "a", "address": {"city": "x"}},
+		{"name": "b", "address": {"city": "y"}, "tags": ["t1", "t2"]}
+	],
+	"a.b": 1,
+	"a": {"b": 2},
+	"*": "star",
+	"": "empty",
+	"q\"k": 3
+}`
+
+func TestGet(t *testing.T) {
+	v := mustParse(t, pathDoc)
+	tests := []struct {
+		path string
+		want string // 空表示不存在
+	}{
+		{"", ""}, // 自身, 单独检查
+		{"users[1].address.city", `"y"`},
+		{"users.1.address.city", `"y"`},
+		{"$.users[0].name", `"a"`},
+		{"users[1].tags[1]", `"t2"`},
+		{`["a.b"]`, `1`},
+		{`a\.b`, `1`},
+		{"a.b", `2`},
+		{`['a'].b`, `2`},
+		{`\*`, `"star"`},
+		{`["*"]`, `"star"`},
+		{`[""]`, `"empty"`},
+		{`["q\"k"]`, `3`},
+		{"users[2]", ""},
+		{"users[0].tags[0]", ""},
+		{"users.x", ""},
+		{"a[0]", ""},
+		{"a.b.c", ""},
+		{"nope.deeper.still", ""},
+		{"users[-1]", ""},
+		{"users[", ""},
+		{"a..", ""},
+	}
+	for _, tt := range tests {
+		got := v.Get(tt.path)
+		if tt.path == "" {
+			if got != v {
+				t.Errorf("empty path should return the value itself")
+			}
+			continue
+		}
+		if tt.want == "" {
+			if got.Exists() {
+				t.Errorf("%s: got %s, want missing", tt.path, mustEncode(t, got))
+			}
+			continue
+		}
+		if !got.Exists() {
+			t.Errorf("%s: missing, want %s", tt.path, tt.want)
+			continue
+		}
+		if s := mustEncode(t, got); s != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.path, s, tt.want)
+		}
+	}
+
+	// 链式查找不存在的值也是安全的
+	if v.Get("nope").Get("x").Get("[0]").Exists() {
+		t.Errorf("chained lookup on missing value should be missing")
+	}
+	var missing *Value
+	if missing.Get("a").Exists() {
+		t.Errorf("lookup on nil should be missing")
+	}
+}
+
+func TestParsePathErrors(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"a..", `invalid path "a..": empty key at offset 3`},
+		{"a.", `invalid path "a.": empty key at offset 2`},
+		{"a[1", `invalid path "a[1": unterminated bracket at offset 1`},
+		{`a["x]`, `invalid path "a[\"x]": unterminated bracket at offset 1`},
+		{"a[x]", `invalid path "a[x]": bad index "x"`},
+		{"a[-1]", `invalid path "a[-1]": bad index "-1"`},
+	}
+	for _, tt := range tests {
+		_, err := parsePath(tt.path)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: got %v, want %s", tt.path, err, tt.want)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		doc   string
+		path  string
+		value string
+		want  string
+	}{
+		{`{"a":1}`, "a", `2`, `{"a":2}`},
+		{`{"a":1}`, "b", `"x"`, `{"a":1,"b":"x"}`},
+		{`{}`, "a.b.c", `true`, `{"a":{"b":{"c":true}}}`},
+		{`{}`, "a[0].b", `1`, `{"a":[{"b":1}]}`},
+		{`{"a":[1,2]}`, "a[1]", `null`, `{"a":[1,null]}`},
+		{`{"a":[1,2]}`, "a[2]", `3`, `{"a":[1,2,3]}`},
+		{`{"a":[1,2]}`, "a.0", `0`, `{"a":[0,2]}`},
+		{`{"a":1}`, "", `[1]`, `[1]`},
+		{`{"a":[1]}`, "a[5]", `1`, `error: set "a[5]": index [5] out of range, array length is 1`},
+		{`{"a":1}`, "a.b", `1`, `error: set "a.b": cannot set "b" on number`},
+		{`{"a":{}}`, "a[0]", `1`, `error: set "a[0]": cannot use index [0] on object`},
+		{`{"a":[]}`, "a.x", `1`, `error: set "a.x": cannot use key "x" on array`},
+		{`{"a":[]}`, "a[*]", `1`, `error: set "a[*]": cannot use * or .. in path`},
+		{`{}`, "a[", `1`, `error: invalid path "a[": unterminated bracket at offset 1`},
+	}
+	for _, tt := range tests {
+		v := mustParse(t, tt.doc)
+		err := v.Set(tt.path, mustParse(t, tt.value))
+		if msg, ok := strings.CutPrefix(tt.want, "error: "); ok {
+			if err == nil || err.Error() != msg {
+				t.Errorf("%s set %s: got error %v, want %s", tt.doc, tt.path, err, msg)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s set %s: %v", tt.doc, tt.path, err)
+			continue
+		}
+		if got := mustEncode(t, v); got != tt.want {
+			t.Errorf("%s set %s: got %s, want %s", tt.doc, tt.path, got, tt.want)
+		}
+	}
+
+	// v 为 nil 时写入 null
+	v := mustParse(t, `{}`)
+	if err := v.Set("a", nil); err != nil || mustEncode(t, v) != `{"a":null}` {
+		t.Errorf("nil value: got %s, %v", mustEncode(t, v), err)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	tests := []struct {
+		doc  string
+		path string
+		want string
+	}{
+		{`{"a":1,"b":2,"c":3}`, "b", `{"a":1,"c":3}`},
+		{`{"a":{"b":1,"c":2}}`, "a.b", `{"a":{"c":2}}`},
+		{`{"a":[1,2,3]}`, "a[1]", `{"a":[1,3]}`},
+		{`{"a":[1,2,3]}`, "a.0", `{"a":[2,3]}`},
+		{`{"a":1}`, "b", `error: delete "b": "b" not found`},
+		{`{"a":[1]}`, "a[1]", `error: delete "a[1]": [1] not found`},
+		{`{"a":1}`, "x.y", `error: delete "x.y": "x" not found`},
+		{`{"a":1}`, "a.b", `error: delete "a.b": cannot delete "b" on number`},
+		{`{"a":1}`, "", `error: delete: empty path`},
+		{`{"a":1}`, "..a", `error: delete "..a": cannot use * or .. in path`},
+	}
+	for _, tt := range tests {
+		v := mustParse(t, tt.doc)
+		err := v.Delete(tt.path)
+		if msg, ok := strings.CutPrefix(tt.want, "error: "); ok {
+			if err == nil || err.Error() != msg {
+				t.Errorf("%s delete %s: got error %v, want %s", tt.doc, tt.path, err, msg)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s delete %s: %v", tt.doc, tt.path, err)
+			continue
+		}
+		if got := mustEncode(t, v); got != tt.want {
+			t.Errorf("%s delete %s: got %s, want %s", tt.doc, tt.path, got, tt.want)
+		}
+	}
+
+	// 删除后 Keys 的顺序同步更新, 重新写入的键排在最后
+	v := mustParse(t, `{"a":1,"b":2}`)
+	if err := v.Delete("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Set("a", NewInt(3)); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustEncode(t, v); got != `{"b":2,"a":3}` {
+		t.Errorf("got %s", got)
+	}
+}
+
+// 编译后的路径与直接使用路径字符串的各个函数结果相同
+func TestCompilePath(t *testing.T) {
+	paths := []string{
+		"", "users[1].address.city", "$.users[0].name", `["a.b"]`, `a\.b`, "a.b", `\*`,
+		`["q\"k"]`, "users[2]", "users.x", "users[*].name", "..city", "users..tags[0]",
+	}
+	for _, expr := range paths {
+		p, err := CompilePath(expr)
+		if err != nil {
+			t.Fatalf("%s: %v", expr, err)
+		}
+		if p.String() != expr {
+			t.Errorf("String: got %q, want %q", p.String(), expr)
+		}
+		v := mustParse(t, pathDoc)
+		if got, want := p.Get(v), v.Get(expr); got.Exists() != want.Exists() || (got.Exists() && got != want) {
+			t.Errorf("%s: Get returned a different node", expr)
+		}
+		got, want := p.GetAll(v), v.GetAll(expr)
+		if len(got) != len(want) {
+			t.Errorf("%s: GetAll got %d values, want %d", expr, len(got), len(want))
+		}
+		for i := range got {
+			if i < len(want) && got[i] != want[i] {
+				t.Errorf("%s: GetAll[%d] differs", expr, i)
+			}
+		}
+		if res, want := p.GetBytes([]byte(pathDoc)), GetBytes([]byte(pathDoc), expr); string(res.Raw) != string(want.Raw) || res.Index != want.Index {
+			t.Errorf("%s: GetBytes got %+v, want %+v", expr, res, want)
+		}
+
+		out, err := p.SetBytes([]byte(pathDoc), 7)
+		want2, err2 := SetBytes([]byte(pathDoc), expr, 7)
+		if string(out) != string(want2) || (err == nil) != (err2 == nil) {
+			t.Errorf("%s: SetBytes got %s %v, want %s %v", expr, out, err, want2, err2)
+		}
+		err, err2 = p.Set(mustParse(t, pathDoc), NewInt(7)), mustParse(t, pathDoc).Set(expr, NewInt(7))
+		if (err == nil) != (err2 == nil) {
+			t.Errorf("%s: Set got %v, want %v", expr, err, err2)
+		}
+		err, err2 = p.Delete(mustParse(t, pathDoc)), mustParse(t, pathDoc).Delete(expr)
+		if (err == nil) != (err2 == nil) || (err != nil && err.Error() != err2.Error()) {
+			t.Errorf("%s: Delete got %v, want %v", expr, err, err2)
+		}
+	}
+
+	if _, err := CompilePath("a["); err == nil {
+		t.Errorf("CompilePath: expected an error")
+	}
+}
+
+func TestCompilePathConcurrent(t *testing.T) {
+	p := MustCompilePath("users[1].tags[1]")
+	v := mustParse(t, pathDoc)
+	data := []byte(pathDoc)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if s, _ := p.Get(v).String(); s != "t2" {
+					t.Errorf("Get: got %q", s)
+					return
+				}
+				if res := p.GetBytes(data); string(res.Raw) != `"t2"` {
+					t.Errorf("GetBytes: got %s", res.Raw)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	allocs := testing.AllocsPerRun(100, func() { p.Get(v) })
+	if allocs != 0 {
+		t.Errorf("Get allocated %v times", allocs)
+	}
+}
+
+func TestGetAll(t *testing.T) {
+	const store = `{"store":{"book":[{"title":"a","price":8},{"title":"b","price":12,"isbn":"x"}],"bicycle":{"price":20,"color":"red"}},"items":[{"id":1},{"id":2},{"name":"no id"}],"*":"star"}`
+	v := mustParse(t, store)
+	tests := []struct {
+		path string
+		want string // 匹配值编码后以空格连接
+	}{
+		{"$..price", `8 12 20`},
+		{"..price", `8 12 20`},
+		{"items.*.id", `1 2`},
+		{"items[*].id", `1 2`},
+		{"store.book[*].title", `"a" "b"`},
+		{"store.*", `[{"title":"a","price":8},{"title":"b","price":12,"isbn":"x"}] {"price":20,"color":"red"}`},
+		{"store.bicycle.*", `20 "red"`},
+		{"store..title", `"a" "b"`},
+		{"..book[1].isbn", `"x"`},
+		{"..[0]", `{"title":"a","price":8} {"id":1}`},
+		{"..isbn", `"x"`},
+		{"$.*.book..price", `8 12`},
+		// 没有通配符时最多一个匹配
+		{"store.bicycle.color", `"red"`},
+		{`\*`, `"star"`},
+		{`["*"]`, `"star"`},
+		{"*.*.price", `20`},
+		{"..nope", ``},
+		{"items.*.id.x", ``},
+		{"a..", ``},
+		{"store..", ``},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, m := range v.GetAll(tt.path) {
+			got = append(got, mustEncode(t, m))
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("%s:\ngot  %s\nwant %s", tt.path, strings.Join(got, " "), tt.want)
+		}
+
+		// Get 返回第一个匹配
+		first := v.Get(tt.path)
+		if tt.want == "" {
+			if first.Exists() {
+				t.Errorf("Get(%s): got %s, want missing", tt.path, mustEncode(t, first))
+			}
+		} else if mustEncode(t, first) != got[0] {
+			t.Errorf("Get(%s): got %s, want %s", tt.path, mustEncode(t, first), got[0])
+		}
+	}
+
+	// ..key 在匹配的值内部继续查找
+	nested := mustParse(t, `{"a":{"a":{"a":1}},"b":[{"a":2}]}`)
+	var got []string
+	for _, m := range nested.GetAll("..a") {
+		got = append(got, mustEncode(t, m))
+	}
+	if want := `{"a":{"a":1}} {"a":1} 1 2`; strings.Join(got, " ") != want {
+		t.Errorf("nested ..a: got %s, want %s", strings.Join(got, " "), want)
+	}
+}
+
+func TestMultiMatchPathsAreReadOnly(t *testing.T) {
+	for _, path := range []string{"a.*", "..a", "a[*]", "$..b"} {
+		v := mustParse(t, `{"a":{"b":1}}`)
+		if err := v.Set(path, NewInt(2)); err == nil || !strings.Contains(err.Error(), "cannot use * or .. in path") {
+			t.Errorf("Set(%s): got %v", path, err)
+		}
+		if err := v.Delete(path); err == nil || !strings.Contains(err.Error(), "cannot use * or .. in path") {
+			t.Errorf("Delete(%s): got %v", path, err)
+		}
+		if got := mustEncode(t, v); got != `{"a":{"b":1}}` {
+			t.Errorf("%s: value changed to %s", path, got)
+		}
+	}
+}
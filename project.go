@@ -0,0 +1,83 @@
+package yjson
+
+// 按 Get 的路径语法从 v 中挑出 paths 列出的值, 组成保留原有嵌套结构的新文档,
+// 如 Project(v, "id", "user.name") 得到 {"id":..,"user":{"name":..}}.
+// 对象成员保持 v 中的顺序, 数组只保留选中的元素并按原顺序排列.
+// 不存在或非法的路径被忽略, 都不存在时返回与 v 同类型的空容器.
+// 结果与 v 共享选中的子树, 修改其中一个会影响另一个
+func Project(v *Value, paths ...string) *Value {
+	selected := make([][]pathSegment, 0, len(paths))
+	for _, path := range paths {
+		segments, err := parsePath(path)
+		if err == nil {
+			selected = append(selected, segments)
+		}
+	}
+	if out := project(v, selected); out != nil {
+		return out
+	}
+	if v.Type() == JSON_ARRAY {
+		return NewArray()
+	}
+	return NewObject()
+}
+
+// 没有选中任何值时返回 nil
+func project(v *Value, paths [][]pathSegment) *Value {
+	if len(paths) == 0 || v.load() != nil {
+		return nil
+	}
+	for _, segments := range paths {
+		if len(segments) == 0 {
+			return v
+		}
+	}
+
+	switch v.Type() {
+	case JSON_OBJECT:
+		var out *Value
+		for _, key := range v.keys {
+			var sub [][]pathSegment
+			for _, segments := range paths {
+				if segments[0].matchKey(key) {
+					sub = append(sub, segments[1:])
+				}
+				if segments[0].recursive {
+					sub = append(sub, segments)
+				}
+			}
+			child := project(v.obj[key], sub)
+			if child == nil {
+				continue
+			}
+			if out == nil {
+				out = NewObject()
+			}
+			out.setMember(key, child)
+		}
+		return out
+	case JSON_ARRAY:
+		var out *Value
+		for i, elem := range v.arr {
+			var sub [][]pathSegment
+			for _, segments := range paths {
+				if segments[0].matchIndex(i) {
+					sub = append(sub, segments[1:])
+				}
+				if segments[0].recursive {
+					sub = append(sub, segments)
+				}
+			}
+			child := project(elem, sub)
+			if child == nil {
+				continue
+			}
+			if out == nil {
+				out = NewArray()
+			}
+			out.arr = append(out.arr, child)
+		}
+		return out
+	}
+	return nil
+}
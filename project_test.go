@@ -0,0 +1,59 @@
+package yjson
+
+import "testing"
+
+func TestProject(t *testing.T) {
+	const doc = `{"id":1,"user":{"name":"a","email":"e","roles":["x","y","z"]},"items":[{"sku":"s1","qty":2},{"sku":"s2","qty":3}],"meta":null}`
+	tests := []struct {
+		input string
+		paths []string
+		want  string
+	}{
+		{doc, []string{"id", "user.name"}, `{"id":1,"user":{"name":"a"}}`},
+		// 保持 v 中的顺序, 与 paths 的顺序无关
+		{doc, []string{"user.name", "id"}, `{"id":1,"user":{"name":"a"}}`},
+		{doc, []string{"user", "user.name"}, `{"user":{"name":"a","email":"e","roles":["x","y","z"]}}`},
+		{doc, []string{"user.roles[2]", "user.roles[0]"}, `{"user":{"roles":["x","z"]}}`},
+		{doc, []string{"items[*].sku"}, `{"items":[{"sku":"s1"},{"sku":"s2"}]}`},
+		{doc, []string{"items.1.qty"}, `{"items":[{"qty":3}]}`},
+		{doc, []string{"..qty"}, `{"items":[{"qty":2},{"qty":3}]}`},
+		{doc, []string{"meta"}, `{"meta":null}`},
+		{doc, []string{"*"}, doc},
+		{doc, []string{""}, doc},
+		// 不存在和非法的路径被忽略
+		{doc, []string{"id", "nope", "user.name.x", "items[9]", "a["}, `{"id":1}`},
+		{doc, []string{"nope"}, `{}`},
+		{doc, nil, `{}`},
+		{`[{"a":1,"b":2},3]`, []string{"[0].b"}, `[{"b":2}]`},
+		{`[{"a":1,"b":2},3]`, []string{"x"}, `[]`},
+		{`5`, []string{"a"}, `{}`},
+	}
+	for _, tt := range tests {
+		got := Project(mustParse(t, tt.input), tt.paths...)
+		if s := mustEncode(t, got); s != tt.want {
+			t.Errorf("%q:\ngot  %s\nwant %s", tt.paths, s, tt.want)
+		}
+	}
+}
+
+func TestProjectSharesSubtrees(t *testing.T) {
+	v := mustParse(t, `{"a":{"b":1},"c":2}`)
+	out := Project(v, "a")
+	if out.Get("a") != v.Get("a") {
+		t.Errorf("selected subtree should be shared")
+	}
+	if err := out.Set("d", NewInt(3)); err != nil {
+		t.Fatal(err)
+	}
+	if v.Get("d").Exists() {
+		t.Errorf("adding to the result changed the input")
+	}
+
+	lazy, err := ParseWithOptions([]byte(`{"a":{"b":[1,2]},"c":2}`), ParseOptions{Lazy: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mustEncode(t, Project(lazy, "a.b[1]")); got != `{"a":{"b":[2]}}` {
+		t.Errorf("lazy: got %s", got)
+	}
+}
@@ -0,0 +1,81 @@
+package yjson
+
+import (
+	"errors"
+	"io"
+)
+
+var errNeedMore = errors.New("need more input")
+
+// 数据由 Write 推入, 没有更多数据时告诉 Decoder 等待, Close 之后才是输入结束
+type pushReader struct {
+	closed bool
+}
+
+func (r *pushReader) Read([]byte) (int, error) {
+	if r.closed {
+		return 0, io.EOF
+	}
+	return 0, errNeedMore
+}
+
+// 推送式解析: 数据分块写入, 每个文档闭合时立即回调, 适合来自 socket 或 SSE 的数据.
+// 多个文档可以首尾相连或以空白分隔, 同 Decoder
+//
+//	p := yjson.NewPushParser(func(v *yjson.Value) error { ...; return nil })
+//	p.Write(chunk1)
+//	p.Write(chunk2)
+//	err := p.Close()
+type PushParser struct {
+	r   *pushReader
+	dec *Decoder
+	fn  func(v *Value) error
+	err error
+}
+
+func NewPushParser(fn func(v *Value) error) *PushParser {
+	r := &pushReader{}
+	return &PushParser{r: r, dec: NewDecoder(r), fn: fn}
+}
+
+func (p *PushParser) SetOptions(opts ParseOptions) {
+	p.dec.SetOptions(opts)
+}
+
+// 追加数据并回调其中已经完整的文档. 解析或回调出错后, 之后的 Write 都返回该错误
+func (p *PushParser) Write(chunk []byte) (int, error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+	if p.r.closed {
+		return 0, errors.New("push parser: write after close")
+	}
+	p.dec.buf = append(p.dec.buf, chunk...)
+	return len(chunk), p.drain()
+}
+
+// 输入结束, 回调最后一个文档 (如末尾没有分隔符的数字). 文档不完整时返回 io.ErrUnexpectedEOF
+func (p *PushParser) Close() error {
+	if p.err != nil || p.r.closed {
+		return p.err
+	}
+	p.r.closed = true
+	return p.drain()
+}
+
+func (p *PushParser) drain() error {
+	for {
+		v := &Value{}
+		err := p.dec.Decode(v)
+		if err == errNeedMore || err == io.EOF {
+			return nil
+		}
+		if err == nil {
+			err = p.fn(v)
+		}
+		if err != nil {
+			p.err = err
+			return err
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package yjson
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// 按 size 字节分块写入 input, 返回回调收到的文档和 Close 的结果
+func pushAll(t *testing.T, input string, size int, opts ParseOptions) ([]string, error) {
+	var got []string
+	p := NewPushParser(func(v *Value) error {
+		got = append(got, mustEncode(t, v))
+		return nil
+	})
+	p.SetOptions(opts)
+	for i := 0; i < len(input); i += size {
+		end := i + size
+		if end > len(input) {
+			end = len(input)
+		}
+		if n, err := p.Write([]byte(input[i:end])); err != nil || n != end-i {
+			return got, err
+		}
+	}
+	return got, p.Close()
+}
+
+func TestPushParser(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  ParseOptions
+		want  string
+	}{
+		{"single", `{"a": [1, 2]}`, ParseOptions{}, `{"a":[1,2]}`},
+		{"concatenated", `{"a":1}[2]"s"`, ParseOptions{}, `{"a":1} [2] "s"`},
+		{"trailing number", `[1] 12 345`, ParseOptions{}, `[1] 12 345`},
+		{"literals", `true false null`, ParseOptions{}, `true false null`},
+		{"escapes", `"a\"b" {"}":"{"}`, ParseOptions{}, `"a\"b" {"}":"{"}`},
+		{"empty", ``, ParseOptions{}, ``},
+		{"whitespace", " \n\t ", ParseOptions{}, ``},
+		{"comments", "/* a */ 1 // b\n 2", ParseOptions{AllowComments: true}, `1 2`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, size := range []int{1, 2, 3, 7, len(tt.input) + 1} {
+				got, err := pushAll(t, tt.input, size, tt.opts)
+				if err != nil {
+					t.Fatalf("chunk %d: %v", size, err)
+				}
+				if strings.Join(got, " ") != tt.want {
+					t.Errorf("chunk %d: got %q, want %s", size, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// 每个文档在最后一个字节写入时就回调, 不需要等到 Close
+func TestPushParserCallsEarly(t *testing.T) {
+	n := 0
+	p := NewPushParser(func(v *Value) error {
+		n++
+		return nil
+	})
+	p.Write([]byte(`{"a":`))
+	if n != 0 {
+		t.Fatalf("called before the document ended")
+	}
+	p.Write([]byte(`1}`))
+	if n != 1 {
+		t.Errorf("object: got %d callbacks", n)
+	}
+	// 数字只有在看到分隔符或 Close 时才知道结束了
+	p.Write([]byte(` 42`))
+	if n != 1 {
+		t.Errorf("number: got %d callbacks before the separator", n)
+	}
+	p.Write([]byte(` `))
+	if n != 2 {
+		t.Errorf("number: got %d callbacks", n)
+	}
+	if err := p.Close(); err != nil || n != 2 {
+		t.Errorf("close: got %d callbacks, %v", n, err)
+	}
+}
+
+func TestPushParserErrors(t *testing.T) {
+	if _, err := pushAll(t, `{"a": [1`, 3, ParseOptions{}); err != io.ErrUnexpectedEOF {
+		t.Errorf("incomplete document: got %v", err)
+	}
+	if _, err := pushAll(t, `{"a" 1}`, 1, ParseOptions{}); err == nil {
+		t.Errorf("syntax error: expected an error")
+	}
+
+	// 回调的错误被保留, 之后的 Write 和 Close 都返回它
+	fail := errors.New("fail")
+	calls := 0
+	p := NewPushParser(func(v *Value) error {
+		calls++
+		return fail
+	})
+	if _, err := p.Write([]byte(`[1] [2]`)); err != fail {
+		t.Errorf("write: got %v", err)
+	}
+	if _, err := p.Write([]byte(`[3]`)); err != fail {
+		t.Errorf("second write: got %v", err)
+	}
+	if err := p.Close(); err != fail || calls != 1 {
+		t.Errorf("close: got %v after %d calls", err, calls)
+	}
+
+	p = NewPushParser(func(v *Value) error { return nil })
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Close(); err != nil {
+		t.Errorf("second close: got %v", err)
+	}
+	if _, err := p.Write([]byte(`1`)); err == nil || err.Error() != "push parser: write after close" {
+		t.Errorf("write after close: got %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package yjson
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// 记录接口类型的实现, 解码到接口字段时按对象中判别字段的值选择具体类型:
+//
+//	types := yjson.NewTypeRegistry()
+//	types.Register((*Shape)(nil), "type", "circle", Circle{})
+//	types.Register((*Shape)(nil), "type", "rect", &Rect{})
+//
+// 之后 {"type":"circle","r":1} 解码到 Shape 字段时得到 Circle 值.
+// 编码时如果对象中没有判别字段会自动加在最前面
+type TypeRegistry struct {
+	mu     sync.RWMutex
+	unions map[reflect.Type]*union
+}
+
+type union struct {
+	field string
+	types map[string]reflect.Type // 判别值 -> 具体类型
+	names map[reflect.Type]string
+}
+
+// UnmarshalOptions.Types 和 MarshalOptions.Types 为 nil 时使用
+var DefaultTypes = NewTypeRegistry()
+
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{unions: make(map[reflect.Type]*union)}
+}
+
+// iface 是接口类型的 nil 指针, 如 (*Shape)(nil). v 是具体类型的值,
+// 传入指针时解码得到的也是指针. 同一接口必须使用相同的判别字段
+func (r *TypeRegistry) Register(iface interface{}, field, name string, v interface{}) error {
+	it := reflect.TypeOf(iface)
+	if it == nil || it.Kind() != reflect.Pointer || it.Elem().Kind() != reflect.Interface {
+		return fmt.Errorf("register: expect pointer to interface, but get %T", iface)
+	}
+	it = it.Elem()
+	t := reflect.TypeOf(v)
+	if t == nil || !t.Implements(it) {
+		return fmt.Errorf("register: %T does not implement %s", v, it)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.unions[it]
+	if !ok {
+		u = &union{field: field, types: make(map[string]reflect.Type), names: make(map[reflect.Type]string)}
+		r.unions[it] = u
+	}
+	if u.field != field {
+		return fmt.Errorf("register: %s already uses discriminator field %q", it, u.field)
+	}
+	if old, ok := u.types[name]; ok && old != t {
+		return fmt.Errorf("register: %s %q already registered as %s", it, name, old)
+	}
+	u.types[name] = t
+	u.names[t] = name
+	return nil
+}
+
+// 同 Register, 出错时 panic, 用于包初始化
+func (r *TypeRegistry) MustRegister(iface interface{}, field, name string, v interface{}) {
+	if err := r.Register(iface, field, name, v); err != nil {
+		panic(err)
+	}
+}
+
+func (r *TypeRegistry) lookup(it reflect.Type) *union {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.unions[it]
+}
+
+func (d *decodeState) types() *TypeRegistry {
+	if d.opts.Types != nil {
+		return d.opts.Types
+	}
+	return DefaultTypes
+}
+
+func (m *marshalState) types() *TypeRegistry {
+	if m.opts.Types != nil {
+		return m.opts.Types
+	}
+	return DefaultTypes
+}
+
+// 读取判别字段创建对应的具体类型再解码. 具体类型没有判别字段时解码前去掉它,
+// 以免 DisallowUnknownFields 报错
+func (d *decodeState) decodeUnion(path string, j *Value, rv reflect.Value, u *union) error {
+	if j.Type() != JSON_OBJECT {
+		return d.typeError(path, j, rv.Type())
+	}
+	disc := j.obj[u.field]
+	name, err := disc.String()
+	if err != nil {
+		return fmt.Errorf("unmarshal: discriminator %q of %s: %v at %s", u.field, rv.Type(), err, joinKeyPath(path, u.field))
+	}
+	t, ok := u.types[name]
+	if !ok {
+		return fmt.Errorf("unmarshal: unknown %s %q for %s at %s", u.field, name, rv.Type(), joinKeyPath(path, u.field))
+	}
+
+	st := t
+	if st.Kind() == reflect.Pointer {
+		st = st.Elem()
+	}
+	if st.Kind() == reflect.Struct {
+		if _, ok := cachedFields(st, d.opts.Naming).lookup(u.field); !ok {
+			j = withoutMember(j, u.field)
+		}
+	}
+
+	v := reflect.New(st)
+	if err := d.decode(path, j, v.Elem()); err != nil {
+		return err
+	}
+	if t.Kind() == reflect.Pointer {
+		rv.Set(v)
+	} else {
+		rv.Set(v.Elem())
+	}
+	return nil
+}
+
+// 浅拷贝对象并去掉一个成员
+func withoutMember(j *Value, key string) *Value {
+	obj := NewObject()
+	for _, k := range j.keys {
+		if k != key {
+			obj.setMember(k, j.obj[k])
+		}
+	}
+	return obj
+}
+
+// 具体类型已注册且对象中没有判别字段时, 把判别字段加在最前面
+func (m *marshalState) marshalUnion(path string, rv reflect.Value, u *union) (*Value, error) {
+	j, err := m.marshal(path, rv.Elem())
+	if err != nil || j.Type() != JSON_OBJECT {
+		return j, err
+	}
+	name, ok := u.names[rv.Elem().Type()]
+	if !ok {
+		return j, nil
+	}
+	if _, exists := j.obj[u.field]; !exists {
+		j.setMember(u.field, NewString(name))
+		copy(j.keys[1:], j.keys[:len(j.keys)-1])
+		j.keys[0] = u.field
+	}
+	return j, nil
+}
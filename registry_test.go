@@ -0,0 +1,121 @@
+package yjson
+
+import (
+	"reflect"
+	"testing"
+)
+
+type shape interface{ area() float64 }
+
+type circle struct {
+	R float64 `json:"r"`
+}
+
+func (c circle) area() float64 { return 3 * c.R * c.R }
+
+type rect struct {
+	Kind string  `json:"type"` // 具体类型自己带有判别字段
+	W    float64 `json:"w"`
+	H    float64 `json:"h"`
+}
+
+func (r *rect) area() float64 { return r.W * r.H }
+
+type drawing struct {
+	Main   shape   `json:"main"`
+	Shapes []shape `json:"shapes"`
+}
+
+func newShapeTypes(t *testing.T) *TypeRegistry {
+	types := NewTypeRegistry()
+	if err := types.Register((*shape)(nil), "type", "circle", circle{}); err != nil {
+		t.Fatal(err)
+	}
+	types.MustRegister((*shape)(nil), "type", "rect", &rect{})
+	return types
+}
+
+func TestTypeRegistry(t *testing.T) {
+	types := newShapeTypes(t)
+	input := `{"main":{"type":"circle","r":1},"shapes":[{"type":"rect","w":2,"h":3},null,{"r":2,"type":"circle"}]}`
+	var d drawing
+	opts := UnmarshalOptions{Types: types, DisallowUnknownFields: true}
+	if err := UnmarshalWithOptions([]byte(input), &d, opts); err != nil {
+		t.Fatal(err)
+	}
+	want := drawing{Main: circle{1}, Shapes: []shape{&rect{"rect", 2, 3}, nil, circle{2}}}
+	if !reflect.DeepEqual(d, want) {
+		t.Errorf("got %#v, want %#v", d, want)
+	}
+
+	// 编码时补上判别字段, 已有的不重复
+	got, err := MarshalWithOptions(d, MarshalOptions{Types: types})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"main":{"type":"circle","r":1},"shapes":[{"type":"rect","w":2,"h":3},null,{"type":"circle","r":2}]}`; string(got) != want {
+		t.Errorf("marshal: got %s, want %s", got, want)
+	}
+
+	// 没有在 DefaultTypes 中注册, 按普通的非空接口处理
+	var plain struct{ S shape }
+	if err := Unmarshal([]byte(`{"S":{}}`), &plain); err == nil || err.Error() != "unmarshal: cannot unmarshal object into yjson.shape at S" {
+		t.Errorf("default registry: got %v", err)
+	}
+}
+
+func TestTypeRegistryDecodeErrors(t *testing.T) {
+	opts := UnmarshalOptions{Types: newShapeTypes(t)}
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"main":{"type":"hexagon"}}`, `unmarshal: unknown type "hexagon" for yjson.shape at main.type`},
+		{`{"main":{"r":1}}`, `unmarshal: discriminator "type" of yjson.shape: expect string, but get missing at main.type`},
+		{`{"main":{"type":1}}`, `unmarshal: discriminator "type" of yjson.shape: expect string, but get number at main.type`},
+		{`{"main":[1]}`, `unmarshal: cannot unmarshal array into yjson.shape at main`},
+		{`{"shapes":[{"type":"circle","r":"x"}]}`, `unmarshal: cannot unmarshal string into float64 at shapes[0].r`},
+	}
+	for _, tt := range tests {
+		var d drawing
+		err := UnmarshalWithOptions([]byte(tt.input), &d, opts)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: got %v, want %s", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestTypeRegistryRegisterErrors(t *testing.T) {
+	types := newShapeTypes(t)
+	tests := []struct {
+		name  string
+		iface interface{}
+		field string
+		key   string
+		v     interface{}
+		want  string
+	}{
+		{"not an interface pointer", shape(nil), "type", "x", circle{}, "register: expect pointer to interface, but get <nil>"},
+		{"struct pointer", &circle{}, "type", "x", circle{}, "register: expect pointer to interface, but get *yjson.circle"},
+		{"does not implement", (*shape)(nil), "type", "x", rect{}, "register: yjson.rect does not implement yjson.shape"},
+		{"different field", (*shape)(nil), "kind", "x", circle{}, `register: yjson.shape already uses discriminator field "type"`},
+		{"name taken", (*shape)(nil), "type", "circle", &rect{}, `register: yjson.shape "circle" already registered as yjson.circle`},
+	}
+	for _, tt := range tests {
+		err := types.Register(tt.iface, tt.field, tt.key, tt.v)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: got %v, want %s", tt.name, err, tt.want)
+		}
+	}
+	// 重复注册同样的类型不报错
+	if err := types.Register((*shape)(nil), "type", "circle", circle{}); err != nil {
+		t.Errorf("same registration: %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustRegister should panic")
+		}
+	}()
+	types.MustRegister((*shape)(nil), "type", "circle", &rect{})
+}
@@ -0,0 +1,107 @@
+package yjson
+
+import (
+	"errors"
+	"io"
+)
+
+// 回调中返回 ErrStop 可以提前结束解析, 此时 DecodeEvents 返回 nil
+var ErrStop = errors.New("yjson: stop")
+
+// 事件驱动解析的回调, 为 nil 的回调被忽略. 不构建 Value 树, 内存占用只和
+// 嵌套深度及单个标量的大小有关. 任一回调返回错误时停止解析
+type Handler struct {
+	ObjectStart func() error
+	ObjectEnd   func() error
+	ArrayStart  func() error
+	ArrayEnd    func() error
+	Key         func(key string) error
+	Value       func(v *Value) error // 字符串, 数字, 布尔值和 null
+}
+
+// 从 r 中读取一个文档并按事件调用 h, 文档之后只允许空白. 回调返回 ErrStop 时不再检查
+func ParseEvents(r io.Reader, h *Handler) error {
+	return ParseEventsWithOptions(r, h, ParseOptions{})
+}
+
+func ParseEventsWithOptions(r io.Reader, h *Handler, opts ParseOptions) error {
+	dec := NewDecoder(r)
+	dec.SetOptions(opts)
+	if err := dec.decodeEvents(h); err != nil {
+		if err == ErrStop {
+			return nil
+		}
+		return err
+	}
+	if err := dec.skipSpace(); err != io.EOF {
+		if err != nil {
+			return err
+		}
+		return dec.tokenError()
+	}
+	return nil
+}
+
+// 读取下一个完整的值并按事件调用 h, 可以和 Token, Decode 混用
+func (dec *Decoder) DecodeEvents(h *Handler) error {
+	err := dec.decodeEvents(h)
+	if err == ErrStop {
+		return nil
+	}
+	return err
+}
+
+func (dec *Decoder) decodeEvents(h *Handler) error {
+	if err := dec.tokenPrepareForDecode(); err != nil {
+		return err
+	}
+	if !dec.tokenValueAllowed() {
+		return dec.tokenError()
+	}
+
+	depth := 0
+	for {
+		tok, j, err := dec.token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case nil:
+			if h.Value != nil {
+				err = h.Value(j)
+			}
+		case string:
+			if h.Key != nil {
+				err = h.Key(t)
+			}
+		case Delim:
+			switch t {
+			case OB:
+				depth++
+				err = call(h.ObjectStart)
+			case LB:
+				depth++
+				err = call(h.ArrayStart)
+			case CB:
+				depth--
+				err = call(h.ObjectEnd)
+			case RB:
+				depth--
+				err = call(h.ArrayEnd)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+func call(fn func() error) error {
+	if fn == nil {
+		return nil
+	}
+	return fn()
+}
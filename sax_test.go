@@ -0,0 +1,124 @@
+package yjson
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// 把事件记录为空格分隔的字符串
+func recordingHandler(t *testing.T, out *[]string) *Handler {
+	add := func(s string) func() error {
+		return func() error {
+			*out = append(*out, s)
+			return nil
+		}
+	}
+	return &Handler{
+		ObjectStart: add("{"),
+		ObjectEnd:   add("}"),
+		ArrayStart:  add("["),
+		ArrayEnd:    add("]"),
+		Key: func(key string) error {
+			*out = append(*out, "k:"+key)
+			return nil
+		},
+		Value: func(v *Value) error {
+			*out = append(*out, mustEncode(t, v))
+			return nil
+		},
+	}
+}
+
+func TestParseEvents(t *testing.T) {
+	tests := []struct {
+		input string
+		opts  ParseOptions
+		want  string
+	}{
+		{`1`, ParseOptions{}, `1`},
+		{` "s" `, ParseOptions{}, `"s"`},
+		{`[]`, ParseOptions{}, `[ ]`},
+		{`{"a": [1, {"b": null}], "c": "x"}`, ParseOptions{}, `{ k:a [ 1 { k:b null } ] k:c "x" }`},
+		{`[[true], [false, []]]`, ParseOptions{}, `[ [ true ] [ false [ ] ] ]`},
+		{`{"a\nb": 1.50}`, ParseOptions{}, `{ k:a` + "\n" + `b 1.5 }`},
+		{"[1, // c\n 2,]", ParseOptions{AllowComments: true, AllowTrailingCommas: true}, `[ 1 2 ]`},
+		{`{a: 'b'}`, ParseOptions{JSON5: true}, `{ k:a "b" }`},
+		{`[1`, ParseOptions{}, "error: unexpected EOF"},
+		{`[1] 2`, ParseOptions{}, "error: unexpected '2' at offset 4"},
+		{`{"a" 1}`, ParseOptions{}, "error: unexpected '1' at offset 5"},
+		{``, ParseOptions{}, "error: EOF"},
+	}
+	for _, tt := range tests {
+		var got []string
+		err := ParseEventsWithOptions(iotest.OneByteReader(strings.NewReader(tt.input)), recordingHandler(t, &got), tt.opts)
+		if want, ok := strings.CutPrefix(tt.want, "error: "); ok {
+			if err == nil || err.Error() != want {
+				t.Errorf("%q: got error %v, want %s", tt.input, err, want)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%q: %v", tt.input, err)
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.input, strings.Join(got, " "), tt.want)
+		}
+	}
+}
+
+func TestParseEventsStop(t *testing.T) {
+	// 为 nil 的回调被忽略; ErrStop 之后不再检查剩余的输入
+	var keys []string
+	h := &Handler{Key: func(key string) error {
+		keys = append(keys, key)
+		if key == "stop" {
+			return ErrStop
+		}
+		return nil
+	}}
+	if err := ParseEvents(strings.NewReader(`{"a": {"b": 1}, "stop": 2, "c": 3 garbage`), h); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(keys, " ") != "a b stop" {
+		t.Errorf("got %v", keys)
+	}
+
+	// 其他错误原样返回
+	fail := errors.New("fail")
+	h = &Handler{ArrayEnd: func() error { return fail }}
+	if err := ParseEvents(strings.NewReader(`[[1]]`), h); err != fail {
+		t.Errorf("got %v", err)
+	}
+}
+
+// DecodeEvents 与 Token 混用, 对数组的每个元素单独处理
+func TestDecodeEvents(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(`[{"a": 1}, [2], 3] {"b": 4}`))
+	if tok, err := dec.Token(); err != nil || tok != Delim('[') {
+		t.Fatalf("got %v, %v", tok, err)
+	}
+	var got []string
+	h := recordingHandler(t, &got)
+	for dec.More() {
+		if err := dec.DecodeEvents(h); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, "|")
+	}
+	if tok, err := dec.Token(); err != nil || tok != Delim(']') {
+		t.Fatalf("got %v, %v", tok, err)
+	}
+	if err := dec.DecodeEvents(h); err != nil {
+		t.Fatal(err)
+	}
+	want := `{ k:a 1 } | [ 2 ] | 3 | { k:b 4 }`
+	if strings.Join(got, " ") != want {
+		t.Errorf("got %s, want %s", strings.Join(got, " "), want)
+	}
+	if err := dec.DecodeEvents(h); err != io.EOF {
+		t.Errorf("end of input: got %v", err)
+	}
+}
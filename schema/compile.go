@@ -0,0 +1,526 @@
+package schema
+
+import (
+	"fmt"
+	"math/big"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Yohox/yjson"
+)
+
+// 编译后的 schema 节点, 未设置的整数约束为 -1, 未设置的子 schema 为 nil
+type node struct {
+	loc    string // 绝对位置 资源 URI#JSON Pointer, 用于 Violation.KeywordLocation
+	base   string // 所在资源的 URI
+	always *bool  // 布尔 schema
+
+	refURI, refFrag   string
+	ref               *node
+	dynRefURI         string
+	dynRefFrag        string
+	dynRef            *node
+	dynamicAnchor     string
+	hasRef, hasDynRef bool
+
+	types    []string
+	enum     []*yjson.Value
+	hasEnum  bool
+	constVal *yjson.Value
+
+	multipleOf, maximum, exclusiveMaximum, minimum, exclusiveMinimum *big.Rat
+
+	minLength, maxLength         int
+	minItems, maxItems           int
+	minContains, maxContains     int
+	minProperties, maxProperties int
+
+	pattern     *regexp.Regexp
+	format      string
+	uniqueItems bool
+
+	required          []string
+	dependentRequired map[string][]string
+	dependentKeys     []string
+
+	allOf, anyOf, oneOf             []*node
+	not, ifNode, thenNode, elseNode *node
+	dependentSchemas                map[string]*node
+	dependentSchemaKeys             []string
+
+	prefixItems    []*node
+	items          *node
+	contains       *node
+	properties     map[string]*node
+	propertyKeys   []string
+	patternProps   []patternSchema
+	additionalProp *node
+	propertyNames  *node
+
+	unevaluatedItems *node
+	unevaluatedProps *node
+}
+
+// 编译失败的关键字, loc 为关键字的绝对位置
+type schemaError struct {
+	loc string
+	err error
+}
+
+func (e *schemaError) Error() string {
+	return fmt.Sprintf("invalid schema at %s: %v", e.loc, e.err)
+}
+
+// 子 schema 的错误已经带有位置, 不再包装
+func wrapSchemaError(loc string, err error) error {
+	if _, ok := err.(*schemaError); ok {
+		return err
+	}
+	return &schemaError{loc: loc, err: err}
+}
+
+type patternSchema struct {
+	re   *regexp.Regexp
+	node *node
+}
+
+type compiler struct {
+	opts Options
+
+	nodes          map[string]*node        // 资源 URI#JSON Pointer
+	anchors        map[string]*node        // 资源 URI#名称, 包括 $dynamicAnchor
+	dynamicAnchors map[string]*node        // 资源 URI#名称
+	resources      map[string]*yjson.Value // 资源 URI -> 资源的根
+	external       map[string]*yjson.Value // Options.Resources, 键去掉了片段
+	pending        []*node                 // 还没有解析 $ref 的节点
+}
+
+func newCompiler(opts Options) *compiler {
+	c := &compiler{
+		opts:           opts,
+		nodes:          make(map[string]*node),
+		anchors:        make(map[string]*node),
+		dynamicAnchors: make(map[string]*node),
+		resources:      make(map[string]*yjson.Value),
+		external:       make(map[string]*yjson.Value),
+	}
+	for uri, doc := range opts.Resources {
+		c.external[stripFragment(uri)] = doc
+	}
+	return c
+}
+
+func stripFragment(uri string) string {
+	if i := strings.IndexByte(uri, '#'); i >= 0 {
+		return uri[:i]
+	}
+	return uri
+}
+
+// 按 base 解析 ref, 返回去掉片段的 URI 和解码后的片段
+func resolveURI(base, ref string) (string, string, error) {
+	bu, err := url.Parse(base)
+	if err != nil {
+		return "", "", err
+	}
+	ru, err := url.Parse(ref)
+	if err != nil {
+		return "", "", err
+	}
+	u := bu.ResolveReference(ru)
+	frag := u.Fragment
+	u.Fragment, u.RawFragment = "", ""
+	return u.String(), frag, nil
+}
+
+func (c *compiler) compileRoot(doc *yjson.Value) (*node, error) {
+	c.resources[""] = doc
+	root, err := c.compile(doc, "", "")
+	if err != nil {
+		return nil, err
+	}
+	// 解析引用时可能编译新的节点, 新节点的引用也加入 pending
+	for len(c.pending) > 0 {
+		n := c.pending[len(c.pending)-1]
+		c.pending = c.pending[:len(c.pending)-1]
+		if n.hasRef {
+			if n.ref, err = c.resolve(n.refURI, n.refFrag); err != nil {
+				return nil, wrapSchemaError(n.loc+"/$ref", err)
+			}
+		}
+		if n.hasDynRef {
+			if n.dynRef, err = c.resolve(n.dynRefURI, n.dynRefFrag); err != nil {
+				return nil, wrapSchemaError(n.loc+"/$dynamicRef", err)
+			}
+		}
+	}
+	return root, nil
+}
+
+func (c *compiler) resolve(uri, frag string) (*node, error) {
+	key := uri + "#" + frag
+	if n, ok := c.nodes[key]; ok {
+		return n, nil
+	}
+	if n, ok := c.anchors[key]; ok {
+		return n, nil
+	}
+
+	root, ok := c.resources[uri]
+	if !ok {
+		ext, found := c.external[uri]
+		if !found {
+			return nil, fmt.Errorf("%s not found, provide it in Options.Resources", key)
+		}
+		c.resources[uri] = ext
+		if _, err := c.compile(ext, uri, ""); err != nil {
+			return nil, err
+		}
+		root = ext
+		if n, ok := c.nodes[key]; ok {
+			return n, nil
+		}
+		if n, ok := c.anchors[key]; ok {
+			return n, nil
+		}
+	}
+	if frag != "" && frag[0] != '/' {
+		return nil, fmt.Errorf("anchor %s not found", key)
+	}
+
+	// 指向没有按 schema 遍历过的位置, 按 JSON Pointer 找到后编译
+	target := root
+	if frag != "" {
+		for _, tok := range strings.Split(frag[1:], "/") {
+			tok = unescapePointer(tok)
+			if target.Type() == yjson.JSON_ARRAY {
+				items, _ := target.Array()
+				i, err := strconv.Atoi(tok)
+				if err != nil || i < 0 || i >= len(items) {
+					return nil, fmt.Errorf("%s not found", key)
+				}
+				target = items[i]
+				continue
+			}
+			members, err := target.Map()
+			if err != nil {
+				return nil, fmt.Errorf("%s not found", key)
+			}
+			next, ok := members[tok]
+			if !ok {
+				return nil, fmt.Errorf("%s not found", key)
+			}
+			target = next
+		}
+	}
+	return c.compile(target, uri, frag)
+}
+
+func (c *compiler) compile(v *yjson.Value, base, ptr string) (*node, error) {
+	if n, ok := c.nodes[base+"#"+ptr]; ok {
+		return n, nil
+	}
+	n := &node{
+		loc: base + "#" + ptr, base: base,
+		minLength: -1, maxLength: -1, minItems: -1, maxItems: -1,
+		minContains: -1, maxContains: -1, minProperties: -1, maxProperties: -1,
+	}
+	c.nodes[n.loc] = n
+
+	switch v.Type() {
+	case yjson.JSON_BOOLEAN:
+		b, _ := v.Bool()
+		n.always = &b
+		return n, nil
+	case yjson.JSON_OBJECT:
+	default:
+		return nil, &schemaError{loc: n.loc, err: fmt.Errorf("expect object or boolean, but get %s", v.Type())}
+	}
+
+	members, err := v.Map()
+	if err != nil {
+		return nil, err
+	}
+	if id, ok := members["$id"]; ok {
+		s, err := id.String()
+		if err != nil {
+			return nil, &schemaError{loc: n.loc + "/$id", err: err}
+		}
+		uri, _, err := resolveURI(base, s)
+		if err != nil {
+			return nil, &schemaError{loc: n.loc + "/$id", err: err}
+		}
+		// 子资源中的 JSON Pointer 从资源的根开始
+		base, ptr = uri, ""
+		n.base, n.loc = uri, uri+"#"
+		c.nodes[n.loc] = n
+		c.resources[uri] = v
+	}
+
+	kc := &keywordCompiler{c: c, n: n, base: base, ptr: ptr}
+	for _, key := range v.Keys() {
+		if err := kc.keyword(key, members[key]); err != nil {
+			return nil, wrapSchemaError(n.loc+"/"+key, err)
+		}
+	}
+	return n, nil
+}
+
+// 编译一个 schema 对象的各个关键字
+type keywordCompiler struct {
+	c    *compiler
+	n    *node
+	base string
+	ptr  string
+}
+
+func (kc *keywordCompiler) sub(v *yjson.Value, path ...string) (*node, error) {
+	ptr := kc.ptr
+	for _, p := range path {
+		ptr += "/" + escapePointer(p)
+	}
+	return kc.c.compile(v, kc.base, ptr)
+}
+
+func (kc *keywordCompiler) subArray(v *yjson.Value, kw string) ([]*node, error) {
+	items, err := v.Array()
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 && kw != "prefixItems" {
+		return nil, fmt.Errorf("expect non-empty array")
+	}
+	nodes := make([]*node, len(items))
+	for i, item := range items {
+		if nodes[i], err = kc.sub(item, kw, fmt.Sprint(i)); err != nil {
+			return nil, err
+		}
+	}
+	return nodes, nil
+}
+
+// 对象的每个成员都是 schema, 返回按键顺序排列的键
+func (kc *keywordCompiler) subMap(v *yjson.Value, kw string) (map[string]*node, []string, error) {
+	members, err := v.Map()
+	if err != nil {
+		return nil, nil, err
+	}
+	keys := v.Keys()
+	nodes := make(map[string]*node, len(keys))
+	for _, key := range keys {
+		if nodes[key], err = kc.sub(members[key], kw, key); err != nil {
+			return nil, nil, err
+		}
+	}
+	return nodes, keys, nil
+}
+
+func (kc *keywordCompiler) keyword(kw string, v *yjson.Value) error {
+	n, c := kc.n, kc.c
+	var err error
+	switch kw {
+	case "$ref":
+		s, err := v.String()
+		if err != nil {
+			return err
+		}
+		if n.refURI, n.refFrag, err = resolveURI(kc.base, s); err != nil {
+			return err
+		}
+		n.hasRef = true
+		c.pending = append(c.pending, n)
+	case "$dynamicRef":
+		s, err := v.String()
+		if err != nil {
+			return err
+		}
+		if n.dynRefURI, n.dynRefFrag, err = resolveURI(kc.base, s); err != nil {
+			return err
+		}
+		n.hasDynRef = true
+		c.pending = append(c.pending, n)
+	case "$anchor", "$dynamicAnchor":
+		s, err := v.String()
+		if err != nil {
+			return err
+		}
+		c.anchors[kc.base+"#"+s] = n
+		if kw == "$dynamicAnchor" {
+			c.dynamicAnchors[kc.base+"#"+s] = n
+			n.dynamicAnchor = s
+		}
+	case "$defs", "definitions":
+		_, _, err = kc.subMap(v, kw)
+
+	case "type":
+		if v.Type() == yjson.JSON_STRING {
+			s, _ := v.String()
+			n.types = []string{s}
+		} else if n.types, err = stringList(v); err != nil {
+			return err
+		}
+		for _, t := range n.types {
+			switch t {
+			case "null", "boolean", "object", "array", "number", "string", "integer":
+			default:
+				return fmt.Errorf("unknown type %q", t)
+			}
+		}
+	case "enum":
+		n.enum, err = v.Array()
+		n.hasEnum = true
+	case "const":
+		n.constVal = v
+
+	case "multipleOf":
+		if n.multipleOf, err = ratOf(v); err == nil && n.multipleOf.Sign() <= 0 {
+			err = fmt.Errorf("expect positive number")
+		}
+	case "maximum":
+		n.maximum, err = ratOf(v)
+	case "exclusiveMaximum":
+		n.exclusiveMaximum, err = ratOf(v)
+	case "minimum":
+		n.minimum, err = ratOf(v)
+	case "exclusiveMinimum":
+		n.exclusiveMinimum, err = ratOf(v)
+
+	case "minLength":
+		n.minLength, err = nonNegInt(v)
+	case "maxLength":
+		n.maxLength, err = nonNegInt(v)
+	case "pattern":
+		s, err := v.String()
+		if err != nil {
+			return err
+		}
+		if n.pattern, err = regexp.Compile(s); err != nil {
+			return err
+		}
+	case "format":
+		n.format, err = v.String()
+
+	case "minItems":
+		n.minItems, err = nonNegInt(v)
+	case "maxItems":
+		n.maxItems, err = nonNegInt(v)
+	case "minContains":
+		n.minContains, err = nonNegInt(v)
+	case "maxContains":
+		n.maxContains, err = nonNegInt(v)
+	case "uniqueItems":
+		n.uniqueItems, err = v.Bool()
+	case "prefixItems":
+		n.prefixItems, err = kc.subArray(v, kw)
+	case "items":
+		n.items, err = kc.sub(v, kw)
+	case "contains":
+		n.contains, err = kc.sub(v, kw)
+	case "unevaluatedItems":
+		n.unevaluatedItems, err = kc.sub(v, kw)
+
+	case "minProperties":
+		n.minProperties, err = nonNegInt(v)
+	case "maxProperties":
+		n.maxProperties, err = nonNegInt(v)
+	case "required":
+		n.required, err = stringList(v)
+	case "dependentRequired":
+		deps, err := v.Map()
+		if err != nil {
+			return err
+		}
+		n.dependentRequired = make(map[string][]string, len(deps))
+		n.dependentKeys = v.Keys()
+		for key, d := range deps {
+			if n.dependentRequired[key], err = stringList(d); err != nil {
+				return err
+			}
+		}
+	case "properties":
+		n.properties, n.propertyKeys, err = kc.subMap(v, kw)
+	case "patternProperties":
+		nodes, keys, err := kc.subMap(v, kw)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			re, err := regexp.Compile(key)
+			if err != nil {
+				return err
+			}
+			n.patternProps = append(n.patternProps, patternSchema{re: re, node: nodes[key]})
+		}
+	case "additionalProperties":
+		n.additionalProp, err = kc.sub(v, kw)
+	case "propertyNames":
+		n.propertyNames, err = kc.sub(v, kw)
+	case "dependentSchemas":
+		n.dependentSchemas, n.dependentSchemaKeys, err = kc.subMap(v, kw)
+	case "unevaluatedProperties":
+		n.unevaluatedProps, err = kc.sub(v, kw)
+
+	case "allOf":
+		n.allOf, err = kc.subArray(v, kw)
+	case "anyOf":
+		n.anyOf, err = kc.subArray(v, kw)
+	case "oneOf":
+		n.oneOf, err = kc.subArray(v, kw)
+	case "not":
+		n.not, err = kc.sub(v, kw)
+	case "if":
+		n.ifNode, err = kc.sub(v, kw)
+	case "then":
+		n.thenNode, err = kc.sub(v, kw)
+	case "else":
+		n.elseNode, err = kc.sub(v, kw)
+	}
+	return err
+}
+
+func stringList(v *yjson.Value) ([]string, error) {
+	items, err := v.Array()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]string, len(items))
+	for i, item := range items {
+		if list[i], err = item.String(); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+func nonNegInt(v *yjson.Value) (int, error) {
+	r, err := ratOf(v)
+	if err != nil {
+		return 0, err
+	}
+	if !r.IsInt() || r.Sign() < 0 || !r.Num().IsInt64() {
+		return 0, fmt.Errorf("expect non-negative integer")
+	}
+	return int(r.Num().Int64()), nil
+}
+
+// 按数字的源文本精确转换, 1.0 和 1 得到相同的结果
+func ratOf(v *yjson.Value) (*big.Rat, error) {
+	if v.Type() != yjson.JSON_NUMBER {
+		return nil, fmt.Errorf("expect number, but get %s", v.Type())
+	}
+	text := v.Raw()
+	if len(text) == 0 {
+		b, err := v.Encode()
+		if err != nil {
+			return nil, err
+		}
+		text = b
+	}
+	r, ok := new(big.Rat).SetString(string(text))
+	if !ok {
+		return nil, fmt.Errorf("invalid number %s", text)
+	}
+	return r, nil
+}
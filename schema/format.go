@@ -0,0 +1,111 @@
+package schema
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	durationPattern = regexp.MustCompile(`^P(?:(?:\d+Y)?(?:\d+M)?(?:\d+D)?(?:T(?:\d+H)?(?:\d+M)?(?:\d+S)?)?|\d+W)$`)
+	uuidPattern     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	timePattern     = regexp.MustCompile(`^(\d{2}):(\d{2}):(\d{2})(\.\d+)?([zZ]|[+-]\d{2}:\d{2})$`)
+	hostnameLabel   = regexp.MustCompile(`^[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+)
+
+// 按 format 检查 s, 未知的 format 总是通过
+func checkFormat(format, s string) error {
+	switch format {
+	case "date-time":
+		// RFC 3339 允许小写的 t 和 z, 以及 60 秒的闰秒
+		t := strings.ToUpper(s)
+		if i := strings.IndexByte(t, 'T'); i >= 0 {
+			if err := checkFormat("date", t[:i]); err != nil {
+				return err
+			}
+			return checkFormat("time", t[i+1:])
+		}
+		return fmt.Errorf("missing T between date and time")
+	case "date":
+		if _, err := time.Parse("2006-01-02", s); err != nil {
+			return fmt.Errorf("expect YYYY-MM-DD")
+		}
+	case "time":
+		m := timePattern.FindStringSubmatch(s)
+		if m == nil {
+			return fmt.Errorf("expect HH:MM:SS with time zone")
+		}
+		if m[1] > "23" || m[2] > "59" || m[3] > "60" {
+			return fmt.Errorf("time out of range")
+		}
+		if zone := m[5]; len(zone) == 6 && (zone[1:3] > "23" || zone[4:] > "59") {
+			return fmt.Errorf("time zone out of range")
+		}
+	case "duration":
+		if !durationPattern.MatchString(s) || strings.HasSuffix(s, "T") || s == "P" {
+			return fmt.Errorf("expect ISO 8601 duration")
+		}
+	case "email":
+		addr, err := mail.ParseAddress(s)
+		if err != nil || addr.Address != s {
+			return fmt.Errorf("expect email address")
+		}
+	case "hostname":
+		if len(s) == 0 || len(s) > 253 {
+			return fmt.Errorf("length must be between 1 and 253")
+		}
+		for _, label := range strings.Split(strings.TrimSuffix(s, "."), ".") {
+			if !hostnameLabel.MatchString(label) {
+				return fmt.Errorf("invalid label %q", label)
+			}
+		}
+	case "ipv4":
+		ip := net.ParseIP(s)
+		if ip == nil || ip.To4() == nil || strings.Contains(s, ":") {
+			return fmt.Errorf("expect dotted IPv4 address")
+		}
+		for _, part := range strings.Split(s, ".") {
+			if len(part) > 1 && part[0] == '0' {
+				return fmt.Errorf("leading zero in %q", part)
+			}
+		}
+	case "ipv6":
+		if ip := net.ParseIP(s); ip == nil || !strings.Contains(s, ":") {
+			return fmt.Errorf("expect IPv6 address")
+		}
+	case "uri":
+		u, err := url.Parse(s)
+		if err != nil {
+			return err
+		}
+		if !u.IsAbs() {
+			return fmt.Errorf("missing scheme")
+		}
+	case "uri-reference":
+		if _, err := url.Parse(s); err != nil {
+			return err
+		}
+	case "uuid":
+		if !uuidPattern.MatchString(s) {
+			return fmt.Errorf("expect 8-4-4-4-12 hex digits")
+		}
+	case "regex":
+		if _, err := regexp.Compile(s); err != nil {
+			return err
+		}
+	case "json-pointer":
+		if s != "" && s[0] != '/' {
+			return fmt.Errorf("must start with /")
+		}
+		for i := 0; i < len(s); i++ {
+			if s[i] == '~' && (i+1 == len(s) || (s[i+1] != '0' && s[i+1] != '1')) {
+				return fmt.Errorf("bad escape at offset %d", i)
+			}
+		}
+	}
+	return nil
+}
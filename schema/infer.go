@@ -0,0 +1,178 @@
+package schema
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Yohox/yjson"
+)
+
+// InferSchema 生成的 schema 使用的 $schema
+const DRAFT_2020_12 = "https://json-schema.org/draft/2020-12/schema"
+
+// 推断时尝试的 format, 按顺序取第一个匹配的. hostname 和 uri-reference 几乎匹配
+// 任何字符串, 不参与推断
+var inferFormats = []string{"date-time", "date", "time", "duration", "uuid", "email", "ipv4", "ipv6", "uri"}
+
+// 同一位置上观察到的所有值
+type shape struct {
+	types map[string]bool
+
+	format   string // 所有字符串都满足的 format
+	noFormat bool   // 已经出现过不满足 format 的字符串
+
+	objects    int // 观察到的对象个数, 用于判断 required
+	props      map[string]*shape
+	propKeys   []string
+	propCounts map[string]int
+
+	items *shape // 所有数组的所有元素
+}
+
+// 根据样本文档推断 JSON Schema (draft 2020-12), 生成的 schema 接受所有样本.
+// 记录每个位置出现过的类型, 全部是整数的数字推断为 integer; 对象的 properties
+// 按键第一次出现的顺序排列, 每个样本中都出现的键放入 required; 所有数组元素合并
+// 为一个 items; 所有字符串都符合同一 format (如 date-time, email, uuid) 时加上
+// format. 没有样本时返回只含 $schema 的 schema, 接受任何文档
+func InferSchema(samples ...*yjson.Value) *yjson.Value {
+	root := &shape{}
+	for _, v := range samples {
+		if v != nil && v.Exists() {
+			root.add(v)
+		}
+	}
+	out := yjson.NewObject()
+	setKey(out, "$schema", yjson.NewString(DRAFT_2020_12))
+	root.describe(out)
+	return out
+}
+
+func (s *shape) add(v *yjson.Value) {
+	if s.types == nil {
+		s.types = make(map[string]bool)
+	}
+	switch v.Type() {
+	case yjson.JSON_NUMBER:
+		if r, err := ratOf(v); err == nil && r.IsInt() {
+			s.types["integer"] = true
+		} else {
+			s.types["number"] = true
+		}
+	case yjson.JSON_STRING:
+		s.types["string"] = true
+		str, _ := v.String()
+		s.addString(str)
+	case yjson.JSON_ARRAY:
+		s.types["array"] = true
+		items, _ := v.Array()
+		for _, item := range items {
+			if s.items == nil {
+				s.items = &shape{}
+			}
+			s.items.add(item)
+		}
+	case yjson.JSON_OBJECT:
+		s.types["object"] = true
+		s.objects++
+		if s.props == nil {
+			s.props = make(map[string]*shape)
+			s.propCounts = make(map[string]int)
+		}
+		v.Range(func(key string, member *yjson.Value) bool {
+			p, ok := s.props[key]
+			if !ok {
+				p = &shape{}
+				s.props[key] = p
+				s.propKeys = append(s.propKeys, key)
+			}
+			// 重复的键只算一次
+			if s.propCounts[key] < s.objects {
+				s.propCounts[key]++
+			}
+			p.add(member)
+			return true
+		})
+	default:
+		s.types[v.Type().String()] = true
+	}
+}
+
+func (s *shape) addString(str string) {
+	if s.noFormat {
+		return
+	}
+	if s.format != "" {
+		if !matchFormat(s.format, str) {
+			s.format, s.noFormat = "", true
+		}
+		return
+	}
+	for _, f := range inferFormats {
+		if matchFormat(f, str) {
+			s.format = f
+			return
+		}
+	}
+	s.noFormat = true
+}
+
+// 只有带 :// 的字符串推断为 uri, 避免把 a:b 这样的普通字符串当作 URI
+func matchFormat(format, str string) bool {
+	if format == "uri" && !strings.Contains(str, "://") {
+		return false
+	}
+	return checkFormat(format, str) == nil
+}
+
+// 写入 out 对应的关键字
+func (s *shape) describe(out *yjson.Value) {
+	var types []string
+	for _, t := range []string{"null", "boolean", "integer", "number", "string", "array", "object"} {
+		// 同时出现整数和小数时只保留 number
+		if s.types[t] && !(t == "integer" && s.types["number"]) {
+			types = append(types, t)
+		}
+	}
+	switch len(types) {
+	case 0:
+		return
+	case 1:
+		setKey(out, "type", yjson.NewString(types[0]))
+	default:
+		list := yjson.NewArray()
+		for _, t := range types {
+			list.Append(yjson.NewString(t))
+		}
+		setKey(out, "type", list)
+	}
+
+	if s.types["string"] && s.format != "" {
+		setKey(out, "format", yjson.NewString(s.format))
+	}
+	if s.items != nil {
+		items := yjson.NewObject()
+		s.items.describe(items)
+		setKey(out, "items", items)
+	}
+	if s.objects > 0 {
+		props := yjson.NewObject()
+		required := yjson.NewArray()
+		for _, key := range s.propKeys {
+			p := yjson.NewObject()
+			s.props[key].describe(p)
+			setKey(props, key, p)
+			if s.propCounts[key] == s.objects {
+				required.Append(yjson.NewString(key))
+			}
+		}
+		setKey(out, "properties", props)
+		if required.Len() > 0 {
+			setKey(out, "required", required)
+		}
+	}
+}
+
+// 任意键都按带引号的形式写, 不受路径语法中 . 和 [ 的影响
+func setKey(obj *yjson.Value, key string, v *yjson.Value) {
+	obj.Set("["+strconv.Quote(key)+"]", v)
+}
@@ -0,0 +1,106 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/Yohox/yjson"
+)
+
+func TestInferSchema(t *testing.T) {
+	const prefix = `{"$schema":"https://json-schema.org/draft/2020-12/schema"`
+	tests := []struct {
+		samples []string
+		want    string // $schema 之后的部分
+	}{
+		{nil, `}`},
+		{[]string{`1`}, `,"type":"integer"}`},
+		{[]string{`1`, `2.0`}, `,"type":"integer"}`},
+		{[]string{`1`, `2.5`}, `,"type":"number"}`},
+		{[]string{`"a"`, `null`, `true`}, `,"type":["null","boolean","string"]}`},
+		{[]string{`[]`}, `,"type":"array"}`},
+		{[]string{`[1,"a"]`, `[2]`}, `,"type":"array","items":{"type":["integer","string"]}}`},
+		// 每个样本都有的键才放入 required, properties 按第一次出现的顺序
+		{[]string{`{"id":1,"name":"a"}`, `{"tags":[],"id":2}`}, `,"type":"object","properties":{"id":{"type":"integer"},"name":{"type":"string"},"tags":{"type":"array"}},"required":["id"]}`},
+		{[]string{`{}`}, `,"type":"object","properties":{}}`},
+		{[]string{`{"a":{"b":null}}`, `{"a":{"b":1,"c":1}}`}, `,"type":"object","properties":{"a":{"type":"object","properties":{"b":{"type":["null","integer"]},"c":{"type":"integer"}},"required":["b"]}},"required":["a"]}`},
+		{[]string{`[{"a":1},{"b":1}]`}, `,"type":"array","items":{"type":"object","properties":{"a":{"type":"integer"},"b":{"type":"integer"}}}}`},
+		{[]string{`{"a.b":1,"[x]":2}`}, `,"type":"object","properties":{"a.b":{"type":"integer"},"[x]":{"type":"integer"}},"required":["a.b","[x]"]}`},
+		{[]string{`{"a":1,"a":2}`, `{"b":1}`}, `,"type":"object","properties":{"a":{"type":"integer"},"b":{"type":"integer"}}}`},
+		// format
+		{[]string{`"2024-01-02T03:04:05Z"`, `"2023-12-31T00:00:00+08:00"`}, `,"type":"string","format":"date-time"}`},
+		{[]string{`"2024-01-02"`}, `,"type":"string","format":"date"}`},
+		{[]string{`"2024-01-02"`, `"2024-01-02T03:04:05Z"`}, `,"type":"string"}`},
+		{[]string{`"a@example.com"`}, `,"type":"string","format":"email"}`},
+		{[]string{`"123e4567-e89b-12d3-a456-426614174000"`}, `,"type":"string","format":"uuid"}`},
+		{[]string{`"10.0.0.1"`}, `,"type":"string","format":"ipv4"}`},
+		{[]string{`"::1"`}, `,"type":"string","format":"ipv6"}`},
+		{[]string{`"https://example.com/x"`}, `,"type":"string","format":"uri"}`},
+		{[]string{`"a:b"`}, `,"type":"string"}`},
+		{[]string{`"10.0.0.1"`, `"x"`, `"10.0.0.2"`}, `,"type":"string"}`},
+		{[]string{`"2024-01-02"`, `3`}, `,"type":["integer","string"],"format":"date"}`},
+	}
+	for _, tt := range tests {
+		var samples []*yjson.Value
+		for _, s := range tt.samples {
+			v, err := yjson.Parse([]byte(s))
+			if err != nil {
+				t.Fatal(err)
+			}
+			samples = append(samples, v)
+		}
+		out, err := InferSchema(samples...).Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := prefix + tt.want; string(out) != want {
+			t.Errorf("%v:\ngot  %s\nwant %s", tt.samples, out, want)
+		}
+
+		// 生成的 schema 接受所有样本, 包括检查 format
+		s, err := CompileWithOptions(InferSchema(samples...), Options{AssertFormat: true})
+		if err != nil {
+			t.Fatalf("%v: compile: %v", tt.samples, err)
+		}
+		for i, v := range samples {
+			if err := s.Validate(v); err != nil {
+				t.Errorf("%v: sample %d rejected: %v", tt.samples, i, err)
+			}
+		}
+	}
+
+	// nil 和不存在的值被忽略
+	missing, _ := yjson.Parse([]byte(`{}`))
+	if out, _ := InferSchema(nil, missing.Get("x")).Encode(); string(out) != prefix+`}` {
+		t.Errorf("nil samples: got %s", out)
+	}
+}
+
+func TestInferSchemaRejects(t *testing.T) {
+	samples := []string{`{"id":1,"email":"a@example.com"}`, `{"id":2,"email":"b@example.com","note":"x"}`}
+	var values []*yjson.Value
+	for _, s := range samples {
+		v, _ := yjson.Parse([]byte(s))
+		values = append(values, v)
+	}
+	s, err := CompileWithOptions(InferSchema(values...), Options{AssertFormat: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		inst string
+		ok   bool
+	}{
+		{`{"id":3,"email":"c@example.com"}`, true},
+		{`{"id":3,"email":"c@example.com","other":true}`, true},
+		{`{"email":"c@example.com"}`, false},
+		{`{"id":1.5,"email":"c@example.com"}`, false},
+		{`{"id":3,"email":"not an email"}`, false},
+		{`{"id":3,"email":"c@example.com","note":1}`, false},
+		{`[]`, false},
+	}
+	for _, tt := range tests {
+		if err := s.ValidateBytes([]byte(tt.inst)); (err == nil) != tt.ok {
+			t.Errorf("%s: got %v, want ok=%v", tt.inst, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Package schema 按 JSON Schema draft 2020-12 编译 schema 文档, 并校验 yjson.Value
+// 或原始 JSON:
+//
+//	s, err := schema.CompileBytes(schemaJSON)
+//	if err := s.ValidateBytes(data); err != nil {
+//		for _, v := range err.(*schema.ValidationError).Violations { ... }
+//	}
+//
+// 支持除 $vocabulary 以外的核心, 应用和校验关键字, 包括 $ref, $dynamicRef,
+// $anchor, unevaluatedItems 和 unevaluatedProperties. 引用其他文档时需要通过
+// Options.Resources 提供, 不会访问网络. format 默认只作为注解, 见 Options.AssertFormat.
+// pattern 使用 Go 的 regexp (RE2) 语法, 不支持 ECMA-262 的反向引用和环视.
+// 编译后的 Schema 只读, 可以在多个 goroutine 中同时使用.
+// InferSchema 根据样本文档生成描述它们的 schema
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Yohox/yjson"
+)
+
+type Options struct {
+	// 按 format 关键字检查字符串, 支持 date-time, date, time, duration, email,
+	// hostname, ipv4, ipv6, uri, uri-reference, uuid, regex, json-pointer;
+	// 其他 format 总是通过
+	AssertFormat bool
+
+	// $ref 可以引用的其他 schema 文档, 键为绝对 URI, 如 https://example.com/defs.json
+	Resources map[string]*yjson.Value
+}
+
+type Schema struct {
+	root *node
+	opts Options
+
+	// $dynamicAnchor, 键为 资源 URI#名称
+	dynamicAnchors map[string]*node
+}
+
+// 违反 schema 的一处位置
+type Violation struct {
+	InstancePath    string // 实例中的 JSON Pointer, 根节点为空字符串
+	KeywordLocation string // 失败的关键字在 schema 中的绝对位置, 如 #/properties/age/minimum
+	Keyword         string // 失败的关键字, 如 minimum; false schema 为空字符串
+	Message         string
+}
+
+func (v Violation) String() string {
+	path := v.InstancePath
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Sprintf("%s: %s (%s)", path, v.Message, v.KeywordLocation)
+}
+
+// Validate 的结果, 至少包含一处违反
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = v.String()
+	}
+	return "schema validation failed: " + strings.Join(parts, "; ")
+}
+
+func Compile(doc *yjson.Value) (*Schema, error) {
+	return CompileWithOptions(doc, Options{})
+}
+
+func CompileBytes(data []byte) (*Schema, error) {
+	doc, err := yjson.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return Compile(doc)
+}
+
+func CompileWithOptions(doc *yjson.Value, opts Options) (*Schema, error) {
+	c := newCompiler(opts)
+	root, err := c.compileRoot(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{root: root, opts: opts, dynamicAnchors: c.dynamicAnchors}, nil
+}
+
+// 校验 v, 通过时返回 nil, 否则返回 *ValidationError, 按 schema 的检查顺序列出每处违反
+func (s *Schema) Validate(v *yjson.Value) error {
+	vd := &validator{schema: s}
+	vd.validate(s.root, v, "")
+	if len(vd.errs) > 0 {
+		return &ValidationError{Violations: vd.errs}
+	}
+	return nil
+}
+
+// 解析并校验 data, 语法错误直接返回
+func (s *Schema) ValidateBytes(data []byte) error {
+	v, err := yjson.Parse(data)
+	if err != nil {
+		return err
+	}
+	return s.Validate(v)
+}
+
+// JSON Pointer 中键的 ~ 和 / 写作 ~0 和 ~1
+func escapePointer(key string) string {
+	if !strings.ContainsAny(key, "~/") {
+		return key
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+func unescapePointer(key string) string {
+	if !strings.Contains(key, "~") {
+		return key
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~1", "/"), "~0", "~")
+}
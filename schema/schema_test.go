@@ -0,0 +1,220 @@
+package schema
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Yohox/yjson"
+)
+
+func mustCompile(t *testing.T, src string) *Schema {
+	t.Helper()
+	s, err := CompileBytes([]byte(src))
+	if err != nil {
+		t.Fatalf("compile %s: %v", src, err)
+	}
+	return s
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		schema string
+		inst   string
+		ok     bool
+	}{
+		{`{"type":"integer"}`, `1.0`, true},
+		{`{"type":"integer"}`, `1.5`, false},
+		{`{"type":["string","null"]}`, `null`, true},
+		{`{"minimum":0.1,"multipleOf":0.1}`, `0.3`, true},
+		{`{"exclusiveMaximum":3}`, `3`, false},
+		{`{"maximum":9007199254740992}`, `9007199254740993`, false},
+		{`{"minLength":2}`, `"日本"`, true},
+		{`{"minLength":3}`, `"日本"`, false},
+		{`{"pattern":"^a+$"}`, `"aab"`, false},
+		{`{"enum":[1,"a",{"x":[1]}]}`, `{"x":[1.0]}`, true},
+		{`{"const":{"a":1}}`, `{"a":2}`, false},
+		{`{"const":9007199254740993}`, `9007199254740993`, true},
+		{`{"properties":{"a":{"type":"string"}},"required":["a","b"]}`, `{"a":"x"}`, false},
+		{`{"properties":{"a":true},"additionalProperties":false}`, `{"a":1,"b":2}`, false},
+		{`{"patternProperties":{"^x":{"type":"number"}},"additionalProperties":false}`, `{"x1":1}`, true},
+		{`{"propertyNames":{"maxLength":2}}`, `{"abc":1}`, false},
+		{`{"dependentRequired":{"a":["b"]}}`, `{"a":1}`, false},
+		{`{"dependentSchemas":{"a":{"required":["b"]}}}`, `{"a":1,"b":1}`, true},
+		{`{"minProperties":2}`, `{"a":1}`, false},
+		{`{"prefixItems":[{"type":"number"}],"items":false}`, `[1,2]`, false},
+		{`{"prefixItems":[{"type":"number"}],"items":false}`, `[1]`, true},
+		{`{"contains":{"type":"string"},"minContains":2}`, `["a",1,"b"]`, true},
+		{`{"contains":{"type":"string"},"maxContains":1}`, `["a",1,"b"]`, false},
+		{`{"contains":{"type":"string"},"minContains":0}`, `[1]`, true},
+		{`{"uniqueItems":true}`, `[1,1.0]`, false},
+		{`{"uniqueItems":true}`, `[9007199254740993,9007199254740992]`, true},
+		{`{"enum":[9007199254740992]}`, `9007199254740993`, false},
+		{`{"const":9007199254740992}`, `9007199254740993`, false},
+		{`{"allOf":[{"minimum":1},{"maximum":3}]}`, `4`, false},
+		{`{"anyOf":[{"type":"string"},{"minimum":3}]}`, `1`, false},
+		{`{"oneOf":[{"minimum":1},{"minimum":2}]}`, `3`, false},
+		{`{"oneOf":[{"minimum":1},{"minimum":2}]}`, `1`, true},
+		{`{"not":{"type":"null"}}`, `null`, false},
+		{`{"if":{"properties":{"a":{"const":1}}},"then":{"required":["b"]},"else":{"required":["c"]}}`, `{"a":1,"c":1}`, false},
+		{`{"if":{"properties":{"a":{"const":1}}},"then":{"required":["b"]},"else":{"required":["c"]}}`, `{"a":2,"c":1}`, true},
+		{`{"$defs":{"pos":{"minimum":0}},"properties":{"n":{"$ref":"#/$defs/pos"}}}`, `{"n":-1}`, false},
+		{`{"$defs":{"pos":{"$anchor":"p","minimum":0}},"items":{"$ref":"#p"}}`, `[1,-1]`, false},
+		{`{"$ref":"#/$defs/a","$defs":{"a":{"type":"object","properties":{"next":{"$ref":"#/$defs/a"}}}}}`, `{"next":{"next":1}}`, false},
+		{`{"$id":"https://ex.com/root","$defs":{"b":{"$id":"b.json","type":"string"}},"$ref":"b.json"}`, `"s"`, true},
+		{`{"$id":"https://ex.com/root","$defs":{"b":{"$id":"b.json","type":"string"}},"$ref":"b.json"}`, `1`, false},
+		{`{"properties":{"a":true},"allOf":[{"properties":{"b":true}}],"unevaluatedProperties":false}`, `{"a":1,"b":1}`, true},
+		{`{"properties":{"a":true},"allOf":[{"properties":{"b":true}}],"unevaluatedProperties":false}`, `{"a":1,"b":1,"c":1}`, false},
+		{`{"anyOf":[{"properties":{"a":true}},{"properties":{"b":true}}],"unevaluatedProperties":false}`, `{"a":1,"b":1}`, true},
+		{`{"prefixItems":[true],"unevaluatedItems":false}`, `[1,2]`, false},
+		{`{"allOf":[{"prefixItems":[true,true]}],"unevaluatedItems":false}`, `[1,2]`, true},
+		{`{"contains":{"type":"string"},"unevaluatedItems":false}`, `["a",1]`, false},
+		{`false`, `1`, false},
+		{`true`, `1`, true},
+		{`{"$id":"https://ex.com/strict","$dynamicAnchor":"node","$ref":"tree","$defs":{"tree":{"$id":"tree","$dynamicAnchor":"node","type":"object","properties":{"data":true,"children":{"type":"array","items":{"$dynamicRef":"#node"}}}}},"properties":{"data":{"type":"number"}}}`, `{"data":1,"children":[{"data":"x"}]}`, false},
+		{`{"$id":"https://ex.com/strict","$dynamicAnchor":"node","$ref":"tree","$defs":{"tree":{"$id":"tree","$dynamicAnchor":"node","type":"object","properties":{"data":true,"children":{"type":"array","items":{"$dynamicRef":"#node"}}}}},"properties":{"data":{"type":"number"}}}`, `{"data":1,"children":[{"data":2}]}`, true},
+	}
+	for _, tt := range tests {
+		err := mustCompile(t, tt.schema).ValidateBytes([]byte(tt.inst))
+		if (err == nil) != tt.ok {
+			t.Errorf("%s against %s: got %v, want ok=%v", tt.schema, tt.inst, err, tt.ok)
+		}
+	}
+}
+
+func TestValidateViolations(t *testing.T) {
+	s := mustCompile(t, `{"properties":{"a/b":{"type":"number"},"n":{"$ref":"#/$defs/pos"}},"$defs":{"pos":{"minimum":0}}}`)
+	err := s.ValidateBytes([]byte(`{"a/b":"x","n":-1}`))
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("got %v, want *ValidationError", err)
+	}
+	want := []Violation{
+		{InstancePath: "/a~1b", KeywordLocation: "#/properties/a~1b/type", Keyword: "type"},
+		{InstancePath: "/n", KeywordLocation: "#/$defs/pos/minimum", Keyword: "minimum"},
+	}
+	if len(verr.Violations) != len(want) {
+		t.Fatalf("got %v, want %d violations", verr.Violations, len(want))
+	}
+	for i, v := range verr.Violations {
+		v.Message = ""
+		if v != want[i] {
+			t.Errorf("violation %d: got %+v, want %+v", i, v, want[i])
+		}
+	}
+}
+
+// 不消耗实例的 $ref 循环必须报告为违反, 而不是无限递归
+func TestValidateRefLoop(t *testing.T) {
+	tests := []struct {
+		schema string
+		inst   string
+	}{
+		{`{"$ref":"#"}`, `1`},
+		{`{"$defs":{"a":{"$ref":"#/$defs/b"},"b":{"$ref":"#/$defs/a"}},"$ref":"#/$defs/a"}`, `{}`},
+		{`{"properties":{"x":{"$ref":"#/properties/x"}}}`, `{"x":1}`},
+		{`{"$dynamicAnchor":"a","$dynamicRef":"#a"}`, `null`},
+	}
+	for _, tt := range tests {
+		err := mustCompile(t, tt.schema).ValidateBytes([]byte(tt.inst))
+		if err == nil || !strings.Contains(err.Error(), "infinite reference loop") {
+			t.Errorf("%s against %s: got %v, want reference loop", tt.schema, tt.inst, err)
+		}
+	}
+
+	// anyOf 丢弃分支中的违反, 只需要能够结束
+	if err := mustCompile(t, `{"anyOf":[{"$ref":"#"}]}`).ValidateBytes([]byte(`1`)); err == nil {
+		t.Error("anyOf loop: got nil, want error")
+	}
+
+	// 每次进入实例的子节点时并不是循环
+	s := mustCompile(t, `{"type":"array","items":{"$ref":"#"}}`)
+	if err := s.ValidateBytes([]byte(`[[[]],[]]`)); err != nil {
+		t.Errorf("recursive schema: %v", err)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		schema string
+		err    string
+	}{
+		{`{"type":"foo"}`, `invalid schema at #/type: unknown type "foo"`},
+		{`{"properties":{"a":{"minimum":"x"}}}`, `invalid schema at #/properties/a/minimum: expect number, but get string`},
+		{`{"$ref":"#/nope"}`, `invalid schema at #/$ref: #/nope not found`},
+		{`{"$ref":"https://other/x"}`, `invalid schema at #/$ref: https://other/x# not found, provide it in Options.Resources`},
+		{`{"allOf":[]}`, `invalid schema at #/allOf: expect non-empty array`},
+		{`1`, `invalid schema at #: expect object or boolean, but get number`},
+	}
+	for _, tt := range tests {
+		_, err := CompileBytes([]byte(tt.schema))
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("%s: got %v, want %s", tt.schema, err, tt.err)
+		}
+	}
+}
+
+func TestCompileResources(t *testing.T) {
+	ext, err := yjson.Parse([]byte(`{"$defs":{"n":{"type":"number"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := yjson.Parse([]byte(`{"items":{"$ref":"https://ex.com/d.json#/$defs/n"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := CompileWithOptions(doc, Options{Resources: map[string]*yjson.Value{"https://ex.com/d.json": ext}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ValidateBytes([]byte(`[1,2]`)); err != nil {
+		t.Error(err)
+	}
+	err = s.ValidateBytes([]byte(`[1,"a"]`))
+	var verr *ValidationError
+	if !errors.As(err, &verr) || len(verr.Violations) != 1 || verr.Violations[0].KeywordLocation != "https://ex.com/d.json#/$defs/n/type" {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		format, good, bad string
+	}{
+		{"date-time", "2020-01-01T10:00:00Z", "2020-13-01T10:00:00Z"},
+		{"date", "2020-02-29", "2021-02-29"},
+		{"time", "23:59:60+01:00", "24:00:00Z"},
+		{"duration", "P1DT2H", "PT"},
+		{"email", "a@b.com", "a@"},
+		{"hostname", "a.b-c.com", "-a.com"},
+		{"ipv4", "1.2.3.4", "01.2.3.4"},
+		{"ipv6", "::1", "1.2.3.4"},
+		{"uri", "http://a/b", "a/b"},
+		{"uuid", "123e4567-e89b-12d3-a456-426614174000", "x"},
+		{"regex", "^a+$", "("},
+		{"json-pointer", "/a~0", "/a~2"},
+	}
+	for _, tt := range tests {
+		doc, err := yjson.Parse([]byte(`{"format":"` + tt.format + `"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert, err := CompileWithOptions(doc, Options{AssertFormat: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := assert.Validate(yjson.NewString(tt.good)); err != nil {
+			t.Errorf("%s %q: %v", tt.format, tt.good, err)
+		}
+		if err := assert.Validate(yjson.NewString(tt.bad)); err == nil {
+			t.Errorf("%s %q: got nil, want error", tt.format, tt.bad)
+		}
+		annotate, err := Compile(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := annotate.Validate(yjson.NewString(tt.bad)); err != nil {
+			t.Errorf("%s %q without AssertFormat: %v", tt.format, tt.bad, err)
+		}
+	}
+}
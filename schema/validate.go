@@ -0,0 +1,475 @@
+package schema
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/Yohox/yjson"
+)
+
+// 一次 Validate 的状态. scope 为进入过的资源 URI, 用于解析 $dynamicRef;
+// active 为正在校验的 schema 和实例, 用于发现不消耗实例的 $ref 循环
+type validator struct {
+	schema *Schema
+	errs   []Violation
+	scope  []string
+	active map[activeKey]bool
+}
+
+type activeKey struct {
+	n    *node
+	inst *yjson.Value
+}
+
+// 成功的子 schema 评估过的成员和元素, 用于 unevaluatedProperties 和 unevaluatedItems
+type evaluated struct {
+	props   map[string]bool
+	items   int          // 前 items 个元素已经评估过
+	all     bool         // 所有元素都已经评估过
+	indexes map[int]bool // contains 匹配的元素
+}
+
+func (e *evaluated) merge(o *evaluated) {
+	if o == nil {
+		return
+	}
+	for k := range o.props {
+		if e.props == nil {
+			e.props = make(map[string]bool)
+		}
+		e.props[k] = true
+	}
+	if o.items > e.items {
+		e.items = o.items
+	}
+	e.all = e.all || o.all
+	for i := range o.indexes {
+		if e.indexes == nil {
+			e.indexes = make(map[int]bool)
+		}
+		e.indexes[i] = true
+	}
+}
+
+func (e *evaluated) prop(key string) {
+	if e.props == nil {
+		e.props = make(map[string]bool)
+	}
+	e.props[key] = true
+}
+
+// 校验但不记录违反, 用于 anyOf, oneOf, not, if, contains 等只关心是否通过的位置
+func (vd *validator) try(n *node, inst *yjson.Value, path string) (*evaluated, bool) {
+	mark := len(vd.errs)
+	ev, ok := vd.validate(n, inst, path)
+	vd.errs = vd.errs[:mark]
+	return ev, ok
+}
+
+func (vd *validator) validate(n *node, inst *yjson.Value, path string) (*evaluated, bool) {
+	if n.always != nil {
+		if !*n.always {
+			vd.errs = append(vd.errs, Violation{InstancePath: path, KeywordLocation: n.loc, Message: "false schema never matches"})
+			return nil, false
+		}
+		return &evaluated{}, true
+	}
+
+	key := activeKey{n: n, inst: inst}
+	if vd.active == nil {
+		vd.active = make(map[activeKey]bool)
+	}
+	vd.active[key] = true
+	defer delete(vd.active, key)
+
+	if len(vd.scope) == 0 || vd.scope[len(vd.scope)-1] != n.base {
+		vd.scope = append(vd.scope, n.base)
+		defer func() { vd.scope = vd.scope[:len(vd.scope)-1] }()
+	}
+
+	ev := &evaluated{}
+	ok := true
+	fail := func(kw, format string, args ...interface{}) {
+		vd.errs = append(vd.errs, Violation{
+			InstancePath:    path,
+			KeywordLocation: n.loc + "/" + kw,
+			Keyword:         kw,
+			Message:         fmt.Sprintf(format, args...),
+		})
+		ok = false
+	}
+	apply := func(sub *node) {
+		if sev, sok := vd.validate(sub, inst, path); sok {
+			ev.merge(sev)
+		} else {
+			ok = false
+		}
+	}
+
+	// 同一个实例再次进入正在校验的 schema 时会无限递归
+	applyRef := func(kw string, target *node) {
+		if vd.active[activeKey{n: target, inst: inst}] {
+			fail(kw, "infinite reference loop to %s", target.loc)
+			return
+		}
+		apply(target)
+	}
+	if n.ref != nil {
+		applyRef("$ref", n.ref)
+	}
+	if n.dynRef != nil {
+		applyRef("$dynamicRef", vd.dynamicTarget(n))
+	}
+
+	if len(n.types) > 0 && !matchType(n.types, inst) {
+		fail("type", "expect %s, but get %s", typeList(n.types), typeOf(inst))
+	}
+	if n.hasEnum {
+		found := false
+		for _, e := range n.enum {
+			if yjson.Equal(inst, e) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("enum", "value is not one of the enumerated values")
+		}
+	}
+	if n.constVal != nil && !yjson.Equal(inst, n.constVal) {
+		fail("const", "value does not equal the const value")
+	}
+
+	switch inst.Type() {
+	case yjson.JSON_NUMBER:
+		vd.validateNumber(n, inst, fail)
+	case yjson.JSON_STRING:
+		s, _ := inst.String()
+		vd.validateString(n, s, fail)
+	case yjson.JSON_ARRAY:
+		if !vd.validateArray(n, inst, path, ev, fail) {
+			ok = false
+		}
+	case yjson.JSON_OBJECT:
+		if !vd.validateObject(n, inst, path, ev, fail) {
+			ok = false
+		}
+	}
+
+	for _, sub := range n.allOf {
+		apply(sub)
+	}
+	if len(n.anyOf) > 0 {
+		// 所有通过的分支都产生注解, 不能在第一个通过后停止
+		matched := false
+		for _, sub := range n.anyOf {
+			if sev, sok := vd.try(sub, inst, path); sok {
+				matched = true
+				ev.merge(sev)
+			}
+		}
+		if !matched {
+			fail("anyOf", "value does not match any schema in anyOf")
+		}
+	}
+	if len(n.oneOf) > 0 {
+		var matches []int
+		var mev *evaluated
+		for i, sub := range n.oneOf {
+			if sev, sok := vd.try(sub, inst, path); sok {
+				matches = append(matches, i)
+				mev = sev
+			}
+		}
+		switch len(matches) {
+		case 0:
+			fail("oneOf", "value does not match any schema in oneOf")
+		case 1:
+			ev.merge(mev)
+		default:
+			fail("oneOf", "value matches schemas %d and %d in oneOf, expect exactly one", matches[0], matches[1])
+		}
+	}
+	if n.not != nil {
+		if _, sok := vd.try(n.not, inst, path); sok {
+			fail("not", "value must not match the schema in not")
+		}
+	}
+	if n.ifNode != nil {
+		if iev, iok := vd.try(n.ifNode, inst, path); iok {
+			ev.merge(iev)
+			if n.thenNode != nil {
+				apply(n.thenNode)
+			}
+		} else if n.elseNode != nil {
+			apply(n.elseNode)
+		}
+	}
+
+	// 必须在其他关键字之后, 需要它们产生的注解
+	switch inst.Type() {
+	case yjson.JSON_ARRAY:
+		if n.unevaluatedItems != nil && !ev.all {
+			items, _ := inst.Array()
+			for i := ev.items; i < len(items); i++ {
+				if ev.indexes[i] {
+					continue
+				}
+				if _, sok := vd.validate(n.unevaluatedItems, items[i], path+"/"+strconv.Itoa(i)); !sok {
+					ok = false
+				}
+			}
+			ev.all = true
+		}
+	case yjson.JSON_OBJECT:
+		if n.unevaluatedProps != nil {
+			members, _ := inst.Map()
+			for _, key := range inst.Keys() {
+				if ev.props[key] {
+					continue
+				}
+				if _, sok := vd.validate(n.unevaluatedProps, members[key], path+"/"+escapePointer(key)); !sok {
+					ok = false
+				}
+				ev.prop(key)
+			}
+		}
+	}
+	return ev, ok
+}
+
+// $dynamicRef 静态解析到的 schema 带有同名的 $dynamicAnchor 时, 使用动态作用域中
+// 最外层带有该锚点的资源
+func (vd *validator) dynamicTarget(n *node) *node {
+	target := n.dynRef
+	name := n.dynRefFrag
+	if target.dynamicAnchor == "" || target.dynamicAnchor != name {
+		return target
+	}
+	for _, base := range vd.scope {
+		if d, ok := vd.schema.dynamicAnchors[base+"#"+name]; ok {
+			return d
+		}
+	}
+	return target
+}
+
+func typeOf(v *yjson.Value) string {
+	return v.Type().String()
+}
+
+func typeList(types []string) string {
+	if len(types) == 1 {
+		return types[0]
+	}
+	return fmt.Sprint(types)
+}
+
+func matchType(types []string, v *yjson.Value) bool {
+	for _, t := range types {
+		switch t {
+		case "integer":
+			if v.Type() == yjson.JSON_NUMBER {
+				if r, err := ratOf(v); err == nil && r.IsInt() {
+					return true
+				}
+			}
+		default:
+			if v.Type().String() == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type failFunc func(kw, format string, args ...interface{})
+
+func (vd *validator) validateNumber(n *node, inst *yjson.Value, fail failFunc) {
+	x, err := ratOf(inst)
+	if err != nil {
+		fail("type", "%v", err)
+		return
+	}
+	if n.multipleOf != nil && !new(big.Rat).Quo(x, n.multipleOf).IsInt() {
+		fail("multipleOf", "%s is not a multiple of %s", ratText(x), ratText(n.multipleOf))
+	}
+	if n.maximum != nil && x.Cmp(n.maximum) > 0 {
+		fail("maximum", "%s is greater than %s", ratText(x), ratText(n.maximum))
+	}
+	if n.exclusiveMaximum != nil && x.Cmp(n.exclusiveMaximum) >= 0 {
+		fail("exclusiveMaximum", "%s is not less than %s", ratText(x), ratText(n.exclusiveMaximum))
+	}
+	if n.minimum != nil && x.Cmp(n.minimum) < 0 {
+		fail("minimum", "%s is less than %s", ratText(x), ratText(n.minimum))
+	}
+	if n.exclusiveMinimum != nil && x.Cmp(n.exclusiveMinimum) <= 0 {
+		fail("exclusiveMinimum", "%s is not greater than %s", ratText(x), ratText(n.exclusiveMinimum))
+	}
+}
+
+func ratText(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	f, _ := r.Float64()
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func (vd *validator) validateString(n *node, s string, fail failFunc) {
+	length := utf8.RuneCountInString(s)
+	if n.minLength >= 0 && length < n.minLength {
+		fail("minLength", "length %d is less than %d", length, n.minLength)
+	}
+	if n.maxLength >= 0 && length > n.maxLength {
+		fail("maxLength", "length %d is greater than %d", length, n.maxLength)
+	}
+	if n.pattern != nil && !n.pattern.MatchString(s) {
+		fail("pattern", "%q does not match pattern %q", s, n.pattern.String())
+	}
+	if n.format != "" && vd.schema.opts.AssertFormat {
+		if err := checkFormat(n.format, s); err != nil {
+			fail("format", "%q is not a valid %s: %v", s, n.format, err)
+		}
+	}
+}
+
+func (vd *validator) validateArray(n *node, inst *yjson.Value, path string, ev *evaluated, fail failFunc) bool {
+	ok := true
+	items, _ := inst.Array()
+	if n.minItems >= 0 && len(items) < n.minItems {
+		fail("minItems", "array has %d items, expect at least %d", len(items), n.minItems)
+	}
+	if n.maxItems >= 0 && len(items) > n.maxItems {
+		fail("maxItems", "array has %d items, expect at most %d", len(items), n.maxItems)
+	}
+	if n.uniqueItems {
+	unique:
+		for i := range items {
+			for k := i + 1; k < len(items); k++ {
+				if yjson.Equal(items[i], items[k]) {
+					fail("uniqueItems", "items %d and %d are equal", i, k)
+					break unique
+				}
+			}
+		}
+	}
+
+	for i, sub := range n.prefixItems {
+		if i >= len(items) {
+			break
+		}
+		if _, sok := vd.validate(sub, items[i], path+"/"+strconv.Itoa(i)); !sok {
+			ok = false
+		}
+	}
+	if len(n.prefixItems) > ev.items {
+		ev.items = len(n.prefixItems)
+	}
+	if n.items != nil {
+		for i := len(n.prefixItems); i < len(items); i++ {
+			if _, sok := vd.validate(n.items, items[i], path+"/"+strconv.Itoa(i)); !sok {
+				ok = false
+			}
+		}
+		ev.all = true
+	}
+
+	if n.contains != nil {
+		count := 0
+		for i, item := range items {
+			if _, sok := vd.try(n.contains, item, path+"/"+strconv.Itoa(i)); sok {
+				count++
+				if ev.indexes == nil {
+					ev.indexes = make(map[int]bool)
+				}
+				ev.indexes[i] = true
+			}
+		}
+		min := 1
+		if n.minContains >= 0 {
+			min = n.minContains
+		}
+		if count < min {
+			fail("contains", "array contains %d matching items, expect at least %d", count, min)
+		}
+		if n.maxContains >= 0 && count > n.maxContains {
+			fail("maxContains", "array contains %d matching items, expect at most %d", count, n.maxContains)
+		}
+	}
+	return ok
+}
+
+func (vd *validator) validateObject(n *node, inst *yjson.Value, path string, ev *evaluated, fail failFunc) bool {
+	ok := true
+	members, _ := inst.Map()
+	keys := inst.Keys()
+	if n.minProperties >= 0 && len(keys) < n.minProperties {
+		fail("minProperties", "object has %d properties, expect at least %d", len(keys), n.minProperties)
+	}
+	if n.maxProperties >= 0 && len(keys) > n.maxProperties {
+		fail("maxProperties", "object has %d properties, expect at most %d", len(keys), n.maxProperties)
+	}
+	for _, key := range n.required {
+		if _, found := members[key]; !found {
+			fail("required", "missing required property %q", key)
+		}
+	}
+	for _, key := range n.dependentKeys {
+		if _, found := members[key]; !found {
+			continue
+		}
+		for _, dep := range n.dependentRequired[key] {
+			if _, found := members[dep]; !found {
+				fail("dependentRequired", "property %q requires property %q", key, dep)
+			}
+		}
+	}
+
+	for _, key := range keys {
+		child := path + "/" + escapePointer(key)
+		matched := false
+		if sub, found := n.properties[key]; found {
+			matched = true
+			if _, sok := vd.validate(sub, members[key], child); !sok {
+				ok = false
+			}
+		}
+		for _, pp := range n.patternProps {
+			if pp.re.MatchString(key) {
+				matched = true
+				if _, sok := vd.validate(pp.node, members[key], child); !sok {
+					ok = false
+				}
+			}
+		}
+		if !matched && n.additionalProp != nil {
+			matched = true
+			if _, sok := vd.validate(n.additionalProp, members[key], child); !sok {
+				ok = false
+			}
+		}
+		if matched {
+			ev.prop(key)
+		}
+		if n.propertyNames != nil {
+			if _, sok := vd.try(n.propertyNames, yjson.NewString(key), child); !sok {
+				fail("propertyNames", "invalid property name %q", key)
+			}
+		}
+	}
+
+	for _, key := range n.dependentSchemaKeys {
+		if _, found := members[key]; !found {
+			continue
+		}
+		if sev, sok := vd.validate(n.dependentSchemas[key], inst, path); sok {
+			ev.merge(sev)
+		} else {
+			ok = false
+		}
+	}
+	return ok
+}
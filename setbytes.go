@@ -0,0 +1,164 @@
+package yjson
+
+import "fmt"
+
+// 按 Get 的路径语法把 value 写入原始 JSON, 只替换或插入目标值的源文本, 其余部分
+// (包括空白和键的顺序) 原样保留, 不修改 data. value 按 Marshal 序列化.
+// 与 Value.Set 相同: 缺失的中间节点按下一段的类型创建为对象或数组, 数组下标等于
+// 长度时追加, 中间节点类型不符或下标越界时返回错误; data 为空时按路径新建文档
+func SetBytes(data []byte, path string, value interface{}) ([]byte, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return setBytes(data, path, segments, value)
+}
+
+func setBytes(data []byte, path string, segments []pathSegment, value interface{}) ([]byte, error) {
+	if multiMatch(segments) {
+		return nil, fmt.Errorf("set %q: cannot use * or .. in path", path)
+	}
+	raw, err := Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("set %q: %v", path, err)
+	}
+
+	i := rawSpace(data, 0)
+	if i >= len(data) {
+		created, err := rawBuild(segments, raw)
+		if err != nil {
+			return nil, fmt.Errorf("set %q: %v", path, err)
+		}
+		return splice(data, i, i, created), nil
+	}
+	for k, seg := range segments {
+		next := rawLookup(data, i, seg)
+		if next < 0 {
+			out, err := rawInsert(data, i, segments[k:], raw)
+			if err != nil {
+				return nil, fmt.Errorf("set %q: %v", path, err)
+			}
+			return out, nil
+		}
+		i = next
+	}
+	end := rawSkip(data, i)
+	if end < 0 {
+		return nil, fmt.Errorf("set %q: invalid JSON at offset %d", path, i)
+	}
+	return splice(data, i, end, raw), nil
+}
+
+// 返回 data[:start] + ins + data[end:], 只分配一次
+func splice(data []byte, start, end int, ins []byte) []byte {
+	out := make([]byte, 0, len(data)-(end-start)+len(ins))
+	out = append(out, data[:start]...)
+	out = append(out, ins...)
+	return append(out, data[end:]...)
+}
+
+// 为 segments 新建嵌套的容器, 最内层的值为 raw. 新数组的长度为 0, 只能写入下标 0
+func rawBuild(segments []pathSegment, raw []byte) ([]byte, error) {
+	for k := len(segments) - 1; k >= 0; k-- {
+		seg := segments[k]
+		if seg.isIndex {
+			if seg.index != 0 {
+				return nil, fmt.Errorf("index [%d] out of range, array length is 0", seg.index)
+			}
+			raw = append(append([]byte{LB}, raw...), RB)
+			continue
+		}
+		key, err := Marshal(seg.key)
+		if err != nil {
+			return nil, err
+		}
+		member := append(append(append([]byte{OB}, key...), VALUE_SEPARATOR), raw...)
+		raw = append(member, CB)
+	}
+	return raw, nil
+}
+
+// data[i] 开始的容器中没有 segments[0], 在最后一个成员之后插入
+func rawInsert(data []byte, i int, segments []pathSegment, raw []byte) ([]byte, error) {
+	seg := segments[0]
+	if data[i] != OB && data[i] != LB {
+		return nil, fmt.Errorf("cannot set %s on %s", seg, rawKind(data[i]))
+	}
+	last, count := rawLastMember(data, i)
+	if last < 0 {
+		return nil, fmt.Errorf("invalid JSON at offset %d", i)
+	}
+
+	var member []byte
+	switch data[i] {
+	case OB:
+		if seg.isIndex {
+			return nil, fmt.Errorf("cannot use index [%d] on object", seg.index)
+		}
+		key, err := Marshal(seg.key)
+		if err != nil {
+			return nil, err
+		}
+		member = append(key, VALUE_SEPARATOR)
+	case LB:
+		index, err := seg.arrayIndex()
+		if err != nil {
+			return nil, err
+		}
+		if index != count {
+			return nil, fmt.Errorf("index [%d] out of range, array length is %d", index, count)
+		}
+	}
+	nested, err := rawBuild(segments[1:], raw)
+	if err != nil {
+		return nil, err
+	}
+	member = append(member, nested...)
+	if count > 0 {
+		member = append([]byte{DOT}, member...)
+	}
+	return splice(data, last, last, member), nil
+}
+
+// 返回 data[i] 开始的对象或数组中最后一个成员值之后的下标 (没有成员时为括号之后)
+// 和成员个数, 输入不完整时下标为 -1
+func rawLastMember(data []byte, i int) (int, int) {
+	closer := byte(RB)
+	if data[i] == OB {
+		closer = CB
+	}
+	last, count := i+1, 0
+	i = rawSpace(data, i+1)
+	if i < len(data) && data[i] == closer {
+		return last, 0
+	}
+	for i >= 0 && i < len(data) {
+		if closer == CB {
+			if data[i] != DQ {
+				return -1, 0
+			}
+			i = rawSpace(data, rawStringEnd(data, i))
+			if i < 0 || i >= len(data) || data[i] != VALUE_SEPARATOR {
+				return -1, 0
+			}
+			i = rawSpace(data, i+1)
+		}
+		if i = rawSkip(data, i); i < 0 {
+			return -1, 0
+		}
+		last, count = i, count+1
+		i = rawSpace(data, i)
+		if i < 0 || i >= len(data) {
+			return -1, 0
+		}
+		switch data[i] {
+		case closer:
+			return last, count
+		case DOT:
+			i = rawSpace(data, i+1)
+		default:
+			return -1, 0
+		}
+	}
+	return -1, 0
+}
@@ -0,0 +1,86 @@
+package yjson
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetBytes(t *testing.T) {
+	tests := []struct {
+		input string
+		path  string
+		value interface{}
+		want  string // 以 "error: " 开头时为错误信息中应包含的内容
+	}{
+		// 替换时保留其余部分的空白和顺序
+		{`{ "b" : 1 , "a" : [ 1 , 2 ] }`, "b", "x", `{ "b" : "x" , "a" : [ 1 , 2 ] }`},
+		{`{ "b" : 1 , "a" : [ 1 , 2 ] }`, "a[1]", map[string]int{"k": 1}, `{ "b" : 1 , "a" : [ 1 , {"k":1} ] }`},
+		{`{ "b" : 1 , "a" : [ 1 , 2 ] }`, "a", nil, `{ "b" : 1 , "a" : null }`},
+		{`{"a":{"b":"}"},"c":2}`, "a.b", true, `{"a":{"b":true},"c":2}`},
+		{`{"a":1,"a":2}`, "a", 3, `{"a":3,"a":2}`},
+		{` [1] `, "", []int{2, 3}, ` [2,3] `},
+		// 插入新成员
+		{`{"a":1}`, "b", 2, `{"a":1,"b":2}`},
+		{`{"a":1 }`, "b", 2, `{"a":1,"b":2 }`},
+		{`{ }`, "b", 2, `{"b":2 }`},
+		{`[1,2]`, "[2]", 3, `[1,2,3]`},
+		{`[]`, "[0]", "x", `["x"]`},
+		{`{"a":{}}`, "a.b.c[0].d", 1, `{"a":{"b":{"c":[{"d":1}]}}}`},
+		{`{"a":1}`, `["x.y"]`, 1, `{"a":1,"x.y":1}`},
+		{`{"a":1}`, `q"k`, 1, `{"a":1,"q\"k":1}`},
+		// 空文档按路径新建
+		{``, "a.b", 1, `{"a":{"b":1}}`},
+		{` `, "[0].a", 1, ` [{"a":1}]`},
+		{``, "", "s", `"s"`},
+		// 错误
+		{``, "[1]", 1, `error: index [1] out of range, array length is 0`},
+		{`[1,2]`, "[5]", 1, `error: set "[5]": index [5] out of range, array length is 2`},
+		{`{"a":1}`, "a.b", 1, `error: set "a.b": cannot set "b" on number`},
+		{`{"a":1}`, "[0]", 1, `error: cannot use index [0] on object`},
+		{`[1]`, "a", 1, `error: cannot use key "a" on array`},
+		{`{"a":[1]}`, "a[*]", 1, `error: cannot use * or .. in path`},
+		{`{"a":[1]}`, "..a", 1, `error: cannot use * or .. in path`},
+		{`{"a":1}`, "a[", 1, `error: `},
+		{`{"a":1}`, "b", func() {}, `error: set "b": `},
+		{`{"a":[1,2`, "a[2]", 1, `error: invalid JSON`},
+		{`{"a":"x`, "a", 1, `error: invalid JSON at offset 5`},
+	}
+	for _, tt := range tests {
+		data := []byte(tt.input)
+		got, err := SetBytes(data, tt.path, tt.value)
+		if string(data) != tt.input {
+			t.Errorf("%s: modified the input", tt.path)
+		}
+		if msg, ok := strings.CutPrefix(tt.want, "error: "); ok {
+			if err == nil || !strings.Contains(err.Error(), msg) {
+				t.Errorf("%s in %s: got %s, %v, want error %q", tt.path, tt.input, got, err, msg)
+			}
+			continue
+		}
+		if err != nil || string(got) != tt.want {
+			t.Errorf("%s in %s:\ngot  %s %v\nwant %s", tt.path, tt.input, got, err, tt.want)
+		}
+	}
+}
+
+// 与解析后调用 Value.Set 得到相同的文档
+func TestSetBytesMatchesSet(t *testing.T) {
+	paths := []string{
+		"users[0].name", "users[1].tags[2]", "users[2]", "users[1].address", `["a.b"]`,
+		"a.b", "a.c.d", "new[0][0]", `[""]`, `["q\"k"]`, "users.1.name",
+	}
+	for _, path := range paths {
+		got, err := SetBytes([]byte(pathDoc), path, []interface{}{"v", 1})
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+			continue
+		}
+		v := mustParse(t, pathDoc)
+		if err := v.Set(path, mustParse(t, `["v",1]`)); err != nil {
+			t.Fatal(err)
+		}
+		if mustEncode(t, mustParse(t, string(got))) != mustEncode(t, v) {
+			t.Errorf("%s:\ngot  %s\nwant %s", path, got, mustEncode(t, v))
+		}
+	}
+}
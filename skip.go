@@ -0,0 +1,131 @@
+package yjson
+
+import "io"
+
+// 跳过下一个完整的值, 不解析内容也不做语法检查. 边读边丢弃, 缓冲区不会因为
+// 值很大而增长. 常用于 Token 读到不关心的键之后跳过它的值
+func (dec *Decoder) Skip() error {
+	dec.resume()
+	if err := dec.tokenPrepareForDecode(); err != nil {
+		return err
+	}
+	if !dec.tokenValueAllowed() {
+		return dec.tokenError()
+	}
+	if err := dec.skipSpace(); err != nil {
+		return dec.unexpectedEOF()
+	}
+
+	var err error
+	if c := dec.buf[dec.scanp]; c == OB || c == LB || c == DQ || (c == SQ && dec.opts.JSON5) {
+		err = dec.skipComposite()
+	} else {
+		err = dec.skipScalar()
+	}
+	if err == nil {
+		dec.tokenValueEnd()
+	}
+	return err
+}
+
+func (dec *Decoder) skipScalar() error {
+	n := 0
+	for {
+		for dec.scanp < len(dec.buf) && isScalarByte(dec.buf[dec.scanp]) {
+			dec.scanp++
+			n++
+		}
+		if dec.scanp < len(dec.buf) || dec.err != nil {
+			break
+		}
+		dec.refill()
+	}
+	if n == 0 {
+		return dec.tokenError()
+	}
+	return nil
+}
+
+// 对象, 数组和字符串. 状态在多次 refill 之间保留
+func (dec *Decoder) skipComposite() error {
+	var (
+		depth   int
+		quote   byte
+		escaped bool
+		slash   bool // 上一个字符是注释开头的 /
+		star    bool // 块注释中上一个字符是 *
+		comment = noComment
+	)
+
+	for {
+		for dec.scanp < len(dec.buf) {
+			c := dec.buf[dec.scanp]
+			dec.scanp++
+
+			switch {
+			case quote != 0:
+				if escaped {
+					escaped = false
+				} else if c == BACKSLASH {
+					escaped = true
+				} else if c == quote {
+					quote = 0
+					if depth == 0 {
+						return nil
+					}
+				}
+				continue
+			case comment == lineComment:
+				if c == LINE_BREAK {
+					comment = noComment
+				}
+				continue
+			case comment == blockComment:
+				if star && c == SLASH {
+					comment = noComment
+				}
+				star = c == '*'
+				continue
+			case slash:
+				slash = false
+				if c == SLASH {
+					comment = lineComment
+					continue
+				}
+				if c == '*' {
+					comment, star = blockComment, false
+					continue
+				}
+			}
+
+			switch c {
+			case DQ:
+				quote = c
+			case SQ:
+				if dec.opts.JSON5 {
+					quote = c
+				}
+			case OB, LB:
+				depth++
+			case CB, RB:
+				depth--
+				if depth <= 0 {
+					return nil
+				}
+			case SLASH:
+				slash = dec.opts.AllowComments
+			}
+		}
+		if dec.err != nil {
+			if dec.err == io.EOF {
+				return io.ErrUnexpectedEOF // 值已经读了一部分
+			}
+			return dec.err
+		}
+		dec.refill()
+	}
+}
+
+func isScalarByte(c byte) bool {
+	return c == PLUS || c == MINUS || c == DECIMAL_POINT || isDigit(c) || (c|0x20 >= 'a' && c|0x20 <= 'z')
+}
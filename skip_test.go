@@ -0,0 +1,124 @@
+package yjson
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// 用 Token 遍历对象, 跳过 skip 中的键的值, 其余的值用 ReadValue 读取
+func TestSkip(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  ParseOptions
+		want  string
+	}{
+		{"scalars", `{"a": 1, "b": true, "c": null, "d": -1.5e3}`, ParseOptions{}, `a b c d`},
+		{"containers", `{"a": {"x": [1, {"y": "}"}]}, "b": [[], {}], "keep": 1}`, ParseOptions{}, `a b keep=1`},
+		{"strings", `{"a": "x\"]}", "b": "\\", "keep": "k"}`, ParseOptions{}, `a b keep="k"`},
+		{"comments", "{\"a\": [1, // ]\n 2 /* } */], \"keep\": 3}", ParseOptions{AllowComments: true}, `a keep=3`},
+		{"slash in string", `{"a": ["//", "/*"], "keep": 4}`, ParseOptions{AllowComments: true}, `a keep=4`},
+		{"json5", `{a: {'b': '}'}, keep: 5}`, ParseOptions{JSON5: true}, `a keep=5`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := NewDecoder(iotest.OneByteReader(strings.NewReader(tt.input)))
+			dec.SetOptions(tt.opts)
+			if _, err := dec.Token(); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if key == "keep" {
+					data, err := dec.ReadValue()
+					if err != nil {
+						t.Fatal(err)
+					}
+					got = append(got, "keep="+string(data))
+					continue
+				}
+				if err := dec.Skip(); err != nil {
+					t.Fatalf("skip %v: %v", key, err)
+				}
+				got = append(got, key.(string))
+			}
+			if tok, err := dec.Token(); err != nil || tok != Delim('}') {
+				t.Fatalf("end: got %v, %v", tok, err)
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("got %s, want %s", strings.Join(got, " "), tt.want)
+			}
+		})
+	}
+}
+
+func TestSkipTopLevel(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(`{"a": [1]} 12 "s" [2] true`))
+	for i := 0; i < 4; i++ {
+		if err := dec.Skip(); err != nil {
+			t.Fatalf("skip %d: %v", i, err)
+		}
+	}
+	var v Value
+	if err := dec.Decode(&v); err != nil || mustEncode(t, &v) != `true` {
+		t.Errorf("got %v", err)
+	}
+	if err := dec.Skip(); err != io.EOF {
+		t.Errorf("end of input: got %v", err)
+	}
+}
+
+func TestSkipErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"a": [1, 2`, "unexpected EOF"},
+		{`"abc`, "unexpected EOF"},
+		{`]`, "unexpected ']' at offset 0"},
+		{`,`, "unexpected ',' at offset 0"},
+	}
+	for _, tt := range tests {
+		err := NewDecoder(strings.NewReader(tt.input)).Skip()
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: got %v, want %s", tt.input, err, tt.want)
+		}
+	}
+
+	// 在对象的键的位置不能跳过值
+	dec := NewDecoder(strings.NewReader(`{"a": 1}`))
+	dec.Token()
+	if err := dec.Skip(); err == nil {
+		t.Errorf("skip at key: expected an error")
+	}
+}
+
+// 跳过很大的值时缓冲区不会增长
+func TestSkipLargeValue(t *testing.T) {
+	var b bytes.Buffer
+	b.WriteString(`{"big": [`)
+	for b.Len() < 1<<20 {
+		b.WriteString(`{"s": "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxx"},`)
+	}
+	b.WriteString(`0], "n": 1}`)
+
+	dec := NewDecoder(&b)
+	dec.Token()
+	dec.Token()
+	if err := dec.Skip(); err != nil {
+		t.Fatal(err)
+	}
+	if cap(dec.buf) > 4*readSize {
+		t.Errorf("buffer grew to %d bytes", cap(dec.buf))
+	}
+	if key, err := dec.Token(); err != nil || key != "n" {
+		t.Errorf("got %v, %v", key, err)
+	}
+}
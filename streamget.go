@@ -0,0 +1,97 @@
+package yjson
+
+import "io"
+
+// 按 Get 的路径语法从 r 中边读边提取, 每找到一个匹配的值就解析后传给 fn.
+// 路径之外的值只扫描后跳过, 不构建 Value, 内存占用只与匹配值的大小有关.
+// r 中可以有多个首尾相连或以空白分隔的文档 (如 NDJSON), 每个文档分别匹配;
+// 对象有重复的键时每一个都会匹配. 路径不存在时不调用 fn, 输入有语法错误时返回错误.
+// 含有 * 或 .. 的路径按 GetAll 的顺序逐个传给 fn, 如 items.* 逐个读取数组元素;
+// .. 匹配到的容器需要完整解析后才能在其中继续查找
+func StreamGet(r io.Reader, path string, fn func(v *Value)) error {
+	return StreamGetWithOptions(r, path, ParseOptions{}, fn)
+}
+
+func StreamGetWithOptions(r io.Reader, path string, opts ParseOptions, fn func(v *Value)) error {
+	segments, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	return streamGet(r, segments, opts, fn)
+}
+
+func streamGet(r io.Reader, segments []pathSegment, opts ParseOptions, fn func(v *Value)) error {
+	dec := NewDecoder(r)
+	dec.SetOptions(opts)
+	for dec.More() {
+		if err := dec.streamMatch(segments, fn); err != nil {
+			return err
+		}
+	}
+	// More 在语法错误时也返回 false, 剩余的内容交给 Token 报告
+	if _, err := dec.Token(); err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// 读取下一个值并在其中匹配 segments
+func (dec *Decoder) streamMatch(segments []pathSegment, fn func(v *Value)) error {
+	if len(segments) == 0 {
+		v := &Value{}
+		if err := dec.Decode(v); err != nil {
+			return err
+		}
+		fn(v)
+		return nil
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	delim, ok := tok.(Delim)
+	if !ok {
+		// 标量中没有下一段
+		return nil
+	}
+	seg := segments[0]
+	for n := 0; dec.More(); n++ {
+		match := false
+		if delim == OB {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key, _ := tok.(string)
+			match = seg.matchKey(key)
+		} else {
+			match = seg.matchIndex(n)
+		}
+		switch {
+		case match && seg.recursive:
+			// 成员本身匹配后还要在其中继续查找, 只能完整解析
+			v := &Value{}
+			if err := dec.Decode(v); err != nil {
+				return err
+			}
+			emit := func(v *Value) bool {
+				fn(v)
+				return true
+			}
+			matchPath(v, segments[1:], emit)
+			matchPath(v, segments, emit)
+		case match:
+			err = dec.streamMatch(segments[1:], fn)
+		case seg.recursive:
+			err = dec.streamMatch(segments, fn)
+		default:
+			err = dec.Skip()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err = dec.Token()
+	return err
+}
@@ -0,0 +1,99 @@
+package yjson
+
+import (
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func streamGetAll(t *testing.T, input, path string) ([]string, error) {
+	t.Helper()
+	var got []string
+	err := StreamGet(iotest.OneByteReader(strings.NewReader(input)), path, func(v *Value) {
+		got = append(got, mustEncode(t, v))
+	})
+	return got, err
+}
+
+// 与 GetAll 的结果和顺序相同
+func TestStreamGetMatchesGetAll(t *testing.T) {
+	paths := []string{
+		"", "users", "users[1].address.city", "$.users[0].name", "users[1].tags[1]", `["a.b"]`,
+		"a.b", `\*`, `["q\"k"]`, "users[2]", "users.x", "a[0]", "users[*].name", "users.*",
+		"*", "..city", "..tags[0]", "users..name", "..*", "..[0]",
+	}
+	v := mustParse(t, pathDoc)
+	for _, path := range paths {
+		got, err := streamGetAll(t, pathDoc, path)
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+			continue
+		}
+		var want []string
+		for _, m := range v.GetAll(path) {
+			want = append(want, mustEncode(t, m))
+		}
+		if strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("%s:\ngot  %v\nwant %v", path, got, want)
+		}
+	}
+}
+
+func TestStreamGet(t *testing.T) {
+	tests := []struct {
+		input string
+		path  string
+		want  string // 匹配值编码后以空格连接, 以 "error: " 开头时为错误信息
+	}{
+		// 多个文档分别匹配
+		{"{\"id\":1}\n{\"id\":2}\n{\"x\":3}\n{\"id\":4}", "id", `1 2 4`},
+		{`{"id":1}{"id":2} [3]`, "id", `1 2`},
+		{`1 "s" null`, "a", ``},
+		{`1 "s" null`, "", `1 "s" null`},
+		{`{"a":1,"a":2}`, "a", `1 2`},
+		{`{"items":[{"n":1},{"n":2},{"n":3}]}`, "items.*.n", `1 2 3`},
+		{`{"items":[{"n":1},{"n":2},{"n":3}]}`, "items[1]", `{"n":2}`},
+		{`{"a":{"a":{"a":1}}}`, "..a", `{"a":{"a":1}} {"a":1} 1`},
+		{`{"skip":{"deep":[[{"x":"}"}]]},"k":"v"}`, "k", `"v"`},
+		{``, "a", ``},
+		{`{"a":[1,2}`, "b", `error: `},
+		{`{"a":1} x`, "a", `error: `},
+		{`{"a":`, "a", `error: EOF`},
+		{`{"a":1}`, "a[", `error: invalid path "a["`},
+	}
+	for _, tt := range tests {
+		got, err := streamGetAll(t, tt.input, tt.path)
+		if msg, ok := strings.CutPrefix(tt.want, "error: "); ok {
+			if err == nil || !strings.Contains(err.Error(), msg) {
+				t.Errorf("%s in %q: got %v, %v, want error %q", tt.path, tt.input, got, err, msg)
+			}
+			continue
+		}
+		if err != nil || strings.Join(got, " ") != tt.want {
+			t.Errorf("%s in %q: got %v, %v, want %s", tt.path, tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestStreamGetOptions(t *testing.T) {
+	input := "// comment\n{\"a\": [1, 2,],}"
+	if err := StreamGet(strings.NewReader(input), "a", func(*Value) {}); err == nil {
+		t.Errorf("default options: expected an error")
+	}
+	var got []string
+	opts := ParseOptions{AllowComments: true, AllowTrailingCommas: true}
+	err := StreamGetWithOptions(strings.NewReader(input), "a.*", opts, func(v *Value) {
+		got = append(got, mustEncode(t, v))
+	})
+	if err != nil || strings.Join(got, " ") != "1 2" {
+		t.Errorf("got %v, %v", got, err)
+	}
+
+	got = got[:0]
+	p := MustCompilePath("a[1]")
+	if err := p.StreamGet(strings.NewReader(`{"a":[1,2]}`), func(v *Value) {
+		got = append(got, mustEncode(t, v))
+	}); err != nil || strings.Join(got, " ") != "2" {
+		t.Errorf("Path.StreamGet: got %v, %v", got, err)
+	}
+}
@@ -0,0 +1,61 @@
+package yjson
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// 一次处理 8 个字节 (SWAR): 把 8 个字节读成一个 uint64, 用整数运算同时比较每个字节
+const (
+	swarOnes = 0x0101010101010101
+	swarHigh = 0x8080808080808080
+)
+
+// 等于 b 的字节最高位为 1. 只保证最低的一个标记准确, 足够找到第一个匹配
+func swarEqual(x uint64, b byte) uint64 {
+	y := x ^ (swarOnes * uint64(b))
+	return (y - swarOnes) &^ y & swarHigh
+}
+
+// 小于 n (n <= 128) 的字节最高位为 1, 同样只保证最低的标记准确
+func swarLess(x uint64, n byte) uint64 {
+	return (x - swarOnes*uint64(n)) &^ x & swarHigh
+}
+
+// 不为 0 的字节最高位为 1, 每个字节都准确 (低 7 位加 0x7F 不会进位到相邻字节)
+func swarNonZero(y uint64) uint64 {
+	const low = 0x7F7F7F7F7F7F7F7F
+	return ((y & low) + low | y) & swarHigh
+}
+
+// 从 i 开始第一个 quote, 反斜杠或控制字符的下标, 没有时返回 len(buf)
+func stringRunEnd(buf []byte, i int, quote byte) int {
+	for i+8 <= len(buf) {
+		x := binary.LittleEndian.Uint64(buf[i:])
+		if m := swarEqual(x, quote) | swarEqual(x, BACKSLASH) | swarLess(x, BLANK_SPACE); m != 0 {
+			return i + bits.TrailingZeros64(m)/8
+		}
+		i += 8
+	}
+	for ; i < len(buf); i++ {
+		if c := buf[i]; c == quote || c == BACKSLASH || c < BLANK_SPACE {
+			return i
+		}
+	}
+	return i
+}
+
+// 从 i 开始跳过连续的空格, 缩进通常是成段的空格
+func skipBlanks(buf []byte, i int) int {
+	for i+8 <= len(buf) {
+		y := binary.LittleEndian.Uint64(buf[i:]) ^ (swarOnes * BLANK_SPACE)
+		if m := swarNonZero(y); m != 0 {
+			return i + bits.TrailingZeros64(m)/8
+		}
+		i += 8
+	}
+	for i < len(buf) && buf[i] == BLANK_SPACE {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,117 @@
+package yjson
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func naiveStringRunEnd(buf []byte, i int, quote byte) int {
+	for ; i < len(buf); i++ {
+		if c := buf[i]; c == quote || c == BACKSLASH || c < BLANK_SPACE {
+			return i
+		}
+	}
+	return i
+}
+
+func naiveSkipBlanks(buf []byte, i int) int {
+	for i < len(buf) && buf[i] == BLANK_SPACE {
+		i++
+	}
+	return i
+}
+
+func TestStringRunEnd(t *testing.T) {
+	tests := []struct {
+		input string
+		quote byte
+		want  int
+	}{
+		{``, DQ, 0},
+		{`abc`, DQ, 3},
+		{`abc"`, DQ, 3},
+		{`abcdefghijklmnop"`, DQ, 16},
+		{`abcdefg\h`, DQ, 7},
+		{"abcdefghij\nk", DQ, 10},
+		{`ab'cd"`, DQ, 5},
+		{`ab"cd'`, SQ, 5},
+		{"é中文字符😀\"", DQ, 18},
+		{"\xff\xfe\x80\x81\x82\x83\x84\x85\"", DQ, 8},
+		{"\x7f\x7f\x7f\x7f\x7f\x7f\x7f\x7f\x1f", DQ, 8},
+		{strings.Repeat("x", 63) + `"`, DQ, 63},
+	}
+	for _, tt := range tests {
+		if got := stringRunEnd([]byte(tt.input), 0, tt.quote); got != tt.want {
+			t.Errorf("%q: got %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+// 所有位置和长度都与逐字节的实现一致, 重点是 8 字节块的边界和最高位为 1 的字节
+func TestSWARMatchesNaive(t *testing.T) {
+	alphabet := []byte{'a', ' ', '"', '\'', '\\', '\n', 0, 0x1f, 0x20, 0x21, 0x7f, 0x80, 0xa2, 0xdc, 0xff}
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 2000; n++ {
+		buf := make([]byte, rng.Intn(40))
+		for i := range buf {
+			// 多数字节是普通字符, 使匹配出现在不同的位置
+			if rng.Intn(4) == 0 {
+				buf[i] = alphabet[rng.Intn(len(alphabet))]
+			} else {
+				buf[i] = byte('a' + rng.Intn(26))
+				if rng.Intn(3) == 0 {
+					buf[i] = BLANK_SPACE
+				}
+			}
+		}
+		for i := 0; i <= len(buf); i++ {
+			for _, q := range []byte{DQ, SQ} {
+				if got, want := stringRunEnd(buf, i, q), naiveStringRunEnd(buf, i, q); got != want {
+					t.Fatalf("stringRunEnd(%q, %d, %c): got %d, want %d", buf, i, q, got, want)
+				}
+			}
+			if got, want := skipBlanks(buf, i), naiveSkipBlanks(buf, i); got != want {
+				t.Fatalf("skipBlanks(%q, %d): got %d, want %d", buf, i, got, want)
+			}
+		}
+	}
+}
+
+// swarNonZero 对每个字节都准确
+func TestSWARNonZero(t *testing.T) {
+	for b := 0; b < 256; b++ {
+		for pos := 0; pos < 8; pos++ {
+			var bytes [8]byte
+			bytes[pos] = byte(b)
+			m := swarNonZero(binary.LittleEndian.Uint64(bytes[:]))
+			var want uint64
+			if b != 0 {
+				want = 0x80 << (8 * pos)
+			}
+			if m != want {
+				t.Fatalf("byte %#x at %d: got %#x, want %#x", b, pos, m, want)
+			}
+		}
+	}
+}
+
+func TestSkipBlanks(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{``, 0},
+		{`x`, 0},
+		{`   x`, 3},
+		{strings.Repeat(" ", 8), 8},
+		{strings.Repeat(" ", 17) + "\t", 17},
+		{strings.Repeat(" ", 7) + "\xa0", 7},
+	}
+	for _, tt := range tests {
+		if got := skipBlanks([]byte(tt.input), 0); got != tt.want {
+			t.Errorf("%q: got %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package yjson
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// 时间布局的特殊取值, 表示以 Unix 时间戳数字编码
+const (
+	TimeUnix      = "unix"      // 秒, 解码时允许小数
+	TimeUnixMilli = "unixmilli" // 毫秒
+	TimeUnixNano  = "unixnano"  // 纳秒
+)
+
+var timeReflectType = reflect.TypeOf(time.Time{})
+
+func isUnixLayout(layout string) bool {
+	return layout == TimeUnix || layout == TimeUnixMilli || layout == TimeUnixNano
+}
+
+// 字符串按 layout 解析, layout 为空时依次尝试 UnmarshalOptions.TimeLayouts,
+// 默认 RFC 3339. 数字是 Unix 时间戳, 单位由 layout 决定, 默认为秒. null 保持原值
+func (d *decodeState) decodeTime(path string, j *Value, rv reflect.Value, layout string) error {
+	var (
+		t   time.Time
+		err error
+	)
+	switch j.Type() {
+	case JSON_NULL:
+		return nil
+	case JSON_STRING:
+		t, err = d.parseTime(j.str, layout)
+	case JSON_NUMBER:
+		t, err = unixTime(j, layout)
+	default:
+		return d.typeError(path, j, rv.Type())
+	}
+	if err != nil {
+		if path == "" {
+			return fmt.Errorf("unmarshal: %v", err)
+		}
+		return fmt.Errorf("unmarshal: %v at %s", err, path)
+	}
+	rv.Set(reflect.ValueOf(t))
+	return nil
+}
+
+func (d *decodeState) parseTime(s, layout string) (time.Time, error) {
+	if isUnixLayout(layout) {
+		return time.Time{}, fmt.Errorf("expect %s timestamp number, but get string %q", layout, s)
+	}
+
+	layouts := []string{time.RFC3339Nano}
+	if layout != "" {
+		layouts = []string{layout}
+	} else if len(d.opts.TimeLayouts) > 0 {
+		layouts = d.opts.TimeLayouts
+	}
+	for _, l := range layouts {
+		if t, err := time.Parse(l, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q as time", s)
+}
+
+func unixTime(j *Value, layout string) (time.Time, error) {
+	if i, err := j.Int64(); err == nil {
+		switch layout {
+		case TimeUnixMilli:
+			return time.UnixMilli(i), nil
+		case TimeUnixNano:
+			return time.Unix(0, i), nil
+		}
+		return time.Unix(i, 0), nil
+	}
+	if layout != "" && layout != TimeUnix {
+		return time.Time{}, fmt.Errorf("timestamp %v is not an integer", j.number())
+	}
+
+	f, err := j.Float64()
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return time.Time{}, fmt.Errorf("invalid timestamp %v", j.number())
+	}
+	sec := math.Floor(f)
+	return time.Unix(int64(sec), int64(math.Round((f-sec)*1e9))), nil
+}
+
+// layout 为空时使用 MarshalOptions.TimeLayout, 默认 RFC 3339, 与 time.Time.MarshalJSON 相同
+func (m *marshalState) marshalTime(rv reflect.Value, layout string) *Value {
+	t := rv.Interface().(time.Time)
+	if layout == "" {
+		layout = m.opts.TimeLayout
+	}
+	switch layout {
+	case "":
+		return NewString(t.Format(time.RFC3339Nano))
+	case TimeUnix:
+		return NewInt(t.Unix())
+	case TimeUnixMilli:
+		return NewInt(t.UnixMilli())
+	case TimeUnixNano:
+		return NewInt(t.UnixNano())
+	}
+	return NewString(t.Format(layout))
+}
@@ -0,0 +1,118 @@
+package yjson
+
+import (
+	"testing"
+	"time"
+)
+
+type timeFields struct {
+	At    time.Time  `json:"at"`
+	Day   time.Time  `json:"day" layout:"2006-01-02"`
+	Sec   time.Time  `json:"sec" layout:"unix"`
+	Milli time.Time  `json:"milli" layout:"unixmilli"`
+	Nano  *time.Time `json:"nano" layout:"unixnano"`
+}
+
+func TestDecodeTime(t *testing.T) {
+	ref := time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)
+	tests := []struct {
+		input string
+		get   func(v *timeFields) time.Time
+		want  time.Time
+	}{
+		{`{"at":"2023-11-14T22:13:20Z"}`, func(v *timeFields) time.Time { return v.At }, ref},
+		{`{"at":"2023-11-15T06:13:20.5+08:00"}`, func(v *timeFields) time.Time { return v.At }, ref.Add(500 * time.Millisecond)},
+		{`{"at":1700000000}`, func(v *timeFields) time.Time { return v.At }, ref},
+		{`{"at":1700000000.25}`, func(v *timeFields) time.Time { return v.At }, ref.Add(250 * time.Millisecond)},
+		{`{"at":-1.5}`, func(v *timeFields) time.Time { return v.At }, time.Unix(-2, 5e8)},
+		{`{"day":"2023-11-14"}`, func(v *timeFields) time.Time { return v.Day }, time.Date(2023, 11, 14, 0, 0, 0, 0, time.UTC)},
+		{`{"sec":1700000000}`, func(v *timeFields) time.Time { return v.Sec }, ref},
+		{`{"milli":1700000000123}`, func(v *timeFields) time.Time { return v.Milli }, ref.Add(123 * time.Millisecond)},
+		{`{"nano":1700000000000000001}`, func(v *timeFields) time.Time { return *v.Nano }, ref.Add(1)},
+	}
+	for _, tt := range tests {
+		var v timeFields
+		if err := Unmarshal([]byte(tt.input), &v); err != nil {
+			t.Errorf("%s: %v", tt.input, err)
+			continue
+		}
+		if got := tt.get(&v); !got.Equal(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.input, got, tt.want)
+		}
+	}
+
+	// null 保持原值, 指针置为 nil
+	v := timeFields{At: ref, Nano: &ref}
+	if err := Unmarshal([]byte(`{"at":null,"nano":null}`), &v); err != nil || !v.At.Equal(ref) || v.Nano != nil {
+		t.Errorf("null: got %+v, %v", v, err)
+	}
+}
+
+func TestDecodeTimeLayouts(t *testing.T) {
+	opts := UnmarshalOptions{TimeLayouts: []string{"2006-01-02", time.RFC1123}}
+	for _, input := range []string{`"2023-11-14"`, `"Tue, 14 Nov 2023 00:00:00 UTC"`} {
+		var got time.Time
+		if err := UnmarshalWithOptions([]byte(input), &got, opts); err != nil {
+			t.Errorf("%s: %v", input, err)
+			continue
+		}
+		if got.Year() != 2023 || got.YearDay() != 318 {
+			t.Errorf("%s: got %v", input, got)
+		}
+	}
+	// 字段的 layout 标签优先于选项
+	var v timeFields
+	if err := UnmarshalWithOptions([]byte(`{"day":"Tue, 14 Nov 2023 00:00:00 UTC"}`), &v, opts); err == nil {
+		t.Errorf("layout tag should take precedence")
+	}
+}
+
+func TestDecodeTimeErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"at":"yesterday"}`, `unmarshal: cannot parse "yesterday" as time at at`},
+		{`{"at":true}`, `unmarshal: cannot unmarshal boolean into time.Time at at`},
+		{`{"day":"2023-11-14T00:00:00Z"}`, `unmarshal: cannot parse "2023-11-14T00:00:00Z" as time at day`},
+		{`{"sec":"1700000000"}`, `unmarshal: expect unix timestamp number, but get string "1700000000" at sec`},
+		{`{"milli":1.5}`, `unmarshal: timestamp 1.5 is not an integer at milli`},
+		{`{"nano":[]}`, `unmarshal: cannot unmarshal array into time.Time at nano`},
+	}
+	for _, tt := range tests {
+		var v timeFields
+		err := Unmarshal([]byte(tt.input), &v)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: got %v, want %s", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestMarshalTime(t *testing.T) {
+	ref := time.Date(2023, 11, 14, 22, 13, 20, 123000000, time.UTC)
+	v := timeFields{At: ref, Day: ref, Sec: ref, Milli: ref, Nano: &ref}
+	got, err := Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"at":"2023-11-14T22:13:20.123Z","day":"2023-11-14","sec":1700000000,"milli":1700000000123,"nano":1700000000123000000}`
+	if string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	// 标签优先于 MarshalOptions.TimeLayout
+	got, _ = MarshalWithOptions(v, MarshalOptions{TimeLayout: TimeUnix})
+	want = `{"at":1700000000,"day":"2023-11-14","sec":1700000000,"milli":1700000000123,"nano":1700000000123000000}`
+	if string(got) != want {
+		t.Errorf("TimeLayout: got %s, want %s", got, want)
+	}
+
+	// 编解码对称
+	var back timeFields
+	if err := Unmarshal(got, &back); err != nil {
+		t.Fatal(err)
+	}
+	if !back.Milli.Equal(ref) || !back.Nano.Equal(ref) || !back.Sec.Equal(ref.Truncate(time.Second)) {
+		t.Errorf("round trip: got %+v", back)
+	}
+}
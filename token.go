@@ -0,0 +1,239 @@
+package yjson
+
+import (
+	"fmt"
+	"io"
+)
+
+// Token 返回的值: Delim, string, Number, bool 或 nil (null)
+type Token interface{}
+
+// 对象和数组的边界 { } [ ]
+type Delim byte
+
+func (d Delim) String() string {
+	return string(d)
+}
+
+// 与 encoding/json 相同, 逗号和冒号由状态机检查后跳过
+const (
+	tokenTopValue = iota
+	tokenArrayStart
+	tokenArrayValue
+	tokenArrayComma
+	tokenObjectStart
+	tokenObjectKey
+	tokenObjectColon
+	tokenObjectValue
+	tokenObjectComma
+)
+
+// 返回输入中的下一个 token, 不构建 Value 树. 数字以 Number 返回, 保留源文本.
+// 可以和 Decode / ReadValue 混用. 输入结束时返回 io.EOF
+func (dec *Decoder) Token() (Token, error) {
+	dec.resume()
+	tok, j, err := dec.token()
+	if err != nil || j == nil {
+		return tok, err
+	}
+	if j.Type() == JSON_NUMBER {
+		return Number(j.raw), nil
+	}
+	return j.Interface(), nil
+}
+
+// 分隔符和键以 Token 返回, 标量以 Value 返回
+func (dec *Decoder) token() (Token, *Value, error) {
+	for {
+		c, err := dec.peek()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch c {
+		case LB, OB:
+			if !dec.tokenValueAllowed() {
+				return nil, nil, dec.tokenError()
+			}
+			dec.scanp++
+			dec.tokenStack = append(dec.tokenStack, dec.tokenState)
+			if c == LB {
+				dec.tokenState = tokenArrayStart
+			} else {
+				dec.tokenState = tokenObjectStart
+			}
+			return Delim(c), nil, nil
+		case RB:
+			if dec.tokenState != tokenArrayStart && dec.tokenState != tokenArrayComma &&
+				!(dec.tokenState == tokenArrayValue && dec.opts.AllowTrailingCommas) {
+				return nil, nil, dec.tokenError()
+			}
+			dec.scanp++
+			dec.tokenPop()
+			return Delim(c), nil, nil
+		case CB:
+			if dec.tokenState != tokenObjectStart && dec.tokenState != tokenObjectComma &&
+				!(dec.tokenState == tokenObjectKey && dec.opts.AllowTrailingCommas) {
+				return nil, nil, dec.tokenError()
+			}
+			dec.scanp++
+			dec.tokenPop()
+			return Delim(c), nil, nil
+		case DOT:
+			switch dec.tokenState {
+			case tokenArrayComma:
+				dec.tokenState = tokenArrayValue
+			case tokenObjectComma:
+				dec.tokenState = tokenObjectKey
+			default:
+				return nil, nil, dec.tokenError()
+			}
+			dec.scanp++
+		case VALUE_SEPARATOR:
+			if dec.tokenState != tokenObjectColon {
+				return nil, nil, dec.tokenError()
+			}
+			dec.scanp++
+			dec.tokenState = tokenObjectValue
+		default:
+			if dec.tokenState == tokenObjectStart || dec.tokenState == tokenObjectKey {
+				key, err := dec.readKey()
+				if err != nil {
+					return nil, nil, err
+				}
+				dec.tokenState = tokenObjectColon
+				return key, nil, nil
+			}
+			if !dec.tokenValueAllowed() {
+				return nil, nil, dec.tokenError()
+			}
+			j, err := dec.readScalarValue()
+			return nil, j, err
+		}
+	}
+}
+
+// 跳过空白后查看下一个字符, 在数组或对象内部遇到输入结束时返回 io.ErrUnexpectedEOF
+func (dec *Decoder) peek() (byte, error) {
+	if err := dec.skipSpace(); err != nil {
+		return 0, dec.unexpectedEOF()
+	}
+	return dec.buf[dec.scanp], nil
+}
+
+func (dec *Decoder) tokenValueAllowed() bool {
+	switch dec.tokenState {
+	case tokenTopValue, tokenArrayStart, tokenArrayValue, tokenObjectValue:
+		return true
+	}
+	return false
+}
+
+func (dec *Decoder) tokenValueEnd() {
+	switch dec.tokenState {
+	case tokenArrayStart, tokenArrayValue:
+		dec.tokenState = tokenArrayComma
+	case tokenObjectValue:
+		dec.tokenState = tokenObjectComma
+	}
+}
+
+func (dec *Decoder) tokenPop() {
+	n := len(dec.tokenStack) - 1
+	dec.tokenState = dec.tokenStack[n]
+	dec.tokenStack = dec.tokenStack[:n]
+	dec.tokenValueEnd()
+}
+
+// Decode 前跳过元素之间的逗号和键后的冒号
+func (dec *Decoder) tokenPrepareForDecode() error {
+	switch dec.tokenState {
+	case tokenArrayComma:
+		c, err := dec.peek()
+		if err != nil {
+			return err
+		}
+		if c != DOT {
+			return dec.tokenError()
+		}
+		dec.scanp++
+		dec.tokenState = tokenArrayValue
+	case tokenObjectColon:
+		c, err := dec.peek()
+		if err != nil {
+			return err
+		}
+		if c != VALUE_SEPARATOR {
+			return dec.tokenError()
+		}
+		dec.scanp++
+		dec.tokenState = tokenObjectValue
+	}
+	return nil
+}
+
+func (dec *Decoder) tokenError() error {
+	if dec.scanp >= len(dec.buf) {
+		return io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("unexpected %q at offset %d", dec.buf[dec.scanp], dec.InputOffset())
+}
+
+// 字符串, 数字, true, false, null
+func (dec *Decoder) readScalarValue() (*Value, error) {
+	data, err := dec.readValue()
+	if err != nil {
+		return nil, err
+	}
+	j, err := parseDocument(dec.ctx, append([]byte(nil), data...), dec.opts)
+	if err != nil {
+		return nil, err
+	}
+	if t := j.Type(); t == JSON_OBJECT || t == JSON_ARRAY {
+		// readValue 只会在当前字符是 { [ 时返回容器, 这里不会出现
+		return nil, fmt.Errorf("unexpected %s at offset %d", t, dec.InputOffset())
+	}
+	dec.tokenValueEnd()
+	return j, nil
+}
+
+// 对象的键. JSON5 下允许不带引号的标识符
+func (dec *Decoder) readKey() (string, error) {
+	c := dec.buf[dec.scanp]
+	if dec.opts.JSON5 && c != DQ && c != SQ {
+		for {
+			n := identifierEnd(dec.buf[dec.scanp:])
+			if n < len(dec.buf)-dec.scanp || dec.atEOF() {
+				if n == 0 {
+					return "", dec.tokenError()
+				}
+				key := string(dec.buf[dec.scanp : dec.scanp+n])
+				dec.scanp += n
+				return key, nil
+			}
+			dec.refill()
+		}
+	}
+	if c != DQ && !(c == SQ && dec.opts.JSON5) {
+		return "", dec.tokenError()
+	}
+
+	data, err := dec.readValue()
+	if err != nil {
+		return "", err
+	}
+	j, err := parseDocument(dec.ctx, data, dec.opts)
+	if err != nil {
+		return "", err
+	}
+	return j.String()
+}
+
+func identifierEnd(buf []byte) int {
+	for i, c := range buf {
+		if !(c == '_' || c == '$' || isDigit(c) || (c|0x20 >= 'a' && c|0x20 <= 'z') || c >= 0x80) {
+			return i
+		}
+	}
+	return len(buf)
+}
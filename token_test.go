@@ -0,0 +1,203 @@
+package yjson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// 依次读取所有 token, 以 "类型:值" 的形式返回
+func tokens(r io.Reader, opts ParseOptions) ([]string, error) {
+	dec := NewDecoder(r)
+	dec.SetOptions(opts)
+	var out []string
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, formatToken(tok))
+	}
+}
+
+func formatToken(tok interface{}) string {
+	switch t := tok.(type) {
+	case Delim:
+		return t.String()
+	case json.Delim:
+		return t.String()
+	case Number:
+		return "n:" + string(t)
+	case json.Number:
+		return "n:" + string(t)
+	case string:
+		return fmt.Sprintf("s:%q", t)
+	}
+	return fmt.Sprint(tok)
+}
+
+func TestToken(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"scalars", ` 1 "a" true false null `, `n:1 s:"a" true false <nil>`},
+		{"empty containers", `[] {}`, `[ ] { }`},
+		{"object", `{"a": 1, "b": [2, {"c": null}]}`, `{ s:"a" n:1 s:"b" [ n:2 { s:"c" <nil> } ] }`},
+		{"numbers keep source", `[1.50, -0, 1e3]`, `[ n:1.50 n:-0 n:1e3 ]`},
+		{"escaped key", `{"a\"b": "c\\d"}`, `{ s:"a\"b" s:"c\\d" }`},
+		{"nested arrays", `[[[]],[]]`, `[ [ [ ] ] [ ] ]`},
+		{"concatenated", `{"a":1}[2]`, `{ s:"a" n:1 } [ n:2 ]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, r := range []io.Reader{strings.NewReader(tt.input), iotest.OneByteReader(strings.NewReader(tt.input))} {
+				got, err := tokens(r, ParseOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if strings.Join(got, " ") != tt.want {
+					t.Errorf("got %s, want %s", strings.Join(got, " "), tt.want)
+				}
+			}
+
+			// 与 encoding/json 的 token 序列相同
+			dec := json.NewDecoder(strings.NewReader(tt.input))
+			dec.UseNumber()
+			var std []string
+			for {
+				tok, err := dec.Token()
+				if err != nil {
+					break
+				}
+				std = append(std, formatToken(tok))
+			}
+			if strings.Join(std, " ") != tt.want {
+				t.Errorf("encoding/json gives %s", strings.Join(std, " "))
+			}
+		})
+	}
+}
+
+func TestTokenOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  ParseOptions
+		want  string
+	}{
+		{"trailing commas", `[1,] {"a":2,}`, ParseOptions{AllowTrailingCommas: true}, `[ n:1 ] { s:"a" n:2 }`},
+		{"comments", "[1, /* x */ 2] // end", ParseOptions{AllowComments: true}, `[ n:1 n:2 ]`},
+		{"json5 keys", `{a: 'x', $b_1: 2}`, ParseOptions{JSON5: true}, `{ s:"a" s:"x" s:"$b_1" n:2 }`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tokens(iotest.OneByteReader(strings.NewReader(tt.input)), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("got %s, want %s", strings.Join(got, " "), tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`[1 2]`, "unexpected '2' at offset 3"},
+		{`[1,]`, "unexpected ']' at offset 3"},
+		{`{"a" 1}`, "unexpected '1' at offset 5"},
+		{`{"a":1,}`, "unexpected '}' at offset 7"},
+		{`{1:2}`, "unexpected '1' at offset 1"},
+		{`]`, "unexpected ']' at offset 0"},
+		{`[1}`, "unexpected '}' at offset 2"},
+		{`:`, "unexpected ':' at offset 0"},
+		{`[1,`, io.ErrUnexpectedEOF.Error()},
+		{`{"a":`, io.ErrUnexpectedEOF.Error()},
+	}
+	for _, tt := range tests {
+		got, err := tokens(strings.NewReader(tt.input), ParseOptions{})
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: got %v (after %q), want %s", tt.input, err, got, tt.want)
+		}
+	}
+}
+
+// Token 和 Decode 混用: 用 Token 进入数组, Decode 读取每个元素
+func TestTokenThenDecode(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(`{"items": [{"a":1}, [2], "x"], "n": 3}`))
+	var got []string
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, formatToken(tok))
+		if tok == Delim('[') {
+			for dec.More() {
+				var v Value
+				if err := dec.Decode(&v); err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, mustEncode(t, &v))
+			}
+		}
+	}
+	want := `{ s:"items" [ {"a":1} [2] "x" ] s:"n" n:3 }`
+	if strings.Join(got, " ") != want {
+		t.Errorf("got %s, want %s", strings.Join(got, " "), want)
+	}
+}
+
+// 允许结尾的逗号时 More 在 ",]" 前返回 false
+func TestTokenMoreTrailingComma(t *testing.T) {
+	for _, input := range []string{`[1, 2, ]`, `{"a": [1,], "b": 2,}`} {
+		dec := NewDecoder(iotest.OneByteReader(strings.NewReader(input)))
+		dec.SetOptions(ParseOptions{AllowTrailingCommas: true})
+		var got []string
+		for {
+			tok, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: %v", input, err)
+			}
+			got = append(got, formatToken(tok))
+			if tok == Delim('[') {
+				for dec.More() {
+					data, err := dec.ReadValue()
+					if err != nil {
+						t.Fatalf("%s: %v", input, err)
+					}
+					got = append(got, string(data))
+				}
+			}
+			if _, ok := tok.(string); ok && !dec.More() {
+				t.Errorf("%s: More returned false after key %v", input, tok)
+			}
+		}
+		want := map[string]string{
+			`[1, 2, ]`:             `[ 1 2 ]`,
+			`{"a": [1,], "b": 2,}`: `{ s:"a" [ 1 ] s:"b" n:2 }`,
+		}[input]
+		if strings.Join(got, " ") != want {
+			t.Errorf("%s: got %s, want %s", input, strings.Join(got, " "), want)
+		}
+	}
+}
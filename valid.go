@@ -0,0 +1,250 @@
+package yjson
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// 检查 data 是否恰好是一个符合 RFC 8259 的文档, 规则与 Strict 模式相同, 但只检查
+// 语法: 不构建 Value, 不分配内存, 也不检查数字是否超出 float64 的范围
+func Valid(data []byte) bool {
+	v := validator{data: data}
+	i := v.value(v.space(0), 0)
+	return i >= 0 && v.space(i) == len(data)
+}
+
+// 每个方法从下标 i 开始检查一段语法, 返回之后的下标, 不合法时返回 -1
+type validator struct {
+	data []byte
+}
+
+func (v *validator) space(i int) int {
+	for i >= 0 && i < len(v.data) {
+		switch v.data[i] {
+		case BLANK_SPACE, HORIZONTAL_TAB, LINE_BREAK, CARRIAGE_RETURN:
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+func (v *validator) value(i, depth int) int {
+	if i < 0 || i >= len(v.data) {
+		return -1
+	}
+
+	switch c := v.data[i]; {
+	case c == OB:
+		return v.object(i, depth+1)
+	case c == LB:
+		return v.array(i, depth+1)
+	case c == DQ:
+		return v.string(i)
+	case c == 't':
+		return v.literal(i, TRUE)
+	case c == 'f':
+		return v.literal(i, FALSE)
+	case c == 'n':
+		return v.literal(i, NULL)
+	case c == MINUS || isDigit(c):
+		return v.number(i)
+	}
+	return -1
+}
+
+func (v *validator) literal(i int, lit string) int {
+	if len(v.data)-i < len(lit) || string(v.data[i:i+len(lit)]) != lit {
+		return -1
+	}
+	return i + len(lit)
+}
+
+func (v *validator) object(i, depth int) int {
+	if depth > DEFAULT_MAX_DEPTH {
+		return -1
+	}
+
+	i = v.space(i + 1)
+	if i < len(v.data) && v.data[i] == CB {
+		return i + 1
+	}
+	for {
+		if i >= len(v.data) || v.data[i] != DQ {
+			return -1
+		}
+		i = v.space(v.string(i))
+		if i < 0 || i >= len(v.data) || v.data[i] != VALUE_SEPARATOR {
+			return -1
+		}
+		i = v.value(v.space(i+1), depth)
+		if i < 0 {
+			return -1
+		}
+		i = v.space(i)
+		if i >= len(v.data) {
+			return -1
+		}
+		switch v.data[i] {
+		case DOT:
+			i = v.space(i + 1)
+		case CB:
+			return i + 1
+		default:
+			return -1
+		}
+	}
+}
+
+func (v *validator) array(i, depth int) int {
+	if depth > DEFAULT_MAX_DEPTH {
+		return -1
+	}
+
+	i = v.space(i + 1)
+	if i < len(v.data) && v.data[i] == RB {
+		return i + 1
+	}
+	for {
+		i = v.value(i, depth)
+		if i < 0 {
+			return -1
+		}
+		i = v.space(i)
+		if i >= len(v.data) {
+			return -1
+		}
+		switch v.data[i] {
+		case DOT:
+			i = v.space(i + 1)
+		case RB:
+			return i + 1
+		default:
+			return -1
+		}
+	}
+}
+
+func (v *validator) string(i int) int {
+	i++
+	for i < len(v.data) {
+		c := v.data[i]
+		switch {
+		case c == DQ:
+			return i + 1
+		case c < BLANK_SPACE:
+			return -1
+		case c == BACKSLASH:
+			i = v.escape(i + 1)
+			if i < 0 {
+				return -1
+			}
+		case c < utf8.RuneSelf:
+			i++
+		default:
+			r, size := utf8.DecodeRune(v.data[i:])
+			if r == utf8.RuneError && size == 1 {
+				return -1
+			}
+			i += size
+		}
+	}
+	return -1
+}
+
+// 反斜杠之后的转义, \u 的代理项必须成对出现
+func (v *validator) escape(i int) int {
+	if i >= len(v.data) {
+		return -1
+	}
+
+	switch v.data[i] {
+	case DQ, BACKSLASH, '/', 'b', 'f', 'n', 'r', 't':
+		return i + 1
+	case 'u':
+		r, ok := v.hex4(i + 1)
+		if !ok {
+			return -1
+		}
+		i += 5
+		if !utf16.IsSurrogate(r) {
+			return i
+		}
+		if r >= 0xDC00 || len(v.data)-i < 6 || v.data[i] != BACKSLASH || v.data[i+1] != 'u' {
+			return -1
+		}
+		r2, ok := v.hex4(i + 2)
+		if !ok || utf16.DecodeRune(r, r2) == utf8.RuneError {
+			return -1
+		}
+		return i + 6
+	}
+	return -1
+}
+
+func (v *validator) hex4(i int) (rune, bool) {
+	if len(v.data)-i < 4 {
+		return 0, false
+	}
+
+	var r rune
+	for _, b := range v.data[i : i+4] {
+		switch {
+		case b >= '0' && b <= '9':
+			r = r<<4 | rune(b-'0')
+		case b >= 'a' && b <= 'f':
+			r = r<<4 | rune(b-'a'+10)
+		case b >= 'A' && b <= 'F':
+			r = r<<4 | rune(b-'A'+10)
+		default:
+			return 0, false
+		}
+	}
+	return r, true
+}
+
+// number = [ minus ] int [ frac ] [ exp ]
+func (v *validator) number(i int) int {
+	if v.data[i] == MINUS {
+		i++
+	}
+
+	switch {
+	case i < len(v.data) && v.data[i] == '0':
+		i++
+	case i < len(v.data) && isDigit(v.data[i]):
+		i = v.digits(i)
+	default:
+		return -1
+	}
+
+	if i < len(v.data) && v.data[i] == DECIMAL_POINT {
+		if i = v.digits(i + 1); i < 0 {
+			return -1
+		}
+	}
+
+	if i < len(v.data) && (v.data[i] == 'e' || v.data[i] == 'E') {
+		i++
+		if i < len(v.data) && (v.data[i] == PLUS || v.data[i] == MINUS) {
+			i++
+		}
+		if i = v.digits(i); i < 0 {
+			return -1
+		}
+	}
+	return i
+}
+
+// 至少一个数字
+func (v *validator) digits(i int) int {
+	start := i
+	for i < len(v.data) && isDigit(v.data[i]) {
+		i++
+	}
+	if i == start {
+		return -1
+	}
+	return i
+}
@@ -0,0 +1,117 @@
+package yjson
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{`1`, true},
+		{` {"a": [1, -2.5e+3, true, false, null, "x"]} `, true},
+		{`[]`, true},
+		{`{}`, true},
+		{`"\"\\\/\b\f\n\r\t\u00e9"`, true},
+		{`-0`, true},
+		{``, false},
+		{` `, false},
+		{`{`, false},
+		{`[1,]`, false},
+		{`{"a":1,}`, false},
+		{`{"a" 1}`, false},
+		{`{a: 1}`, false},
+		{`{1: 1}`, false},
+		{`[1 2]`, false},
+		{`01`, false},
+		{`1.`, false},
+		{`.5`, false},
+		{`+1`, false},
+		{`1e`, false},
+		{`-`, false},
+		{`NaN`, false},
+		{`tru`, false},
+		{`nul`, false},
+		{`truex`, false},
+		{`'a'`, false},
+		{`"a`, false},
+		{`"\x"`, false},
+		{`"\u12"`, false},
+		{`"\u12G4"`, false},
+		{"\"\t\"", false},
+		{`1 2`, false},
+		{`{} x`, false},
+		{`// c` + "\n1", false},
+		{`[1] /* c */`, false},
+	}
+	for _, tt := range tests {
+		if got := Valid([]byte(tt.input)); got != tt.want {
+			t.Errorf("%q: got %v, want %v", tt.input, got, tt.want)
+		}
+		if std := json.Valid([]byte(tt.input)); std != tt.want {
+			t.Errorf("%q: encoding/json gives %v", tt.input, std)
+		}
+		if _, err := ParseWithOptions([]byte(tt.input), ParseOptions{Strict: true}); (err == nil) != tt.want {
+			t.Errorf("%q: Strict parse gives %v", tt.input, err)
+		}
+	}
+}
+
+// 与 Strict 模式相同, 拒绝非法 UTF-8 和孤立的代理项
+// 不检查数字的范围, Strict 模式解析时超出 float64 范围的数字是错误
+func TestValidNumberRange(t *testing.T) {
+	for _, input := range []string{`1e999`, `[-1e400]`} {
+		if !Valid([]byte(input)) {
+			t.Errorf("%s: got false", input)
+		}
+		if _, err := ParseWithOptions([]byte(input), ParseOptions{Strict: true}); err == nil {
+			t.Errorf("%s: Strict parse succeeded", input)
+		}
+	}
+}
+
+func TestValidUnicode(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{`"é中😀"`, true},
+		{`"\ud83d\ude00"`, true},
+		{`"\ud83d"`, false},
+		{`"\ude00"`, false},
+		{`"\ud83dx"`, false},
+		{`"\ud83d\u0041"`, false},
+		{"\"\xff\"", false},
+		{"\"\xc3\"", false},
+		{"\"\xed\xa0\x80\"", false},
+	}
+	for _, tt := range tests {
+		if got := Valid([]byte(tt.input)); got != tt.want {
+			t.Errorf("%q: got %v, want %v", tt.input, got, tt.want)
+		}
+		if _, err := ParseWithOptions([]byte(tt.input), ParseOptions{Strict: true}); (err == nil) != tt.want {
+			t.Errorf("%q: Strict parse gives %v", tt.input, err)
+		}
+	}
+}
+
+func TestValidDepth(t *testing.T) {
+	ok := strings.Repeat("[", DEFAULT_MAX_DEPTH) + strings.Repeat("]", DEFAULT_MAX_DEPTH)
+	deep := strings.Repeat(`{"a":`, DEFAULT_MAX_DEPTH+1) + "1" + strings.Repeat("}", DEFAULT_MAX_DEPTH+1)
+	if !Valid([]byte(ok)) {
+		t.Errorf("max depth: got false")
+	}
+	if Valid([]byte(deep)) {
+		t.Errorf("over max depth: got true")
+	}
+}
+
+func TestValidDoesNotAllocate(t *testing.T) {
+	data := []byte(`{"a": [1, 2.5, "x\n", {"b": null}], "c": true}`)
+	if allocs := testing.AllocsPerRun(100, func() { Valid(data) }); allocs != 0 {
+		t.Errorf("got %v allocs", allocs)
+	}
+}
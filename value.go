@@ -0,0 +1,395 @@
+package yjson
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+type Kind int
+
+const (
+	JSON_MISSING Kind = iota - 1 // 不存在的值, 如路径查找失败
+	JSON_NUMBER
+	JSON_STRING
+	JSON_BOOLEAN
+	JSON_OBJECT
+	JSON_ARRAY
+	JSON_NULL
+)
+
+func (k Kind) String() string {
+	switch k {
+	case JSON_MISSING:
+		return "missing"
+	case JSON_NUMBER:
+		return "number"
+	case JSON_STRING:
+		return "string"
+	case JSON_BOOLEAN:
+		return "boolean"
+	case JSON_OBJECT:
+		return "object"
+	case JSON_ARRAY:
+		return "array"
+	case JSON_NULL:
+		return "null"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// JSON_NUMBER 的子类型, 区分 1 和 1.0
+type NumberKind int
+
+const (
+	NUMBER_INT NumberKind = iota + 1
+	NUMBER_FLOAT
+)
+
+// 数字实际存放在哪个字段
+type numberRepr uint8
+
+const (
+	REPR_INT   numberRepr = iota // i
+	REPR_UINT                    // i, 按位存放 uint64
+	REPR_FLOAT                   // f
+	REPR_OTHER                   // num
+)
+
+// 每种类型使用各自的字段, 不把标量装箱到 interface{} 中
+type Value struct {
+	valueType  Kind
+	numberType NumberKind
+	repr       numberRepr
+	b          bool
+	i          int64
+	f          float64
+	str        string
+	arr        []*Value
+	obj        map[string]*Value
+	num        interface{} // Number, *big.Int, *big.Float 或 NumberHook 的返回值
+	raw        []byte
+	keys       []string // 对象的键, 按源文本或插入顺序
+
+	lazy    *ParseOptions // 不为 nil 时是 Lazy 模式下还没有展开的对象或数组, 源文本在 raw 中
+	lazyErr error         // 展开失败时的错误, 之后的访问直接返回它而不是重新解析
+}
+
+// 旧名字, 保留兼容
+type JsonValue = Value
+
+// nil 也视为 JSON_MISSING
+func (j *Value) Type() Kind {
+	if j == nil {
+		return JSON_MISSING
+	}
+	return j.valueType
+}
+
+func (j *Value) Exists() bool {
+	return j.Type() != JSON_MISSING
+}
+
+func (j *Value) IsNull() bool {
+	return j.Type() == JSON_NULL
+}
+
+// 数字的子类型, 非数字返回 0
+func (j *Value) NumberType() NumberKind {
+	if j == nil {
+		return 0
+	}
+	return j.numberType
+}
+
+// 底层的 Go 值, 对象的 map 不保留键的顺序, 需要顺序时使用 Keys 或 Range.
+// 数字为 int64, uint64, float64, Number, *big.Int, *big.Float
+// 或 NumberHook 的返回值, 字符串为 string, 布尔为 bool, 对象为
+// map[string]*Value, 数组为 []*Value, null 为 nil.
+// Lazy 模式下展开失败的对象和数组返回 nil, 错误由 Map, Array 或 Expand 返回
+func (j *Value) Interface() interface{} {
+	switch j.Type() {
+	case JSON_STRING:
+		return j.str
+	case JSON_BOOLEAN:
+		return j.b
+	case JSON_NUMBER:
+		return j.number()
+	case JSON_OBJECT:
+		if j.load() != nil {
+			return nil
+		}
+		return j.obj
+	case JSON_ARRAY:
+		if j.load() != nil {
+			return nil
+		}
+		return j.arr
+	}
+	return nil
+}
+
+// 装箱后的数字, 只在需要 interface{} 时使用
+func (j *Value) number() interface{} {
+	switch j.repr {
+	case REPR_INT:
+		return j.i
+	case REPR_UINT:
+		return uint64(j.i)
+	case REPR_FLOAT:
+		return j.f
+	}
+	return j.num
+}
+
+// 以下设置数字的方法都不修改 numberType, 由调用方决定
+func (j *Value) setInt(i int64) {
+	j.valueType, j.repr, j.i = JSON_NUMBER, REPR_INT, i
+}
+
+func (j *Value) setUint(u uint64) {
+	j.valueType, j.repr, j.i = JSON_NUMBER, REPR_UINT, int64(u)
+}
+
+func (j *Value) setFloat(f float64) {
+	j.valueType, j.repr, j.f = JSON_NUMBER, REPR_FLOAT, f
+}
+
+func (j *Value) setNumber(n interface{}) {
+	j.valueType = JSON_NUMBER
+	switch n := n.(type) {
+	case int64:
+		j.repr, j.i = REPR_INT, n
+	case uint64:
+		j.repr, j.i = REPR_UINT, int64(n)
+	case float64:
+		j.repr, j.f = REPR_FLOAT, n
+	default:
+		j.repr, j.num = REPR_OTHER, n
+	}
+}
+
+// 标量在源文本中的原始字节 (字符串包含引号), 与输入共享内存, 不要修改;
+// 对象和数组返回 nil, Lazy 模式下还没有展开的对象和数组返回其源文本
+func (j *Value) Raw() []byte {
+	if j == nil {
+		return nil
+	}
+	return j.raw
+}
+
+func (j *Value) expectKind(k Kind) error {
+	if t := j.Type(); t != k {
+		return fmt.Errorf("expect %s, but get %s", k, t)
+	}
+	return nil
+}
+
+func (j *Value) String() (string, error) {
+	if err := j.expectKind(JSON_STRING); err != nil {
+		return "", err
+	}
+	return j.str, nil
+}
+
+func (j *Value) Bool() (bool, error) {
+	if err := j.expectKind(JSON_BOOLEAN); err != nil {
+		return false, err
+	}
+	return j.b, nil
+}
+
+func (j *Value) Array() ([]*Value, error) {
+	if err := j.expectKind(JSON_ARRAY); err != nil {
+		return nil, err
+	}
+	if err := j.load(); err != nil {
+		return nil, err
+	}
+	return j.arr, nil
+}
+
+// 返回对象底层的 map, 增删成员请使用 Set/Delete, 否则 Keys 的顺序不会更新
+func (j *Value) Map() (map[string]*Value, error) {
+	if err := j.expectKind(JSON_OBJECT); err != nil {
+		return nil, err
+	}
+	if err := j.load(); err != nil {
+		return nil, err
+	}
+	return j.obj, nil
+}
+
+func (j *Value) Int64() (int64, error) {
+	if err := j.expectKind(JSON_NUMBER); err != nil {
+		return 0, err
+	}
+
+	switch j.repr {
+	case REPR_INT:
+		return j.i, nil
+	case REPR_UINT:
+		if j.i >= 0 {
+			return j.i, nil
+		}
+	case REPR_FLOAT:
+		n := j.f
+		if n == math.Trunc(n) && n >= math.MinInt64 && n < math.MaxInt64 {
+			return int64(n), nil
+		}
+		if n != math.Trunc(n) {
+			return 0, fmt.Errorf("number %v is not an integer", n)
+		}
+	default:
+		switch n := j.num.(type) {
+		case Number:
+			return n.Int64()
+		case *big.Int:
+			if n.IsInt64() {
+				return n.Int64(), nil
+			}
+		case *big.Float:
+			if !n.IsInt() {
+				return 0, fmt.Errorf("number %v is not an integer", n)
+			}
+			if i, acc := n.Int64(); acc == big.Exact {
+				return i, nil
+			}
+		default:
+			return 0, fmt.Errorf("unsupported number type %T", n)
+		}
+	}
+	return 0, fmt.Errorf("number %v overflows int64", j.number())
+}
+
+func (j *Value) Uint64() (uint64, error) {
+	if err := j.expectKind(JSON_NUMBER); err != nil {
+		return 0, err
+	}
+
+	switch j.repr {
+	case REPR_INT:
+		if j.i >= 0 {
+			return uint64(j.i), nil
+		}
+	case REPR_UINT:
+		return uint64(j.i), nil
+	case REPR_FLOAT:
+		n := j.f
+		if n != math.Trunc(n) {
+			return 0, fmt.Errorf("number %v is not an integer", n)
+		}
+		if n >= 0 && n < math.MaxUint64 {
+			return uint64(n), nil
+		}
+	default:
+		switch n := j.num.(type) {
+		case Number:
+			return n.Uint64()
+		case *big.Int:
+			if n.IsUint64() {
+				return n.Uint64(), nil
+			}
+		case *big.Float:
+			if !n.IsInt() {
+				return 0, fmt.Errorf("number %v is not an integer", n)
+			}
+			if u, acc := n.Uint64(); acc == big.Exact {
+				return u, nil
+			}
+		default:
+			return 0, fmt.Errorf("unsupported number type %T", n)
+		}
+	}
+	return 0, fmt.Errorf("number %v overflows uint64", j.number())
+}
+
+func (j *Value) Float64() (float64, error) {
+	if err := j.expectKind(JSON_NUMBER); err != nil {
+		return 0, err
+	}
+
+	switch j.repr {
+	case REPR_INT:
+		return float64(j.i), nil
+	case REPR_UINT:
+		return float64(uint64(j.i)), nil
+	case REPR_FLOAT:
+		return j.f, nil
+	}
+
+	switch n := j.num.(type) {
+	case Number:
+		return n.Float64()
+	case *big.Int:
+		f, _ := new(big.Float).SetInt(n).Float64()
+		if math.IsInf(f, 0) {
+			return f, fmt.Errorf("number %v overflows float64", n)
+		}
+		return f, nil
+	case *big.Float:
+		f, _ := n.Float64()
+		if math.IsInf(f, 0) && !n.IsInf() {
+			return f, fmt.Errorf("number %v overflows float64", n)
+		}
+		return f, nil
+	}
+	return 0, fmt.Errorf("unsupported number type %T", j.num)
+}
+
+func NewNull() *Value {
+	return &Value{valueType: JSON_NULL}
+}
+
+func NewBool(b bool) *Value {
+	return &Value{valueType: JSON_BOOLEAN, b: b}
+}
+
+func NewInt(i int64) *Value {
+	return &Value{valueType: JSON_NUMBER, numberType: NUMBER_INT, repr: REPR_INT, i: i}
+}
+
+func NewUint(u uint64) *Value {
+	return &Value{valueType: JSON_NUMBER, numberType: NUMBER_INT, repr: REPR_UINT, i: int64(u)}
+}
+
+func NewFloat(f float64) *Value {
+	return &Value{valueType: JSON_NUMBER, numberType: NUMBER_FLOAT, repr: REPR_FLOAT, f: f}
+}
+
+func NewString(s string) *Value {
+	return &Value{valueType: JSON_STRING, str: s}
+}
+
+func NewArray(items ...*Value) *Value {
+	arr := make([]*Value, 0, len(items))
+	arr = append(arr, items...)
+	return &Value{valueType: JSON_ARRAY, arr: arr}
+}
+
+func NewObject() *Value {
+	return &Value{valueType: JSON_OBJECT, obj: make(map[string]*Value), keys: make([]string, 0)}
+}
+
+// 深拷贝 j, 修改结果不会影响 j. Lazy 模式下还没有展开的子树只拷贝引用的源文本,
+// 之后各自展开
+func copyValue(j *Value) *Value {
+	c := *j
+	if c.lazy != nil {
+		return &c
+	}
+	switch c.valueType {
+	case JSON_ARRAY:
+		c.arr = make([]*Value, len(j.arr))
+		for i, v := range j.arr {
+			c.arr[i] = copyValue(v)
+		}
+	case JSON_OBJECT:
+		c.obj = make(map[string]*Value, len(j.obj))
+		for k, v := range j.obj {
+			c.obj[k] = copyValue(v)
+		}
+		c.keys = append(make([]string, 0, len(j.keys)), j.keys...)
+	}
+	return &c
+}
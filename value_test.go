@@ -0,0 +1,240 @@
+package yjson
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRaw(t *testing.T) {
+	input := `{"n": -1.50E+3, "big": 123456789012345678901234567890, "s": "a\"b", "t": true, "f": false, "z": null, "a": [ 1 ], "o": {}}`
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"n", `-1.50E+3`},
+		{"big", `123456789012345678901234567890`},
+		{"s", `"a\"b"`},
+		{"t", `true`},
+		{"f", `false`},
+		{"z", `null`},
+		{"a", ``},
+		{"o", ``},
+	}
+	for _, opts := range []ParseOptions{{}, {UseRawNumbers: true}, {UseBigNumbers: true}} {
+		v, err := ParseWithOptions([]byte(input), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tt := range tests {
+			if got := string(v.Get(tt.path).Raw()); got != tt.want {
+				t.Errorf("%+v %s: got %q, want %q", opts, tt.path, got, tt.want)
+			}
+		}
+	}
+
+	// Lazy 模式下还没有展开的容器返回源文本, 展开后返回 nil
+	v, err := ParseWithOptions([]byte(`{"a": [ 1.0 , 2 ]}`), ParseOptions{Lazy: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := v.Get("a")
+	if got := string(a.Raw()); got != `[ 1.0 , 2 ]` {
+		t.Errorf("lazy array: got %q", got)
+	}
+	if got := string(v.Get("a[0]").Raw()); got != `1.0` {
+		t.Errorf("lazy element: got %q", got)
+	}
+	if a.Raw() != nil {
+		t.Errorf("expanded array: got %q", a.Raw())
+	}
+
+	// 不是解析得到的值没有原文
+	if NewInt(1).Raw() != nil || NewString("x").Raw() != nil {
+		t.Errorf("built values should have no raw text")
+	}
+	var missing *Value
+	if missing.Raw() != nil {
+		t.Errorf("nil value should have no raw text")
+	}
+}
+
+func TestAccessors(t *testing.T) {
+	call := func(v *Value, accessor string) (interface{}, error) {
+		switch accessor {
+		case "String":
+			return v.String()
+		case "Bool":
+			return v.Bool()
+		case "Int64":
+			return v.Int64()
+		case "Uint64":
+			return v.Uint64()
+		case "Float64":
+			return v.Float64()
+		case "Array":
+			arr, err := v.Array()
+			return len(arr), err
+		case "Map":
+			m, err := v.Map()
+			return len(m), err
+		}
+		panic(accessor)
+	}
+	tests := []struct {
+		input    string
+		accessor string
+		want     interface{}
+		err      string
+	}{
+		{`"x"`, "String", "x", ""},
+		{`1`, "String", "", "expect string, but get number"},
+		{`true`, "Bool", true, ""},
+		{`null`, "Bool", false, "expect boolean, but get null"},
+		{`-42`, "Int64", int64(-42), ""},
+		{`4e2`, "Int64", int64(400), ""},
+		{`1.5`, "Int64", int64(0), "number 1.5 is not an integer"},
+		{`9223372036854775808`, "Int64", int64(0), "number 9223372036854775808 overflows int64"},
+		{`"1"`, "Int64", int64(0), "expect number, but get string"},
+		{`18446744073709551615`, "Uint64", uint64(18446744073709551615), ""},
+		{`-1`, "Uint64", uint64(0), "number -1 overflows uint64"},
+		{`3`, "Float64", 3.0, ""},
+		{`-0.25`, "Float64", -0.25, ""},
+		{`[]`, "Float64", 0.0, "expect number, but get array"},
+		{`[1,2,3]`, "Array", 3, ""},
+		{`{}`, "Array", 0, "expect array, but get object"},
+		{`{"a":1,"b":2}`, "Map", 2, ""},
+		{`[]`, "Map", 0, "expect object, but get array"},
+	}
+	for _, tt := range tests {
+		got, err := call(mustParse(t, tt.input), tt.accessor)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("%s.%s: got error %v, want %s", tt.input, tt.accessor, err, tt.err)
+			}
+		} else if err != nil {
+			t.Errorf("%s.%s: %v", tt.input, tt.accessor, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s.%s: got %#v, want %#v", tt.input, tt.accessor, got, tt.want)
+		}
+	}
+
+	// 不存在的值和 nil 也可以安全调用
+	var missing *Value
+	if _, err := missing.String(); err == nil || err.Error() != "expect string, but get missing" {
+		t.Errorf("nil: got %v", err)
+	}
+	if _, err := mustParse(t, `{}`).Get("a").Int64(); err == nil || err.Error() != "expect number, but get missing" {
+		t.Errorf("missing: got %v", err)
+	}
+}
+
+func TestKind(t *testing.T) {
+	v := mustParse(t, `{"n":1,"s":"","b":false,"o":{},"a":[],"z":null}`)
+	tests := []struct {
+		path   string
+		kind   Kind
+		name   string
+		exists bool
+		null   bool
+	}{
+		{"n", JSON_NUMBER, "number", true, false},
+		{"s", JSON_STRING, "string", true, false},
+		{"b", JSON_BOOLEAN, "boolean", true, false},
+		{"o", JSON_OBJECT, "object", true, false},
+		{"a", JSON_ARRAY, "array", true, false},
+		{"z", JSON_NULL, "null", true, true},
+		{"nope", JSON_MISSING, "missing", false, false},
+		{"z.deeper", JSON_MISSING, "missing", false, false},
+		{"a[0]", JSON_MISSING, "missing", false, false},
+	}
+	for _, tt := range tests {
+		got := v.Get(tt.path)
+		if got.Type() != tt.kind || got.Type().String() != tt.name {
+			t.Errorf("%s: got kind %s, want %s", tt.path, got.Type(), tt.name)
+		}
+		if got.Exists() != tt.exists || got.IsNull() != tt.null {
+			t.Errorf("%s: Exists %v IsNull %v", tt.path, got.Exists(), got.IsNull())
+		}
+	}
+
+	var missing *Value
+	if missing.Exists() || missing.IsNull() || missing.Type() != JSON_MISSING {
+		t.Errorf("nil value should be missing")
+	}
+	if got := Kind(42).String(); got != "Kind(42)" {
+		t.Errorf("unknown kind: got %s", got)
+	}
+}
+
+// 数字按大小和选项存放在不同的字段中, 各个访问器对每种存放方式给出相同的结果
+func TestNumberRepr(t *testing.T) {
+	tests := []struct {
+		input   string
+		opts    ParseOptions
+		iface   string // Interface 的类型
+		int64   string // 值或错误信息
+		uint64  string
+		float64 string
+	}{
+		{`-7`, ParseOptions{}, "int64", "-7", "error", "-7"},
+		{`7`, ParseOptions{}, "int64", "7", "7", "7"},
+		{`18446744073709551615`, ParseOptions{}, "uint64", "error", "18446744073709551615", "1.8446744073709552e+19"},
+		{`2.5`, ParseOptions{}, "float64", "error", "error", "2.5"},
+		{`1e300`, ParseOptions{}, "float64", "error", "error", "1e+300"},
+		{`12`, ParseOptions{UseRawNumbers: true}, "yjson.Number", "12", "12", "12"},
+		{`-3`, ParseOptions{UseRawNumbers: true}, "yjson.Number", "-3", "error", "-3"},
+		{`123456789012345678901234567890`, ParseOptions{UseBigNumbers: true}, "*big.Int", "error", "error", "1.2345678901234568e+29"},
+		{`0.1000000000000000000000001`, ParseOptions{UseBigNumbers: true}, "*big.Float", "error", "error", "0.1"},
+	}
+	show := func(v interface{}, err error) string {
+		if err != nil {
+			return "error"
+		}
+		return fmt.Sprint(v)
+	}
+	for _, tt := range tests {
+		v, err := ParseWithOptions([]byte(tt.input), tt.opts)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.input, err)
+		}
+		if got := fmt.Sprintf("%T", v.Interface()); got != tt.iface {
+			t.Errorf("%s: Interface is %s, want %s", tt.input, got, tt.iface)
+		}
+		if got := show(v.Int64()); got != tt.int64 {
+			t.Errorf("%s: Int64 %s, want %s", tt.input, got, tt.int64)
+		}
+		if got := show(v.Uint64()); got != tt.uint64 {
+			t.Errorf("%s: Uint64 %s, want %s", tt.input, got, tt.uint64)
+		}
+		if got := show(v.Float64()); got != tt.float64 {
+			t.Errorf("%s: Float64 %s, want %s", tt.input, got, tt.float64)
+		}
+	}
+
+	// 构造函数和解析得到相同的结果
+	built := []*Value{NewInt(-7), NewUint(18446744073709551615), NewFloat(2.5)}
+	for i, input := range []string{`-7`, `18446744073709551615`, `2.5`} {
+		if !Equal(built[i], mustParse(t, input)) || built[i].Interface() != mustParse(t, input).Interface() {
+			t.Errorf("%s: built value differs", input)
+		}
+	}
+}
+
+// 标量的访问器不装箱, 不分配内存
+func TestAccessorsDoNotAllocate(t *testing.T) {
+	v := mustParse(t, `{"s": "str", "b": true, "i": 42, "u": 18446744073709551615, "f": 1.5}`)
+	s, b, i, u, f := v.Get("s"), v.Get("b"), v.Get("i"), v.Get("u"), v.Get("f")
+	allocs := testing.AllocsPerRun(100, func() {
+		s.String()
+		b.Bool()
+		i.Int64()
+		u.Uint64()
+		f.Float64()
+		i.Float64()
+		s.Type()
+	})
+	if allocs != 0 {
+		t.Errorf("got %v allocs", allocs)
+	}
+}
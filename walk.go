@@ -0,0 +1,99 @@
+package yjson
+
+import (
+	"strconv"
+	"strings"
+)
+
+// 拼接 Get 能识别的路径, 含有特殊字符的键, 键 * 和开头的键 $ 使用 ["key"] 形式
+func joinKeyPath(path, key string) string {
+	if key == "" || key == "*" || (path == "" && key == "$") || strings.ContainsAny(key, ".[]\\\"'") {
+		return path + "[" + strconv.Quote(key) + "]"
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func joinIndexPath(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
+
+// 深度优先遍历, 先访问节点本身再访问子节点, 对象按键的顺序.
+// fn 返回 false 时跳过该节点的子节点. 根节点的路径为空字符串
+func (j *Value) Walk(fn func(path string, v *Value) bool) {
+	j.walk("", fn)
+}
+
+func (j *Value) walk(path string, fn func(path string, v *Value) bool) {
+	if !fn(path, j) {
+		return
+	}
+
+	switch j.Type() {
+	case JSON_OBJECT:
+		j.Range(func(key string, v *Value) bool {
+			v.walk(joinKeyPath(path, key), fn)
+			return true
+		})
+	case JSON_ARRAY:
+		arr, _ := j.Array()
+		for i, v := range arr {
+			v.walk(joinIndexPath(path, i), fn)
+		}
+	}
+}
+
+// Find 和 FindAll 找到的节点, Path 可以直接传给 Get
+type Match struct {
+	Path  string
+	Value *Value
+}
+
+// 按 Walk 的顺序返回所有满足 pred 的节点, 包括根节点. 满足条件的节点的子节点
+// 仍会继续检查. 没有匹配时返回空切片
+func (j *Value) FindAll(pred func(path string, v *Value) bool) []Match {
+	matches := make([]Match, 0)
+	j.find("", pred, func(m Match) bool {
+		matches = append(matches, m)
+		return true
+	})
+	return matches
+}
+
+// 按 Walk 的顺序返回第一个满足 pred 的节点, 找到后立即停止遍历
+func (j *Value) Find(pred func(path string, v *Value) bool) (Match, bool) {
+	var found Match
+	ok := false
+	j.find("", pred, func(m Match) bool {
+		found, ok = m, true
+		return false
+	})
+	return found, ok
+}
+
+// emit 返回 false 时停止遍历, 此时 find 也返回 false
+func (j *Value) find(path string, pred func(path string, v *Value) bool, emit func(m Match) bool) bool {
+	if pred(path, j) && !emit(Match{Path: path, Value: j}) {
+		return false
+	}
+
+	switch j.Type() {
+	case JSON_OBJECT:
+		next := true
+		j.Range(func(key string, v *Value) bool {
+			next = v.find(joinKeyPath(path, key), pred, emit)
+			return next
+		})
+		return next
+	case JSON_ARRAY:
+		arr, _ := j.Array()
+		for i, v := range arr {
+			if !v.find(joinIndexPath(path, i), pred, emit) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+package yjson
+
+import (
+	"reflect"
+	"testing"
+)
+
+const walkDoc = `{"a":{"b":[1,{"c":2}]},"x.y":3,"":4,"*":5,"q\"":6,"secret":{"k":7}}`
+
+func TestWalk(t *testing.T) {
+	v := mustParse(t, walkDoc)
+	var paths []string
+	v.Walk(func(path string, node *Value) bool {
+		paths = append(paths, path)
+		if got := v.Get(path); got != node {
+			t.Errorf("Get(%s) does not return the visited node", path)
+		}
+		return path != "secret"
+	})
+	want := []string{
+		"",
+		"a",
+		"a.b",
+		"a.b[0]",
+		"a.b[1]",
+		"a.b[1].c",
+		`["x.y"]`,
+		`[""]`,
+		`["*"]`,
+		`["q\""]`,
+		"secret",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("got %q\nwant %q", paths, want)
+	}
+
+	// 根节点返回 false 时只访问根节点
+	calls := 0
+	v.Walk(func(string, *Value) bool { calls++; return false })
+	if calls != 1 {
+		t.Errorf("pruned root: %d calls", calls)
+	}
+
+	// 标量根节点和 Lazy 模式
+	calls = 0
+	mustParse(t, `1`).Walk(func(string, *Value) bool { calls++; return true })
+	if calls != 1 {
+		t.Errorf("scalar root: %d calls", calls)
+	}
+	lazy, err := ParseWithOptions([]byte(walkDoc), ParseOptions{Lazy: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lazyPaths []string
+	lazy.Walk(func(path string, _ *Value) bool {
+		lazyPaths = append(lazyPaths, path)
+		return path != "secret"
+	})
+	if !reflect.DeepEqual(lazyPaths, want) {
+		t.Errorf("lazy: got %q", lazyPaths)
+	}
+}
+
+func TestJoinKeyPath(t *testing.T) {
+	tests := []struct {
+		path, key, want string
+	}{
+		{"", "a", "a"},
+		{"a", "b", "a.b"},
+		{"", "$", `["$"]`},
+		{"a", "$", "a.$"},
+		{"a", "b.c", `a["b.c"]`},
+		{"a", "[0]", `a["[0]"]`},
+		{"a", `x\y`, `a["x\\y"]`},
+		{"a", "it's", `a["it's"]`},
+	}
+	for _, tt := range tests {
+		if got := joinKeyPath(tt.path, tt.key); got != tt.want {
+			t.Errorf("joinKeyPath(%q, %q) = %s, want %s", tt.path, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestFindAll(t *testing.T) {
+	v := mustParse(t, walkDoc)
+	isNumber := func(_ string, n *Value) bool { return n.Type() == JSON_NUMBER }
+	tests := []struct {
+		name string
+		pred func(path string, v *Value) bool
+		want []string
+	}{
+		{"numbers", isNumber, []string{"a.b[0]", "a.b[1].c", `["x.y"]`, `[""]`, `["*"]`, `["q\""]`, "secret.k"}},
+		{"root", func(path string, _ *Value) bool { return path == "" }, []string{""}},
+		// 匹配的节点的子节点继续检查
+		{"objects", func(_ string, n *Value) bool { return n.Type() == JSON_OBJECT }, []string{"", "a", "a.b[1]", "secret"}},
+		{"by path", func(path string, _ *Value) bool { return path == "secret.k" }, []string{"secret.k"}},
+		{"none", func(string, *Value) bool { return false }, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := v.FindAll(tt.pred)
+			paths := []string{}
+			for _, m := range matches {
+				paths = append(paths, m.Path)
+				if v.Get(m.Path) != m.Value {
+					t.Errorf("Get(%s) does not return the matched value", m.Path)
+				}
+			}
+			if matches == nil || !reflect.DeepEqual(paths, tt.want) {
+				t.Errorf("got %q, want %q", paths, tt.want)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	v := mustParse(t, walkDoc)
+	var visited []string
+	m, ok := v.Find(func(path string, n *Value) bool {
+		visited = append(visited, path)
+		return n.Type() == JSON_NUMBER
+	})
+	if !ok || m.Path != "a.b[0]" || m.Value != v.Get("a.b[0]") {
+		t.Errorf("got %+v, %v", m, ok)
+	}
+	// 找到后不再访问其他节点
+	if want := []string{"", "a", "a.b", "a.b[0]"}; !reflect.DeepEqual(visited, want) {
+		t.Errorf("visited %q, want %q", visited, want)
+	}
+
+	found, ok := v.Find(func(_ string, n *Value) bool {
+		s, _ := n.String()
+		return s == "missing"
+	})
+	if ok || found != (Match{}) {
+		t.Errorf("no match: got %+v, %v", found, ok)
+	}
+
+	// 在对象内部找到时也立即停止
+	visited = visited[:0]
+	if m, ok := v.Find(func(path string, n *Value) bool {
+		visited = append(visited, path)
+		return path == "a.b[1].c"
+	}); !ok || m.Path != "a.b[1].c" || len(visited) != 6 {
+		t.Errorf("got %+v, %v after visiting %q", m, ok, visited)
+	}
+}